@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/cfioretti/recipe-manager/configs"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/application"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/interfaces/api/http/dto"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/interfaces/card"
+)
+
+const cardUsage = `Usage: recipe-manager card -pans PANS [-format FORMAT] [-o FILE] [-demo [-fixtures DIR]] UUID
+
+Prints the prep sheet of a recipe balanced for the pans, as served by
+GET /recipes/:uuid/card: the dough and topping quantities of each pan and the
+numbered steps. The recipe is read from the database, or from the fixtures
+with -demo, and balanced by the calculator and balancer services configured.
+
+Flags:
+`
+
+func runCardCommand(args []string) error {
+	flags := flag.NewFlagSet("card", flag.ContinueOnError)
+	pansSpec := flags.String("pans", "", "pans as shape:measures in centimetres, such as round:30,rectangular:30x40/8")
+	format := flags.String("format", card.FormatMarkdown, "format of the card: "+strings.Join(card.Formats, ", "))
+	output := flags.String("o", "", "file to write the card to, instead of the standard output")
+	demo := flags.Bool("demo", false, "read the recipe from fixtures instead of the database")
+	fixtures := flags.String("fixtures", "", "directory of YAML or JSON recipe fixtures for -demo, instead of the bundled ones")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), cardUsage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("expected the UUID of one recipe")
+	}
+	recipeUuid, err := uuid.Parse(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid recipe UUID %q", flags.Arg(0))
+	}
+	pans, err := dto.ParsePans(*pansSpec)
+	if err != nil {
+		return fmt.Errorf("invalid -pans: %w", err)
+	}
+	if !slices.Contains(card.Formats, *format) {
+		return fmt.Errorf("-format must be one of %s, got %q", strings.Join(card.Formats, ", "), *format)
+	}
+	if *fixtures != "" && !*demo {
+		return errors.New("-fixtures needs -demo")
+	}
+	if *output == "" {
+		// The card goes to the standard output, the logs must not.
+		logger.SetOutput(os.Stderr)
+	}
+
+	config, err := configs.Load(serviceName, version)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	setLogLevel(config.Logging.Level)
+
+	var repository application.RecipeRepository
+	if *demo {
+		if repository, err = loadDemoRepository(*fixtures); err != nil {
+			return fmt.Errorf("failed to load demo fixtures: %w", err)
+		}
+	} else {
+		db, err := loadDBConfig(config.Database)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		if repository, err = newRecipeRepository(config.Database, db); err != nil {
+			return err
+		}
+	}
+	calculatorService, err := initializeCalculatorService(config.Calculator)
+	if err != nil {
+		return err
+	}
+	balancerService, err := initializeBalancerService(config.Balancer)
+	if err != nil {
+		return err
+	}
+
+	var serviceOptions []application.Option
+	if config.Recipes.Rounding.Enabled {
+		serviceOptions = append(serviceOptions, application.WithRounding(config.Recipes.Rounding.Policy()))
+	}
+	aggregate, err := application.NewRecipeService(repository, calculatorService, balancerService, serviceOptions...).
+		Handle(context.Background(), recipeUuid, pans)
+	if err != nil {
+		return fmt.Errorf("failed to aggregate recipe %s: %w", recipeUuid, err)
+	}
+	var rendered bytes.Buffer
+	if err := card.Render(&rendered, *format, *aggregate); err != nil {
+		return fmt.Errorf("failed to render the card: %w", err)
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(rendered.Bytes())
+		return err
+	}
+	if err := os.WriteFile(*output, rendered.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write the card: %w", err)
+	}
+	logger.WithField("file", *output).Info("Recipe card written")
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/catalogue"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/memory"
+	embedded "github.com/cfioretti/recipe-manager/migrations"
+)
+
+type serveOptions struct {
+	demo     bool
+	fixtures string
+}
+
+func parseServeFlags(args []string) (serveOptions, error) {
+	var options serveOptions
+	flags := flag.NewFlagSet(serviceName, flag.ContinueOnError)
+	flags.BoolVar(&options.demo, "demo", false, "serve recipes from fixtures kept in memory, without a database")
+	flags.StringVar(&options.fixtures, "fixtures", "", "directory of YAML or JSON recipe fixtures for -demo, instead of the bundled ones")
+	if err := flags.Parse(args); err != nil {
+		return options, err
+	}
+	if flags.NArg() > 0 {
+		return options, fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+	if options.fixtures != "" && !options.demo {
+		return options, errors.New("-fixtures needs -demo")
+	}
+	return options, nil
+}
+
+// loadDemoRepository keeps the recipe fixtures in memory, linked to the
+// bundled ingredient catalogue. Changes are lost on restart.
+func loadDemoRepository(dir string) (*memory.RecipeRepository, error) {
+	fixtures := embedded.Fixtures()
+	if dir != "" {
+		fixtures = os.DirFS(dir)
+	}
+	recipes, err := memory.LoadFixtures(fixtures)
+	if err != nil {
+		return nil, err
+	}
+	ingredients, err := catalogue.Parse(embedded.Catalogue())
+	if err != nil {
+		return nil, err
+	}
+
+	repository := memory.NewRecipeRepository(memory.WithCatalogue(ingredients))
+	for _, recipe := range recipes {
+		repository.Save(recipe)
+	}
+	logger.WithField("recipes", len(recipes)).Warn("Demo mode: recipes are served from memory, without a database")
+	return repository, nil
+}
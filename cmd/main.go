@@ -8,24 +8,34 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/sdk/resource"
+	_ "modernc.org/sqlite"
 
 	"github.com/cfioretti/recipe-manager/configs"
 	"github.com/cfioretti/recipe-manager/internal/recipe-manager/application"
+	domainMetrics "github.com/cfioretti/recipe-manager/internal/recipe-manager/domain/metrics"
 	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/grpc/client"
 	httpHandlers "github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/http"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/http/envelope"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/http/middleware"
 	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/logging"
-	prometheusMetrics "github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/metrics"
-	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/mysql"
+	infraMetrics "github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/metrics"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/sqlstore"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/telemetry"
 	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/tracing"
+	apigraphql "github.com/cfioretti/recipe-manager/internal/recipe-manager/interfaces/api/graphql"
 	apihttp "github.com/cfioretti/recipe-manager/internal/recipe-manager/interfaces/api/http"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/interfaces/api/openapi"
 )
 
 const (
@@ -38,10 +48,44 @@ var logger *logging.Logger
 func main() {
 	logger = logging.NewLogger(serviceName, version)
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrateCommand(os.Args[2:]); err != nil {
+			logger.WithError(err).Fatal("Migration failed")
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "purge" {
+		if err := runPurgeCommand(os.Args[2:]); err != nil {
+			logger.WithError(err).Fatal("Purge failed")
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "card" {
+		if err := runCardCommand(os.Args[2:]); err != nil {
+			logger.WithError(err).Fatal("Printing the recipe card failed")
+		}
+		return
+	}
+
+	options, err := parseServeFlags(os.Args[1:])
+	if err != nil {
+		logger.WithError(err).Fatal("Invalid arguments")
+	}
+
 	ctx := context.Background()
 	logger.WithContext(ctx).Info("Starting recipe-manager service", logging.ServiceNameKey, serviceName)
 
-	if err := tracing.InitTracing(nil); err != nil {
+	config, err := configs.Load(serviceName, version)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to load configuration")
+	}
+	logger.WithField("config", viper.ConfigFileUsed()).Info("Configuration loaded")
+	setLogLevel(config.Logging.Level)
+	if config.AdminToken == "" && !options.demo {
+		logger.Fatal("admin.token must be set outside demo mode")
+	}
+
+	if err := tracing.InitTracing(config.Tracing); err != nil {
 		logger.WithError(err).Fatal("Failed to initialize tracing")
 	}
 	defer func() {
@@ -52,50 +96,172 @@ func main() {
 		}
 	}()
 
-	if err := setEnvConfigs(); err != nil {
-		logger.WithError(err).Fatal("Failed to load configuration")
+	var db *sql.DB
+	var repository application.RecipeRepository
+	serviceOptions := []application.Option{application.WithLogger(logger.WithField("component", "recipe-service"))}
+	var webhooks application.WebhookRepository
+	if options.demo {
+		repository, err = loadDemoRepository(options.fixtures)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to load demo fixtures")
+		}
+	} else {
+		db, err = loadDBConfig(config.Database)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to connect to database")
+		}
+		defer func() {
+			if err := db.Close(); err != nil {
+				logger.WithError(err).Error("Failed to close database connection")
+			}
+		}()
+
+		if config.Database.Migrations.Auto {
+			if err := autoMigrate(config.Database); err != nil {
+				logger.WithError(err).Fatal("Failed to apply migrations")
+			}
+		}
+
+		repository, err = newRecipeRepository(config.Database, db)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize recipe repository")
+		}
+
+		dialect, err := sqlstore.DialectFor(config.Database.Driver)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize webhook subscriptions")
+		}
+		webhooks = sqlstore.NewWebhookStore(db, dialect)
+
+		if config.Outbox.Enabled {
+			store, workers, closeSink, err := newOutbox(config, db)
+			if err != nil {
+				logger.WithError(err).Fatal("Failed to initialize outbox")
+			}
+			defer func() {
+				if err := closeSink(); err != nil {
+					logger.WithError(err).Error("Failed to close outbox sink")
+				}
+			}()
+			serviceOptions = append(serviceOptions, application.WithOutbox(store))
+
+			workersCtx, stopWorkers := context.WithCancel(ctx)
+			defer stopWorkers()
+			for _, run := range workers {
+				go run(workersCtx)
+			}
+		}
+	}
+	if options.demo && config.Outbox.Enabled {
+		logger.Warn("outbox.enabled is ignored in demo mode, no events are emitted")
+	}
+	if config.Recipes.Rounding.Enabled {
+		serviceOptions = append(serviceOptions, application.WithRounding(config.Recipes.Rounding.Policy()))
 	}
 
-	db, err := loadDBConfig()
+	res, err := telemetry.NewResource(ctx, config.Resource)
 	if err != nil {
-		logger.WithError(err).Fatal("Failed to connect to database")
+		logger.WithError(err).Fatal("Failed to create telemetry resource")
 	}
-	defer func() {
-		if err := db.Close(); err != nil {
-			logger.WithError(err).Error("Failed to close database connection")
-		}
-	}()
 
-	prometheusMetrics := prometheusMetrics.NewPrometheusMetrics()
+	shutdownLogs, err := initLogExport(ctx, config.Logs, res)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize log export")
+	}
+	defer shutdownTelemetry("log export", shutdownLogs)
+
+	recipeMetrics, promMetrics, shutdownMetrics, err := initMetrics(ctx, config.Metrics, res)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize metrics")
+	}
+	defer shutdownTelemetry("metrics", shutdownMetrics)
+	if promMetrics != nil && db != nil {
+		prometheus.MustRegister(infraMetrics.NewDBStatsCollector(db, config.Database.Name()))
+	}
+
+	reloadable := newReloadableMiddleware(config)
+	configs.Watch(reloadable.apply, func(err error) {
+		logger.WithError(err).Error("Failed to reload configuration")
+	})
+
+	router := setupRouter(config, repository, serviceOptions, webhooks, recipeMetrics, promMetrics, reloadable)
+
+	startServerWithGracefulShutdown(router, config.Server.Port)
+}
+
+// reloadableMiddleware holds the middleware whose settings are safe to change
+// while serving requests.
+type reloadableMiddleware struct {
+	cors        *middleware.CORSMiddleware
+	rateLimiter *middleware.RateLimiter
+}
+
+func newReloadableMiddleware(config *configs.Config) *reloadableMiddleware {
+	return &reloadableMiddleware{
+		cors:        middleware.NewCORSMiddleware(config.Server.CORSOrigins),
+		rateLimiter: middleware.NewRateLimiter(config.RateLimit),
+	}
+}
 
-	router := setupRouter(db, prometheusMetrics)
+func (r *reloadableMiddleware) apply(config *configs.Config) {
+	setLogLevel(config.Logging.Level)
+	r.cors.SetAllowedOrigins(config.Server.CORSOrigins)
+	r.rateLimiter.Update(config.RateLimit)
 
-	startServerWithGracefulShutdown(router)
+	logger.WithFields(map[string]interface{}{
+		"log_level":    logger.GetLevel().String(),
+		"cors_origins": config.Server.CORSOrigins,
+		"rate_limit":   config.RateLimit.RequestsPerSecond,
+	}).Info("Configuration reloaded, settings other than logging.level, rateLimit and server.cors need a restart")
 }
 
-func setupRouter(db *sql.DB, metrics *prometheusMetrics.PrometheusMetrics) *gin.Engine {
-	calculatorService, err := initializeCalculatorService()
+func setLogLevel(level string) {
+	if level == "" {
+		return
+	}
+	parsed, err := logrus.ParseLevel(level)
+	if err != nil {
+		logger.WithError(err).Error("Ignoring invalid log level")
+		return
+	}
+	logger.SetLevel(parsed)
+}
+
+func setupRouter(
+	config *configs.Config,
+	repository application.RecipeRepository,
+	serviceOptions []application.Option,
+	webhooks application.WebhookRepository,
+	recipeMetrics domainMetrics.RecipeMetrics,
+	promMetrics *infraMetrics.PrometheusMetrics,
+	reloadable *reloadableMiddleware,
+) *gin.Engine {
+	calculatorService, err := initializeCalculatorService(config.Calculator)
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to initialize calculator service")
 	}
 
-	balancerService, err := initializeBalancerService()
+	balancerService, err := initializeBalancerService(config.Balancer)
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to initialize balancer service")
 	}
 
-	recipeHandler := apihttp.NewRecipeHandler(
-		application.NewRecipeService(
-			mysql.NewMySqlRecipeRepository(db),
-			calculatorService,
-			balancerService,
-		),
+	recipeService := application.NewRecipeService(
+		repository,
+		calculatorService,
+		balancerService,
+		serviceOptions...,
 	)
+	recipeHandler := apihttp.NewRecipeHandler(recipeService)
 
 	router := gin.New()
 
+	router.Use(envelope.Start())
+	// Ahead of the access log, which captures the bodies before they are
+	// compressed.
+	router.Use(middleware.Compression(config.Server.Compression))
 	router.Use(otelgin.Middleware(serviceName))
-	router.Use(logger.GinMiddleware())
+	router.Use(logger.GinMiddlewareWithConfig(config.Logging.Access))
 
 	router.Use(gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
 		logger.WithContext(c.Request.Context()).WithFields(map[string]interface{}{
@@ -104,21 +270,120 @@ func setupRouter(db *sql.DB, metrics *prometheusMetrics.PrometheusMetrics) *gin.
 		c.AbortWithStatus(500)
 	}))
 
-	router.Use(corsMiddleware())
+	router.Use(reloadable.cors.Middleware())
+	router.Use(reloadable.rateLimiter.Middleware())
+	router.Use(middleware.NewMetricsMiddleware(recipeMetrics, promMetrics).HTTPMetricsMiddleware())
 
-	metricsHandler := httpHandlers.NewMetricsHandler()
-	metricsHandler.RegisterRoutes(router)
+	if promMetrics != nil {
+		metricsHandler := httpHandlers.NewMetricsHandler()
+		metricsHandler.RegisterRoutes(router)
+	}
 
 	healthHandler := httpHandlers.NewHealthHandler()
 	healthHandler.RegisterRoutes(router)
 
-	router.POST("/recipes/:uuid/aggregate", recipeHandler.RetrieveRecipeAggregate)
+	openAPIHandler, err := openapi.NewHandler()
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to load the OpenAPI document")
+	}
+	openAPIHandler.RegisterRoutes(router)
+
+	graphQLHandler, err := apigraphql.NewHandler(recipeService)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to parse the GraphQL schema")
+	}
+	graphQLHandler.RegisterRoutes(router)
+
+	if config.AdminToken == "" {
+		logger.Warn("admin.token is not set, admin endpoints are disabled")
+	}
+	logLevelHandler := httpHandlers.NewLogLevelHandler(logger.Logger, config.AdminToken)
+	logLevelHandler.RegisterRoutes(router)
+
+	// Subscriptions are stored in the database, which the demo mode has not.
+	var webhookHandler *apihttp.WebhookHandler
+	if webhooks != nil {
+		webhookHandler = apihttp.NewWebhookHandler(application.NewWebhookService(webhooks))
+	}
+
+	registerAPI(router.Group(envelope.Prefix), config.AdminToken, recipeHandler, webhookHandler)
+	if config.API.Legacy {
+		legacy := router.Group("", middleware.Deprecation(config.API.LegacyDeprecation))
+		registerAPI(legacy, config.AdminToken, recipeHandler, webhookHandler)
+	}
 
 	return router
 }
 
-func startServerWithGracefulShutdown(router *gin.Engine) {
-	port := viper.GetInt("server.port")
+// registerAPI registers the recipe and webhook routes on router, once under
+// /api/v1 and once at the root for the legacy clients.
+func registerAPI(router *gin.RouterGroup, adminToken string, recipeHandler *apihttp.RecipeHandler, webhookHandler *apihttp.WebhookHandler) {
+	recipeHandler.RegisterRoutes(router)
+	admin := router.Group("/admin", httpHandlers.RequireAdminToken(adminToken))
+	recipeHandler.RegisterAdminRoutes(admin)
+	if webhookHandler != nil {
+		webhookHandler.RegisterAdminRoutes(admin)
+	}
+}
+
+// initMetrics selects the RecipeMetrics implementation configured in
+// telemetry.metrics.backend. The Prometheus metrics are nil when the backend
+// is OTLP only, in which case /metrics is not exposed.
+func initMetrics(ctx context.Context, config telemetry.MetricsConfig, res *resource.Resource) (domainMetrics.RecipeMetrics, *infraMetrics.PrometheusMetrics, func(context.Context) error, error) {
+	noShutdown := func(context.Context) error { return nil }
+	if err := config.Validate(); err != nil {
+		return nil, nil, noShutdown, err
+	}
+
+	var promMetrics *infraMetrics.PrometheusMetrics
+	if config.UsesPrometheus() {
+		promMetrics = infraMetrics.NewPrometheusMetrics()
+	}
+	if !config.UsesOTLP() {
+		logger.WithField("backend", config.Backend).Info("Metrics initialized")
+		return promMetrics, promMetrics, noShutdown, nil
+	}
+
+	meterProvider, err := telemetry.NewMeterProvider(ctx, config, res)
+	if err != nil {
+		return nil, nil, noShutdown, err
+	}
+	otelMetrics, err := infraMetrics.NewOTelMetrics(meterProvider.Meter(serviceName))
+	if err != nil {
+		return nil, nil, noShutdown, err
+	}
+
+	logger.WithField("backend", config.Backend).Info("Metrics initialized")
+	if promMetrics == nil {
+		return otelMetrics, nil, meterProvider.Shutdown, nil
+	}
+	return infraMetrics.NewCompositeMetrics(promMetrics, otelMetrics), promMetrics, meterProvider.Shutdown, nil
+}
+
+func initLogExport(ctx context.Context, config telemetry.LogsConfig, res *resource.Resource) (func(context.Context) error, error) {
+	if !config.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	loggerProvider, err := telemetry.NewLoggerProvider(ctx, config, res)
+	if err != nil {
+		return nil, err
+	}
+	logger.AddHook(logging.NewOTelHook(loggerProvider))
+
+	logger.WithField("exporter", config.Exporter).Info("OTLP log export enabled")
+	return loggerProvider.Shutdown, nil
+}
+
+func shutdownTelemetry(signal string, shutdown func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdown(ctx); err != nil {
+		logger.WithError(err).WithField("signal", signal).Error("Failed to shutdown telemetry")
+	}
+}
+
+func startServerWithGracefulShutdown(router *gin.Engine, port int) {
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", port),
 		Handler: router,
@@ -147,23 +412,13 @@ func startServerWithGracefulShutdown(router *gin.Engine) {
 	logger.Info("Server stopped")
 }
 
-func corsMiddleware() gin.HandlerFunc {
-	feLocalHost := viper.GetString("local.fe-host")
-	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", feLocalHost)
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Correlation-ID")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-		c.Next()
+func initializeCalculatorService(config configs.GRPCConfig) (application.CalculatorService, error) {
+	if config.Local {
+		logger.Info("Calculator service measuring pans locally")
+		return application.NewLocalCalculatorService(), nil
 	}
-}
 
-func initializeCalculatorService() (application.CalculatorService, error) {
-	calculatorClient, err := loadCalculatorGrpcClient()
+	calculatorClient, err := loadCalculatorGrpcClient(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize calculator service: %w", err)
 	}
@@ -172,19 +427,18 @@ func initializeCalculatorService() (application.CalculatorService, error) {
 	return application.NewRemoteDoughCalculatorService(calculatorClient), nil
 }
 
-func loadCalculatorGrpcClient() (*client.CalculatorClient, error) {
-	config := configs.LoadCalculatorGRPCConfig()
+func loadCalculatorGrpcClient(config configs.GRPCConfig) (*client.CalculatorClient, error) {
 	calculatorClient, err := client.NewDoughCalculatorClient(config.Address, config.Timeout)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Calculator gRPC client: %w", err)
 	}
 
-	logger.WithField("address", config.Address).Info("Calculator gRPC client created")
+	logger.WithFields(map[string]interface{}{"address": config.Address, "timeout": config.Timeout.String()}).Info("Calculator gRPC client created")
 	return calculatorClient, nil
 }
 
-func initializeBalancerService() (application.BalancerService, error) {
-	balancerClient, err := loadBalancerGrpcClient()
+func initializeBalancerService(config configs.GRPCConfig) (application.BalancerService, error) {
+	balancerClient, err := loadBalancerGrpcClient(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize balancer service: %w", err)
 	}
@@ -193,56 +447,60 @@ func initializeBalancerService() (application.BalancerService, error) {
 	return application.NewRemoteIngredientsBalancerService(balancerClient), nil
 }
 
-func loadBalancerGrpcClient() (*client.IngredientsBalancerClient, error) {
-	config := configs.LoadBalancerGRPCConfig()
+func loadBalancerGrpcClient(config configs.GRPCConfig) (*client.IngredientsBalancerClient, error) {
 	balancerClient, err := client.NewIngredientsBalancerClient(config.Address, config.Timeout)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Balancer gRPC client: %w", err)
 	}
 
-	logger.WithField("address", config.Address).Info("Balancer gRPC client created")
+	logger.WithFields(map[string]interface{}{"address": config.Address, "timeout": config.Timeout.String()}).Info("Balancer gRPC client created")
 	return balancerClient, nil
 }
 
-func loadDBConfig() (*sql.DB, error) {
-	config := configs.NewDBConfig()
+func newRecipeRepository(config *configs.DBConfig, db *sql.DB) (*sqlstore.RecipeRepository, error) {
+	dialect, err := sqlstore.DialectFor(config.Driver)
+	if err != nil {
+		return nil, err
+	}
+	return sqlstore.NewRecipeRepository(db, dialect, sqlstore.WithQueryTimeout(config.QueryTimeout)), nil
+}
+
+func loadDBConfig(config *configs.DBConfig) (*sql.DB, error) {
 	db, err := newDBConnection(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	logger.WithField("database", config.DBName).Info("Database connection established")
+	logger.WithFields(map[string]interface{}{
+		"driver":   config.Driver,
+		"database": config.Name(),
+	}).Info("Database connection established")
 	return db, nil
 }
 
 func newDBConnection(config *configs.DBConfig) (*sql.DB, error) {
-	db, err := sql.Open("mysql", config.DSN())
+	if err := config.RegisterTLS(); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open(config.DriverName(), config.DSN())
 	if err != nil {
 		return nil, fmt.Errorf("error opening database: %w", err)
 	}
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	db.SetMaxIdleConns(config.MaxIdleConns)
+	db.SetConnMaxLifetime(config.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(config.ConnMaxIdleTime)
 
-	if err := db.Ping(); err != nil {
+	ctx := context.Background()
+	if config.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.ConnectTimeout)
+		defer cancel()
+	}
+	if err := db.PingContext(ctx); err != nil {
 		return nil, fmt.Errorf("error connecting to the database: %w", err)
 	}
 
 	return db, nil
 }
-
-func setEnvConfigs() error {
-	configName := os.Getenv("CONFIG_NAME")
-	if configName == "" {
-		configName = "props"
-	}
-	viper.SetConfigName(configName)
-	viper.SetConfigType("yml")
-	viper.AddConfigPath("configs/")
-	if err := viper.ReadInConfig(); err != nil {
-		return fmt.Errorf("failed to read properties config: %w", err)
-	}
-
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	viper.AutomaticEnv()
-
-	logger.WithField("config", configName).Info("Configuration loaded")
-	return nil
-}
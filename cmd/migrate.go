@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/cfioretti/recipe-manager/configs"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/catalogue"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/sqlstore"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/sqlstore/migrations"
+	embedded "github.com/cfioretti/recipe-manager/migrations"
+)
+
+const migrateUsage = `Usage: recipe-manager migrate [-set schema|seed|all] <command>
+
+Commands:
+  up              apply all pending migrations
+  down [N]        revert the last N migrations, or all of them
+  status          print the applied and pending migrations
+  force VERSION   record VERSION as applied and clear the dirty flag
+  catalogue       import the bundled ingredient catalogue, after the schema
+
+Flags:
+`
+
+type migrationSet struct {
+	name  string
+	files func(driver string) (fs.FS, error)
+	table string
+}
+
+var (
+	schemaMigrations = migrationSet{name: "schema", files: embedded.Schema, table: migrations.SchemaMigrationsTable}
+	seedMigrations   = migrationSet{name: "seed", files: embedded.Seed, table: migrations.SeedMigrationsTable}
+)
+
+func runMigrateCommand(args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	set := flags.String("set", "schema", "migrations to run: schema, seed or all")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), migrateUsage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("missing migrate command")
+	}
+	command, commandArgs := flags.Arg(0), flags.Args()[1:]
+	if !slices.Contains([]string{"up", "down", "status", "force", "catalogue"}, command) {
+		flags.Usage()
+		return fmt.Errorf("unknown migrate command %q", command)
+	}
+
+	sets, err := selectMigrationSets(*set)
+	if err != nil {
+		return err
+	}
+	// Seed data references the schema, so it is reverted first.
+	if command == "down" {
+		slices.Reverse(sets)
+	}
+
+	config, err := configs.Load(serviceName, version)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := config.Database.RegisterTLS(); err != nil {
+		return err
+	}
+
+	if command == "catalogue" {
+		return importCatalogue(config.Database)
+	}
+
+	for _, set := range sets {
+		status, err := runMigrationSet(config.Database, set, command, commandArgs)
+		if err != nil {
+			return fmt.Errorf("%s migrations: %w", set.name, err)
+		}
+		fmt.Fprintf(os.Stdout, "%s: version %d, dirty %t, applied %v, pending %v\n",
+			set.name, status.Version, status.Dirty, status.Applied, status.Pending)
+	}
+	return nil
+}
+
+func selectMigrationSets(name string) ([]migrationSet, error) {
+	switch name {
+	case "schema":
+		return []migrationSet{schemaMigrations}, nil
+	case "seed":
+		return []migrationSet{seedMigrations}, nil
+	case "all":
+		return []migrationSet{schemaMigrations, seedMigrations}, nil
+	default:
+		return nil, fmt.Errorf("unknown migration set %q, expected schema, seed or all", name)
+	}
+}
+
+func runMigrationSet(config *configs.DBConfig, set migrationSet, command string, args []string) (migrations.Status, error) {
+	files, err := set.files(config.Driver)
+	if err != nil {
+		return migrations.Status{}, err
+	}
+	migrator, err := migrations.NewMigrator(config.Driver, config.DSN(), files, set.table)
+	if err != nil {
+		return migrations.Status{}, err
+	}
+	defer func() {
+		if err := migrator.Close(); err != nil {
+			logger.WithError(err).Error("Failed to close migrator")
+		}
+	}()
+
+	switch command {
+	case "up":
+		if err := migrator.Up(); err != nil {
+			return migrations.Status{}, err
+		}
+	case "down":
+		steps := 0
+		if len(args) > 0 {
+			if steps, err = strconv.Atoi(args[0]); err != nil || steps < 1 {
+				return migrations.Status{}, fmt.Errorf("down expects a positive number of steps, got %q", args[0])
+			}
+		}
+		if err := migrator.Down(steps); err != nil {
+			return migrations.Status{}, err
+		}
+	case "force":
+		if len(args) != 1 {
+			return migrations.Status{}, errors.New("force expects exactly one VERSION")
+		}
+		forcedVersion, err := strconv.Atoi(args[0])
+		if err != nil {
+			return migrations.Status{}, fmt.Errorf("force expects a numeric VERSION, got %q", args[0])
+		}
+		if err := migrator.Force(forcedVersion); err != nil {
+			return migrations.Status{}, err
+		}
+	case "status":
+	default:
+		return migrations.Status{}, fmt.Errorf("unknown migrate command %q", command)
+	}
+
+	return migrator.Status()
+}
+
+// autoMigrate applies the schema migrations and imports the ingredient
+// catalogue, then the seed data when enabled, before the server starts.
+// Concurrent instances are serialised by the migration lock taken in the
+// database, and the catalogue import is idempotent.
+func autoMigrate(config *configs.DBConfig) error {
+	if err := applyMigrationSet(config, schemaMigrations); err != nil {
+		return err
+	}
+	if err := importCatalogue(config); err != nil {
+		return err
+	}
+	if config.Migrations.Seed {
+		return applyMigrationSet(config, seedMigrations)
+	}
+	return nil
+}
+
+func applyMigrationSet(config *configs.DBConfig, set migrationSet) error {
+	status, err := runMigrationSet(config, set, "up", nil)
+	if err != nil {
+		return fmt.Errorf("%s migrations: %w", set.name, err)
+	}
+	logger.WithFields(map[string]interface{}{
+		"set":     set.name,
+		"version": status.Version,
+	}).Info("Migrations applied")
+	return nil
+}
+
+func importCatalogue(config *configs.DBConfig) error {
+	dialect, err := sqlstore.DialectFor(config.Driver)
+	if err != nil {
+		return err
+	}
+	ingredients, err := catalogue.Parse(embedded.Catalogue())
+	if err != nil {
+		return err
+	}
+
+	db, err := sql.Open(config.DriverName(), config.DSN())
+	if err != nil {
+		return fmt.Errorf("failed to connect to the database: %w", err)
+	}
+	defer db.Close()
+
+	if err := sqlstore.NewIngredientCatalogue(db, dialect).Import(context.Background(), ingredients); err != nil {
+		return err
+	}
+	logger.WithField("ingredients", len(ingredients)).Info("Ingredient catalogue imported")
+	return nil
+}
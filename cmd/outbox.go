@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/cfioretti/recipe-manager/configs"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/outbox"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/sqlstore"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/webhook"
+)
+
+// newOutbox returns the outbox the recipe service stores events in and the
+// workers publishing them to the configured sink, to run until shutdown.
+// closeSink releases the sink.
+func newOutbox(config *configs.Config, db *sql.DB) (store *sqlstore.Outbox, workers []func(context.Context), closeSink func() error, err error) {
+	dialect, err := sqlstore.DialectFor(config.Database.Driver)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var sink outbox.Sink
+	closeSink = func() error { return nil }
+	switch config.Outbox.Sink {
+	case configs.OutboxSinkWebhook:
+		sink = outbox.NewWebhookSink(config.Outbox.WebhookURL, config.Outbox.WebhookTimeout)
+	case configs.OutboxSinkFile:
+		fileSink, err := outbox.NewFileSink(config.Outbox.FilePath)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		sink, closeSink = fileSink, fileSink.Close
+	case configs.OutboxSinkSubscriptions:
+		webhooks := sqlstore.NewWebhookStore(db, dialect)
+		sink = webhook.NewDispatcher(webhooks)
+		worker := webhook.NewWorker(webhooks, logger.WithField("component", "webhook-worker"),
+			webhook.WithInterval(config.Webhooks.Interval),
+			webhook.WithBatchSize(config.Webhooks.BatchSize),
+			webhook.WithTimeout(config.Webhooks.Timeout),
+			webhook.WithRetries(config.Webhooks.MaxAttempts, config.Webhooks.InitialBackoff, config.Webhooks.MaxBackoff),
+		)
+		workers = append(workers, worker.Run)
+	default:
+		return nil, nil, nil, fmt.Errorf("unknown outbox sink %q", config.Outbox.Sink)
+	}
+
+	store = sqlstore.NewOutbox(db, dialect)
+	relay := outbox.NewRelay(store, sink, logger.WithField("component", "outbox-relay"),
+		outbox.WithInterval(config.Outbox.RelayInterval),
+		outbox.WithBatchSize(config.Outbox.BatchSize),
+		outbox.WithMaxAttempts(config.Outbox.MaxAttempts),
+	)
+	logger.WithField("sink", config.Outbox.Sink).Info("Outbox relay initialized")
+	return store, append(workers, relay.Run), closeSink, nil
+}
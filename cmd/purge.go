@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/cfioretti/recipe-manager/configs"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/sqlstore"
+)
+
+const purgeUsage = `Usage: recipe-manager purge [-older-than DURATION]
+
+Removes for good the recipes deleted longer ago than the retention, with their
+steps and ingredients, and the outbox events published and webhook deliveries
+delivered longer ago than outbox.retention. Meant to be scheduled, from cron or a Kubernetes CronJob.
+
+Flags:
+`
+
+func runPurgeCommand(args []string) error {
+	flags := flag.NewFlagSet("purge", flag.ContinueOnError)
+	olderThan := flags.Duration("older-than", 0, "retention of deleted recipes, instead of recipes.purge.retention")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), purgeUsage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return fmt.Errorf("unexpected arguments %v", flags.Args())
+	}
+	if *olderThan < 0 {
+		return fmt.Errorf("-older-than must not be negative, got %s", *olderThan)
+	}
+
+	config, err := configs.Load(serviceName, version)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	retention := config.Recipes.PurgeRetention
+	if *olderThan > 0 {
+		retention = *olderThan
+	}
+	if err := config.Database.RegisterTLS(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	purged, err := purge(config.Database, now.Add(-retention), now.Add(-config.Outbox.Retention))
+	if err != nil {
+		return err
+	}
+	logger.WithFields(map[string]interface{}{
+		"retention":            retention.String(),
+		"purged":               purged.recipes,
+		"published_events":     purged.events,
+		"delivered_deliveries": purged.deliveries,
+	}).Info("Deleted recipes purged")
+	return nil
+}
+
+// purged counts what purge removed.
+type purged struct {
+	recipes, events, deliveries int64
+}
+
+// purge removes the recipes deleted before deletedBefore, and the outbox
+// events published and the webhook deliveries delivered before
+// publishedBefore.
+func purge(config *configs.DBConfig, deletedBefore, publishedBefore time.Time) (purged, error) {
+	var result purged
+	dialect, err := sqlstore.DialectFor(config.Driver)
+	if err != nil {
+		return result, err
+	}
+	db, err := sql.Open(config.DriverName(), config.DSN())
+	if err != nil {
+		return result, fmt.Errorf("failed to connect to the database: %w", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	if result.recipes, err = sqlstore.NewRecipeRepository(db, dialect).PurgeRecipes(ctx, deletedBefore); err != nil {
+		return result, fmt.Errorf("failed to purge deleted recipes: %w", err)
+	}
+	if result.events, err = sqlstore.NewOutbox(db, dialect).PurgePublished(ctx, publishedBefore); err != nil {
+		return result, fmt.Errorf("failed to purge published events: %w", err)
+	}
+	if result.deliveries, err = sqlstore.NewWebhookStore(db, dialect).PurgeDeliveries(ctx, publishedBefore); err != nil {
+		return result, fmt.Errorf("failed to purge webhook deliveries: %w", err)
+	}
+	return result, nil
+}
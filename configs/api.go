@@ -0,0 +1,47 @@
+package configs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/http/middleware"
+)
+
+type APIConfig struct {
+	// Legacy serves the API at the root next to /api/v1, with the
+	// Deprecation and Sunset headers of LegacyDeprecation, until the
+	// clients have moved.
+	Legacy            bool
+	LegacyDeprecation middleware.DeprecationConfig
+	// dateErrs holds the dates that did not parse, reported by Validate.
+	dateErrs []error
+}
+
+func setAPIDefaults() {
+	viper.SetDefault("api.legacy.enabled", true)
+	viper.SetDefault("api.legacy.deprecatedAt", "2026-10-19")
+	viper.SetDefault("api.legacy.sunset", "2027-04-19")
+}
+
+func LoadAPIConfig() APIConfig {
+	config := APIConfig{Legacy: viper.GetBool("api.legacy.enabled")}
+	config.LegacyDeprecation.DeprecatedAt = config.date("api.legacy.deprecatedAt")
+	config.LegacyDeprecation.Sunset = config.date("api.legacy.sunset")
+	return config
+}
+
+// date parses the YYYY-MM-DD date at key as midnight UTC; an empty value
+// leaves it unset.
+func (c *APIConfig) date(key string) time.Time {
+	value := viper.GetString(key)
+	if value == "" {
+		return time.Time{}
+	}
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		c.dateErrs = append(c.dateErrs, fmt.Errorf("%s must be a date like 2027-04-19, got %q", key, value))
+	}
+	return date
+}
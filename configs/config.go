@@ -0,0 +1,340 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/http/middleware"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/logging"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/telemetry"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/tracing"
+)
+
+// Config is the whole service configuration, read once from the properties
+// file and the environment and validated before anything is started.
+type Config struct {
+	Server     ServerConfig
+	API        APIConfig
+	Database   *DBConfig
+	Calculator GRPCConfig
+	Balancer   GRPCConfig
+	Recipes    RecipesConfig
+	Outbox     OutboxConfig
+	Webhooks   WebhooksConfig
+	RateLimit  middleware.RateLimitConfig
+	Logging    LoggingConfig
+	AdminToken string
+	Resource   telemetry.ResourceConfig
+	Metrics    telemetry.MetricsConfig
+	Logs       telemetry.LogsConfig
+	Tracing    *tracing.TracerConfig
+}
+
+type ServerConfig struct {
+	Port        int
+	CORSOrigins []string
+	Compression middleware.CompressionConfig
+}
+
+type LoggingConfig struct {
+	// Level is empty when neither logging.level nor LOG_LEVEL is set.
+	Level  string
+	Access logging.AccessLogConfig
+}
+
+// Load reads the properties file named by CONFIG_NAME (default "props") from
+// CONFIG_PATH (default "configs/"), applies environment overrides and
+// validates the result.
+func Load(serviceName, version string) (*Config, error) {
+	configName := os.Getenv("CONFIG_NAME")
+	if configName == "" {
+		configName = "props"
+	}
+	configPath := os.Getenv("CONFIG_PATH")
+	if configPath == "" {
+		configPath = "configs/"
+	}
+
+	viper.SetConfigName(configName)
+	viper.SetConfigType("yml")
+	viper.AddConfigPath(configPath)
+	if err := viper.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read properties config: %w", err)
+	}
+
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
+	if err := bindLegacyEnv(); err != nil {
+		return nil, fmt.Errorf("failed to bind environment variables: %w", err)
+	}
+
+	setDefaults(serviceName, version)
+	config := build()
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration in %s: %w", viper.ConfigFileUsed(), err)
+	}
+	return config, nil
+}
+
+// Watch reloads the properties file whenever it changes on disk and hands
+// the new configuration to onChange once it validates. Invalid edits are
+// reported to onError and the running configuration is left untouched. Load
+// must have set the defaults before, as the reloads only read them: viper is
+// not safe for concurrent writes.
+func Watch(onChange func(*Config), onError func(error)) {
+	viper.OnConfigChange(func(fsnotify.Event) {
+		config := build()
+		if err := config.Validate(); err != nil {
+			onError(fmt.Errorf("ignoring invalid configuration in %s: %w", viper.ConfigFileUsed(), err))
+			return
+		}
+		onChange(config)
+	})
+	viper.WatchConfig()
+}
+
+// bindLegacyEnv keeps the variable names used by the deployment manifests
+// working next to the ones derived from the config keys.
+func bindLegacyEnv() error {
+	return errors.Join(
+		viper.BindEnv("grpc.calculator.address", "GRPC_CALCULATOR_ADDRESS", "CALCULATOR_ADDR"),
+		viper.BindEnv("grpc.balancer.address", "GRPC_BALANCER_ADDRESS", "INGREDIENTS_BALANCER_ADDR"),
+		viper.BindEnv("database.username", "DATABASE_USERNAME", "DATABASE_USER"),
+		viper.BindEnv("logging.level", "LOGGING_LEVEL", "LOG_LEVEL"),
+	)
+}
+
+// setDefaults registers the default of every key, once at startup.
+func setDefaults(serviceName, version string) {
+	viper.SetDefault("server.port", 8080)
+	viper.SetDefault("server.compression.enabled", true)
+	viper.SetDefault("server.compression.minSize", 1024)
+	viper.SetDefault("server.compression.encodings", []string{middleware.EncodingZstd, middleware.EncodingGzip})
+	viper.SetDefault("rateLimit.enabled", false)
+	viper.SetDefault("rateLimit.requestsPerSecond", 10)
+	viper.SetDefault("rateLimit.burst", 20)
+	viper.SetDefault("rateLimit.exemptPaths", []string{"/health", "/metrics"})
+	setAPIDefaults()
+	setDatabaseDefaults()
+	setGRPCDefaults("grpc.calculator")
+	setGRPCDefaults("grpc.balancer")
+	setRecipesDefaults()
+	setOutboxDefaults()
+	setWebhooksDefaults()
+	setAccessLogDefaults()
+	setResourceDefaults(serviceName, version)
+	setMetricsDefaults()
+	setExporterDefaults("telemetry.metrics")
+	setLogsDefaults()
+	setExporterDefaults("telemetry.logs")
+	setTracingDefaults()
+}
+
+func build() *Config {
+	corsOrigins := viper.GetStringSlice("server.cors.allowedOrigins")
+	if len(corsOrigins) == 0 && viper.GetString("local.fe-host") != "" {
+		corsOrigins = []string{viper.GetString("local.fe-host")}
+	}
+
+	return &Config{
+		Server: ServerConfig{
+			Port:        viper.GetInt("server.port"),
+			CORSOrigins: corsOrigins,
+			Compression: middleware.CompressionConfig{
+				Enabled:   viper.GetBool("server.compression.enabled"),
+				MinSize:   viper.GetInt("server.compression.minSize"),
+				Encodings: viper.GetStringSlice("server.compression.encodings"),
+			},
+		},
+		API:        LoadAPIConfig(),
+		Database:   NewDBConfig(),
+		Calculator: LoadCalculatorGRPCConfig(),
+		Balancer:   LoadBalancerGRPCConfig(),
+		Recipes:    LoadRecipesConfig(),
+		Outbox:     LoadOutboxConfig(),
+		Webhooks:   LoadWebhooksConfig(),
+		RateLimit: middleware.RateLimitConfig{
+			Enabled:           viper.GetBool("rateLimit.enabled"),
+			RequestsPerSecond: viper.GetFloat64("rateLimit.requestsPerSecond"),
+			Burst:             viper.GetInt("rateLimit.burst"),
+			ExemptPaths:       viper.GetStringSlice("rateLimit.exemptPaths"),
+		},
+		Logging: LoggingConfig{
+			Level:  viper.GetString("logging.level"),
+			Access: LoadAccessLogConfig(),
+		},
+		AdminToken: LoadAdminToken(),
+		Resource:   LoadResourceConfig(),
+		Metrics:    LoadMetricsConfig(),
+		Logs:       LoadLogsConfig(),
+		Tracing:    LoadTracingConfig(),
+	}
+}
+
+// Validate reports every invalid value at once, each prefixed with the
+// config key to fix.
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		fail("server.port must be between 1 and 65535, got %d", c.Server.Port)
+	}
+	for _, origin := range c.Server.CORSOrigins {
+		if origin == "*" {
+			continue
+		}
+		if parsed, err := url.Parse(origin); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			fail("server.cors.allowedOrigins: %q is not an origin like https://example.com", origin)
+		}
+	}
+
+	if c.Server.Compression.MinSize < 0 {
+		fail("server.compression.minSize must not be negative, got %d", c.Server.Compression.MinSize)
+	}
+	for _, encoding := range c.Server.Compression.Encodings {
+		if encoding != middleware.EncodingZstd && encoding != middleware.EncodingGzip {
+			fail("server.compression.encodings must be zstd or gzip, got %q", encoding)
+		}
+	}
+
+	errs = append(errs, c.API.dateErrs...)
+	if deprecation := c.API.LegacyDeprecation; !deprecation.DeprecatedAt.IsZero() && !deprecation.Sunset.IsZero() &&
+		!deprecation.Sunset.After(deprecation.DeprecatedAt) {
+		fail("api.legacy.sunset must be after api.legacy.deprecatedAt")
+	}
+
+	switch c.Database.Driver {
+	case DBDriverMySQL, DBDriverPostgres:
+		if c.Database.Host == "" {
+			fail("database.host is required")
+		}
+		if c.Database.Port == "" {
+			fail("database.port is required")
+		}
+		if c.Database.User == "" {
+			fail("database.username is required")
+		}
+		if c.Database.DBName == "" {
+			fail("database.dbName is required")
+		}
+	case DBDriverSQLite:
+		if c.Database.Path == "" {
+			fail("database.path is required")
+		}
+	default:
+		fail("database.driver must be one of mysql, postgres or sqlite, got %q", c.Database.Driver)
+	}
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 {
+		fail("database.pool connection limits must not be negative")
+	}
+	if c.Database.ConnMaxLifetime < 0 || c.Database.ConnMaxIdleTime < 0 {
+		fail("database.pool connection lifetimes must not be negative")
+	}
+	switch c.Database.TLSMode {
+	case "", DBTLSModeDisabled, DBTLSModeEnabled, DBTLSModeSkipVerify, DBTLSModePreferred, DBTLSModeCustom:
+	default:
+		fail("database.tls.mode must be one of false, true, skip-verify, preferred or custom, got %q", c.Database.TLSMode)
+	}
+	if c.Database.QueryTimeout < 0 || c.Database.ConnectTimeout < 0 || c.Database.ReadTimeout < 0 || c.Database.WriteTimeout < 0 {
+		fail("database.timeouts must not be negative")
+	}
+
+	downstreams := []struct {
+		key    string
+		config GRPCConfig
+	}{{"grpc.calculator", c.Calculator}, {"grpc.balancer", c.Balancer}}
+	for _, downstream := range downstreams {
+		if downstream.config.Local {
+			continue
+		}
+		if host, port, err := net.SplitHostPort(downstream.config.Address); err != nil || host == "" || port == "" {
+			fail("%s.address must be host:port, got %q", downstream.key, downstream.config.Address)
+		}
+		if downstream.config.Timeout <= 0 {
+			fail("%s.timeout must be positive, got %s", downstream.key, downstream.config.Timeout)
+		}
+	}
+
+	if c.Recipes.PurgeRetention <= 0 {
+		fail("recipes.purge.retention must be positive, got %s", c.Recipes.PurgeRetention)
+	}
+	if c.Recipes.Rounding.Enabled {
+		if !(c.Recipes.Rounding.Default >= 0) {
+			fail("recipes.rounding.default must not be negative, got %g", c.Recipes.Rounding.Default)
+		}
+		for _, class := range slices.Sorted(maps.Keys(c.Recipes.Rounding.Classes)) {
+			if step := c.Recipes.Rounding.Classes[class]; !(step >= 0) {
+				fail("recipes.rounding.classes.%s must be a non-negative number of grams", class)
+			}
+		}
+	}
+
+	if c.Outbox.Enabled {
+		if c.Outbox.RelayInterval <= 0 || c.Outbox.BatchSize <= 0 {
+			fail("outbox.relay.interval and outbox.relay.batchSize must be positive")
+		}
+		switch c.Outbox.Sink {
+		case OutboxSinkWebhook:
+			if parsed, err := url.Parse(c.Outbox.WebhookURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				fail("outbox.sink.url must be an http or https URL, got %q", c.Outbox.WebhookURL)
+			}
+		case OutboxSinkFile:
+			if c.Outbox.FilePath == "" {
+				fail("outbox.sink.path is required")
+			}
+		case OutboxSinkSubscriptions:
+			if c.Webhooks.Interval <= 0 || c.Webhooks.BatchSize <= 0 || c.Webhooks.Timeout <= 0 {
+				fail("webhooks.delivery.interval, batchSize and timeout must be positive")
+			}
+			if c.Webhooks.MaxAttempts < 1 {
+				fail("webhooks.retry.maxAttempts must be at least 1, got %d", c.Webhooks.MaxAttempts)
+			}
+			if c.Webhooks.InitialBackoff <= 0 || c.Webhooks.MaxBackoff < c.Webhooks.InitialBackoff {
+				fail("webhooks.retry.initialBackoff must be positive and at most webhooks.retry.maxBackoff")
+			}
+		default:
+			fail("outbox.sink.type must be webhook, file or subscriptions, got %q", c.Outbox.Sink)
+		}
+	}
+	if c.Outbox.Retention <= 0 {
+		fail("outbox.retention must be positive, got %s", c.Outbox.Retention)
+	}
+
+	if c.RateLimit.Enabled && (c.RateLimit.RequestsPerSecond <= 0 || c.RateLimit.Burst <= 0) {
+		fail("rateLimit.requestsPerSecond and rateLimit.burst must be positive when rate limiting is enabled")
+	}
+
+	if c.Logging.Level != "" {
+		if _, err := logrus.ParseLevel(c.Logging.Level); err != nil {
+			fail("logging.level: %w", err)
+		}
+	}
+	if c.Logging.Access.SampleRate < 0 || c.Logging.Access.SampleRate > 1 {
+		fail("logging.access.sampling.rate must be between 0 and 1, got %g", c.Logging.Access.SampleRate)
+	}
+	if c.Logging.Access.MaxBodyBytes < 0 {
+		fail("logging.access.maxBodyBytes must not be negative")
+	}
+
+	if err := c.Metrics.Validate(); err != nil {
+		fail("telemetry.metrics: %w", err)
+	}
+	if c.Tracing.SamplingRatio < 0 || c.Tracing.SamplingRatio > 1 {
+		fail("tracing.sampling.ratio must be between 0 and 1, got %g", c.Tracing.SamplingRatio)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,349 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/http/middleware"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/telemetry"
+)
+
+const validProps = `
+server:
+  port: 9090
+  cors:
+    allowedOrigins: ["https://pizzamaker.example"]
+database:
+  host: "db"
+  port: 3306
+  username: "user"
+  password: "secret"
+  dbName: "pizzamaker"
+grpc:
+  host: "localhost"
+  calculator:
+    port: 50051
+    timeout: 2s
+  balancer:
+    port: 50052
+tracing:
+  exporter: "none"
+recipes:
+  rounding:
+    enabled: true
+    classes:
+      Flour: 5
+      leavening: 0.1
+`
+
+func writeProps(t *testing.T, content string) {
+	t.Helper()
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "props.yml"), []byte(content), 0o600))
+	t.Setenv("CONFIG_PATH", dir)
+	t.Setenv("CONFIG_NAME", "props")
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("reads file from CONFIG_PATH", func(t *testing.T) {
+		writeProps(t, validProps)
+
+		config, err := Load("recipe-manager", "1.0.0")
+		require.NoError(t, err)
+
+		assert.Equal(t, 9090, config.Server.Port)
+		assert.Equal(t, []string{"https://pizzamaker.example"}, config.Server.CORSOrigins)
+		assert.Equal(t, middleware.CompressionConfig{Enabled: true, MinSize: 1024, Encodings: []string{"zstd", "gzip"}}, config.Server.Compression)
+		assert.Equal(t, "user:secret@tcp(db:3306)/pizzamaker?collation=utf8mb4_unicode_ci&parseTime=true&readTimeout=30s&timeout=5s&writeTimeout=30s&charset=utf8mb4", config.Database.DSN())
+		assert.Equal(t, 3*time.Second, config.Database.QueryTimeout)
+		assert.Equal(t, 25, config.Database.MaxOpenConns)
+		assert.Equal(t, GRPCConfig{Address: "localhost:50051", Timeout: 2 * time.Second}, config.Calculator)
+		assert.Equal(t, GRPCConfig{Address: "localhost:50052", Timeout: 5 * time.Second}, config.Balancer)
+		assert.Equal(t, 720*time.Hour, config.Recipes.PurgeRetention)
+		assert.Equal(t, RoundingConfig{Enabled: true, Classes: map[string]float64{"flour": 5, "leavening": 0.1}}, config.Recipes.Rounding)
+		assert.False(t, config.Outbox.Enabled)
+		assert.Equal(t, 168*time.Hour, config.Outbox.Retention)
+		assert.Equal(t, 8, config.Webhooks.MaxAttempts)
+		assert.Equal(t, 30*time.Second, config.Webhooks.InitialBackoff)
+		assert.True(t, config.API.Legacy)
+		assert.Equal(t, time.Date(2027, 4, 19, 0, 0, 0, 0, time.UTC), config.API.LegacyDeprecation.Sunset)
+	})
+
+	t.Run("applies deployment environment variables", func(t *testing.T) {
+		writeProps(t, validProps)
+		t.Setenv("CALCULATOR_ADDR", "calculator:50051")
+		t.Setenv("DATABASE_USER", "deployer")
+		t.Setenv("LOG_LEVEL", "debug")
+		t.Setenv("GRPC_BALANCER_TIMEOUT", "750ms")
+
+		config, err := Load("recipe-manager", "1.0.0")
+		require.NoError(t, err)
+
+		assert.Equal(t, "calculator:50051", config.Calculator.Address)
+		assert.Equal(t, "deployer", config.Database.User)
+		assert.Equal(t, "debug", config.Logging.Level)
+		assert.Equal(t, 750*time.Millisecond, config.Balancer.Timeout)
+	})
+
+	t.Run("reports every invalid value", func(t *testing.T) {
+		writeProps(t, `
+server:
+  port: 0
+  cors:
+    allowedOrigins: ["localhost:3000"]
+  compression:
+    minSize: -1
+    encodings: ["gzip", "br"]
+database:
+  host: "db"
+  port: 3306
+  username: "user"
+grpc:
+  calculator:
+    address: "calculator:50051"
+    timeout: 0s
+  balancer:
+    address: "balancer"
+logging:
+  level: "loud"
+rateLimit:
+  enabled: true
+  requestsPerSecond: 0
+recipes:
+  purge:
+    retention: 0s
+  rounding:
+    enabled: true
+    default: -1
+    classes:
+      flour: "a handful"
+outbox:
+  enabled: true
+  sink:
+    url: "menu-board/events"
+`)
+
+		_, err := Load("recipe-manager", "1.0.0")
+		require.Error(t, err)
+
+		for _, expected := range []string{
+			"server.port must be between 1 and 65535",
+			`server.cors.allowedOrigins: "localhost:3000"`,
+			"server.compression.minSize must not be negative, got -1",
+			`server.compression.encodings must be zstd or gzip, got "br"`,
+			"database.dbName is required",
+			"grpc.calculator.timeout must be positive",
+			`grpc.balancer.address must be host:port, got "balancer"`,
+			"logging.level",
+			"rateLimit.requestsPerSecond and rateLimit.burst must be positive",
+			"recipes.purge.retention must be positive",
+			"recipes.rounding.default must not be negative, got -1",
+			"recipes.rounding.classes.flour must be a non-negative number of grams",
+			`outbox.sink.url must be an http or https URL, got "menu-board/events"`,
+		} {
+			assert.ErrorContains(t, err, expected)
+		}
+	})
+
+	t.Run("needs only a path for sqlite", func(t *testing.T) {
+		writeProps(t, `
+database:
+  driver: "sqlite"
+  path: "recipes.db"
+grpc:
+  host: "localhost"
+  calculator:
+    port: 50051
+  balancer:
+    port: 50052
+tracing:
+  exporter: "none"
+`)
+
+		config, err := Load("recipe-manager", "1.0.0")
+		require.NoError(t, err)
+
+		assert.Equal(t, DBDriverSQLite, config.Database.Driver)
+		assert.Equal(t, "sqlite", config.Database.DriverName())
+		assert.Equal(t, "recipes.db", config.Database.Name())
+	})
+
+	t.Run("needs no calculator address to measure locally", func(t *testing.T) {
+		writeProps(t, `
+database:
+  driver: "sqlite"
+  path: "recipes.db"
+grpc:
+  calculator:
+    local: true
+  balancer:
+    address: "balancer:50052"
+tracing:
+  exporter: "none"
+`)
+
+		config, err := Load("recipe-manager", "1.0.0")
+		require.NoError(t, err)
+		assert.True(t, config.Calculator.Local)
+		assert.False(t, config.Balancer.Local)
+	})
+
+	t.Run("checks the retries of the webhook subscriptions", func(t *testing.T) {
+		writeProps(t, validProps+`
+outbox:
+  enabled: true
+  sink:
+    type: "subscriptions"
+webhooks:
+  retry:
+    maxAttempts: 0
+    initialBackoff: 2h
+`)
+		_, err := Load("recipe-manager", "1.0.0")
+		assert.ErrorContains(t, err, "webhooks.retry.maxAttempts must be at least 1, got 0")
+		assert.ErrorContains(t, err, "webhooks.retry.initialBackoff must be positive and at most webhooks.retry.maxBackoff")
+	})
+
+	t.Run("checks the deprecation dates of the legacy routes", func(t *testing.T) {
+		writeProps(t, validProps+`
+api:
+  legacy:
+    deprecatedAt: "2027-01-01"
+    sunset: "2026-12-01"
+`)
+		_, err := Load("recipe-manager", "1.0.0")
+		assert.ErrorContains(t, err, "api.legacy.sunset must be after api.legacy.deprecatedAt")
+
+		writeProps(t, validProps+`
+api:
+  legacy:
+    sunset: "next spring"
+`)
+		_, err = Load("recipe-manager", "1.0.0")
+		assert.ErrorContains(t, err, `api.legacy.sunset must be a date like 2027-04-19, got "next spring"`)
+	})
+
+	t.Run("rejects an unknown driver", func(t *testing.T) {
+		writeProps(t, strings.Replace(validProps, `dbName: "pizzamaker"`, `dbName: "pizzamaker"
+  driver: "oracle"`, 1))
+		_, err := Load("recipe-manager", "1.0.0")
+		assert.ErrorContains(t, err, `database.driver must be one of mysql, postgres or sqlite, got "oracle"`)
+	})
+
+	t.Run("fails on missing file", func(t *testing.T) {
+		viper.Reset()
+		t.Cleanup(viper.Reset)
+		t.Setenv("CONFIG_PATH", t.TempDir())
+
+		_, err := Load("recipe-manager", "1.0.0")
+		assert.ErrorContains(t, err, "failed to read properties config")
+	})
+}
+
+func TestDBConfigDSN(t *testing.T) {
+	base := DBConfig{Host: "db", Port: "3306", User: "user", Password: "p@ss", DBName: "pizzamaker"}
+
+	tests := []struct {
+		name     string
+		modify   func(*DBConfig)
+		expected string
+	}{
+		{
+			name:     "minimal",
+			modify:   func(*DBConfig) {},
+			expected: "user:p@ss@tcp(db:3306)/pizzamaker",
+		},
+		{
+			name: "driver options",
+			modify: func(c *DBConfig) {
+				c.ParseTime = true
+				c.Charset = "utf8mb4"
+				c.ReadTimeout = 10 * time.Second
+			},
+			expected: "user:p@ss@tcp(db:3306)/pizzamaker?parseTime=true&readTimeout=10s&charset=utf8mb4",
+		},
+		{
+			name:     "driver TLS mode",
+			modify:   func(c *DBConfig) { c.TLSMode = DBTLSModeSkipVerify },
+			expected: "user:p@ss@tcp(db:3306)/pizzamaker?tls=skip-verify",
+		},
+		{
+			name:     "custom TLS",
+			modify:   func(c *DBConfig) { c.TLSMode = DBTLSModeCustom },
+			expected: "user:p@ss@tcp(db:3306)/pizzamaker?tls=recipe-manager",
+		},
+		{
+			name:     "IPv6 host",
+			modify:   func(c *DBConfig) { c.Host = "::1" },
+			expected: "user:p@ss@tcp([::1]:3306)/pizzamaker",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := base
+			tt.modify(&config)
+			assert.Equal(t, tt.expected, config.DSN())
+		})
+	}
+}
+
+func TestDBConfigRegisterTLS(t *testing.T) {
+	config := DBConfig{Driver: DBDriverMySQL, Host: "db", TLSMode: DBTLSModeCustom}
+	config.TLS.CAFile = filepath.Join(t.TempDir(), "missing.pem")
+
+	assert.ErrorContains(t, config.RegisterTLS(), "failed to build database TLS config")
+
+	config.TLSMode = DBTLSModeEnabled
+	assert.NoError(t, config.RegisterTLS())
+
+	// Other drivers read the certificate files themselves.
+	config.Driver, config.TLSMode = DBDriverPostgres, DBTLSModeCustom
+	assert.NoError(t, config.RegisterTLS())
+}
+
+func TestDBConfigDSNByDriver(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     DBConfig
+		driverName string
+		expected   string
+	}{
+		{
+			name:       "postgres",
+			config:     DBConfig{Driver: DBDriverPostgres, Host: "db", Port: "5432", User: "user", Password: "p@ss", DBName: "pizzamaker", ConnectTimeout: 5 * time.Second},
+			driverName: "pgx",
+			expected:   "postgres://user:p%40ss@db:5432/pizzamaker?connect_timeout=5&sslmode=disable",
+		},
+		{
+			name: "postgres with custom TLS",
+			config: DBConfig{Driver: DBDriverPostgres, Host: "db", Port: "5432", User: "user", DBName: "pizzamaker",
+				TLSMode: DBTLSModeCustom, TLS: telemetry.TLSConfig{CAFile: "/certs/ca.pem"}},
+			driverName: "pgx",
+			expected:   "postgres://user:@db:5432/pizzamaker?sslmode=verify-full&sslrootcert=%2Fcerts%2Fca.pem",
+		},
+		{
+			name:       "sqlite",
+			config:     DBConfig{Driver: DBDriverSQLite, Path: "/var/lib/recipe-manager/recipes.db"},
+			driverName: "sqlite",
+			expected:   "file:/var/lib/recipe-manager/recipes.db?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_time_format=sqlite",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.driverName, tt.config.DriverName())
+			assert.Equal(t, tt.expected, tt.config.DSN())
+		})
+	}
+}
@@ -2,34 +2,242 @@ package configs
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/spf13/viper"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/telemetry"
+)
+
+// customTLSConfigName is the name under which the TLS configuration built
+// from database.tls is registered in the MySQL driver.
+const customTLSConfigName = "recipe-manager"
+
+const (
+	DBDriverMySQL    = "mysql"
+	DBDriverPostgres = "postgres"
+	DBDriverSQLite   = "sqlite"
+)
+
+const (
+	DBTLSModeDisabled   = "false"
+	DBTLSModeEnabled    = "true"
+	DBTLSModeSkipVerify = "skip-verify"
+	DBTLSModePreferred  = "preferred"
+	DBTLSModeCustom     = "custom"
 )
 
 type DBConfig struct {
+	// Driver is one of the DBDriver constants.
+	Driver string
+	// Path is the database file used by DBDriverSQLite, instead of the
+	// network settings below.
+	Path string
+
 	Host     string
 	Port     string
 	User     string
 	Password string
 	DBName   string
+
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+
+	// ParseTime, Charset and Collation only apply to MySQL.
+	ParseTime bool
+	Charset   string
+	Collation string
+	// TLSMode is one of the DBTLSMode constants. DBTLSModeCustom verifies the
+	// server against TLS.CAFile and presents TLS.CertFile as client certificate.
+	TLSMode string
+	TLS     telemetry.TLSConfig
+
+	ConnectTimeout time.Duration
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+	// QueryTimeout bounds each repository query, independently of the request.
+	QueryTimeout time.Duration
+
+	Migrations MigrationsConfig
+}
+
+type MigrationsConfig struct {
+	// Auto applies the schema migrations on startup.
+	Auto bool
+	// Seed also applies the seed data on startup.
+	Seed bool
+}
+
+func setDatabaseDefaults() {
+	viper.SetDefault("database.driver", DBDriverMySQL)
+	viper.SetDefault("database.path", "recipe-manager.db")
+	viper.SetDefault("database.pool.maxOpenConns", 25)
+	viper.SetDefault("database.pool.maxIdleConns", 25)
+	viper.SetDefault("database.pool.connMaxLifetime", "5m")
+	viper.SetDefault("database.pool.connMaxIdleTime", "1m")
+	viper.SetDefault("database.parseTime", true)
+	viper.SetDefault("database.charset", "utf8mb4")
+	viper.SetDefault("database.collation", "utf8mb4_unicode_ci")
+	viper.SetDefault("database.tls.mode", DBTLSModeDisabled)
+	viper.SetDefault("database.timeouts.connect", "5s")
+	viper.SetDefault("database.timeouts.read", "30s")
+	viper.SetDefault("database.timeouts.write", "30s")
+	viper.SetDefault("database.timeouts.query", "3s")
 }
 
 func NewDBConfig() *DBConfig {
 	return &DBConfig{
-		Host:     viper.GetString("database.host"),
-		Port:     viper.GetString("database.port"),
-		User:     viper.GetString("database.username"),
-		Password: viper.GetString("database.password"),
-		DBName:   viper.GetString("database.dbName"),
+		Driver:          viper.GetString("database.driver"),
+		Path:            viper.GetString("database.path"),
+		Host:            viper.GetString("database.host"),
+		Port:            viper.GetString("database.port"),
+		User:            viper.GetString("database.username"),
+		Password:        viper.GetString("database.password"),
+		DBName:          viper.GetString("database.dbName"),
+		MaxOpenConns:    viper.GetInt("database.pool.maxOpenConns"),
+		MaxIdleConns:    viper.GetInt("database.pool.maxIdleConns"),
+		ConnMaxLifetime: viper.GetDuration("database.pool.connMaxLifetime"),
+		ConnMaxIdleTime: viper.GetDuration("database.pool.connMaxIdleTime"),
+		ParseTime:       viper.GetBool("database.parseTime"),
+		Charset:         viper.GetString("database.charset"),
+		Collation:       viper.GetString("database.collation"),
+		TLSMode:         viper.GetString("database.tls.mode"),
+		TLS:             loadTLSConfig("database.tls"),
+		ConnectTimeout:  viper.GetDuration("database.timeouts.connect"),
+		ReadTimeout:     viper.GetDuration("database.timeouts.read"),
+		WriteTimeout:    viper.GetDuration("database.timeouts.write"),
+		QueryTimeout:    viper.GetDuration("database.timeouts.query"),
+		Migrations: MigrationsConfig{
+			Auto: viper.GetBool("database.migrations.auto"),
+			Seed: viper.GetBool("database.migrations.seed"),
+		},
+	}
+}
+
+// DriverName is the database/sql driver to open DSN with.
+func (c *DBConfig) DriverName() string {
+	if c.Driver == DBDriverPostgres {
+		return "pgx"
 	}
+	return c.Driver
+}
+
+// Name identifies the database in logs and metrics: the file of SQLite, the
+// database name otherwise.
+func (c *DBConfig) Name() string {
+	if c.Driver == DBDriverSQLite {
+		return filepath.Base(c.Path)
+	}
+	return c.DBName
 }
 
 func (c *DBConfig) DSN() string {
-	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s",
-		c.User,
-		c.Password,
-		c.Host,
-		c.Port,
-		c.DBName,
-	)
+	switch c.Driver {
+	case DBDriverPostgres:
+		return c.postgresDSN()
+	case DBDriverSQLite:
+		return c.sqliteDSN()
+	default:
+		return c.mysqlDSN()
+	}
+}
+
+func (c *DBConfig) mysqlDSN() string {
+	config := mysql.NewConfig()
+	config.User = c.User
+	config.Passwd = c.Password
+	config.Net = "tcp"
+	config.Addr = net.JoinHostPort(c.Host, c.Port)
+	config.DBName = c.DBName
+	config.ParseTime = c.ParseTime
+	config.Collation = c.Collation
+	config.Timeout = c.ConnectTimeout
+	config.ReadTimeout = c.ReadTimeout
+	config.WriteTimeout = c.WriteTimeout
+	if c.Charset != "" {
+		config.Params = map[string]string{"charset": c.Charset}
+	}
+
+	switch c.TLSMode {
+	case "", DBTLSModeDisabled:
+	case DBTLSModeCustom:
+		config.TLSConfig = customTLSConfigName
+	default:
+		config.TLSConfig = c.TLSMode
+	}
+
+	return config.FormatDSN()
+}
+
+// postgresDSN maps the TLS modes to sslmode: preferred tries TLS without
+// verifying, true and custom verify the server certificate and host name.
+func (c *DBConfig) postgresDSN() string {
+	query := url.Values{}
+	switch c.TLSMode {
+	case "", DBTLSModeDisabled:
+		query.Set("sslmode", "disable")
+	case DBTLSModeSkipVerify:
+		query.Set("sslmode", "require")
+	case DBTLSModePreferred:
+		query.Set("sslmode", "prefer")
+	default:
+		query.Set("sslmode", "verify-full")
+	}
+	if c.TLSMode == DBTLSModeCustom {
+		setIfNotEmpty(query, "sslrootcert", c.TLS.CAFile)
+		setIfNotEmpty(query, "sslcert", c.TLS.CertFile)
+		setIfNotEmpty(query, "sslkey", c.TLS.KeyFile)
+	}
+	if c.ConnectTimeout > 0 {
+		// connect_timeout is in whole seconds, and 0 means no timeout.
+		query.Set("connect_timeout", strconv.Itoa(max(1, int(c.ConnectTimeout.Seconds()))))
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.User, c.Password),
+		Host:     net.JoinHostPort(c.Host, c.Port),
+		Path:     "/" + c.DBName,
+		RawQuery: query.Encode(),
+	}
+	return dsn.String()
+}
+
+// sqliteDSN enables the foreign keys, off by default in SQLite, and waits
+// for locks held by other connections instead of failing at once. Times are
+// written in the format of the SQLite date functions, which sorts as text.
+func (c *DBConfig) sqliteDSN() string {
+	return "file:" + c.Path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_time_format=sqlite"
+}
+
+func setIfNotEmpty(values url.Values, key, value string) {
+	if value != "" {
+		values.Set(key, value)
+	}
+}
+
+// RegisterTLS makes the custom TLS configuration referenced by DSN known to
+// the MySQL driver. It must run before the connection is opened and is a
+// no-op for the other TLS modes and drivers, which read the files from DSN.
+func (c *DBConfig) RegisterTLS() error {
+	if c.Driver != DBDriverMySQL || c.TLSMode != DBTLSModeCustom {
+		return nil
+	}
+
+	tlsConfig, err := telemetry.NewTLSConfig(c.TLS)
+	if err != nil {
+		return fmt.Errorf("failed to build database TLS config: %w", err)
+	}
+	tlsConfig.ServerName = c.Host
+	if err := mysql.RegisterTLSConfig(customTLSConfigName, tlsConfig); err != nil {
+		return fmt.Errorf("failed to register database TLS config: %w", err)
+	}
+	return nil
 }
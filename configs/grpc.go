@@ -1,46 +1,48 @@
 package configs
 
 import (
-	"log"
-	"os"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
+const defaultGRPCTimeout = 5 * time.Second
+
 type GRPCConfig struct {
 	Address string
 	Timeout time.Duration
+	// Local replaces the service with its in-process counterpart, when it
+	// has one, leaving Address unused.
+	Local bool
 }
 
+// LoadCalculatorGRPCConfig reads grpc.calculator.address, falling back to
+// grpc.host and grpc.calculator.port. CALCULATOR_ADDR overrides both.
+// grpc.calculator.local measures the pans in process instead.
 func LoadCalculatorGRPCConfig() GRPCConfig {
-	calculatorAddr := os.Getenv("CALCULATOR_ADDR")
-	if calculatorAddr == "" {
-		host := viper.GetString("grpc.host")
-		port := viper.GetString("grpc.calculator.port")
-		calculatorAddr = host + ":" + port
-	}
-	timeout := 5 * time.Second
-	log.Println("calculatorAddr: ", calculatorAddr)
-
-	return GRPCConfig{
-		Address: calculatorAddr,
-		Timeout: timeout,
-	}
+	config := loadGRPCConfig("grpc.calculator")
+	config.Local = viper.GetBool("grpc.calculator.local")
+	return config
 }
 
+// LoadBalancerGRPCConfig reads grpc.balancer.address, falling back to
+// grpc.host and grpc.balancer.port. INGREDIENTS_BALANCER_ADDR overrides both.
 func LoadBalancerGRPCConfig() GRPCConfig {
-	balancerAddr := os.Getenv("INGREDIENTS_BALANCER_ADDR")
-	if balancerAddr == "" {
-		host := viper.GetString("grpc.host")
-		port := viper.GetString("grpc.balancer.port")
-		balancerAddr = host + ":" + port
+	return loadGRPCConfig("grpc.balancer")
+}
+
+func setGRPCDefaults(prefix string) {
+	viper.SetDefault(prefix+".timeout", defaultGRPCTimeout)
+}
+
+func loadGRPCConfig(prefix string) GRPCConfig {
+	address := viper.GetString(prefix + ".address")
+	if address == "" {
+		address = viper.GetString("grpc.host") + ":" + viper.GetString(prefix+".port")
 	}
-	timeout := 5 * time.Second
-	log.Println("balancerAddr: ", balancerAddr)
 
 	return GRPCConfig{
-		Address: balancerAddr,
-		Timeout: timeout,
+		Address: address,
+		Timeout: viper.GetDuration(prefix + ".timeout"),
 	}
 }
@@ -0,0 +1,41 @@
+package configs
+
+import (
+	"github.com/spf13/viper"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/logging"
+)
+
+func setAccessLogDefaults() {
+	defaults := logging.DefaultAccessLogConfig()
+
+	viper.SetDefault("logging.access.singleLine", defaults.SingleLine)
+	viper.SetDefault("logging.access.captureBodies", defaults.CaptureBodies)
+	viper.SetDefault("logging.access.maxBodyBytes", defaults.MaxBodyBytes)
+	viper.SetDefault("logging.access.redactClientIP", defaults.RedactClientIP)
+	viper.SetDefault("logging.access.includeUserAgent", defaults.IncludeUserAgent)
+	viper.SetDefault("logging.access.logHeaders", defaults.LogHeaders)
+	viper.SetDefault("logging.access.redactHeaders", defaults.RedactHeaders)
+	viper.SetDefault("logging.access.redactFields", defaults.RedactFields)
+	viper.SetDefault("logging.access.sampling.paths", defaults.SampledPaths)
+	viper.SetDefault("logging.access.sampling.rate", defaults.SampleRate)
+}
+
+func LoadAccessLogConfig() logging.AccessLogConfig {
+	return logging.AccessLogConfig{
+		SingleLine:       viper.GetBool("logging.access.singleLine"),
+		CaptureBodies:    viper.GetBool("logging.access.captureBodies"),
+		MaxBodyBytes:     viper.GetInt("logging.access.maxBodyBytes"),
+		RedactClientIP:   viper.GetBool("logging.access.redactClientIP"),
+		IncludeUserAgent: viper.GetBool("logging.access.includeUserAgent"),
+		LogHeaders:       viper.GetStringSlice("logging.access.logHeaders"),
+		RedactHeaders:    viper.GetStringSlice("logging.access.redactHeaders"),
+		RedactFields:     viper.GetStringSlice("logging.access.redactFields"),
+		SampledPaths:     viper.GetStringSlice("logging.access.sampling.paths"),
+		SampleRate:       viper.GetFloat64("logging.access.sampling.rate"),
+	}
+}
+
+func LoadAdminToken() string {
+	return viper.GetString("admin.token")
+}
@@ -0,0 +1,58 @@
+package configs
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	OutboxSinkWebhook = "webhook"
+	OutboxSinkFile    = "file"
+	// OutboxSinkSubscriptions delivers the events to the webhook
+	// subscriptions managed through the admin API.
+	OutboxSinkSubscriptions = "subscriptions"
+)
+
+type OutboxConfig struct {
+	// Enabled stores a domain event with every recipe change and relays
+	// them to the sink.
+	Enabled       bool
+	RelayInterval time.Duration
+	BatchSize     int
+	// MaxAttempts is how many deliveries an event gets before it is parked.
+	MaxAttempts int
+	// Sink is one of the OutboxSink constants.
+	Sink           string
+	WebhookURL     string
+	WebhookTimeout time.Duration
+	FilePath       string
+	// Retention is how long published events are kept before the purge
+	// command removes them.
+	Retention time.Duration
+}
+
+func setOutboxDefaults() {
+	viper.SetDefault("outbox.enabled", false)
+	viper.SetDefault("outbox.relay.interval", time.Second)
+	viper.SetDefault("outbox.relay.batchSize", 100)
+	viper.SetDefault("outbox.relay.maxAttempts", 100)
+	viper.SetDefault("outbox.sink.type", OutboxSinkWebhook)
+	viper.SetDefault("outbox.sink.timeout", 5*time.Second)
+	viper.SetDefault("outbox.sink.path", "events.jsonl")
+	viper.SetDefault("outbox.retention", 7*24*time.Hour)
+}
+
+func LoadOutboxConfig() OutboxConfig {
+	return OutboxConfig{
+		Enabled:        viper.GetBool("outbox.enabled"),
+		RelayInterval:  viper.GetDuration("outbox.relay.interval"),
+		BatchSize:      viper.GetInt("outbox.relay.batchSize"),
+		MaxAttempts:    viper.GetInt("outbox.relay.maxAttempts"),
+		Sink:           viper.GetString("outbox.sink.type"),
+		WebhookURL:     viper.GetString("outbox.sink.url"),
+		WebhookTimeout: viper.GetDuration("outbox.sink.timeout"),
+		FilePath:       viper.GetString("outbox.sink.path"),
+		Retention:      viper.GetDuration("outbox.retention"),
+	}
+}
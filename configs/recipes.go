@@ -0,0 +1,59 @@
+package configs
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+)
+
+type RecipesConfig struct {
+	// PurgeRetention is how long deleted recipes stay restorable before the
+	// purge command removes them for good.
+	PurgeRetention time.Duration
+	Rounding       RoundingConfig
+}
+
+// RoundingConfig sets the steps, in grams, the split amounts of aggregates
+// are rounded to.
+type RoundingConfig struct {
+	Enabled bool
+	Default float64
+	// Classes maps ingredient names and catalogue categories to their step.
+	// Values that are not numbers are kept as NaN for Validate to report.
+	Classes map[string]float64
+}
+
+// Policy is the rounding policy of the configured steps.
+func (c RoundingConfig) Policy() domain.RoundingPolicy {
+	return domain.RoundingPolicy{Default: c.Default, Classes: c.Classes}
+}
+
+func setRecipesDefaults() {
+	viper.SetDefault("recipes.purge.retention", 30*24*time.Hour)
+	viper.SetDefault("recipes.rounding.enabled", false)
+	viper.SetDefault("recipes.rounding.default", 0)
+}
+
+func LoadRecipesConfig() RecipesConfig {
+	classes := make(map[string]float64)
+	for class, value := range viper.GetStringMapString("recipes.rounding.classes") {
+		step, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			step = math.NaN()
+		}
+		classes[class] = step
+	}
+
+	return RecipesConfig{
+		PurgeRetention: viper.GetDuration("recipes.purge.retention"),
+		Rounding: RoundingConfig{
+			Enabled: viper.GetBool("recipes.rounding.enabled"),
+			Default: viper.GetFloat64("recipes.rounding.default"),
+			Classes: classes,
+		},
+	}
+}
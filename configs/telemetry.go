@@ -0,0 +1,73 @@
+package configs
+
+import (
+	"github.com/spf13/viper"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/telemetry"
+)
+
+func setResourceDefaults(serviceName, version string) {
+	viper.SetDefault("telemetry.serviceName", serviceName)
+	viper.SetDefault("telemetry.serviceVersion", version)
+	viper.SetDefault("telemetry.environment", "development")
+}
+
+func LoadResourceConfig() telemetry.ResourceConfig {
+	return telemetry.ResourceConfig{
+		ServiceName:    viper.GetString("telemetry.serviceName"),
+		ServiceVersion: viper.GetString("telemetry.serviceVersion"),
+		Environment:    viper.GetString("telemetry.environment"),
+		Attributes:     telemetry.ParseResourceAttributes(viper.GetStringSlice("telemetry.resourceAttributes")),
+	}
+}
+
+func setMetricsDefaults() {
+	viper.SetDefault("telemetry.metrics.backend", telemetry.MetricsBackendPrometheus)
+	viper.SetDefault("telemetry.metrics.urlPath", "/v1/metrics")
+	viper.SetDefault("telemetry.metrics.interval", "15s")
+}
+
+func LoadMetricsConfig() telemetry.MetricsConfig {
+	return telemetry.MetricsConfig{
+		ExporterConfig: loadExporterConfig("telemetry.metrics"),
+		Backend:        viper.GetString("telemetry.metrics.backend"),
+		Interval:       viper.GetDuration("telemetry.metrics.interval"),
+	}
+}
+
+func setLogsDefaults() {
+	viper.SetDefault("telemetry.logs.urlPath", "/v1/logs")
+}
+
+func LoadLogsConfig() telemetry.LogsConfig {
+	return telemetry.LogsConfig{
+		ExporterConfig: loadExporterConfig("telemetry.logs"),
+		Enabled:        viper.GetBool("telemetry.logs.enabled"),
+	}
+}
+
+func setExporterDefaults(prefix string) {
+	viper.SetDefault(prefix+".exporter", telemetry.ExporterOTLPHTTP)
+	viper.SetDefault(prefix+".endpoint", "localhost:4318")
+	viper.SetDefault(prefix+".insecure", true)
+}
+
+func loadExporterConfig(prefix string) telemetry.ExporterConfig {
+	return telemetry.ExporterConfig{
+		Exporter: viper.GetString(prefix + ".exporter"),
+		Endpoint: viper.GetString(prefix + ".endpoint"),
+		URLPath:  viper.GetString(prefix + ".urlPath"),
+		Insecure: viper.GetBool(prefix + ".insecure"),
+		TLS:      loadTLSConfig(prefix + ".tls"),
+		Headers:  viper.GetStringMapString(prefix + ".headers"),
+	}
+}
+
+func loadTLSConfig(prefix string) telemetry.TLSConfig {
+	return telemetry.TLSConfig{
+		CAFile:             viper.GetString(prefix + ".caFile"),
+		CertFile:           viper.GetString(prefix + ".certFile"),
+		KeyFile:            viper.GetString(prefix + ".keyFile"),
+		InsecureSkipVerify: viper.GetBool(prefix + ".insecureSkipVerify"),
+	}
+}
@@ -0,0 +1,43 @@
+package configs
+
+import (
+	"github.com/spf13/viper"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/tracing"
+)
+
+func setTracingDefaults() {
+	defaults := tracing.DefaultTracerConfig()
+
+	viper.SetDefault("tracing.exporter", defaults.Exporter)
+	viper.SetDefault("tracing.endpoint", defaults.JaegerEndpoint)
+	viper.SetDefault("tracing.urlPath", defaults.URLPath)
+	viper.SetDefault("tracing.insecure", defaults.Insecure)
+	viper.SetDefault("tracing.filePath", defaults.FilePath)
+	viper.SetDefault("tracing.sampling.ratio", defaults.SamplingRatio)
+	viper.SetDefault("tracing.sampling.parentBased", defaults.ParentBased)
+	viper.SetDefault("tracing.batch.timeout", defaults.BatchTimeout)
+	viper.SetDefault("tracing.batch.maxExportSize", defaults.MaxExportBatchSize)
+}
+
+func LoadTracingConfig() *tracing.TracerConfig {
+	resource := LoadResourceConfig()
+
+	return &tracing.TracerConfig{
+		ServiceName:        resource.ServiceName,
+		ServiceVersion:     resource.ServiceVersion,
+		JaegerEndpoint:     viper.GetString("tracing.endpoint"),
+		Environment:        resource.Environment,
+		Exporter:           viper.GetString("tracing.exporter"),
+		URLPath:            viper.GetString("tracing.urlPath"),
+		Insecure:           viper.GetBool("tracing.insecure"),
+		TLS:                loadTLSConfig("tracing.tls"),
+		Headers:            viper.GetStringMapString("tracing.headers"),
+		FilePath:           viper.GetString("tracing.filePath"),
+		SamplingRatio:      viper.GetFloat64("tracing.sampling.ratio"),
+		ParentBased:        viper.GetBool("tracing.sampling.parentBased"),
+		ResourceAttributes: resource.Attributes,
+		BatchTimeout:       viper.GetDuration("tracing.batch.timeout"),
+		MaxExportBatchSize: viper.GetInt("tracing.batch.maxExportSize"),
+	}
+}
@@ -0,0 +1,42 @@
+package configs
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// WebhooksConfig tunes the deliveries to the webhook subscriptions, made
+// when outbox.sink.type is subscriptions.
+type WebhooksConfig struct {
+	Interval  time.Duration
+	BatchSize int
+	Timeout   time.Duration
+	// MaxAttempts is how many attempts a delivery gets before it is moved
+	// to the dead-letter list.
+	MaxAttempts int
+	// InitialBackoff is the wait after the first failed attempt, doubled
+	// after each further one up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+func setWebhooksDefaults() {
+	viper.SetDefault("webhooks.delivery.interval", time.Second)
+	viper.SetDefault("webhooks.delivery.batchSize", 50)
+	viper.SetDefault("webhooks.delivery.timeout", 5*time.Second)
+	viper.SetDefault("webhooks.retry.maxAttempts", 8)
+	viper.SetDefault("webhooks.retry.initialBackoff", 30*time.Second)
+	viper.SetDefault("webhooks.retry.maxBackoff", time.Hour)
+}
+
+func LoadWebhooksConfig() WebhooksConfig {
+	return WebhooksConfig{
+		Interval:       viper.GetDuration("webhooks.delivery.interval"),
+		BatchSize:      viper.GetInt("webhooks.delivery.batchSize"),
+		Timeout:        viper.GetDuration("webhooks.delivery.timeout"),
+		MaxAttempts:    viper.GetInt("webhooks.retry.maxAttempts"),
+		InitialBackoff: viper.GetDuration("webhooks.retry.initialBackoff"),
+		MaxBackoff:     viper.GetDuration("webhooks.retry.maxBackoff"),
+	}
+}
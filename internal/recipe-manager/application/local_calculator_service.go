@@ -0,0 +1,55 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+)
+
+// LocalCalculatorService measures pans in process, for deployments without
+// the dough calculator service.
+type LocalCalculatorService struct{}
+
+func NewLocalCalculatorService() *LocalCalculatorService {
+	return &LocalCalculatorService{}
+}
+
+// TotalDoughWeightByPans works out the area of each pan in cm² and the dough
+// it takes at the dough density of pans, the one of the Neapolitan style
+// when unset.
+func (lc *LocalCalculatorService) TotalDoughWeightByPans(_ context.Context, pans domain.Pans) (*domain.Pans, error) {
+	if pans.DoughDensity == 0 {
+		style, _ := domain.StyleByName(domain.StyleNeapolitan)
+		pans.Style, pans.DoughDensity = style.Name, style.DoughDensity
+	}
+
+	result := domain.Pans{
+		Pans:         make([]domain.Pan, 0, len(pans.Pans)),
+		Style:        pans.Style,
+		DoughDensity: pans.DoughDensity,
+	}
+	for _, pan := range pans.Pans {
+		measures := pan.Measures
+		switch {
+		case pan.Shape == "round" && measures.Diameter != nil:
+			radius := float64(*measures.Diameter) / 2
+			pan.Name = fmt.Sprintf("round %d cm", *measures.Diameter)
+			pan.Area = math.Pi * radius * radius
+		case pan.Shape == "square" && measures.Edge != nil:
+			pan.Name = fmt.Sprintf("square %d cm", *measures.Edge)
+			pan.Area = float64(*measures.Edge * *measures.Edge)
+		case pan.Shape == "rectangular" && measures.Width != nil && measures.Length != nil:
+			pan.Name = fmt.Sprintf("rectangular %dx%d cm", *measures.Width, *measures.Length)
+			pan.Area = float64(*measures.Width * *measures.Length)
+		default:
+			return nil, fmt.Errorf("cannot measure %s pan without its measures", pan.Shape)
+		}
+		pan.Area = math.Round(pan.Area*100) / 100
+		pan.DoughWeight = math.Round(pan.Area*pans.DoughDensity*10) / 10
+		result.Pans = append(result.Pans, pan)
+		result.TotalArea += pan.Area
+	}
+	return &result, nil
+}
@@ -0,0 +1,61 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/application"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+)
+
+func TestLocalTotalDoughWeightByPans(t *testing.T) {
+	diameter, edge, width, length := 30, 20, 30, 40
+	service := application.NewLocalCalculatorService()
+
+	t.Run("measures each shape at the dough density", func(t *testing.T) {
+		result, err := service.TotalDoughWeightByPans(context.Background(), domain.Pans{
+			Pans: []domain.Pan{
+				{Shape: "round", Measures: domain.Measures{Diameter: &diameter}, Slices: 6},
+				{Shape: "square", Measures: domain.Measures{Edge: &edge}},
+				{Shape: "rectangular", Measures: domain.Measures{Width: &width, Length: &length}},
+			},
+			Style:        domain.StyleDetroit,
+			DoughDensity: 0.6,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"round 30 cm", "square 20 cm", "rectangular 30x40 cm"},
+			[]string{result.Pans[0].Name, result.Pans[1].Name, result.Pans[2].Name})
+		assert.Equal(t, 706.86, result.Pans[0].Area)
+		assert.Equal(t, 424.1, result.Pans[0].DoughWeight)
+		assert.Equal(t, 6, result.Pans[0].Slices)
+		assert.Equal(t, 400.0, result.Pans[1].Area)
+		assert.Equal(t, 240.0, result.Pans[1].DoughWeight)
+		assert.Equal(t, 1200.0, result.Pans[2].Area)
+		assert.Equal(t, 720.0, result.Pans[2].DoughWeight)
+		assert.InDelta(t, 2306.86, result.TotalArea, 1e-9)
+		assert.Equal(t, domain.StyleDetroit, result.Style)
+	})
+
+	t.Run("defaults to the neapolitan density", func(t *testing.T) {
+		result, err := service.TotalDoughWeightByPans(context.Background(), domain.Pans{
+			Pans: []domain.Pan{{Shape: "square", Measures: domain.Measures{Edge: &edge}}},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.StyleNeapolitan, result.Style)
+		assert.Equal(t, 0.35, result.DoughDensity)
+		assert.Equal(t, 140.0, result.Pans[0].DoughWeight)
+	})
+
+	t.Run("pan without measures", func(t *testing.T) {
+		result, err := service.TotalDoughWeightByPans(context.Background(), domain.Pans{
+			Pans: []domain.Pan{{Shape: "round"}},
+		})
+
+		assert.Nil(t, result)
+		assert.Error(t, err)
+	})
+}
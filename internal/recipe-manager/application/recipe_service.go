@@ -2,14 +2,29 @@ package application
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
 
 	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
 )
 
 type RecipeRepository interface {
-	GetRecipeByUuid(uuid.UUID) (*domain.Recipe, error)
+	GetRecipeByUuid(context.Context, uuid.UUID) (*domain.Recipe, error)
+	// GetRecipesByUuids returns the recipes found of the given ones, leaving
+	// out the missing ones.
+	GetRecipesByUuids(context.Context, []uuid.UUID) ([]domain.Recipe, error)
+	ListRecipes(context.Context, domain.RecipeFilter) ([]domain.Recipe, error)
+	CreateRecipe(context.Context, domain.Recipe) error
+	// UpdateRecipe, ArchiveRecipe and DeleteRecipe only apply when the stored
+	// recipe is at the given version, failing with domain.ErrVersionConflict
+	// otherwise.
+	UpdateRecipe(context.Context, domain.Recipe) error
+	ArchiveRecipe(ctx context.Context, recipeUuid uuid.UUID, version int, archived bool) error
+	DeleteRecipe(ctx context.Context, recipeUuid uuid.UUID, version int) error
+	RestoreRecipe(context.Context, uuid.UUID) error
 }
 
 type CalculatorService interface {
@@ -20,35 +35,363 @@ type BalancerService interface {
 	Balance(context.Context, domain.Recipe, domain.Pans) (*domain.RecipeAggregate, error)
 }
 
+// Outbox stores the domain events for downstream systems.
+type Outbox interface {
+	// Transaction runs fn in a transaction the repository takes part in, so
+	// the events appended in fn are stored only with the changes made there.
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+	Append(ctx context.Context, events ...domain.Event) error
+}
+
 type RecipeService struct {
 	repository RecipeRepository
 	calculator CalculatorService
 	balancer   BalancerService
+	outbox     Outbox
+	rounding   *domain.RoundingPolicy
+	logger     logrus.FieldLogger
+}
+
+type Option func(*RecipeService)
+
+// WithOutbox emits a domain event for every change of a recipe and every
+// aggregation. Without it no events are emitted.
+func WithOutbox(outbox Outbox) Option {
+	return func(rs *RecipeService) {
+		rs.outbox = outbox
+	}
 }
 
-func NewRecipeService(repository RecipeRepository, calculator CalculatorService, balancer BalancerService) *RecipeService {
-	return &RecipeService{
+// WithRounding rounds the split amounts of every aggregate to the steps of
+// policy and reports the drift. Without it the amounts are left as balanced.
+func WithRounding(policy domain.RoundingPolicy) Option {
+	return func(rs *RecipeService) {
+		rs.rounding = &policy
+	}
+}
+
+// WithLogger sets where the failures the service gets past are logged, the
+// standard logger by default.
+func WithLogger(logger logrus.FieldLogger) Option {
+	return func(rs *RecipeService) {
+		rs.logger = logger
+	}
+}
+
+func NewRecipeService(repository RecipeRepository, calculator CalculatorService, balancer BalancerService, options ...Option) *RecipeService {
+	service := &RecipeService{
 		repository: repository,
 		calculator: calculator,
 		balancer:   balancer,
+		outbox:     noOutbox{},
+		logger:     logrus.StandardLogger(),
 	}
+	for _, option := range options {
+		option(service)
+	}
+	return service
 }
 
+// Handle balances a stored recipe for the requested pans. The dough density
+// of the request, or else the one of the recipe, is passed to the
+// calculator.
 func (rs *RecipeService) Handle(ctx context.Context, recipeUuid uuid.UUID, request domain.Pans) (*domain.RecipeAggregate, error) {
-	pans, calculatorError := rs.calculator.TotalDoughWeightByPans(ctx, request)
-	if calculatorError != nil {
-		return nil, calculatorError
+	recipe, err := rs.repository.GetRecipeByUuid(ctx, recipeUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	pans, err := rs.measure(ctx, request, *recipe)
+	if err != nil {
+		return nil, err
+	}
+	return rs.aggregate(ctx, *recipe, *pans, nil)
+}
+
+// Schedule balances a stored recipe as Handle does, with the yeast adjusted
+// to the fermentation plan and the schedule of its stages.
+func (rs *RecipeService) Schedule(ctx context.Context, recipeUuid uuid.UUID, request domain.Pans, plan domain.FermentationPlan) (*domain.RecipeAggregate, error) {
+	if err := plan.Validate(); err != nil {
+		return nil, err
+	}
+	recipe, err := rs.repository.GetRecipeByUuid(ctx, recipeUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	pans, err := rs.measure(ctx, request, *recipe)
+	if err != nil {
+		return nil, err
+	}
+	return rs.aggregate(ctx, *recipe, *pans, &plan)
+}
+
+// Aggregate balances a recipe already read for the requested pans, as Handle
+// does for a stored one.
+func (rs *RecipeService) Aggregate(ctx context.Context, recipe domain.Recipe, request domain.Pans) (*domain.RecipeAggregate, error) {
+	pans, err := rs.measure(ctx, request, recipe)
+	if err != nil {
+		return nil, err
+	}
+	return rs.aggregate(ctx, recipe, *pans, nil)
+}
+
+// measure has the calculator work out the area and dough weight of the
+// requested pans, at the dough density resolved for recipe.
+func (rs *RecipeService) measure(ctx context.Context, request domain.Pans, recipe domain.Recipe) (*domain.Pans, error) {
+	request, err := request.DoughDensityFor(recipe)
+	if err != nil {
+		return nil, err
+	}
+	pans, err := rs.calculator.TotalDoughWeightByPans(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	// The calculator does not know about slices, so they are carried over
+	// from the request, as the dough density for the calculators that do
+	// not know about it either.
+	pans.Style, pans.DoughDensity = request.Style, request.DoughDensity
+	for i := range pans.Pans {
+		if i < len(request.Pans) {
+			pans.Pans[i].Slices = request.Pans[i].Slices
+		}
+		if pans.Pans[i].DoughWeight == 0 {
+			pans.Pans[i].DoughWeight = pans.Pans[i].Area * pans.DoughDensity
+		}
+	}
+	return pans, nil
+}
+
+// aggregate balances recipe for pans and, given a plan, adjusts its yeast.
+func (rs *RecipeService) aggregate(ctx context.Context, recipe domain.Recipe, pans domain.Pans, plan *domain.FermentationPlan) (*domain.RecipeAggregate, error) {
+	response, err := rs.balancer.Balance(ctx, recipe, pans)
+	if err != nil {
+		return nil, err
+	}
+	response.Pans = pans
+	return rs.complete(ctx, recipe, response, plan)
+}
+
+// Balls splits a stored recipe into dough balls, without pans and without
+// the calculator and balancer services. Given a plan, the yeast is adjusted
+// as Schedule does.
+func (rs *RecipeService) Balls(ctx context.Context, recipeUuid uuid.UUID, balls domain.Balls, plan *domain.FermentationPlan) (*domain.RecipeAggregate, error) {
+	if err := balls.Validate(); err != nil {
+		return nil, err
+	}
+	if plan != nil {
+		if err := plan.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	recipe, err := rs.repository.GetRecipeByUuid(ctx, recipeUuid)
+	if err != nil {
+		return nil, err
 	}
 
-	recipe, err := rs.repository.GetRecipeByUuid(recipeUuid)
+	response, err := domain.SplitBalls(*recipe, balls)
 	if err != nil {
 		return nil, err
 	}
+	return rs.complete(ctx, *recipe, response, plan)
+}
+
+// Scale scales a stored recipe to servings or to a dough weight, without the
+// calculator and balancer services, and adjusts its yeast to plan, if any.
+func (rs *RecipeService) Scale(ctx context.Context, recipeUuid uuid.UUID, scale domain.Scale, plan *domain.FermentationPlan) (*domain.RecipeAggregate, error) {
+	if err := scale.Validate(); err != nil {
+		return nil, err
+	}
+	if plan != nil {
+		if err := plan.Validate(); err != nil {
+			return nil, err
+		}
+	}
 
-	response, balancerError := rs.balancer.Balance(ctx, *recipe, *pans)
-	if balancerError != nil {
-		return nil, balancerError
+	recipe, err := rs.repository.GetRecipeByUuid(ctx, recipeUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := domain.ScaleRecipe(*recipe, scale)
+	if err != nil {
+		return nil, err
+	}
+	return rs.complete(ctx, *recipe, response, plan)
+}
+
+// complete adjusts the yeast of a split recipe to plan, if any, rounds it
+// and works out its nutrition and labels.
+func (rs *RecipeService) complete(ctx context.Context, recipe domain.Recipe, response *domain.RecipeAggregate, plan *domain.FermentationPlan) (*domain.RecipeAggregate, error) {
+	if plan != nil {
+		fermentation, err := plan.Ferment(response, recipe)
+		if err != nil {
+			return nil, err
+		}
+		response.Fermentation = &fermentation
+	}
+	if rs.rounding != nil {
+		report := rs.rounding.Round(response, recipe)
+		response.Rounding = &report
+	}
+	switch {
+	case response.Balls != nil:
+		response.Nutrition = domain.NutritionByBall(recipe, *response)
+	case response.Scale != nil:
+		response.Nutrition = domain.NutritionByServing(recipe, *response)
+	default:
+		response.Nutrition = domain.NutritionByPan(recipe, *response, response.Pans)
+	}
+	response.Labels = recipe.Labels()
+
+	event := domain.NewEvent(domain.EventRecipeAggregated, recipe)
+	event.Pans = response.Pans
+	// An aggregation is a read, so it does not fail for want of its event.
+	if err := rs.outbox.Append(ctx, event); err != nil {
+		rs.logger.WithError(err).WithField("recipe_uuid", recipe.Uuid).Error("Failed to store the aggregated event")
 	}
 
 	return response, nil
 }
+
+func (rs *RecipeService) Recipe(ctx context.Context, recipeUuid uuid.UUID) (*domain.Recipe, error) {
+	return rs.repository.GetRecipeByUuid(ctx, recipeUuid)
+}
+
+// CreateRecipe stores a new recipe and returns it as stored.
+func (rs *RecipeService) CreateRecipe(ctx context.Context, recipe domain.Recipe) (*domain.Recipe, error) {
+	return rs.change(ctx, domain.EventRecipeCreated, recipe.Uuid, func(ctx context.Context) error {
+		return rs.repository.CreateRecipe(ctx, recipe)
+	})
+}
+
+// UpdateRecipe stores recipe if it is still at recipe.Version and returns it
+// as stored, with its new version.
+func (rs *RecipeService) UpdateRecipe(ctx context.Context, recipe domain.Recipe) (*domain.Recipe, error) {
+	return rs.change(ctx, domain.EventRecipeUpdated, recipe.Uuid, func(ctx context.Context) error {
+		return rs.repository.UpdateRecipe(ctx, recipe)
+	})
+}
+
+// ArchiveRecipe archives the recipe, or brings it back to the listings, if
+// it is still at version and returns it as stored.
+func (rs *RecipeService) ArchiveRecipe(ctx context.Context, recipeUuid uuid.UUID, version int, archived bool) (*domain.Recipe, error) {
+	return rs.change(ctx, domain.EventRecipeUpdated, recipeUuid, func(ctx context.Context) error {
+		return rs.repository.ArchiveRecipe(ctx, recipeUuid, version, archived)
+	})
+}
+
+func (rs *RecipeService) DeleteRecipe(ctx context.Context, recipeUuid uuid.UUID, version int) error {
+	return rs.outbox.Transaction(ctx, func(ctx context.Context) error {
+		recipe, err := rs.repository.GetRecipeByUuid(ctx, recipeUuid)
+		if err != nil {
+			return err
+		}
+		// The event describes the version read, so the delete must not apply
+		// to a later one.
+		if version == 0 {
+			version = recipe.Version
+		}
+		if err := rs.repository.DeleteRecipe(ctx, recipeUuid, version); err != nil {
+			return err
+		}
+
+		deletedAt := time.Now().UTC()
+		recipe.Version = version + 1
+		recipe.DeletedAt = &deletedAt
+		return rs.outbox.Append(ctx, domain.NewEvent(domain.EventRecipeDeleted, *recipe))
+	})
+}
+
+// RestoreRecipe brings back a deleted recipe and returns it as stored.
+func (rs *RecipeService) RestoreRecipe(ctx context.Context, recipeUuid uuid.UUID) (*domain.Recipe, error) {
+	return rs.change(ctx, domain.EventRecipeUpdated, recipeUuid, func(ctx context.Context) error {
+		return rs.repository.RestoreRecipe(ctx, recipeUuid)
+	})
+}
+
+// change applies a change to a recipe and stores the event of the given type
+// with the recipe as the change left it, which it returns.
+func (rs *RecipeService) change(ctx context.Context, eventType domain.EventType, recipeUuid uuid.UUID, apply func(ctx context.Context) error) (*domain.Recipe, error) {
+	var changed *domain.Recipe
+	err := rs.outbox.Transaction(ctx, func(ctx context.Context) error {
+		if err := apply(ctx); err != nil {
+			return err
+		}
+		recipe, err := rs.repository.GetRecipeByUuid(ctx, recipeUuid)
+		if err != nil {
+			return err
+		}
+		changed = recipe
+		return rs.outbox.Append(ctx, domain.NewEvent(eventType, *recipe))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+// DeletedRecipes returns the deleted recipes that are not purged yet.
+func (rs *RecipeService) DeletedRecipes(ctx context.Context) ([]domain.Recipe, error) {
+	return rs.repository.ListRecipes(ctx, domain.RecipeFilter{Deleted: true})
+}
+
+// RecipesFreeFrom returns the recipes selected by filter declaring none of
+// allergens, or all of them when allergens is empty. Recipes with
+// ingredients missing from the catalogue are left out of a filtered list, as
+// their declaration is incomplete.
+func (rs *RecipeService) RecipesFreeFrom(ctx context.Context, filter domain.RecipeFilter, allergens []domain.Allergen) ([]domain.Recipe, error) {
+	recipes, err := rs.repository.ListRecipes(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(allergens) == 0 {
+		return recipes, nil
+	}
+
+	freeFrom := make([]domain.Recipe, 0, len(recipes))
+	for _, recipe := range recipes {
+		if recipe.Labels().FreeFrom(allergens) {
+			freeFrom = append(freeFrom, recipe)
+		}
+	}
+	return freeFrom, nil
+}
+
+// RecipesByUuids returns the recipes of recipeUuids that exist, read at once.
+func (rs *RecipeService) RecipesByUuids(ctx context.Context, recipeUuids []uuid.UUID) ([]domain.Recipe, error) {
+	return rs.repository.GetRecipesByUuids(ctx, recipeUuids)
+}
+
+// SearchRecipes returns the recipes of RecipesFreeFrom whose name,
+// description or author contain text, ignoring case; all of them when text
+// is empty.
+func (rs *RecipeService) SearchRecipes(ctx context.Context, filter domain.RecipeFilter, allergens []domain.Allergen, text string) ([]domain.Recipe, error) {
+	recipes, err := rs.RecipesFreeFrom(ctx, filter, allergens)
+	if err != nil || text == "" {
+		return recipes, err
+	}
+
+	text = strings.ToLower(text)
+	matching := make([]domain.Recipe, 0, len(recipes))
+	for _, recipe := range recipes {
+		for _, field := range []string{recipe.Name, recipe.Description, recipe.Author} {
+			if strings.Contains(strings.ToLower(field), text) {
+				matching = append(matching, recipe)
+				break
+			}
+		}
+	}
+	return matching, nil
+}
+
+type noOutbox struct{}
+
+func (noOutbox) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (noOutbox) Append(context.Context, ...domain.Event) error {
+	return nil
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
 )
@@ -16,11 +20,77 @@ type MockRecipeRepository struct {
 	mock.Mock
 }
 
-func (m *MockRecipeRepository) GetRecipeByUuid(recipeUuid uuid.UUID) (*domain.Recipe, error) {
-	args := m.Called(recipeUuid)
+func (m *MockRecipeRepository) GetRecipeByUuid(ctx context.Context, recipeUuid uuid.UUID) (*domain.Recipe, error) {
+	args := m.Called(ctx, recipeUuid)
 	return args.Get(0).(*domain.Recipe), args.Error(1)
 }
 
+func (m *MockRecipeRepository) GetRecipesByUuids(ctx context.Context, recipeUuids []uuid.UUID) ([]domain.Recipe, error) {
+	args := m.Called(ctx, recipeUuids)
+	return args.Get(0).([]domain.Recipe), args.Error(1)
+}
+
+func (m *MockRecipeRepository) ListRecipes(ctx context.Context, filter domain.RecipeFilter) ([]domain.Recipe, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]domain.Recipe), args.Error(1)
+}
+
+func (m *MockRecipeRepository) CreateRecipe(ctx context.Context, recipe domain.Recipe) error {
+	args := m.Called(ctx, recipe)
+	return args.Error(0)
+}
+
+func (m *MockRecipeRepository) UpdateRecipe(ctx context.Context, recipe domain.Recipe) error {
+	args := m.Called(ctx, recipe)
+	return args.Error(0)
+}
+
+func (m *MockRecipeRepository) ArchiveRecipe(ctx context.Context, recipeUuid uuid.UUID, version int, archived bool) error {
+	args := m.Called(ctx, recipeUuid, version, archived)
+	return args.Error(0)
+}
+
+func (m *MockRecipeRepository) RestoreRecipe(ctx context.Context, recipeUuid uuid.UUID) error {
+	args := m.Called(ctx, recipeUuid)
+	return args.Error(0)
+}
+
+func (m *MockRecipeRepository) DeleteRecipe(ctx context.Context, recipeUuid uuid.UUID, version int) error {
+	args := m.Called(ctx, recipeUuid, version)
+	return args.Error(0)
+}
+
+// recordingOutbox keeps the events appended by committed transactions.
+type recordingOutbox struct {
+	events    []domain.Event
+	appendErr error
+}
+
+func (o *recordingOutbox) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	committed := len(o.events)
+	if err := fn(ctx); err != nil {
+		o.events = o.events[:committed]
+		return err
+	}
+	return nil
+}
+
+func (o *recordingOutbox) Append(_ context.Context, events ...domain.Event) error {
+	if o.appendErr != nil {
+		return o.appendErr
+	}
+	o.events = append(o.events, events...)
+	return nil
+}
+
+func (o *recordingOutbox) types() []domain.EventType {
+	types := make([]domain.EventType, 0, len(o.events))
+	for _, event := range o.events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
 type MockCalculatorService struct {
 	mock.Mock
 }
@@ -39,6 +109,40 @@ func (m *MockBalancerService) Balance(ctx context.Context, recipe domain.Recipe,
 	return args.Get(0).(*domain.RecipeAggregate), args.Error(1)
 }
 
+// margherita is the recipe the aggregation tests start from.
+func margherita(recipeUuid uuid.UUID) domain.Recipe {
+	return domain.Recipe{
+		Uuid: recipeUuid,
+		Name: "Margherita",
+		Dough: domain.Dough{Ingredients: []domain.Ingredient{
+			{Name: "flour", Amount: 60, Unit: "%", Catalogue: &domain.CatalogueIngredient{
+				Name: "flour", Category: "flour", Nutrition: &domain.Nutrition{Kcal: 340},
+			}},
+			{Name: "water", Amount: 38, Unit: "%", Catalogue: &domain.CatalogueIngredient{Name: "water", Category: "water"}},
+			{Name: "salt", Amount: 1.5, Unit: "%"},
+			{Name: "yeast", Amount: 0.5, Unit: "%", Catalogue: &domain.CatalogueIngredient{Name: "yeast", Category: "leavening"}},
+		}},
+		Topping: domain.Topping{ReferenceArea: 1200, Ingredients: []domain.Ingredient{{Name: "basil", Amount: 10, Unit: "g"}}},
+	}
+}
+
+// newRecipeService returns a service whose repository holds recipe. The
+// calculator measures the pans as pans and the balancer answers with
+// balanced; left nil, their mocks fail any call.
+func newRecipeService(recipe domain.Recipe, pans *domain.Pans, balanced *domain.RecipeAggregate, options ...Option) *RecipeService {
+	mockRecipeRepository := new(MockRecipeRepository)
+	mockRecipeRepository.On("GetRecipeByUuid", mock.Anything, recipe.Uuid).Return(&recipe, nil)
+	mockCalculatorService := new(MockCalculatorService)
+	if pans != nil {
+		mockCalculatorService.On("TotalDoughWeightByPans", mock.Anything, mock.Anything).Return(pans, nil)
+	}
+	mockBalancerService := new(MockBalancerService)
+	if balanced != nil {
+		mockBalancerService.On("Balance", mock.Anything, recipe, mock.Anything).Return(balanced, nil)
+	}
+	return NewRecipeService(mockRecipeRepository, mockCalculatorService, mockBalancerService, options...)
+}
+
 func TestHandle(t *testing.T) {
 	recipeUuid := uuid.New()
 	ctx := context.Background()
@@ -46,7 +150,7 @@ func TestHandle(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockRecipeRepository := new(MockRecipeRepository)
 		recipe := domain.Recipe{Uuid: recipeUuid}
-		mockRecipeRepository.On("GetRecipeByUuid", recipeUuid).Return(&recipe, nil)
+		mockRecipeRepository.On("GetRecipeByUuid", mock.Anything, recipeUuid).Return(&recipe, nil)
 		pans := domain.Pans{}
 		mockCalculatorService := new(MockCalculatorService)
 		mockCalculatorService.On("TotalDoughWeightByPans", mock.Anything, mock.Anything).Return(&pans, nil)
@@ -60,12 +164,130 @@ func TestHandle(t *testing.T) {
 		assert.Equal(t, recipeAggregate, *result)
 	})
 
+	t.Run("computes nutrition per pan", func(t *testing.T) {
+		flour := &domain.CatalogueIngredient{Name: "flour", Nutrition: &domain.Nutrition{Kcal: 340, Protein: 11, Carbohydrates: 72, Fat: 1}}
+		recipe := domain.Recipe{
+			Uuid: recipeUuid,
+			Dough: domain.Dough{Ingredients: []domain.Ingredient{
+				{Name: "flour", Amount: 60, Unit: "%", Catalogue: flour},
+				{Name: "water", Amount: 40, Unit: "%", Catalogue: &domain.CatalogueIngredient{Name: "water"}},
+			}},
+			Topping: domain.Topping{Ingredients: []domain.Ingredient{
+				{Name: "basil", Amount: 10, Unit: "g"},
+			}},
+		}
+		mockRecipeRepository := new(MockRecipeRepository)
+		mockRecipeRepository.On("GetRecipeByUuid", mock.Anything, recipeUuid).Return(&recipe, nil)
+
+		request := domain.Pans{Pans: []domain.Pan{{Shape: "round"}, {Shape: "square", Slices: 4}}}
+		calculated := domain.Pans{
+			Pans:      []domain.Pan{{Shape: "round", Name: "round 30 cm", Area: 300}, {Shape: "square", Name: "square 10 cm", Area: 100}},
+			TotalArea: 400,
+		}
+		mockCalculatorService := new(MockCalculatorService)
+		mockCalculatorService.On("TotalDoughWeightByPans", mock.Anything, request).Return(&calculated, nil)
+
+		aggregate := domain.RecipeAggregate{
+			Recipe: domain.Recipe{Topping: domain.Topping{Ingredients: []domain.Ingredient{{Name: "basil", Amount: 20}}}},
+			SplitIngredients: domain.SplitIngredients{SplitDough: []domain.Dough{
+				{Name: "round 30 cm", Ingredients: []domain.Ingredient{{Name: "flour", Amount: 200}, {Name: "water", Amount: 130}}},
+				{Name: "square 10 cm", Ingredients: []domain.Ingredient{{Name: "flour", Amount: 100}, {Name: "water", Amount: 65}}},
+			}},
+		}
+		mockBalancerService := new(MockBalancerService)
+		mockBalancerService.On("Balance", mock.Anything, recipe, mock.Anything).Return(&aggregate, nil)
+
+		service := NewRecipeService(mockRecipeRepository, mockCalculatorService, mockBalancerService)
+		result, err := service.Handle(ctx, recipeUuid, request)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.PanNutrition{
+			{
+				Pan:      "round 30 cm",
+				Slices:   domain.DefaultSlices,
+				Total:    domain.Nutrition{Kcal: 680, Protein: 22, Carbohydrates: 144, Fat: 2},
+				PerSlice: domain.Nutrition{Kcal: 85, Protein: 2.75, Carbohydrates: 18, Fat: 0.25},
+				Unknown:  []string{"water", "basil"},
+			},
+			{
+				Pan:      "square 10 cm",
+				Slices:   4,
+				Total:    domain.Nutrition{Kcal: 340, Protein: 11, Carbohydrates: 72, Fat: 1},
+				PerSlice: domain.Nutrition{Kcal: 85, Protein: 2.75, Carbohydrates: 18, Fat: 0.25},
+				Unknown:  []string{"water", "basil"},
+			},
+		}, result.Nutrition)
+	})
+
+	t.Run("measures the pans at the dough density of the recipe style", func(t *testing.T) {
+		recipe := domain.Recipe{Uuid: recipeUuid, Style: domain.StyleSicilian}
+		mockRecipeRepository := new(MockRecipeRepository)
+		mockRecipeRepository.On("GetRecipeByUuid", mock.Anything, recipeUuid).Return(&recipe, nil)
+		mockCalculatorService := new(MockCalculatorService)
+		mockCalculatorService.On("TotalDoughWeightByPans", mock.Anything, mock.MatchedBy(func(pans domain.Pans) bool {
+			return pans.Style == domain.StyleSicilian && pans.DoughDensity == 0.65
+		})).Return(&domain.Pans{Pans: []domain.Pan{{Shape: "square", Area: 400}}, TotalArea: 400}, nil)
+		mockBalancerService := new(MockBalancerService)
+		mockBalancerService.On("Balance", mock.Anything, recipe, mock.Anything).Return(&domain.RecipeAggregate{Recipe: recipe}, nil)
+
+		service := NewRecipeService(mockRecipeRepository, mockCalculatorService, mockBalancerService)
+		result, err := service.Handle(ctx, recipeUuid, domain.Pans{Pans: []domain.Pan{{Shape: "square"}}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.StyleSicilian, result.Pans.Style)
+		assert.Equal(t, 0.65, result.Pans.DoughDensity)
+		assert.InDelta(t, 260, result.Pans.Pans[0].DoughWeight, 1e-9)
+	})
+
+	t.Run("prefers the thickness of the request to the recipe", func(t *testing.T) {
+		recipe := domain.Recipe{Uuid: recipeUuid, Style: domain.StyleFocaccia, DoughDensity: 0.8}
+		mockRecipeRepository := new(MockRecipeRepository)
+		mockRecipeRepository.On("GetRecipeByUuid", mock.Anything, recipeUuid).Return(&recipe, nil)
+		mockCalculatorService := new(MockCalculatorService)
+		mockCalculatorService.On("TotalDoughWeightByPans", mock.Anything, mock.MatchedBy(func(pans domain.Pans) bool {
+			return pans.Style == "" && pans.Thickness == 0 && pans.DoughDensity == domain.ThicknessDensity(4)
+		})).Return(&domain.Pans{}, nil)
+		mockBalancerService := new(MockBalancerService)
+		mockBalancerService.On("Balance", mock.Anything, recipe, mock.Anything).Return(&domain.RecipeAggregate{Recipe: recipe}, nil)
+
+		service := NewRecipeService(mockRecipeRepository, mockCalculatorService, mockBalancerService)
+		_, err := service.Handle(ctx, recipeUuid, domain.Pans{Thickness: 4})
+
+		assert.NoError(t, err)
+		mockCalculatorService.AssertExpectations(t)
+	})
+
+	t.Run("rejects a thickness along with a dough density", func(t *testing.T) {
+		mockRecipeRepository := new(MockRecipeRepository)
+		mockRecipeRepository.On("GetRecipeByUuid", mock.Anything, recipeUuid).Return(&domain.Recipe{Uuid: recipeUuid}, nil)
+		mockCalculatorService := new(MockCalculatorService)
+
+		service := NewRecipeService(mockRecipeRepository, mockCalculatorService, new(MockBalancerService))
+		result, err := service.Handle(ctx, recipeUuid, domain.Pans{Thickness: 4, DoughDensity: 0.4})
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, domain.ErrInvalidDoughDensity)
+		mockCalculatorService.AssertNotCalled(t, "TotalDoughWeightByPans", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects an unknown style", func(t *testing.T) {
+		mockRecipeRepository := new(MockRecipeRepository)
+		mockRecipeRepository.On("GetRecipeByUuid", mock.Anything, recipeUuid).Return(&domain.Recipe{Uuid: recipeUuid}, nil)
+
+		service := NewRecipeService(mockRecipeRepository, new(MockCalculatorService), new(MockBalancerService))
+		_, err := service.Handle(ctx, recipeUuid, domain.Pans{Style: "chicago"})
+
+		assert.ErrorIs(t, err, domain.ErrUnknownStyle)
+	})
+
 	t.Run("calculator service error", func(t *testing.T) {
+		mockRecipeRepository := new(MockRecipeRepository)
+		mockRecipeRepository.On("GetRecipeByUuid", mock.Anything, recipeUuid).Return(&domain.Recipe{Uuid: recipeUuid}, nil)
 		mockCalculatorService := new(MockCalculatorService)
 		calculatorError := errors.New("calculator error")
 		mockCalculatorService.On("TotalDoughWeightByPans", mock.Anything, mock.Anything).Return((*domain.Pans)(nil), calculatorError)
 
-		service := NewRecipeService(new(MockRecipeRepository), mockCalculatorService, new(MockBalancerService))
+		service := NewRecipeService(mockRecipeRepository, mockCalculatorService, new(MockBalancerService))
 		result, err := service.Handle(ctx, recipeUuid, domain.Pans{})
 
 		assert.Nil(t, result)
@@ -75,7 +297,7 @@ func TestHandle(t *testing.T) {
 	t.Run("repository error", func(t *testing.T) {
 		mockRecipeRepository := new(MockRecipeRepository)
 		repositoryError := errors.New("repository error")
-		mockRecipeRepository.On("GetRecipeByUuid", recipeUuid).Return((*domain.Recipe)(nil), repositoryError)
+		mockRecipeRepository.On("GetRecipeByUuid", mock.Anything, recipeUuid).Return((*domain.Recipe)(nil), repositoryError)
 
 		mockCalculatorService := new(MockCalculatorService)
 		pans := domain.Pans{}
@@ -91,7 +313,7 @@ func TestHandle(t *testing.T) {
 	t.Run("balancer service error", func(t *testing.T) {
 		mockRecipeRepository := new(MockRecipeRepository)
 		recipe := domain.Recipe{Uuid: recipeUuid}
-		mockRecipeRepository.On("GetRecipeByUuid", recipeUuid).Return(&recipe, nil)
+		mockRecipeRepository.On("GetRecipeByUuid", mock.Anything, recipeUuid).Return(&recipe, nil)
 
 		mockCalculatorService := new(MockCalculatorService)
 		pans := domain.Pans{}
@@ -107,4 +329,696 @@ func TestHandle(t *testing.T) {
 		assert.Nil(t, result)
 		assert.Equal(t, balancerError, err)
 	})
+
+	t.Run("aggregates a recipe already read without the repository", func(t *testing.T) {
+		recipe := domain.Recipe{Uuid: recipeUuid, Name: "Margherita"}
+		request := domain.Pans{Pans: []domain.Pan{{Shape: "round", Slices: 6}}}
+		calculated := domain.Pans{Pans: []domain.Pan{{Shape: "round", Name: "round 30", Area: 706.9}}, TotalArea: 706.9}
+		mockCalculatorService := new(MockCalculatorService)
+		mockCalculatorService.On("TotalDoughWeightByPans", mock.Anything, request).Return(&calculated, nil)
+		mockBalancerService := new(MockBalancerService)
+		mockBalancerService.On("Balance", mock.Anything, recipe, mock.Anything).Return(&domain.RecipeAggregate{Recipe: recipe}, nil)
+
+		service := NewRecipeService(new(MockRecipeRepository), mockCalculatorService, mockBalancerService)
+		result, err := service.Aggregate(ctx, recipe, request)
+
+		require.NoError(t, err)
+		assert.Equal(t, "round 30", result.Pans.Pans[0].Name)
+		assert.Equal(t, 6, result.Pans.Pans[0].Slices)
+		assert.Len(t, result.Nutrition, 1)
+	})
+}
+
+func TestRounding(t *testing.T) {
+	ctx := context.Background()
+	recipe := margherita(uuid.New())
+	// Without a reference area, the topping is shared among the pans.
+	recipe.Topping.ReferenceArea = 0
+	pans := domain.Pans{
+		Pans:      []domain.Pan{{Shape: "round", Name: "round 30", Area: 100}, {Shape: "round", Area: 100}, {Shape: "square", Area: 100}},
+		TotalArea: 300,
+	}
+	balanced := func() *domain.RecipeAggregate {
+		dough := func(name string) domain.Dough {
+			return domain.Dough{Name: name, Ingredients: []domain.Ingredient{
+				{Name: "flour", Amount: 102.6}, {Name: "water", Amount: 66.65}, {Name: "yeast", Amount: 0.34},
+			}}
+		}
+		return &domain.RecipeAggregate{
+			Recipe:           recipe,
+			SplitIngredients: domain.SplitIngredients{SplitDough: []domain.Dough{dough("round 30"), dough("round"), dough("square")}},
+		}
+	}
+	policy := domain.RoundingPolicy{Classes: map[string]float64{"Flour": 5, "leavening": 0.1, "basil": 1}}
+
+	amounts := func(aggregate *domain.RecipeAggregate, ingredient string) []float64 {
+		var result []float64
+		for _, pan := range aggregate.SplitIngredients.SplitDough {
+			for _, amount := range pan.Ingredients {
+				if amount.Name == ingredient {
+					result = append(result, amount.Amount)
+				}
+			}
+		}
+		for _, pan := range aggregate.SplitIngredients.SplitTopping {
+			for _, amount := range pan.Ingredients {
+				if amount.Name == ingredient {
+					result = append(result, amount.Amount)
+				}
+			}
+		}
+		return result
+	}
+
+	t.Run("rounds each pan to the step of the ingredient class", func(t *testing.T) {
+		result, err := newRecipeService(recipe, &pans, balanced(), WithRounding(policy)).Aggregate(ctx, recipe, domain.Pans{})
+
+		require.NoError(t, err)
+		// 3 × 105 g would overshoot the 307.8 g of flour rounded to 310 g.
+		assert.Equal(t, []float64{100, 105, 105}, amounts(result, "flour"))
+		// 3 × 0.3 g would miss the 1.02 g of yeast rounded to 1 g.
+		assert.Equal(t, []float64{0.4, 0.3, 0.3}, amounts(result, "yeast"))
+		assert.Equal(t, []float64{66.65, 66.65, 66.65}, amounts(result, "water"))
+		assert.Equal(t, []float64{4, 3, 3}, amounts(result, "basil"))
+		assert.Equal(t, []string{"round 30", "round", "square"}, []string{
+			result.SplitIngredients.SplitTopping[0].Name,
+			result.SplitIngredients.SplitTopping[1].Name,
+			result.SplitIngredients.SplitTopping[2].Name,
+		})
+	})
+
+	t.Run("reports the drift of each rounded ingredient", func(t *testing.T) {
+		result, err := newRecipeService(recipe, &pans, balanced(), WithRounding(policy)).Aggregate(ctx, recipe, domain.Pans{})
+
+		require.NoError(t, err)
+		require.NotNil(t, result.Rounding)
+		report := *result.Rounding
+		require.Len(t, report.Ingredients, 3)
+		for i, expected := range []domain.RoundingDrift{
+			{Component: domain.ComponentDough, Ingredient: "flour", Step: 5, Exact: 307.8, Rounded: 310, Adjusted: 1},
+			{Component: domain.ComponentDough, Ingredient: "yeast", Step: 0.1, Exact: 1.02, Rounded: 1, Adjusted: 1},
+			{Component: domain.ComponentTopping, Ingredient: "basil", Step: 1, Exact: 10, Rounded: 10, Adjusted: 1},
+		} {
+			actual := report.Ingredients[i]
+			assert.InDelta(t, expected.Exact, actual.Exact, 1e-9)
+			actual.Exact = expected.Exact
+			assert.Equal(t, expected, actual)
+		}
+		assert.InDelta(t, 2.18, report.Drift(), 1e-9)
+	})
+
+	t.Run("scales the topping to the reference area of the recipe", func(t *testing.T) {
+		recipe := recipe
+		recipe.Topping.ReferenceArea = 50
+
+		service := newRecipeService(recipe, &pans, &domain.RecipeAggregate{Recipe: recipe}, WithRounding(policy))
+		result, err := service.Aggregate(ctx, recipe, domain.Pans{})
+
+		require.NoError(t, err)
+		// 10 g per 50 cm² over each pan of 100 cm², not a third of 10 g.
+		assert.Equal(t, []float64{20, 20, 20}, amounts(result, "basil"))
+	})
+
+	t.Run("counts the rounded amounts in the nutrition", func(t *testing.T) {
+		basil := &domain.CatalogueIngredient{Name: "basil", Nutrition: &domain.Nutrition{Kcal: 100}}
+		recipe := recipe
+		recipe.Topping = domain.Topping{Ingredients: []domain.Ingredient{{Name: "basil", Amount: 10, Unit: "g", Catalogue: basil}}}
+
+		service := newRecipeService(recipe, &pans, &domain.RecipeAggregate{Recipe: recipe}, WithRounding(policy))
+		result, err := service.Aggregate(ctx, recipe, domain.Pans{})
+
+		require.NoError(t, err)
+		require.Len(t, result.Nutrition, 3)
+		assert.Equal(t, 4.0, result.Nutrition[0].Total.Kcal)
+		assert.Equal(t, 3.0, result.Nutrition[1].Total.Kcal)
+	})
+
+	t.Run("leaves the amounts as balanced without a policy", func(t *testing.T) {
+		result, err := newRecipeService(recipe, &pans, balanced()).Aggregate(ctx, recipe, domain.Pans{})
+
+		require.NoError(t, err)
+		assert.Nil(t, result.Rounding)
+		assert.Equal(t, []float64{102.6, 102.6, 102.6}, amounts(result, "flour"))
+		assert.Empty(t, result.SplitIngredients.SplitTopping)
+	})
+}
+
+func TestSchedule(t *testing.T) {
+	recipeUuid := uuid.New()
+	ctx := context.Background()
+	bakeAt := time.Date(2026, 10, 24, 19, 30, 0, 0, time.UTC)
+	recipe := margherita(recipeUuid)
+	pans := domain.Pans{Pans: []domain.Pan{{Shape: "round", Name: "round 30", Area: 706.9}}, TotalArea: 706.9}
+
+	balanced := func(recipe domain.Recipe) *domain.RecipeAggregate {
+		return &domain.RecipeAggregate{
+			Recipe: recipe,
+			SplitIngredients: domain.SplitIngredients{SplitDough: []domain.Dough{{Name: "round 30", Ingredients: []domain.Ingredient{
+				{Name: "flour", Amount: 200, Unit: "g"}, {Name: "water", Amount: 130, Unit: "g"}, {Name: "yeast", Amount: 2, Unit: "g"},
+			}}}},
+		}
+	}
+
+	t.Run("adjusts the yeast to the fermentation and schedules it", func(t *testing.T) {
+		plan := domain.FermentationPlan{Yeast: domain.YeastFresh, Duration: 8 * time.Hour, RoomTemperature: 20, BakeAt: bakeAt}
+		result, err := newRecipeService(recipe, &pans, balanced(recipe)).Schedule(ctx, recipeUuid, domain.Pans{}, plan)
+
+		require.NoError(t, err)
+		require.NotNil(t, result.Fermentation)
+		assert.InDelta(t, 0.375, result.Fermentation.YeastPercent, 1e-9)
+		assert.Equal(t, domain.Ingredient{
+			Name: "yeast", Amount: 0.75, Unit: "g", Catalogue: &domain.CatalogueIngredient{Name: "yeast", Category: "leavening"},
+		}, result.SplitIngredients.SplitDough[0].Ingredients[2])
+
+		at := func(hour, minute int) time.Time { return time.Date(2026, 10, 24, hour, minute, 0, 0, time.UTC) }
+		assert.Equal(t, []domain.ScheduledStage{
+			{Stage: domain.StageMix, Start: at(10, 55), End: at(11, 15), Temperature: 20},
+			{Stage: domain.StageBulk, Start: at(11, 15), End: at(16, 35), Temperature: 20},
+			{Stage: domain.StageBall, Start: at(16, 35), End: at(16, 50), Temperature: 20},
+			{Stage: domain.StageProof, Start: at(16, 50), End: at(19, 30), Temperature: 20},
+			{Stage: domain.StageBake, Start: at(19, 30), End: at(19, 45)},
+		}, result.Fermentation.Schedule)
+	})
+
+	t.Run("takes less yeast for a bulk fermentation in the fridge", func(t *testing.T) {
+		plan := domain.FermentationPlan{
+			Yeast: domain.YeastDry, Duration: 24 * time.Hour, RoomTemperature: 20,
+			FridgeDuration: 18 * time.Hour, FridgeTemperature: 4, BakeAt: bakeAt,
+		}
+		result, err := newRecipeService(recipe, &pans, balanced(recipe)).Schedule(ctx, recipeUuid, domain.Pans{}, plan)
+
+		require.NoError(t, err)
+		// 6 hours at 20 °C and 18 in the fridge, which ferment as 2.8 at 20 °C.
+		assert.InDelta(t, 0.1358, result.Fermentation.YeastPercent, 1e-4)
+		assert.Equal(t, "dryYeast", result.SplitIngredients.SplitDough[0].Ingredients[2].Name)
+
+		schedule := result.Fermentation.Schedule
+		require.Len(t, schedule, 6)
+		assert.Equal(t, domain.ScheduledStage{
+			Stage: domain.StageBulk, Start: bakeAt.Add(-20*time.Hour - 15*time.Minute), End: bakeAt.Add(-2*time.Hour - 15*time.Minute), Temperature: 4,
+		}, schedule[2])
+		assert.Equal(t, bakeAt.Add(-24*time.Hour-35*time.Minute), schedule[0].Start)
+	})
+
+	t.Run("takes the flour and water of a sourdough starter off the dough", func(t *testing.T) {
+		plan := domain.FermentationPlan{Yeast: domain.YeastSourdough, Duration: 8 * time.Hour, RoomTemperature: 20, BakeAt: bakeAt}
+		result, err := newRecipeService(recipe, &pans, balanced(recipe)).Schedule(ctx, recipeUuid, domain.Pans{}, plan)
+
+		require.NoError(t, err)
+		ingredients := result.SplitIngredients.SplitDough[0].Ingredients
+		assert.InDelta(t, 192.5, ingredients[0].Amount, 1e-9)
+		assert.InDelta(t, 122.5, ingredients[1].Amount, 1e-9)
+		assert.Equal(t, "sourdoughStarter", ingredients[2].Name)
+		assert.InDelta(t, 15, ingredients[2].Amount, 1e-9)
+	})
+
+	t.Run("sourdough starter heavier than the dough", func(t *testing.T) {
+		for _, tt := range []struct {
+			plan    domain.FermentationPlan
+			percent string
+		}{
+			{plan: domain.FermentationPlan{Yeast: domain.YeastSourdough, Duration: time.Hour, RoomTemperature: 10, BakeAt: bakeAt}, percent: "190%"},
+			{plan: domain.FermentationPlan{
+				Yeast: domain.YeastSourdough, Duration: 2 * time.Hour, RoomTemperature: 20,
+				FridgeDuration: 119 * time.Minute, FridgeTemperature: 4, BakeAt: bakeAt,
+			}, percent: "182%"},
+		} {
+			_, err := newRecipeService(recipe, &pans, balanced(recipe)).Schedule(ctx, recipeUuid, domain.Pans{}, tt.plan)
+
+			// Half of the starter is water, more than the 130 g of the dough.
+			assert.EqualError(t, err, "invalid fermentation: a sourdough starter of "+tt.percent+
+				" of the flour brings more flour or water than the dough has, ferment longer or warmer")
+		}
+	})
+
+	t.Run("rounds the adjusted yeast", func(t *testing.T) {
+		plan := domain.FermentationPlan{Yeast: domain.YeastInstant, Duration: 8 * time.Hour, RoomTemperature: 20, BakeAt: bakeAt}
+		policy := domain.RoundingPolicy{Classes: map[string]float64{"leavening": 0.1}}
+		result, err := newRecipeService(recipe, &pans, balanced(recipe), WithRounding(policy)).Schedule(ctx, recipeUuid, domain.Pans{}, plan)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0.3, result.SplitIngredients.SplitDough[0].Ingredients[2].Amount)
+	})
+
+	t.Run("invalid plan", func(t *testing.T) {
+		plan := domain.FermentationPlan{Yeast: "beer", Duration: 8 * time.Hour, RoomTemperature: 20, FridgeDuration: 10 * time.Hour}
+		service := NewRecipeService(new(MockRecipeRepository), new(MockCalculatorService), new(MockBalancerService))
+		_, err := service.Schedule(ctx, recipeUuid, domain.Pans{}, plan)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidFermentation)
+		assert.EqualError(t, err, `invalid fermentation: unknown yeast "beer"; fridge duration must be shorter than the duration, got 10h0m0s; bake time is required`)
+	})
+
+	t.Run("recipe without yeast", func(t *testing.T) {
+		unleavened := recipe
+		unleavened.Dough.Ingredients = recipe.Dough.Ingredients[:2]
+		plan := domain.FermentationPlan{Yeast: domain.YeastFresh, Duration: 8 * time.Hour, RoomTemperature: 20, BakeAt: bakeAt}
+		_, err := newRecipeService(unleavened, &pans, balanced(unleavened)).Schedule(ctx, recipeUuid, domain.Pans{}, plan)
+
+		assert.EqualError(t, err, `invalid fermentation: recipe "Margherita" has no yeast to adjust`)
+	})
+}
+
+func TestBalls(t *testing.T) {
+	recipeUuid := uuid.New()
+	ctx := context.Background()
+	recipe := margherita(recipeUuid)
+
+	tests := []struct {
+		name     string
+		balls    domain.Balls
+		expected domain.Balls
+	}{
+		{"by count and weight", domain.Balls{Count: 6, Weight: 250, Loss: 4},
+			domain.Balls{Count: 6, Weight: 250, DoughWeight: 1562.5, Loss: 4}},
+		{"by count, 250 g each", domain.Balls{Count: 4},
+			domain.Balls{Count: 4, Weight: 250, DoughWeight: 1000}},
+		{"dividing the dough evenly into a count", domain.Balls{Count: 4, DoughWeight: 1000, Loss: 2},
+			domain.Balls{Count: 4, Weight: 245, DoughWeight: 1000, Loss: 2}},
+		{"dividing the dough into balls of a weight, sharing what is left", domain.Balls{Weight: 280, DoughWeight: 1000},
+			domain.Balls{Count: 3, Weight: 1000.0 / 3, DoughWeight: 1000}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := newRecipeService(recipe, nil, nil).Balls(ctx, recipeUuid, tt.balls, nil)
+
+			require.NoError(t, err)
+			require.NotNil(t, result.Balls)
+			assert.InDelta(t, tt.expected.Weight, result.Balls.Weight, 1e-9)
+			result.Balls.Weight = tt.expected.Weight
+			assert.Equal(t, tt.expected, *result.Balls)
+			require.Len(t, result.SplitIngredients.SplitDough, 1)
+			assert.InDelta(t, tt.expected.DoughWeight, result.SplitIngredients.SplitDough[0].Total(), 1e-9)
+			assert.Empty(t, result.Pans.Pans)
+		})
+	}
+
+	t.Run("splits the dough in the shares of the recipe", func(t *testing.T) {
+		result, err := newRecipeService(recipe, nil, nil).Balls(ctx, recipeUuid, domain.Balls{Count: 4}, nil)
+
+		require.NoError(t, err)
+		dough := result.SplitIngredients.SplitDough[0]
+		assert.Equal(t, "4 balls of 250 g", dough.Name)
+		assert.Equal(t, []domain.Ingredient{
+			{Name: "flour", Amount: 600, Unit: "g"},
+			{Name: "water", Amount: 380, Unit: "g"},
+			{Name: "salt", Amount: 15, Unit: "g"},
+			{Name: "yeast", Amount: 5, Unit: "g"},
+		}, dough.Ingredients)
+		assert.Empty(t, result.SplitIngredients.SplitTopping)
+	})
+
+	t.Run("reports the nutrition of one ball", func(t *testing.T) {
+		result, err := newRecipeService(recipe, nil, nil).Balls(ctx, recipeUuid, domain.Balls{Count: 2, Weight: 200, Loss: 20}, nil)
+
+		require.NoError(t, err)
+		require.Len(t, result.Nutrition, 1)
+		// 120 g of flour in a ball of 200 g.
+		assert.InDelta(t, 408, result.Nutrition[0].Total.Kcal, 1e-9)
+		assert.Equal(t, "ball", result.Nutrition[0].Pan)
+	})
+
+	t.Run("adjusts the yeast and rounds the batch", func(t *testing.T) {
+		plan := domain.FermentationPlan{Yeast: domain.YeastFresh, Duration: 24 * time.Hour, RoomTemperature: 20, BakeAt: time.Now()}
+		policy := domain.RoundingPolicy{Classes: map[string]float64{"flour": 5, "leavening": 0.1}}
+		result, err := newRecipeService(recipe, nil, nil, WithRounding(policy)).Balls(ctx, recipeUuid, domain.Balls{Count: 3, Weight: 270}, &plan)
+
+		require.NoError(t, err)
+		ingredients := result.SplitIngredients.SplitDough[0].Ingredients
+		// 486 g of flour, 0.125% of it yeast.
+		assert.Equal(t, 485.0, ingredients[0].Amount)
+		assert.Equal(t, 0.6, ingredients[3].Amount)
+		assert.NotNil(t, result.Fermentation)
+	})
+
+	t.Run("invalid balls", func(t *testing.T) {
+		for _, balls := range []domain.Balls{
+			{Weight: 250},
+			{Count: 2, Weight: 250, DoughWeight: 500},
+			{Count: 2, Loss: 50},
+			{Weight: 300, DoughWeight: 250},
+		} {
+			_, err := newRecipeService(recipe, nil, nil).Balls(ctx, recipeUuid, balls, nil)
+			assert.ErrorIs(t, err, domain.ErrInvalidBalls, "%+v", balls)
+		}
+	})
+}
+
+func TestScale(t *testing.T) {
+	recipeUuid := uuid.New()
+	ctx := context.Background()
+	recipe := margherita(recipeUuid)
+	recipe.Name, recipe.Style, recipe.ServingWeight = "Detroit", domain.StyleDetroit, 300
+	recipe.Topping.Ingredients = []domain.Ingredient{
+		{Name: "basil", Amount: 12, Unit: "g", Catalogue: &domain.CatalogueIngredient{
+			Name: "basil", Category: "herb", Nutrition: &domain.Nutrition{Kcal: 20},
+		}},
+	}
+
+	t.Run("scales to servings of the recipe", func(t *testing.T) {
+		result, err := newRecipeService(recipe, nil, nil).Scale(ctx, recipeUuid, domain.Scale{Servings: 40}, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.Scale{Servings: 40, DoughWeight: 12000, ServingWeight: 300}, *result.Scale)
+		dough := result.SplitIngredients.SplitDough[0]
+		assert.Equal(t, "40 servings, 12000 g of dough", dough.Name)
+		assert.Equal(t, []domain.Ingredient{
+			{Name: "flour", Amount: 7200, Unit: "g"},
+			{Name: "water", Amount: 4560, Unit: "g"},
+			{Name: "salt", Amount: 180, Unit: "g"},
+			{Name: "yeast", Amount: 60, Unit: "g"},
+		}, dough.Ingredients)
+		// 12 kg of dough cover 20000 cm² at the 0.6 g/cm² of the style.
+		require.Len(t, result.SplitIngredients.SplitTopping, 1)
+		assert.InDelta(t, 200, result.SplitIngredients.SplitTopping[0].Ingredients[0].Amount, 1e-9)
+		assert.Equal(t, recipe.Topping, result.Recipe.Topping)
+		assert.Empty(t, result.Pans.Pans)
+	})
+
+	t.Run("scales to a dough weight, 250 g a serving", func(t *testing.T) {
+		plain := recipe
+		plain.Style, plain.ServingWeight = "", 0
+		result, err := newRecipeService(plain, nil, nil).Scale(ctx, recipeUuid, domain.Scale{DoughWeight: 10000}, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.Scale{Servings: 40, DoughWeight: 10000, ServingWeight: 250}, *result.Scale)
+		assert.InDelta(t, 10000, result.SplitIngredients.SplitDough[0].Total(), 1e-9)
+		// At the Neapolitan 0.35 g/cm².
+		assert.InDelta(t, 10000/0.35/1200*12, result.SplitIngredients.SplitTopping[0].Ingredients[0].Amount, 1e-9)
+	})
+
+	t.Run("counts the topping in servings without a reference area", func(t *testing.T) {
+		perServing := recipe
+		perServing.Topping.ReferenceArea = 0
+		result, err := newRecipeService(perServing, nil, nil).Scale(ctx, recipeUuid, domain.Scale{Servings: 5}, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, 60.0, result.SplitIngredients.SplitTopping[0].Ingredients[0].Amount)
+	})
+
+	t.Run("reports the nutrition of one serving", func(t *testing.T) {
+		result, err := newRecipeService(recipe, nil, nil).Scale(ctx, recipeUuid, domain.Scale{Servings: 40}, nil)
+
+		require.NoError(t, err)
+		require.Len(t, result.Nutrition, 1)
+		// 180 g of flour and 5 g of basil.
+		assert.InDelta(t, 613, result.Nutrition[0].Total.Kcal, 1e-9)
+		assert.Equal(t, result.Nutrition[0].Total, result.Nutrition[0].PerSlice)
+		assert.Equal(t, "serving", result.Nutrition[0].Pan)
+	})
+
+	t.Run("adjusts the yeast and rounds the batch", func(t *testing.T) {
+		plan := domain.FermentationPlan{Yeast: domain.YeastFresh, Duration: 24 * time.Hour, RoomTemperature: 20, BakeAt: time.Now()}
+		policy := domain.RoundingPolicy{Classes: map[string]float64{"flour": 5, "leavening": 0.1, "herb": 1}}
+		result, err := newRecipeService(recipe, nil, nil, WithRounding(policy)).Scale(ctx, recipeUuid, domain.Scale{DoughWeight: 1001}, &plan)
+
+		require.NoError(t, err)
+		ingredients := result.SplitIngredients.SplitDough[0].Ingredients
+		// 600.6 g of flour, 0.125% of it yeast.
+		assert.Equal(t, 600.0, ingredients[0].Amount)
+		assert.Equal(t, 0.8, ingredients[3].Amount)
+		assert.Equal(t, 17.0, result.SplitIngredients.SplitTopping[0].Ingredients[0].Amount)
+		assert.Equal(t, 12.0, result.Recipe.Topping.Ingredients[0].Amount)
+		assert.NotNil(t, result.Fermentation)
+		assert.NotNil(t, result.Rounding)
+	})
+
+	t.Run("invalid scales", func(t *testing.T) {
+		for _, scale := range []domain.Scale{
+			{},
+			{Servings: 4, DoughWeight: 1000},
+			{Servings: -1},
+		} {
+			_, err := newRecipeService(recipe, nil, nil).Scale(ctx, recipeUuid, scale, nil)
+			assert.ErrorIs(t, err, domain.ErrInvalidScale, "%+v", scale)
+		}
+	})
+}
+
+func TestSearchRecipes(t *testing.T) {
+	ctx := context.Background()
+	recipes := []domain.Recipe{
+		{Name: "Margherita", Description: "Tomato and mozzarella", Author: "PizzaMaker"},
+		{Name: "Marinara", Description: "Tomato, garlic and oregano", Author: "Nonna"},
+		{Name: "Bianca", Description: "Olive oil and sage", Author: "PizzaMaker"},
+	}
+
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{name: "every recipe without text", text: "", expected: []string{"Margherita", "Marinara", "Bianca"}},
+		{name: "by name ignoring case", text: "MAR", expected: []string{"Margherita", "Marinara"}},
+		{name: "by description", text: "garlic", expected: []string{"Marinara"}},
+		{name: "by author", text: "pizzamaker", expected: []string{"Margherita", "Bianca"}},
+		{name: "nothing matching", text: "pineapple", expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRecipeRepository := new(MockRecipeRepository)
+			mockRecipeRepository.On("ListRecipes", mock.Anything, domain.RecipeFilter{}).Return(recipes, nil)
+
+			service := NewRecipeService(mockRecipeRepository, new(MockCalculatorService), new(MockBalancerService))
+			found, err := service.SearchRecipes(ctx, domain.RecipeFilter{}, nil, tt.text)
+
+			assert.NoError(t, err)
+			names := []string{}
+			for _, recipe := range found {
+				names = append(names, recipe.Name)
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestRecipesFreeFrom(t *testing.T) {
+	ctx := context.Background()
+	flour := &domain.CatalogueIngredient{Name: "flour", Category: "flour", Allergens: []domain.Allergen{domain.AllergenGluten}}
+	mozzarella := &domain.CatalogueIngredient{Name: "mozzarellaCheese", Category: "dairy", Allergens: []domain.Allergen{domain.AllergenMilk}}
+	tomato := &domain.CatalogueIngredient{Name: "peeledTomatoes", Category: "vegetable"}
+
+	margherita := domain.Recipe{
+		Name:    "Margherita",
+		Dough:   domain.Dough{Ingredients: []domain.Ingredient{{Name: "flour", Catalogue: flour}}},
+		Topping: domain.Topping{Ingredients: []domain.Ingredient{{Name: "mozzarellaCheese", Catalogue: mozzarella}}},
+	}
+	marinara := domain.Recipe{
+		Name:    "Marinara",
+		Dough:   domain.Dough{Ingredients: []domain.Ingredient{{Name: "flour", Catalogue: flour}}},
+		Topping: domain.Topping{Ingredients: []domain.Ingredient{{Name: "peeledTomatoes", Catalogue: tomato}}},
+	}
+	unlabelled := domain.Recipe{
+		Name:    "Unlabelled",
+		Topping: domain.Topping{Ingredients: []domain.Ingredient{{Name: "garlic", Catalogue: &domain.CatalogueIngredient{Name: "garlic"}}}},
+	}
+
+	tests := []struct {
+		name      string
+		allergens []domain.Allergen
+		expected  []string
+	}{
+		{name: "no allergens", allergens: nil, expected: []string{"Margherita", "Marinara", "Unlabelled"}},
+		{name: "milk", allergens: []domain.Allergen{domain.AllergenMilk}, expected: []string{"Marinara"}},
+		{name: "gluten and milk", allergens: []domain.Allergen{domain.AllergenGluten, domain.AllergenMilk}, expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRecipeRepository := new(MockRecipeRepository)
+			mockRecipeRepository.On("ListRecipes", mock.Anything, domain.RecipeFilter{IncludeArchived: true}).
+				Return([]domain.Recipe{margherita, marinara, unlabelled}, nil)
+
+			service := NewRecipeService(mockRecipeRepository, new(MockCalculatorService), new(MockBalancerService))
+			recipes, err := service.RecipesFreeFrom(ctx, domain.RecipeFilter{IncludeArchived: true}, tt.allergens)
+
+			assert.NoError(t, err)
+			names := []string{}
+			for _, recipe := range recipes {
+				names = append(names, recipe.Name)
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRecipeRepository := new(MockRecipeRepository)
+		repositoryError := errors.New("repository error")
+		mockRecipeRepository.On("ListRecipes", mock.Anything, domain.RecipeFilter{}).Return([]domain.Recipe(nil), repositoryError)
+
+		service := NewRecipeService(mockRecipeRepository, new(MockCalculatorService), new(MockBalancerService))
+		recipes, err := service.RecipesFreeFrom(ctx, domain.RecipeFilter{}, nil)
+
+		assert.Nil(t, recipes)
+		assert.Equal(t, repositoryError, err)
+	})
+}
+
+func TestUpdateRecipe(t *testing.T) {
+	ctx := context.Background()
+	recipe := domain.Recipe{Uuid: uuid.New(), Name: "Margherita DOC", Version: 1}
+
+	t.Run("returns the stored recipe", func(t *testing.T) {
+		stored := recipe
+		stored.Version = 2
+		mockRecipeRepository := new(MockRecipeRepository)
+		mockRecipeRepository.On("UpdateRecipe", mock.Anything, recipe).Return(nil)
+		mockRecipeRepository.On("GetRecipeByUuid", mock.Anything, recipe.Uuid).Return(&stored, nil)
+
+		service := NewRecipeService(mockRecipeRepository, new(MockCalculatorService), new(MockBalancerService))
+		updated, err := service.UpdateRecipe(ctx, recipe)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &stored, updated)
+	})
+
+	t.Run("version conflict", func(t *testing.T) {
+		mockRecipeRepository := new(MockRecipeRepository)
+		mockRecipeRepository.On("UpdateRecipe", mock.Anything, recipe).Return(domain.ErrVersionConflict)
+
+		service := NewRecipeService(mockRecipeRepository, new(MockCalculatorService), new(MockBalancerService))
+		updated, err := service.UpdateRecipe(ctx, recipe)
+
+		assert.Nil(t, updated)
+		assert.ErrorIs(t, err, domain.ErrVersionConflict)
+		mockRecipeRepository.AssertNotCalled(t, "GetRecipeByUuid", mock.Anything, mock.Anything)
+	})
+}
+
+func TestRestoreRecipe(t *testing.T) {
+	ctx := context.Background()
+	recipeUuid := uuid.New()
+
+	t.Run("returns the restored recipe", func(t *testing.T) {
+		restored := &domain.Recipe{Uuid: recipeUuid, Version: 3}
+		mockRecipeRepository := new(MockRecipeRepository)
+		mockRecipeRepository.On("RestoreRecipe", mock.Anything, recipeUuid).Return(nil)
+		mockRecipeRepository.On("GetRecipeByUuid", mock.Anything, recipeUuid).Return(restored, nil)
+
+		service := NewRecipeService(mockRecipeRepository, new(MockCalculatorService), new(MockBalancerService))
+		recipe, err := service.RestoreRecipe(ctx, recipeUuid)
+
+		assert.NoError(t, err)
+		assert.Equal(t, restored, recipe)
+	})
+
+	t.Run("recipe not deleted", func(t *testing.T) {
+		mockRecipeRepository := new(MockRecipeRepository)
+		mockRecipeRepository.On("RestoreRecipe", mock.Anything, recipeUuid).Return(domain.ErrRecipeNotFound)
+
+		service := NewRecipeService(mockRecipeRepository, new(MockCalculatorService), new(MockBalancerService))
+		recipe, err := service.RestoreRecipe(ctx, recipeUuid)
+
+		assert.Nil(t, recipe)
+		assert.ErrorIs(t, err, domain.ErrRecipeNotFound)
+	})
+}
+
+func TestEvents(t *testing.T) {
+	ctx := context.Background()
+	recipe := domain.Recipe{Uuid: uuid.New(), Name: "Margherita", Version: 1}
+
+	t.Run("emits a created event with the stored recipe", func(t *testing.T) {
+		mockRecipeRepository := new(MockRecipeRepository)
+		mockRecipeRepository.On("CreateRecipe", mock.Anything, recipe).Return(nil)
+		mockRecipeRepository.On("GetRecipeByUuid", mock.Anything, recipe.Uuid).Return(&recipe, nil)
+		outbox := new(recordingOutbox)
+
+		service := NewRecipeService(mockRecipeRepository, new(MockCalculatorService), new(MockBalancerService), WithOutbox(outbox))
+		created, err := service.CreateRecipe(ctx, recipe)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &recipe, created)
+		if assert.Len(t, outbox.events, 1) {
+			assert.Equal(t, domain.EventRecipeCreated, outbox.events[0].Type)
+			assert.Equal(t, recipe, outbox.events[0].Recipe)
+			assert.NotEqual(t, uuid.Nil, outbox.events[0].Id)
+		}
+	})
+
+	t.Run("emits nothing when the change fails", func(t *testing.T) {
+		mockRecipeRepository := new(MockRecipeRepository)
+		mockRecipeRepository.On("ArchiveRecipe", mock.Anything, recipe.Uuid, 2, true).Return(domain.ErrVersionConflict)
+		outbox := new(recordingOutbox)
+
+		service := NewRecipeService(mockRecipeRepository, new(MockCalculatorService), new(MockBalancerService), WithOutbox(outbox))
+		_, err := service.ArchiveRecipe(ctx, recipe.Uuid, 2, true)
+
+		assert.ErrorIs(t, err, domain.ErrVersionConflict)
+		assert.Empty(t, outbox.events)
+	})
+
+	t.Run("fails the change when the event cannot be stored", func(t *testing.T) {
+		outboxError := errors.New("outbox error")
+		mockRecipeRepository := new(MockRecipeRepository)
+		mockRecipeRepository.On("UpdateRecipe", mock.Anything, recipe).Return(nil)
+		mockRecipeRepository.On("GetRecipeByUuid", mock.Anything, recipe.Uuid).Return(&recipe, nil)
+
+		service := NewRecipeService(mockRecipeRepository, new(MockCalculatorService), new(MockBalancerService),
+			WithOutbox(&recordingOutbox{appendErr: outboxError}))
+		updated, err := service.UpdateRecipe(ctx, recipe)
+
+		assert.Nil(t, updated)
+		assert.Equal(t, outboxError, err)
+	})
+
+	t.Run("emits a deleted event for the version read", func(t *testing.T) {
+		stored := recipe
+		stored.Version = 4
+		mockRecipeRepository := new(MockRecipeRepository)
+		mockRecipeRepository.On("GetRecipeByUuid", mock.Anything, recipe.Uuid).Return(&stored, nil)
+		mockRecipeRepository.On("DeleteRecipe", mock.Anything, recipe.Uuid, 4).Return(nil)
+		outbox := new(recordingOutbox)
+
+		service := NewRecipeService(mockRecipeRepository, new(MockCalculatorService), new(MockBalancerService), WithOutbox(outbox))
+
+		assert.NoError(t, service.DeleteRecipe(ctx, recipe.Uuid, 0))
+		if assert.Equal(t, []domain.EventType{domain.EventRecipeDeleted}, outbox.types()) {
+			deleted := outbox.events[0].Recipe
+			assert.Equal(t, 5, deleted.Version)
+			assert.NotNil(t, deleted.DeletedAt)
+		}
+	})
+
+	t.Run("emits an aggregated event with the pans", func(t *testing.T) {
+		pans := domain.Pans{Pans: []domain.Pan{{Shape: "round", Area: 706.5}}, TotalArea: 706.5}
+		mockRecipeRepository := new(MockRecipeRepository)
+		mockRecipeRepository.On("GetRecipeByUuid", mock.Anything, recipe.Uuid).Return(&recipe, nil)
+		mockCalculatorService := new(MockCalculatorService)
+		mockCalculatorService.On("TotalDoughWeightByPans", mock.Anything, pans).Return(&pans, nil)
+		mockBalancerService := new(MockBalancerService)
+		mockBalancerService.On("Balance", mock.Anything, recipe, pans).Return(&domain.RecipeAggregate{Recipe: recipe}, nil)
+		outbox := new(recordingOutbox)
+
+		service := NewRecipeService(mockRecipeRepository, mockCalculatorService, mockBalancerService, WithOutbox(outbox))
+		_, err := service.Handle(ctx, recipe.Uuid, pans)
+
+		assert.NoError(t, err)
+		if assert.Equal(t, []domain.EventType{domain.EventRecipeAggregated}, outbox.types()) {
+			assert.Equal(t, pans, outbox.events[0].Pans)
+		}
+	})
+
+	t.Run("returns the aggregate when its event cannot be stored", func(t *testing.T) {
+		pans := domain.Pans{Pans: []domain.Pan{{Shape: "round", Area: 706.5}}, TotalArea: 706.5}
+		mockRecipeRepository := new(MockRecipeRepository)
+		mockRecipeRepository.On("GetRecipeByUuid", mock.Anything, recipe.Uuid).Return(&recipe, nil)
+		mockCalculatorService := new(MockCalculatorService)
+		mockCalculatorService.On("TotalDoughWeightByPans", mock.Anything, pans).Return(&pans, nil)
+		mockBalancerService := new(MockBalancerService)
+		mockBalancerService.On("Balance", mock.Anything, recipe, pans).Return(&domain.RecipeAggregate{Recipe: recipe}, nil)
+		logger, hook := logtest.NewNullLogger()
+
+		service := NewRecipeService(mockRecipeRepository, mockCalculatorService, mockBalancerService,
+			WithOutbox(&recordingOutbox{appendErr: errors.New("outbox error")}), WithLogger(logger))
+		aggregate, err := service.Handle(ctx, recipe.Uuid, pans)
+
+		require.NoError(t, err)
+		assert.Equal(t, recipe.Uuid, aggregate.Uuid)
+		if assert.Len(t, hook.Entries, 1) {
+			assert.Equal(t, logrus.ErrorLevel, hook.LastEntry().Level)
+			assert.Equal(t, "outbox error", hook.LastEntry().Data[logrus.ErrorKey].(error).Error())
+		}
+	})
 }
@@ -0,0 +1,97 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+)
+
+type WebhookRepository interface {
+	CreateSubscription(context.Context, domain.Subscription) error
+	Subscriptions(context.Context) ([]domain.Subscription, error)
+	Subscription(context.Context, uuid.UUID) (*domain.Subscription, error)
+	// DeleteSubscription removes the subscription with its deliveries.
+	DeleteSubscription(context.Context, uuid.UUID) error
+	Deliveries(context.Context, domain.DeliveryFilter) ([]domain.Delivery, error)
+	Delivery(context.Context, uuid.UUID) (*domain.Delivery, error)
+	ReplayDelivery(ctx context.Context, deliveryId uuid.UUID, at time.Time) error
+}
+
+// WebhookService manages the webhook subscriptions of partners and the
+// history of the deliveries made to them.
+type WebhookService struct {
+	repository WebhookRepository
+}
+
+func NewWebhookService(repository WebhookRepository) *WebhookService {
+	return &WebhookService{repository: repository}
+}
+
+// Subscribe stores a subscription of target to the given event types, or to
+// all of them when there are none. A secret is generated when empty; it is
+// only returned here.
+func (ws *WebhookService) Subscribe(ctx context.Context, target string, eventTypes []domain.EventType, secret string) (*domain.Subscription, error) {
+	parsed, err := url.Parse(target)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("%w: url must be an absolute http or https URL, got %q", domain.ErrInvalidSubscription, target)
+	}
+	for _, eventType := range eventTypes {
+		if !slices.Contains(domain.EventTypes, eventType) {
+			return nil, fmt.Errorf("%w: unknown event type %q", domain.ErrInvalidSubscription, eventType)
+		}
+	}
+	if secret == "" {
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			return nil, fmt.Errorf("failed to generate secret: %w", err)
+		}
+		secret = hex.EncodeToString(random)
+	}
+
+	subscription := domain.Subscription{
+		Id:         uuid.New(),
+		URL:        target,
+		EventTypes: slices.Compact(slices.Sorted(slices.Values(eventTypes))),
+		Secret:     secret,
+		CreatedAt:  time.Now().UTC().Truncate(time.Microsecond),
+	}
+	if err := ws.repository.CreateSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (ws *WebhookService) Subscriptions(ctx context.Context) ([]domain.Subscription, error) {
+	return ws.repository.Subscriptions(ctx)
+}
+
+func (ws *WebhookService) Subscription(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	return ws.repository.Subscription(ctx, id)
+}
+
+func (ws *WebhookService) Unsubscribe(ctx context.Context, id uuid.UUID) error {
+	return ws.repository.DeleteSubscription(ctx, id)
+}
+
+// Deliveries returns the delivery history selected by filter, newest first.
+func (ws *WebhookService) Deliveries(ctx context.Context, filter domain.DeliveryFilter) ([]domain.Delivery, error) {
+	return ws.repository.Deliveries(ctx, filter)
+}
+
+// ReplayDelivery schedules a delivery again right away, with a fresh count
+// of attempts, and returns it. Dead and delivered deliveries alike can be
+// replayed.
+func (ws *WebhookService) ReplayDelivery(ctx context.Context, deliveryId uuid.UUID) (*domain.Delivery, error) {
+	if err := ws.repository.ReplayDelivery(ctx, deliveryId, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return ws.repository.Delivery(ctx, deliveryId)
+}
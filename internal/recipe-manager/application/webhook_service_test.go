@@ -0,0 +1,78 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+)
+
+// recordingWebhooks records the subscriptions created; its other methods
+// are not expected to be called.
+type recordingWebhooks struct {
+	WebhookRepository
+	created []domain.Subscription
+}
+
+func (r *recordingWebhooks) CreateSubscription(_ context.Context, subscription domain.Subscription) error {
+	r.created = append(r.created, subscription)
+	return nil
+}
+
+func TestSubscribe(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		eventTypes []domain.EventType
+		secret     string
+		expected   []domain.EventType
+		invalid    bool
+	}{
+		{
+			name:       "sorts and deduplicates the event types",
+			url:        "https://menu-board.example/hooks",
+			eventTypes: []domain.EventType{domain.EventRecipeUpdated, domain.EventRecipeCreated, domain.EventRecipeUpdated},
+			secret:     "menu-board-secret",
+			expected:   []domain.EventType{domain.EventRecipeCreated, domain.EventRecipeUpdated},
+		},
+		{
+			name:   "subscribes to every event type without a filter",
+			url:    "http://localhost:9000/hooks",
+			secret: "menu-board-secret",
+		},
+		{name: "rejects a relative URL", url: "/hooks", invalid: true},
+		{name: "rejects other schemes", url: "ftp://menu-board.example/hooks", invalid: true},
+		{
+			name:       "rejects unknown event types",
+			url:        "https://menu-board.example/hooks",
+			eventTypes: []domain.EventType{"recipe.eaten"},
+			invalid:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &recordingWebhooks{}
+			subscription, err := NewWebhookService(repository).Subscribe(context.Background(), tt.url, tt.eventTypes, tt.secret)
+			if tt.invalid {
+				assert.ErrorIs(t, err, domain.ErrInvalidSubscription)
+				assert.Empty(t, repository.created)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, []domain.Subscription{*subscription}, repository.created)
+			assert.Equal(t, tt.expected, subscription.EventTypes)
+			assert.Equal(t, tt.secret, subscription.Secret)
+		})
+	}
+
+	t.Run("generates a secret", func(t *testing.T) {
+		subscription, err := NewWebhookService(&recordingWebhooks{}).Subscribe(context.Background(), "https://pos.example/hooks", nil, "")
+		require.NoError(t, err)
+		assert.Regexp(t, `^[0-9a-f]{64}$`, subscription.Secret)
+	})
+}
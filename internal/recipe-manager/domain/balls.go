@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ErrInvalidBalls is returned for dough balls that cannot be worked out from
+// what was asked.
+var ErrInvalidBalls = errors.New("invalid dough balls")
+
+// DefaultBallWeight is the weight in grams of the balls asked for by count
+// alone, that of a round pizza about 30 cm across.
+const DefaultBallWeight = 250.0
+
+// Balls asks for the dough of Count balls of Weight grams each, Loss percent
+// of the dough being lost in the bowl and on the bench. The whole dough,
+// DoughWeight, takes the place of either Count or Weight: it is divided
+// evenly into Count balls, or into as many balls of Weight as it makes,
+// which share what is left over.
+type Balls struct {
+	Count       int
+	Weight      float64
+	DoughWeight float64
+	Loss        float64
+}
+
+func (b Balls) Validate() error {
+	given := 0
+	for _, set := range []bool{b.Count > 0, b.Weight > 0, b.DoughWeight > 0} {
+		if set {
+			given++
+		}
+	}
+	switch {
+	case b.Count < 0 || b.Weight < 0 || b.DoughWeight < 0:
+		return fmt.Errorf("%w: count, weight and dough weight must not be negative", ErrInvalidBalls)
+	case given == 3:
+		return fmt.Errorf("%w: give two of count, weight and dough weight, not all three", ErrInvalidBalls)
+	case b.Count == 0 && given < 2:
+		return fmt.Errorf("%w: give the count of the balls, or the dough weight and their count or weight", ErrInvalidBalls)
+	case b.Loss < 0 || b.Loss >= 50:
+		return fmt.Errorf("%w: loss must be at least 0%% and less than 50%%, got %g%%", ErrInvalidBalls, b.Loss)
+	}
+	return nil
+}
+
+// Resolve works out the count, weight and dough weight left out.
+func (b Balls) Resolve() (Balls, error) {
+	if err := b.Validate(); err != nil {
+		return Balls{}, err
+	}
+	kept := 1 - b.Loss/100
+	switch {
+	case b.DoughWeight == 0:
+		if b.Weight == 0 {
+			b.Weight = DefaultBallWeight
+		}
+		b.DoughWeight = float64(b.Count) * b.Weight / kept
+	case b.Count == 0:
+		b.Count = int(math.Floor(b.DoughWeight * kept / b.Weight))
+		if b.Count == 0 {
+			return Balls{}, fmt.Errorf("%w: %g g of dough make no ball of %g g", ErrInvalidBalls, b.DoughWeight, b.Weight)
+		}
+		// What is left over is shared among the balls.
+		fallthrough
+	default:
+		b.Weight = b.DoughWeight * kept / float64(b.Count)
+	}
+	return b, nil
+}
+
+// SplitBalls works out the dough of the balls, the amounts of the recipe
+// dough ingredients being their shares of DoughWeight. The aggregate has a
+// single split dough, the one to mix for every ball, and no pans or topping.
+func SplitBalls(recipe Recipe, balls Balls) (*RecipeAggregate, error) {
+	balls, err := balls.Resolve()
+	if err != nil {
+		return nil, err
+	}
+	shares := recipe.Dough.Total()
+	if shares <= 0 {
+		return nil, fmt.Errorf("%w: recipe %q has no dough", ErrInvalidBalls, recipe.Name)
+	}
+
+	dough := Dough{Name: ballsName(balls), PercentVariation: recipe.Dough.PercentVariation}
+	for _, ingredient := range recipe.Dough.Ingredients {
+		dough.Ingredients = append(dough.Ingredients, Ingredient{
+			Name:   ingredient.Name,
+			Amount: balls.DoughWeight * ingredient.Amount / shares,
+			Unit:   "g",
+			Notes:  ingredient.Notes,
+		})
+	}
+	return &RecipeAggregate{
+		Recipe:           recipe,
+		SplitIngredients: SplitIngredients{SplitDough: []Dough{dough}},
+		Balls:            &balls,
+	}, nil
+}
+
+// NutritionByBall is the nutrition of the pizza base one ball of aggregate
+// makes, cut into DefaultSlices; aggregate is expected from SplitBalls.
+func NutritionByBall(recipe Recipe, aggregate RecipeAggregate) []PanNutrition {
+	if aggregate.Balls == nil || len(aggregate.SplitIngredients.SplitDough) == 0 {
+		return nil
+	}
+	dough := aggregate.SplitIngredients.SplitDough[0]
+	share := aggregate.Balls.Weight / dough.Total()
+	ball := Dough{Name: "ball"}
+	for _, ingredient := range dough.Ingredients {
+		ingredient.Amount *= share
+		ball.Ingredients = append(ball.Ingredients, ingredient)
+	}
+	return NutritionByPan(recipe, RecipeAggregate{
+		SplitIngredients: SplitIngredients{SplitDough: []Dough{ball}},
+	}, Pans{Pans: []Pan{{Name: ball.Name}}})
+}
+
+func ballsName(balls Balls) string {
+	return fmt.Sprintf("%d balls of %s g", balls.Count, strconv.FormatFloat(math.Round(balls.Weight*10)/10, 'f', -1, 64))
+}
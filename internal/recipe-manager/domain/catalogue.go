@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"math"
+	"slices"
+)
+
+// Allergen is one of the fourteen allergens that must be declared under
+// EU Regulation 1169/2011.
+type Allergen string
+
+const (
+	AllergenGluten      Allergen = "gluten"
+	AllergenCrustaceans Allergen = "crustaceans"
+	AllergenEggs        Allergen = "eggs"
+	AllergenFish        Allergen = "fish"
+	AllergenPeanuts     Allergen = "peanuts"
+	AllergenSoybeans    Allergen = "soybeans"
+	AllergenMilk        Allergen = "milk"
+	AllergenNuts        Allergen = "nuts"
+	AllergenCelery      Allergen = "celery"
+	AllergenMustard     Allergen = "mustard"
+	AllergenSesame      Allergen = "sesame"
+	AllergenSulphites   Allergen = "sulphites"
+	AllergenLupin       Allergen = "lupin"
+	AllergenMolluscs    Allergen = "molluscs"
+)
+
+var Allergens = []Allergen{
+	AllergenGluten, AllergenCrustaceans, AllergenEggs, AllergenFish, AllergenPeanuts, AllergenSoybeans, AllergenMilk,
+	AllergenNuts, AllergenCelery, AllergenMustard, AllergenSesame, AllergenSulphites, AllergenLupin, AllergenMolluscs,
+}
+
+func (a Allergen) Valid() bool {
+	return slices.Contains(Allergens, a)
+}
+
+// Diet is a dietary tag that holds for a recipe only when it holds for each
+// of its ingredients.
+type Diet string
+
+const (
+	DietVegetarian Diet = "vegetarian"
+	DietVegan      Diet = "vegan"
+)
+
+var Diets = []Diet{DietVegetarian, DietVegan}
+
+func (d Diet) Valid() bool {
+	return slices.Contains(Diets, d)
+}
+
+// CatalogueIngredient is the shared identity of an ingredient. Recipes refer
+// to it by its canonical Name; Aliases are the other spellings that resolve
+// to the same entry.
+type CatalogueIngredient struct {
+	Id   int
+	Name string
+	// DisplayNames maps a locale, such as "en" or "it", to a human readable name.
+	DisplayNames map[string]string
+	Aliases      []string
+	Category     string
+	Allergens    []Allergen
+	Diets        []Diet
+	// Density in g/ml, 0 when unknown.
+	Density float64
+	// Nutrition per 100 g, nil when unknown.
+	Nutrition *Nutrition
+}
+
+// Nutrition holds energy in kcal and macronutrients in grams.
+type Nutrition struct {
+	Kcal          float64
+	Protein       float64
+	Carbohydrates float64
+	Fat           float64
+}
+
+// Rounded is the nutrition as labels print it: kcal to the unit and grams
+// to one decimal.
+func (n Nutrition) Rounded() Nutrition {
+	return Nutrition{
+		Kcal:          math.Round(n.Kcal),
+		Protein:       math.Round(n.Protein*10) / 10,
+		Carbohydrates: math.Round(n.Carbohydrates*10) / 10,
+		Fat:           math.Round(n.Fat*10) / 10,
+	}
+}
+
+// Catalogued reports whether the entry was imported from the catalogue, as
+// opposed to a bare name added by a recipe. Only catalogued entries have
+// reliable allergen and diet data.
+func (c *CatalogueIngredient) Catalogued() bool {
+	return c != nil && c.Category != ""
+}
+
+func (n Nutrition) Add(other Nutrition) Nutrition {
+	return Nutrition{
+		Kcal:          n.Kcal + other.Kcal,
+		Protein:       n.Protein + other.Protein,
+		Carbohydrates: n.Carbohydrates + other.Carbohydrates,
+		Fat:           n.Fat + other.Fat,
+	}
+}
+
+func (n Nutrition) Scale(factor float64) Nutrition {
+	return Nutrition{
+		Kcal:          n.Kcal * factor,
+		Protein:       n.Protein * factor,
+		Carbohydrates: n.Carbohydrates * factor,
+		Fat:           n.Fat * factor,
+	}
+}
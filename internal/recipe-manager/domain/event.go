@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	EventRecipeCreated    EventType = "recipe.created"
+	EventRecipeUpdated    EventType = "recipe.updated"
+	EventRecipeDeleted    EventType = "recipe.deleted"
+	EventRecipeAggregated EventType = "recipe.aggregated"
+)
+
+// Event tells downstream systems, such as menu boards, that a recipe changed
+// or was used.
+type Event struct {
+	// Id stays the same across deliveries of the event, so consumers can use
+	// it as idempotency key.
+	Id         uuid.UUID
+	Type       EventType
+	OccurredAt time.Time
+	// Recipe is the recipe as the change left it.
+	Recipe Recipe
+	// Pans are the pans of an EventRecipeAggregated.
+	Pans Pans
+}
+
+func NewEvent(eventType EventType, recipe Recipe) Event {
+	return Event{
+		Id:         uuid.New(),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Recipe:     recipe,
+	}
+}
@@ -0,0 +1,272 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidFermentation is returned for a fermentation plan that cannot be
+// scheduled, or for a recipe without yeast to adjust.
+var ErrInvalidFermentation = errors.New("invalid fermentation")
+
+type YeastType string
+
+const (
+	YeastFresh   YeastType = "fresh"
+	YeastInstant YeastType = "instant"
+	YeastDry     YeastType = "dry"
+	// YeastSourdough is a sourdough starter at 100% hydration.
+	YeastSourdough YeastType = "sourdough"
+)
+
+var YeastTypes = []YeastType{YeastFresh, YeastInstant, YeastDry, YeastSourdough}
+
+// yeasts are the ingredient each type of yeast is added as, and its weight
+// for the weight of fresh yeast that raises the dough as much.
+var yeasts = map[YeastType]struct {
+	ingredient string
+	strength   float64
+}{
+	YeastFresh:     {"yeast", 1},
+	YeastInstant:   {"instantYeast", 1.0 / 3},
+	YeastDry:       {"dryYeast", 0.4},
+	YeastSourdough: {"sourdoughStarter", 20},
+}
+
+const (
+	// freshYeastHours is the percentage of fresh yeast on the flour that
+	// raises a dough in an hour at referenceTemperature; twice the hours
+	// take half the yeast.
+	freshYeastHours = 3.0
+	// referenceTemperature is in °C; the yeast works twice as fast every
+	// doublingTemperature above it and half as fast every one below.
+	referenceTemperature = 20.0
+	doublingTemperature  = 6.0
+
+	mixDuration  = 20 * time.Minute
+	ballDuration = 15 * time.Minute
+	bakeDuration = 15 * time.Minute
+)
+
+type Stage string
+
+const (
+	StageMix   Stage = "mix"
+	StageBulk  Stage = "bulk"
+	StageBall  Stage = "ball"
+	StageProof Stage = "proof"
+	StageBake  Stage = "bake"
+)
+
+// FermentationPlan is how long and how warm a dough ferments, to be baked at
+// BakeAt. Temperatures are in °C.
+type FermentationPlan struct {
+	Yeast YeastType
+	// Duration is the whole fermentation, bulk and proof, the time in the
+	// fridge included.
+	Duration        time.Duration
+	RoomTemperature float64
+	// FridgeDuration is the part of the bulk fermentation spent in the
+	// fridge, none for a dough fermented at room temperature.
+	FridgeDuration    time.Duration
+	FridgeTemperature float64
+	BakeAt            time.Time
+}
+
+// Fermentation is the outcome of a plan: the yeast the dough takes and the
+// schedule of its stages.
+type Fermentation struct {
+	Yeast YeastType
+	// YeastPercent is the weight of the yeast on the weight of the flour.
+	YeastPercent float64
+	Schedule     []ScheduledStage
+}
+
+type ScheduledStage struct {
+	Stage Stage
+	Start time.Time
+	End   time.Time
+	// Temperature is where the dough rests during the stage, the room one
+	// but for the bulk fermentation in the fridge. It is 0 for StageBake.
+	Temperature float64
+}
+
+func (p FermentationPlan) Validate() error {
+	var problems []string
+	if !slices.Contains(YeastTypes, p.Yeast) {
+		problems = append(problems, fmt.Sprintf("unknown yeast %q", p.Yeast))
+	}
+	if p.Duration < time.Hour || p.Duration > 96*time.Hour {
+		problems = append(problems, fmt.Sprintf("duration must be between 1h and 96h, got %s", p.Duration))
+	}
+	if p.RoomTemperature < 10 || p.RoomTemperature > 35 {
+		problems = append(problems, fmt.Sprintf("room temperature must be between 10 and 35 °C, got %g", p.RoomTemperature))
+	}
+	if p.FridgeDuration < 0 || p.FridgeDuration >= p.Duration {
+		problems = append(problems, fmt.Sprintf("fridge duration must be shorter than the duration, got %s", p.FridgeDuration))
+	}
+	if p.FridgeDuration > 0 && (p.FridgeTemperature < 0 || p.FridgeTemperature > 12) {
+		problems = append(problems, fmt.Sprintf("fridge temperature must be between 0 and 12 °C, got %g", p.FridgeTemperature))
+	}
+	if p.BakeAt.IsZero() {
+		problems = append(problems, "bake time is required")
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidFermentation, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// YeastPercent is the yeast of the plan's type, on the flour, that raises
+// the dough over the plan: the longer and the warmer, the less it takes.
+func (p FermentationPlan) YeastPercent() float64 {
+	hours := p.roomDuration().Hours()*yeastActivity(p.RoomTemperature) +
+		p.FridgeDuration.Hours()*yeastActivity(p.FridgeTemperature)
+	return freshYeastHours / hours * yeasts[p.Yeast].strength
+}
+
+// yeastActivity is how fast yeast works at temperature, relative to
+// referenceTemperature.
+func yeastActivity(temperature float64) float64 {
+	return math.Pow(2, (temperature-referenceTemperature)/doublingTemperature)
+}
+
+// Schedule times the stages back from BakeAt. The time at room temperature
+// goes two thirds to the bulk fermentation, before the fridge, and one
+// third to the proof.
+func (p FermentationPlan) Schedule() []ScheduledStage {
+	proof := (p.roomDuration() / 3).Round(time.Minute)
+	bulk := p.roomDuration() - proof
+
+	proofStart := p.BakeAt.Add(-proof)
+	ballStart := proofStart.Add(-ballDuration)
+	fridgeStart := ballStart.Add(-p.FridgeDuration)
+	bulkStart := fridgeStart.Add(-bulk)
+
+	schedule := []ScheduledStage{
+		{Stage: StageMix, Start: bulkStart.Add(-mixDuration), End: bulkStart, Temperature: p.RoomTemperature},
+		{Stage: StageBulk, Start: bulkStart, End: fridgeStart, Temperature: p.RoomTemperature},
+	}
+	if p.FridgeDuration > 0 {
+		schedule = append(schedule, ScheduledStage{Stage: StageBulk, Start: fridgeStart, End: ballStart, Temperature: p.FridgeTemperature})
+	}
+	return append(schedule,
+		ScheduledStage{Stage: StageBall, Start: ballStart, End: proofStart, Temperature: p.RoomTemperature},
+		ScheduledStage{Stage: StageProof, Start: proofStart, End: p.BakeAt, Temperature: p.RoomTemperature},
+		ScheduledStage{Stage: StageBake, Start: p.BakeAt, End: p.BakeAt.Add(bakeDuration)},
+	)
+}
+
+func (p FermentationPlan) roomDuration() time.Duration {
+	return p.Duration - p.FridgeDuration
+}
+
+// Ferment replaces the yeast in the dough of each pan of aggregate with the
+// yeast of the plan, YeastPercent of the flour of the pan, the categories
+// coming from the catalogue entries of the recipe ingredients. A sourdough
+// starter brings half its weight of flour and half of water, which are taken
+// off the dough; a plan calling for more than the dough has is invalid.
+func (p FermentationPlan) Ferment(aggregate *RecipeAggregate, recipe Recipe) (Fermentation, error) {
+	if err := p.Validate(); err != nil {
+		return Fermentation{}, err
+	}
+	categories := make(map[string]string)
+	for _, ingredient := range recipe.Dough.Ingredients {
+		if ingredient.Catalogue != nil {
+			categories[ingredient.Name] = ingredient.Catalogue.Category
+		}
+	}
+	category := func(ingredient Ingredient) string {
+		if ingredient.Catalogue != nil {
+			return ingredient.Catalogue.Category
+		}
+		if found, ok := categories[ingredient.Name]; ok {
+			return found
+		}
+		// Bare names, such as those of recipes missing from the catalogue.
+		switch name := strings.ToLower(ingredient.Name); {
+		case strings.Contains(name, "yeast"):
+			return "leavening"
+		case strings.Contains(name, "flour"):
+			return "flour"
+		case name == "water":
+			return "water"
+		}
+		return ""
+	}
+	if !slices.ContainsFunc(recipe.Dough.Ingredients, func(ingredient Ingredient) bool {
+		return category(ingredient) == "leavening"
+	}) {
+		return Fermentation{}, fmt.Errorf("%w: recipe %q has no yeast to adjust", ErrInvalidFermentation, recipe.Name)
+	}
+
+	percent := p.YeastPercent()
+	yeast := yeasts[p.Yeast]
+	for i := range aggregate.SplitIngredients.SplitDough {
+		dough := &aggregate.SplitIngredients.SplitDough[i]
+		flour, water := 0.0, 0.0
+		for _, ingredient := range dough.Ingredients {
+			switch category(ingredient) {
+			case "flour":
+				flour += ingredient.Amount
+			case "water":
+				water += ingredient.Amount
+			}
+		}
+		amount := flour * percent / 100
+		if p.Yeast == YeastSourdough && (amount/2 > flour || amount/2 > water) {
+			return Fermentation{}, fmt.Errorf("%w: a sourdough starter of %.0f%% of the flour brings more flour or water than the dough has, ferment longer or warmer",
+				ErrInvalidFermentation, percent)
+		}
+
+		var ingredients []Ingredient
+		added := false
+		for _, ingredient := range dough.Ingredients {
+			if category(ingredient) != "leavening" {
+				ingredients = append(ingredients, ingredient)
+				continue
+			}
+			// The yeast of the plan takes the place of the first yeast of
+			// the dough, the others are dropped.
+			if !added {
+				ingredients = append(ingredients, Ingredient{
+					Name:      yeast.ingredient,
+					Amount:    amount,
+					Unit:      ingredient.Unit,
+					Catalogue: &CatalogueIngredient{Name: yeast.ingredient, Category: "leavening"},
+				})
+				added = true
+			}
+		}
+		if p.Yeast == YeastSourdough && flour > 0 {
+			takeOff(ingredients, amount/2, func(ingredient Ingredient) bool { return category(ingredient) == "flour" })
+			takeOff(ingredients, amount/2, func(ingredient Ingredient) bool { return category(ingredient) == "water" })
+		}
+		dough.Ingredients = ingredients
+	}
+
+	return Fermentation{Yeast: p.Yeast, YeastPercent: percent, Schedule: p.Schedule()}, nil
+}
+
+// takeOff removes weight from the ingredients matching, in proportion to
+// their amounts.
+func takeOff(ingredients []Ingredient, weight float64, matching func(Ingredient) bool) {
+	total := 0.0
+	for _, ingredient := range ingredients {
+		if matching(ingredient) {
+			total += ingredient.Amount
+		}
+	}
+	if total <= 0 {
+		return
+	}
+	for i := range ingredients {
+		if matching(ingredients[i]) {
+			ingredients[i].Amount -= weight * ingredients[i].Amount / total
+		}
+	}
+}
@@ -11,6 +11,15 @@ type Dough struct {
 	Ingredients      []Ingredient
 }
 
+// Total is the weight of the dough, the sum of its ingredient amounts.
+func (d Dough) Total() float64 {
+	total := 0.0
+	for _, ingredient := range d.Ingredients {
+		total += ingredient.Amount
+	}
+	return total
+}
+
 type Topping struct {
 	Name          string
 	ReferenceArea float64
@@ -20,4 +29,11 @@ type Topping struct {
 type Ingredient struct {
 	Name   string
 	Amount float64
+	// Unit is "%" of the dough weight for dough ingredients and "g" per
+	// ReferenceArea for topping ingredients, unless stated otherwise.
+	Unit  string
+	Notes string
+	// Catalogue is the entry the ingredient is linked to, when loaded from
+	// the repository.
+	Catalogue *CatalogueIngredient
 }
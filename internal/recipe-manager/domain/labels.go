@@ -0,0 +1,66 @@
+package domain
+
+import "slices"
+
+// Labels are the allergen declarations and dietary tags of a recipe.
+type Labels struct {
+	// Allergens contained in at least one ingredient, in the order of Allergens.
+	Allergens []Allergen
+	// Diets satisfied by every ingredient, in the order of Diets.
+	Diets []Diet
+	// Unlabelled lists the ingredients missing from the catalogue. While any
+	// is left the allergen declaration is incomplete and no diet is claimed.
+	Unlabelled []string
+}
+
+// Labels computes the labels of the dough and topping ingredients from their
+// catalogue entries.
+func (r Recipe) Labels() Labels {
+	var labels Labels
+	contained := make(map[Allergen]bool)
+	satisfied := make(map[Diet]int)
+	ingredients := slices.Concat(r.Dough.Ingredients, r.Topping.Ingredients)
+
+	for _, ingredient := range ingredients {
+		if !ingredient.Catalogue.Catalogued() {
+			if !slices.Contains(labels.Unlabelled, ingredient.Name) {
+				labels.Unlabelled = append(labels.Unlabelled, ingredient.Name)
+			}
+			continue
+		}
+		for _, allergen := range ingredient.Catalogue.Allergens {
+			contained[allergen] = true
+		}
+		for _, diet := range ingredient.Catalogue.Diets {
+			satisfied[diet]++
+		}
+	}
+
+	for _, allergen := range Allergens {
+		if contained[allergen] {
+			labels.Allergens = append(labels.Allergens, allergen)
+		}
+	}
+	if len(labels.Unlabelled) == 0 && len(ingredients) > 0 {
+		for _, diet := range Diets {
+			if satisfied[diet] == len(ingredients) {
+				labels.Diets = append(labels.Diets, diet)
+			}
+		}
+	}
+	return labels
+}
+
+// FreeFrom reports whether none of allergens is declared. It is false while
+// the declaration is incomplete.
+func (l Labels) FreeFrom(allergens []Allergen) bool {
+	if len(l.Unlabelled) > 0 {
+		return false
+	}
+	for _, allergen := range allergens {
+		if slices.Contains(l.Allergens, allergen) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package domain
+
+import "slices"
+
+// DefaultSlices is the number of slices a pan is cut into when the request
+// does not say.
+const DefaultSlices = 8
+
+type PanNutrition struct {
+	Pan      string
+	Slices   int
+	Total    Nutrition
+	PerSlice Nutrition
+	// Unknown lists the ingredients without nutrition data in the catalogue,
+	// which are left out of the totals.
+	Unknown []string
+}
+
+// NutritionByPan computes the nutrition of the dough and topping balanced for
+// each pan. Nutrition data comes from the catalogue entries of the recipe
+// ingredients, while the amounts, in grams, come from the aggregate. When the
+// aggregate has no topping split per pan, the recipe topping is shared by
+// area.
+func NutritionByPan(recipe Recipe, aggregate RecipeAggregate, pans Pans) []PanNutrition {
+	catalogue := make(map[string]*CatalogueIngredient)
+	for _, ingredient := range slices.Concat(recipe.Dough.Ingredients, recipe.Topping.Ingredients) {
+		if ingredient.Catalogue != nil {
+			catalogue[ingredient.Name] = ingredient.Catalogue
+		}
+	}
+
+	result := make([]PanNutrition, 0, len(pans.Pans))
+	for i, pan := range pans.Pans {
+		var ingredients []Ingredient
+		if i < len(aggregate.SplitIngredients.SplitDough) {
+			ingredients = append(ingredients, aggregate.SplitIngredients.SplitDough[i].Ingredients...)
+		}
+		ingredients = append(ingredients, panTopping(aggregate, pans, i)...)
+
+		slicesCount := pan.Slices
+		if slicesCount <= 0 {
+			slicesCount = DefaultSlices
+		}
+		panNutrition := PanNutrition{Pan: pan.Name, Slices: slicesCount}
+		for _, ingredient := range ingredients {
+			entry, found := catalogue[ingredient.Name]
+			if !found || entry.Nutrition == nil {
+				if !slices.Contains(panNutrition.Unknown, ingredient.Name) {
+					panNutrition.Unknown = append(panNutrition.Unknown, ingredient.Name)
+				}
+				continue
+			}
+			panNutrition.Total = panNutrition.Total.Add(entry.Nutrition.Scale(ingredient.Amount / 100))
+		}
+		panNutrition.PerSlice = panNutrition.Total.Scale(1 / float64(slicesCount))
+		result = append(result, panNutrition)
+	}
+	return result
+}
+
+// PanTopping returns the topping ingredients of the pan at index of the pans
+// the aggregate was balanced for.
+func (a RecipeAggregate) PanTopping(index int) []Ingredient {
+	return panTopping(a, a.Pans, index)
+}
+
+func panTopping(aggregate RecipeAggregate, pans Pans, index int) []Ingredient {
+	if len(aggregate.SplitIngredients.SplitTopping) == len(pans.Pans) {
+		return aggregate.SplitIngredients.SplitTopping[index].Ingredients
+	}
+
+	// The amounts are grams per ReferenceArea; without one, the recipe
+	// topping is shared among the pans by area.
+	var share float64
+	switch {
+	case aggregate.Recipe.Topping.ReferenceArea > 0:
+		share = pans.Pans[index].Area / aggregate.Recipe.Topping.ReferenceArea
+	case pans.TotalArea > 0:
+		share = pans.Pans[index].Area / pans.TotalArea
+	default:
+		return nil
+	}
+	topping := make([]Ingredient, len(aggregate.Recipe.Topping.Ingredients))
+	for i, ingredient := range aggregate.Recipe.Topping.Ingredients {
+		ingredient.Amount *= share
+		topping[i] = ingredient
+	}
+	return topping
+}
@@ -3,6 +3,13 @@ package domain
 type Pans struct {
 	Pans      []Pan
 	TotalArea float64
+	// Style and Thickness, in millimetres of stretched dough, ask for the
+	// dough density of a style or a thickness; DoughDensity, in grams per
+	// cm², asks for one directly. Once resolved by DoughDensityFor, Style is
+	// the style the density came from, if any, and Thickness is left out.
+	Style        string
+	Thickness    float64
+	DoughDensity float64
 }
 
 type Pan struct {
@@ -10,6 +17,11 @@ type Pan struct {
 	Measures Measures
 	Name     string
 	Area     float64
+	// DoughWeight is the grams of dough the pan takes, 0 when the dough
+	// density is unknown.
+	DoughWeight float64
+	// Slices the pan is cut into, DefaultSlices when 0.
+	Slices int
 }
 
 type Measures struct {
@@ -1,12 +1,39 @@
 package domain
 
 import (
+	"errors"
+	"time"
+
 	"github.com/google/uuid"
 )
 
+var (
+	ErrRecipeNotFound = errors.New("recipe not found")
+	// ErrVersionConflict is returned when a recipe changed since the version
+	// a caller based its change on.
+	ErrVersionConflict = errors.New("recipe version conflict")
+)
+
 type RecipeAggregate struct {
 	Recipe
 	SplitIngredients SplitIngredients
+	Nutrition        []PanNutrition
+	Labels           Labels
+	// Pans are the pans the recipe was balanced for, as the calculator
+	// measured them.
+	Pans Pans
+	// Rounding reports how the split amounts were rounded, nil when they
+	// were left as balanced.
+	Rounding *RoundingReport
+	// Fermentation is the yeast and schedule of the fermentation plan the
+	// aggregate was asked for, nil without one.
+	Fermentation *Fermentation
+	// Balls are the dough balls the aggregate was split into instead of
+	// pans, nil for pans.
+	Balls *Balls
+	// Scale is the servings or dough weight the recipe was scaled to
+	// instead of pans, nil for pans.
+	Scale *Scale
 }
 
 type Recipe struct {
@@ -18,4 +45,28 @@ type Recipe struct {
 	Dough       Dough
 	Topping     Topping
 	Steps       Steps
+	// Style is the pizza style of the recipe, one of Styles, or none.
+	Style string
+	// DoughDensity is the grams of dough per cm² of pan the recipe is made
+	// with, overriding the one of its style; 0 when unset.
+	DoughDensity float64
+	// ServingWeight is the grams of dough of a serving of the recipe,
+	// DefaultServingWeight when 0.
+	ServingWeight float64
+	// Version starts at 1 and is bumped by every update.
+	Version int
+	// ArchivedAt is set while the recipe is archived: it is left out of the
+	// listings but can still be read and changed.
+	ArchivedAt *time.Time
+	// DeletedAt is set once the recipe is deleted. Deleted recipes are only
+	// visible to admins, who can restore them until they are purged.
+	DeletedAt *time.Time
+}
+
+// RecipeFilter selects the recipes to list. The zero value selects the
+// recipes that are neither archived nor deleted.
+type RecipeFilter struct {
+	IncludeArchived bool
+	// Deleted selects the deleted recipes instead.
+	Deleted bool
 }
@@ -0,0 +1,196 @@
+package domain
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+)
+
+const (
+	ComponentDough   = "dough"
+	ComponentTopping = "topping"
+)
+
+// RoundingPolicy sets the step, in grams, the split amounts of each
+// ingredient class are rounded to, such as 0.1 g for yeast and 5 g for
+// flour.
+type RoundingPolicy struct {
+	// Default is the step of the ingredients no class matches; 0 leaves
+	// their amounts as balanced.
+	Default float64
+	// Classes maps ingredient names and catalogue categories, in any case,
+	// to their step. The name of an ingredient wins over its category.
+	Classes map[string]float64
+}
+
+// RoundingReport tells how far rounding moved the amounts of an aggregate
+// from the balanced ones.
+type RoundingReport struct {
+	Ingredients []RoundingDrift
+}
+
+// Drift is the weight rounding added to the whole aggregate, negative when
+// it took some away.
+func (r RoundingReport) Drift() float64 {
+	drift := 0.0
+	for _, ingredient := range r.Ingredients {
+		drift += ingredient.Drift()
+	}
+	return drift
+}
+
+// RoundingDrift is the rounding of one ingredient over every pan.
+type RoundingDrift struct {
+	Component  string
+	Ingredient string
+	Step       float64
+	// Exact is the total amount before rounding, Rounded the total of the
+	// rounded amounts, which is Exact rounded to Step.
+	Exact   float64
+	Rounded float64
+	// Adjusted counts the pans whose amount was rounded away from the
+	// nearest step so the pans add up to Rounded.
+	Adjusted int
+}
+
+func (d RoundingDrift) Drift() float64 {
+	return d.Rounded - d.Exact
+}
+
+// Step returns the step of ingredient, whose category comes from its
+// catalogue entry.
+func (p RoundingPolicy) Step(ingredient Ingredient) float64 {
+	for class, step := range p.Classes {
+		if strings.EqualFold(class, ingredient.Name) {
+			return step
+		}
+	}
+	if ingredient.Catalogue != nil && ingredient.Catalogue.Category != "" {
+		for class, step := range p.Classes {
+			if strings.EqualFold(class, ingredient.Catalogue.Category) {
+				return step
+			}
+		}
+	}
+	return p.Default
+}
+
+// Round rounds the dough and topping amounts of each pan of aggregate to the
+// step of their ingredient, the categories coming from the catalogue entries
+// of the recipe ingredients. The amounts of an ingredient are reconciled
+// over the pans, so they add up to its total rounded to the same step: the
+// pans whose amounts were rounded the furthest take the difference, one
+// step each. The topping is split explicitly for each pan.
+func (p RoundingPolicy) Round(aggregate *RecipeAggregate, recipe Recipe) RoundingReport {
+	catalogue := make(map[string]*CatalogueIngredient)
+	for _, ingredient := range slices.Concat(recipe.Dough.Ingredients, recipe.Topping.Ingredients) {
+		if ingredient.Catalogue != nil {
+			catalogue[ingredient.Name] = ingredient.Catalogue
+		}
+	}
+	step := func(ingredient Ingredient) float64 {
+		if ingredient.Catalogue == nil {
+			ingredient.Catalogue = catalogue[ingredient.Name]
+		}
+		return p.Step(ingredient)
+	}
+
+	split := &aggregate.SplitIngredients
+	if len(aggregate.Pans.Pans) > 0 && len(split.SplitTopping) != len(aggregate.Pans.Pans) {
+		toppings := make([]Topping, len(aggregate.Pans.Pans))
+		for i, pan := range aggregate.Pans.Pans {
+			name := pan.Name
+			if name == "" {
+				name = pan.Shape
+			}
+			toppings[i] = Topping{Name: name, Ingredients: aggregate.PanTopping(i)}
+		}
+		split.SplitTopping = toppings
+	}
+
+	doughs := make([][]Ingredient, len(split.SplitDough))
+	for i := range split.SplitDough {
+		doughs[i] = split.SplitDough[i].Ingredients
+	}
+	toppings := make([][]Ingredient, len(split.SplitTopping))
+	for i := range split.SplitTopping {
+		toppings[i] = split.SplitTopping[i].Ingredients
+	}
+
+	var report RoundingReport
+	report.Ingredients = append(report.Ingredients, reconcile(ComponentDough, doughs, step)...)
+	report.Ingredients = append(report.Ingredients, reconcile(ComponentTopping, toppings, step)...)
+	return report
+}
+
+// reconcile rounds the amounts of each ingredient of pans in place, in the
+// order the ingredients first appear.
+func reconcile(component string, pans [][]Ingredient, step func(Ingredient) float64) []RoundingDrift {
+	type amount struct {
+		ingredient *Ingredient
+		// remainder is how much rounding took away, negative when it added.
+		remainder float64
+	}
+	var names []string
+	amounts := make(map[string][]amount)
+	for _, ingredients := range pans {
+		for i := range ingredients {
+			name := ingredients[i].Name
+			if _, found := amounts[name]; !found {
+				names = append(names, name)
+			}
+			amounts[name] = append(amounts[name], amount{ingredient: &ingredients[i]})
+		}
+	}
+
+	var drifts []RoundingDrift
+	for _, name := range names {
+		group := amounts[name]
+		size := step(*group[0].ingredient)
+		if size <= 0 {
+			continue
+		}
+
+		drift := RoundingDrift{Component: component, Ingredient: name, Step: size}
+		rounded := 0.0
+		for i := range group {
+			exact := group[i].ingredient.Amount
+			drift.Exact += exact
+			group[i].ingredient.Amount = roundToStep(exact, size)
+			group[i].remainder = exact - group[i].ingredient.Amount
+			rounded += group[i].ingredient.Amount
+		}
+		drift.Rounded = roundToStep(drift.Exact, size)
+
+		// The steps the rounded amounts miss, or exceed when negative.
+		missing := int(math.Round((drift.Rounded - rounded) / size))
+		slices.SortStableFunc(group, func(a, b amount) int {
+			if missing > 0 {
+				return cmp.Compare(b.remainder, a.remainder)
+			}
+			return cmp.Compare(a.remainder, b.remainder)
+		})
+		for i := 0; i < len(group) && missing != 0; i++ {
+			ingredient := group[i].ingredient
+			if missing > 0 {
+				ingredient.Amount = roundToStep(ingredient.Amount+size, size)
+				missing--
+			} else if ingredient.Amount >= size {
+				ingredient.Amount = roundToStep(ingredient.Amount-size, size)
+				missing++
+			} else {
+				continue
+			}
+			drift.Adjusted++
+		}
+		drifts = append(drifts, drift)
+	}
+	return drifts
+}
+
+// roundToStep rounds amount to the nearest multiple of step, without the
+// binary noise of multiples such as 0.1 * 3.
+func roundToStep(amount, step float64) float64 {
+	return math.Round(math.Round(amount/step)*step*1e9) / 1e9
+}
@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ErrInvalidScale is returned for a scale that cannot be worked out from what
+// was asked.
+var ErrInvalidScale = errors.New("invalid scale")
+
+// DefaultServingWeight is the grams of dough of a serving for the recipes
+// that do not define one, a ball for a round pizza.
+const DefaultServingWeight = DefaultBallWeight
+
+// Scale asks for the dough and topping of Servings servings, or of
+// DoughWeight grams of dough, without pans. ServingWeight is the dough of a
+// serving, from the recipe once resolved.
+type Scale struct {
+	Servings      float64
+	DoughWeight   float64
+	ServingWeight float64
+}
+
+func (s Scale) Validate() error {
+	switch {
+	case s.Servings < 0 || s.DoughWeight < 0:
+		return fmt.Errorf("%w: servings and dough weight must not be negative", ErrInvalidScale)
+	case s.Servings > 0 && s.DoughWeight > 0:
+		return fmt.Errorf("%w: give the servings or the dough weight, not both", ErrInvalidScale)
+	case s.Servings == 0 && s.DoughWeight == 0:
+		return fmt.Errorf("%w: give the servings or the dough weight", ErrInvalidScale)
+	}
+	return nil
+}
+
+// Resolve works out the serving weight of recipe and the servings or dough
+// weight left out.
+func (s Scale) Resolve(recipe Recipe) (Scale, error) {
+	if err := s.Validate(); err != nil {
+		return Scale{}, err
+	}
+	s.ServingWeight = recipe.ServingWeight
+	if s.ServingWeight <= 0 {
+		s.ServingWeight = DefaultServingWeight
+	}
+	if s.DoughWeight == 0 {
+		s.DoughWeight = s.Servings * s.ServingWeight
+	} else {
+		s.Servings = s.DoughWeight / s.ServingWeight
+	}
+	return s, nil
+}
+
+// ScaleRecipe works out the dough and topping of scale. The amounts of the
+// recipe dough ingredients are their shares of the dough weight. The topping
+// covers the area the dough stretches over at the dough density of the
+// recipe, that of the Neapolitan style when unset; it is counted in servings
+// for recipes without a reference area. The aggregate has the recipe as
+// stored, a single split dough and topping, and no pans.
+func ScaleRecipe(recipe Recipe, scale Scale) (*RecipeAggregate, error) {
+	scale, err := scale.Resolve(recipe)
+	if err != nil {
+		return nil, err
+	}
+	shares := recipe.Dough.Total()
+	if shares <= 0 {
+		return nil, fmt.Errorf("%w: recipe %q has no dough", ErrInvalidScale, recipe.Name)
+	}
+
+	name := scaleName(scale)
+	dough := Dough{Name: name, PercentVariation: recipe.Dough.PercentVariation}
+	for _, ingredient := range recipe.Dough.Ingredients {
+		dough.Ingredients = append(dough.Ingredients, Ingredient{
+			Name:   ingredient.Name,
+			Amount: scale.DoughWeight * ingredient.Amount / shares,
+			Unit:   "g",
+			Notes:  ingredient.Notes,
+		})
+	}
+
+	factor := scale.Servings
+	if recipe.Topping.ReferenceArea > 0 {
+		pans, err := Pans{}.DoughDensityFor(recipe)
+		if err != nil {
+			return nil, err
+		}
+		density := pans.DoughDensity
+		if density <= 0 {
+			style, _ := StyleByName(StyleNeapolitan)
+			density = style.DoughDensity
+		}
+		factor = scale.DoughWeight / density / recipe.Topping.ReferenceArea
+	}
+	topping := Topping{Name: name}
+	for _, ingredient := range recipe.Topping.Ingredients {
+		ingredient.Amount *= factor
+		topping.Ingredients = append(topping.Ingredients, ingredient)
+	}
+
+	return &RecipeAggregate{
+		Recipe:           recipe,
+		SplitIngredients: SplitIngredients{SplitDough: []Dough{dough}, SplitTopping: []Topping{topping}},
+		Scale:            &scale,
+	}, nil
+}
+
+// NutritionByServing is the nutrition of one serving of aggregate, its dough
+// and topping; aggregate is expected from ScaleRecipe.
+func NutritionByServing(recipe Recipe, aggregate RecipeAggregate) []PanNutrition {
+	split := aggregate.SplitIngredients
+	if aggregate.Scale == nil || aggregate.Scale.Servings <= 0 || len(split.SplitDough) == 0 {
+		return nil
+	}
+	share := 1 / aggregate.Scale.Servings
+	serving := RecipeAggregate{SplitIngredients: SplitIngredients{
+		SplitDough:   []Dough{{Ingredients: scaleAmounts(split.SplitDough[0].Ingredients, share)}},
+		SplitTopping: []Topping{{}},
+	}}
+	if len(split.SplitTopping) > 0 {
+		serving.SplitIngredients.SplitTopping[0].Ingredients = scaleAmounts(split.SplitTopping[0].Ingredients, share)
+	}
+	return NutritionByPan(recipe, serving, Pans{Pans: []Pan{{Name: "serving", Slices: 1}}})
+}
+
+func scaleAmounts(ingredients []Ingredient, factor float64) []Ingredient {
+	scaled := make([]Ingredient, len(ingredients))
+	for i, ingredient := range ingredients {
+		ingredient.Amount *= factor
+		scaled[i] = ingredient
+	}
+	return scaled
+}
+
+func scaleName(scale Scale) string {
+	servings := strconv.FormatFloat(math.Round(scale.Servings*10)/10, 'f', -1, 64)
+	return fmt.Sprintf("%s servings, %s g of dough", servings, strconv.FormatFloat(math.Round(scale.DoughWeight*10)/10, 'f', -1, 64))
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrUnknownStyle is returned for a style missing from Styles.
+	ErrUnknownStyle = errors.New("unknown style")
+	// ErrInvalidDoughDensity is returned for a dough density or thickness
+	// that cannot be used.
+	ErrInvalidDoughDensity = errors.New("invalid dough density")
+)
+
+const (
+	StyleNeapolitan  = "neapolitan"
+	StyleRomanTeglia = "roman-teglia"
+	StyleDetroit     = "detroit"
+	StyleSicilian    = "sicilian"
+	StyleFocaccia    = "focaccia"
+)
+
+// Style is a pizza style preset: how much dough it stretches over each cm²
+// of pan.
+type Style struct {
+	Name string
+	// DoughDensity is in grams of dough per cm² of pan.
+	DoughDensity float64
+}
+
+// Styles are the presets, from the thinnest to the thickest.
+var Styles = []Style{
+	{Name: StyleNeapolitan, DoughDensity: 0.35},
+	{Name: StyleRomanTeglia, DoughDensity: 0.55},
+	{Name: StyleDetroit, DoughDensity: 0.6},
+	{Name: StyleSicilian, DoughDensity: 0.65},
+	{Name: StyleFocaccia, DoughDensity: 0.7},
+}
+
+// rawDoughDensity is the grams per cm³ of a dough before it rises, which
+// relates the thickness of the stretched dough to its weight.
+const rawDoughDensity = 1.05
+
+func StyleByName(name string) (Style, error) {
+	index := slices.IndexFunc(Styles, func(style Style) bool { return style.Name == name })
+	if index < 0 {
+		names := make([]string, len(Styles))
+		for i, style := range Styles {
+			names[i] = style.Name
+		}
+		return Style{}, fmt.Errorf("%w %q, expected one of %s", ErrUnknownStyle, name, strings.Join(names, ", "))
+	}
+	return Styles[index], nil
+}
+
+// Thickness is the millimetres of stretched dough the style takes.
+func (s Style) Thickness() float64 {
+	return math.Round(s.DoughDensity/rawDoughDensity*100) / 10
+}
+
+// ThicknessDensity is the dough density of dough stretched thickness
+// millimetres thick.
+func ThicknessDensity(thickness float64) float64 {
+	return thickness / 10 * rawDoughDensity
+}
+
+// DoughDensityFor resolves the dough density of pans, the first set of: the
+// dough density, thickness or style of pans, then the dough density or
+// style of recipe. Pans come back with the density and the style it came
+// from; a density of 0 leaves the dough weight to the calculator.
+func (p Pans) DoughDensityFor(recipe Recipe) (Pans, error) {
+	if p.DoughDensity < 0 || p.Thickness < 0 {
+		return Pans{}, fmt.Errorf("%w: dough density and thickness must not be negative", ErrInvalidDoughDensity)
+	}
+	if p.DoughDensity > 0 && p.Thickness > 0 {
+		return Pans{}, fmt.Errorf("%w: give a dough density or a thickness, not both", ErrInvalidDoughDensity)
+	}
+
+	resolved := p
+	resolved.Thickness = 0
+	switch {
+	case p.DoughDensity > 0:
+		resolved.Style = ""
+	case p.Thickness > 0:
+		resolved.Style = ""
+		resolved.DoughDensity = ThicknessDensity(p.Thickness)
+	case p.Style != "":
+		style, err := StyleByName(p.Style)
+		if err != nil {
+			return Pans{}, err
+		}
+		resolved.DoughDensity = style.DoughDensity
+	case recipe.DoughDensity > 0:
+		resolved.Style = recipe.Style
+		resolved.DoughDensity = recipe.DoughDensity
+	case recipe.Style != "":
+		style, err := StyleByName(recipe.Style)
+		if err != nil {
+			return Pans{}, err
+		}
+		resolved.Style = style.Name
+		resolved.DoughDensity = style.DoughDensity
+	}
+	return resolved, nil
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+	ErrDeliveryNotFound     = errors.New("delivery not found")
+	// ErrInvalidSubscription is returned for a subscription with a URL that
+	// cannot receive deliveries or an unknown event type.
+	ErrInvalidSubscription = errors.New("invalid subscription")
+)
+
+// EventTypes are the types of the events a subscription can ask for.
+var EventTypes = []EventType{EventRecipeCreated, EventRecipeUpdated, EventRecipeDeleted, EventRecipeAggregated}
+
+// Subscription asks for the events of EventTypes, or of every type when it is
+// empty, to be posted to URL and signed with Secret.
+type Subscription struct {
+	Id         uuid.UUID
+	URL        string
+	EventTypes []EventType
+	Secret     string
+	CreatedAt  time.Time
+}
+
+func (s Subscription) Wants(eventType EventType) bool {
+	return len(s.EventTypes) == 0 || slices.Contains(s.EventTypes, eventType)
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	// DeliveryDead is the status of the deliveries that failed every attempt,
+	// kept in the dead-letter list until replayed.
+	DeliveryDead DeliveryStatus = "dead"
+)
+
+// Delivery is an event to post to a subscription, and the record of the
+// attempts so far.
+type Delivery struct {
+	Id             uuid.UUID
+	SubscriptionId uuid.UUID
+	EventId        uuid.UUID
+	EventType      EventType
+	Payload        []byte
+	Status         DeliveryStatus
+	Attempts       int
+	LastError      string
+	// ResponseStatus is the HTTP status of the last attempt, zero when the
+	// receiver did not answer.
+	ResponseStatus int
+	NextAttemptAt  time.Time
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+// DeliveryFilter selects deliveries; zero fields select all.
+type DeliveryFilter struct {
+	SubscriptionId uuid.UUID
+	Status         DeliveryStatus
+	Limit          int
+}
@@ -0,0 +1,158 @@
+// Package catalogue reads the ingredient catalogue from CSV.
+//
+// The header names the columns, in any order: name and category are
+// required; display_name_<locale> columns hold the localized names; aliases,
+// allergens and diets are lists separated by "|"; density is in g/ml and
+// kcal, protein, carbohydrates and fat are per 100 g. Empty numeric cells mean
+// unknown, and the nutrition columns are either all set or all empty.
+package catalogue
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+)
+
+const (
+	displayNamePrefix = "display_name_"
+	listSeparator     = "|"
+)
+
+var nutritionColumns = []string{"kcal", "protein", "carbohydrates", "fat"}
+
+func Parse(r io.Reader) ([]domain.CatalogueIngredient, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalogue header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		columns[strings.TrimSpace(column)] = i
+	}
+	for _, required := range []string{"name", "category"} {
+		if _, found := columns[required]; !found {
+			return nil, fmt.Errorf("catalogue header is missing the %s column", required)
+		}
+	}
+
+	var ingredients []domain.CatalogueIngredient
+	names := make(map[string]string)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read catalogue: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		ingredient, err := parseRecord(record, columns)
+		if err != nil {
+			return nil, fmt.Errorf("catalogue line %d: %w", line, err)
+		}
+		for _, name := range append([]string{ingredient.Name}, ingredient.Aliases...) {
+			key := strings.ToLower(name)
+			if owner, taken := names[key]; taken {
+				return nil, fmt.Errorf("catalogue line %d: %q is already used by %s", line, name, owner)
+			}
+			names[key] = ingredient.Name
+		}
+		ingredients = append(ingredients, ingredient)
+	}
+	return ingredients, nil
+}
+
+func parseRecord(record []string, columns map[string]int) (domain.CatalogueIngredient, error) {
+	get := func(column string) string {
+		if i, found := columns[column]; found && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	ingredient := domain.CatalogueIngredient{
+		Name:     get("name"),
+		Category: get("category"),
+		Aliases:  splitList(get("aliases")),
+	}
+	if ingredient.Name == "" {
+		return ingredient, errors.New("name is required")
+	}
+	if ingredient.Category == "" {
+		return ingredient, fmt.Errorf("%s: category is required", ingredient.Name)
+	}
+
+	for column := range columns {
+		if locale, found := strings.CutPrefix(column, displayNamePrefix); found && get(column) != "" {
+			if ingredient.DisplayNames == nil {
+				ingredient.DisplayNames = make(map[string]string)
+			}
+			ingredient.DisplayNames[locale] = get(column)
+		}
+	}
+
+	for _, value := range splitList(get("allergens")) {
+		allergen := domain.Allergen(strings.ToLower(value))
+		if !allergen.Valid() {
+			return ingredient, fmt.Errorf("%s: unknown allergen %q", ingredient.Name, value)
+		}
+		ingredient.Allergens = append(ingredient.Allergens, allergen)
+	}
+
+	for _, value := range splitList(get("diets")) {
+		diet := domain.Diet(strings.ToLower(value))
+		if !diet.Valid() {
+			return ingredient, fmt.Errorf("%s: unknown diet %q", ingredient.Name, value)
+		}
+		ingredient.Diets = append(ingredient.Diets, diet)
+	}
+
+	if density := get("density"); density != "" {
+		value, err := strconv.ParseFloat(density, 64)
+		if err != nil || value <= 0 {
+			return ingredient, fmt.Errorf("%s: density must be a positive number, got %q", ingredient.Name, density)
+		}
+		ingredient.Density = value
+	}
+
+	values := make([]float64, 0, len(nutritionColumns))
+	for _, column := range nutritionColumns {
+		cell := get(column)
+		if cell == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(cell, 64)
+		if err != nil || value < 0 {
+			return ingredient, fmt.Errorf("%s: %s must be a non negative number, got %q", ingredient.Name, column, cell)
+		}
+		values = append(values, value)
+	}
+	switch len(values) {
+	case 0:
+	case len(nutritionColumns):
+		ingredient.Nutrition = &domain.Nutrition{Kcal: values[0], Protein: values[1], Carbohydrates: values[2], Fat: values[3]}
+	default:
+		return ingredient, fmt.Errorf("%s: %s must be all set or all empty", ingredient.Name, strings.Join(nutritionColumns, ", "))
+	}
+
+	return ingredient, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, listSeparator) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
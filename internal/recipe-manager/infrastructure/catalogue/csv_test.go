@@ -0,0 +1,101 @@
+package catalogue
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+	"github.com/cfioretti/recipe-manager/migrations"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("reads every column", func(t *testing.T) {
+		ingredients, err := Parse(strings.NewReader(`name,category,display_name_en,display_name_it,aliases,allergens,diets,density,kcal,protein,carbohydrates,fat
+mozzarellaCheese,dairy,Mozzarella,Mozzarella,mozzarella| fior di latte,Milk,vegetarian,,253,18.7,0.7,19.5
+basil,herb,Basil,,,,,,,,,
+`))
+
+		require.NoError(t, err)
+		assert.Equal(t, []domain.CatalogueIngredient{
+			{
+				Name:         "mozzarellaCheese",
+				DisplayNames: map[string]string{"en": "Mozzarella", "it": "Mozzarella"},
+				Aliases:      []string{"mozzarella", "fior di latte"},
+				Category:     "dairy",
+				Allergens:    []domain.Allergen{domain.AllergenMilk},
+				Diets:        []domain.Diet{domain.DietVegetarian},
+				Nutrition:    &domain.Nutrition{Kcal: 253, Protein: 18.7, Carbohydrates: 0.7, Fat: 19.5},
+			},
+			{
+				Name:         "basil",
+				DisplayNames: map[string]string{"en": "Basil"},
+				Category:     "herb",
+			},
+		}, ingredients)
+	})
+
+	tests := []struct {
+		name     string
+		csv      string
+		expected string
+	}{
+		{
+			name:     "missing column",
+			csv:      "name,aliases\nflour,farina\n",
+			expected: "missing the category column",
+		},
+		{
+			name:     "unknown allergen",
+			csv:      "name,category,allergens\nflour,flour,wheat\n",
+			expected: `catalogue line 2: flour: unknown allergen "wheat"`,
+		},
+		{
+			name:     "unknown diet",
+			csv:      "name,category,diets\nflour,flour,paleo\n",
+			expected: `flour: unknown diet "paleo"`,
+		},
+		{
+			name:     "partial nutrition",
+			csv:      "name,category,kcal,protein,carbohydrates,fat\nflour,flour,340,,,\n",
+			expected: "must be all set or all empty",
+		},
+		{
+			name:     "invalid density",
+			csv:      "name,category,density\nwater,water,heavy\n",
+			expected: `density must be a positive number, got "heavy"`,
+		},
+		{
+			name:     "alias clashing with another ingredient",
+			csv:      "name,category,aliases\nflour,flour,\nsemolina,flour,Flour\n",
+			expected: `catalogue line 3: "Flour" is already used by flour`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.csv))
+			assert.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestParseBundledCatalogue(t *testing.T) {
+	ingredients, err := Parse(migrations.Catalogue())
+	require.NoError(t, err)
+
+	byName := make(map[string]domain.CatalogueIngredient)
+	for _, ingredient := range ingredients {
+		byName[ingredient.Name] = ingredient
+	}
+	// The seed recipe refers to these names.
+	for _, name := range []string{"flour", "water", "evoOil", "salt", "yeast", "peeledTomatoes", "mozzarellaCheese", "parmesanCheese", "basil"} {
+		if assert.Contains(t, byName, name) {
+			assert.NotNil(t, byName[name].Nutrition, name)
+		}
+	}
+	assert.Equal(t, []domain.Allergen{domain.AllergenGluten}, byName["flour"].Allergens)
+	assert.Equal(t, []domain.Diet{domain.DietVegetarian, domain.DietVegan}, byName["flour"].Diets)
+}
@@ -77,15 +77,18 @@ func toProtoMessage(domainPans *domain.Pans) *pb.PansProto {
 				Width:    fromPointer(p.Measures.Width),
 				Length:   fromPointer(p.Measures.Length),
 			},
-			Name: p.Name,
-			Area: p.Area,
+			Name:        p.Name,
+			Area:        p.Area,
+			DoughWeight: p.DoughWeight,
 		}
 		panProtos = append(panProtos, panProto)
 	}
 
 	return &pb.PansProto{
-		Pans:      panProtos,
-		TotalArea: domainPans.TotalArea,
+		Pans:         panProtos,
+		TotalArea:    domainPans.TotalArea,
+		DoughDensity: domainPans.DoughDensity,
+		Style:        domainPans.Style,
 	}
 }
 
@@ -101,15 +104,18 @@ func toDomainPans(protoMessage *pb.PansProto) domain.Pans {
 				Width:    toPointer(p.Measures.Width),
 				Length:   toPointer(p.Measures.Length),
 			},
-			Name: p.Name,
-			Area: p.Area,
+			Name:        p.Name,
+			Area:        p.Area,
+			DoughWeight: p.DoughWeight,
 		}
 		pans = append(pans, pan)
 	}
 
 	return domain.Pans{
-		Pans:      pans,
-		TotalArea: protoMessage.TotalArea,
+		Pans:         pans,
+		TotalArea:    protoMessage.TotalArea,
+		DoughDensity: protoMessage.DoughDensity,
+		Style:        protoMessage.Style,
 	}
 }
 
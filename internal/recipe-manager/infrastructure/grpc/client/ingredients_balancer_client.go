@@ -141,14 +141,38 @@ func toProtoPans(pans domain.Pans) *pb.Pans {
 				Width:    toProtoInt32Pointer(pan.Measures.Width),
 				Length:   toProtoInt32Pointer(pan.Measures.Length),
 			},
-			Name: pan.Name,
-			Area: pan.Area,
+			Name:        pan.Name,
+			Area:        pan.Area,
+			DoughWeight: pan.DoughWeight,
 		})
 	}
 
 	return &pb.Pans{
-		Pans:      protoPans,
-		TotalArea: pans.TotalArea,
+		Pans:         protoPans,
+		TotalArea:    pans.TotalArea,
+		DoughDensity: pans.DoughDensity,
+		Style:        pans.Style,
+	}
+}
+
+// ToProtoRecipeAggregate maps an aggregate to the message the balancer
+// answers with, which the HTTP API also serves as protobuf.
+func ToProtoRecipeAggregate(aggregate domain.RecipeAggregate) *pb.RecipeAggregate {
+	splitDough := make([]*pb.Dough, 0, len(aggregate.SplitIngredients.SplitDough))
+	for _, dough := range aggregate.SplitIngredients.SplitDough {
+		splitDough = append(splitDough, toProtoDough(dough))
+	}
+	splitTopping := make([]*pb.Topping, 0, len(aggregate.SplitIngredients.SplitTopping))
+	for _, topping := range aggregate.SplitIngredients.SplitTopping {
+		splitTopping = append(splitTopping, toProtoTopping(topping))
+	}
+
+	return &pb.RecipeAggregate{
+		Recipe: toProtoRecipe(aggregate.Recipe),
+		SplitIngredients: &pb.SplitIngredients{
+			SplitDough:   splitDough,
+			SplitTopping: splitTopping,
+		},
 	}
 }
 
@@ -502,6 +502,8 @@ type Pan struct {
 	Measures *Measures `protobuf:"bytes,2,opt,name=measures,proto3" json:"measures,omitempty"`
 	Name     string    `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
 	Area     float64   `protobuf:"fixed64,4,opt,name=area,proto3" json:"area,omitempty"`
+	// Grams of dough, as the calculator worked them out; 0 when unknown.
+	DoughWeight float64 `protobuf:"fixed64,5,opt,name=dough_weight,json=doughWeight,proto3" json:"dough_weight,omitempty"`
 }
 
 func (x *Pan) Reset() {
@@ -564,6 +566,13 @@ func (x *Pan) GetArea() float64 {
 	return 0
 }
 
+func (x *Pan) GetDoughWeight() float64 {
+	if x != nil {
+		return x.DoughWeight
+	}
+	return 0
+}
+
 type Pans struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -571,6 +580,9 @@ type Pans struct {
 
 	Pans      []*Pan  `protobuf:"bytes,1,rep,name=pans,proto3" json:"pans,omitempty"`
 	TotalArea float64 `protobuf:"fixed64,2,opt,name=total_area,json=totalArea,proto3" json:"total_area,omitempty"`
+	// Grams of dough per square centimetre of pan; 0 when unknown.
+	DoughDensity float64 `protobuf:"fixed64,3,opt,name=dough_density,json=doughDensity,proto3" json:"dough_density,omitempty"`
+	Style        string  `protobuf:"bytes,4,opt,name=style,proto3" json:"style,omitempty"`
 }
 
 func (x *Pans) Reset() {
@@ -619,6 +631,20 @@ func (x *Pans) GetTotalArea() float64 {
 	return 0
 }
 
+func (x *Pans) GetDoughDensity() float64 {
+	if x != nil {
+		return x.DoughDensity
+	}
+	return 0
+}
+
+func (x *Pans) GetStyle() string {
+	if x != nil {
+		return x.Style
+	}
+	return ""
+}
+
 type SplitIngredients struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -901,20 +927,26 @@ var file_internal_recipe_manager_infrastructure_grpc_proto_ingredients_balancer_
 	0x67, 0x74, 0x68, 0x88, 0x01, 0x01, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x64, 0x69, 0x61, 0x6d, 0x65,
 	0x74, 0x65, 0x72, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x65, 0x64, 0x67, 0x65, 0x42, 0x08, 0x0a, 0x06,
 	0x5f, 0x77, 0x69, 0x64, 0x74, 0x68, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x6c, 0x65, 0x6e, 0x67, 0x74,
-	0x68, 0x22, 0x7f, 0x0a, 0x03, 0x50, 0x61, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x68, 0x61, 0x70,
-	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x68, 0x61, 0x70, 0x65, 0x12, 0x3a,
-	0x0a, 0x08, 0x6d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x1e, 0x2e, 0x69, 0x6e, 0x67, 0x72, 0x65, 0x64, 0x69, 0x65, 0x6e, 0x74, 0x73, 0x5f, 0x62,
-	0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x72, 0x2e, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x73,
-	0x52, 0x08, 0x6d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61,
-	0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12,
-	0x0a, 0x04, 0x61, 0x72, 0x65, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x61, 0x72,
-	0x65, 0x61, 0x22, 0x54, 0x0a, 0x04, 0x50, 0x61, 0x6e, 0x73, 0x12, 0x2d, 0x0a, 0x04, 0x70, 0x61,
-	0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x69, 0x6e, 0x67, 0x72, 0x65,
-	0x64, 0x69, 0x65, 0x6e, 0x74, 0x73, 0x5f, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x72, 0x2e,
-	0x50, 0x61, 0x6e, 0x52, 0x04, 0x70, 0x61, 0x6e, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x6f, 0x74,
-	0x61, 0x6c, 0x5f, 0x61, 0x72, 0x65, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x74,
-	0x6f, 0x74, 0x61, 0x6c, 0x41, 0x72, 0x65, 0x61, 0x22, 0x94, 0x01, 0x0a, 0x10, 0x53, 0x70, 0x6c,
+	0x68, 0x22, 0xa2, 0x01, 0x0a, 0x03, 0x50, 0x61, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x68, 0x61,
+	0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x68, 0x61, 0x70, 0x65, 0x12,
+	0x3a, 0x0a, 0x08, 0x6d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1e, 0x2e, 0x69, 0x6e, 0x67, 0x72, 0x65, 0x64, 0x69, 0x65, 0x6e, 0x74, 0x73, 0x5f,
+	0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x72, 0x2e, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65,
+	0x73, 0x52, 0x08, 0x6d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x61, 0x72, 0x65, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x61,
+	0x72, 0x65, 0x61, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x6f, 0x75, 0x67, 0x68, 0x5f, 0x77, 0x65, 0x69,
+	0x67, 0x68, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x64, 0x6f, 0x75, 0x67, 0x68,
+	0x57, 0x65, 0x69, 0x67, 0x68, 0x74, 0x22, 0x8f, 0x01, 0x0a, 0x04, 0x50, 0x61, 0x6e, 0x73, 0x12,
+	0x2d, 0x0a, 0x04, 0x70, 0x61, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e,
+	0x69, 0x6e, 0x67, 0x72, 0x65, 0x64, 0x69, 0x65, 0x6e, 0x74, 0x73, 0x5f, 0x62, 0x61, 0x6c, 0x61,
+	0x6e, 0x63, 0x65, 0x72, 0x2e, 0x50, 0x61, 0x6e, 0x52, 0x04, 0x70, 0x61, 0x6e, 0x73, 0x12, 0x1d,
+	0x0a, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x61, 0x72, 0x65, 0x61, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x09, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x41, 0x72, 0x65, 0x61, 0x12, 0x23, 0x0a,
+	0x0d, 0x64, 0x6f, 0x75, 0x67, 0x68, 0x5f, 0x64, 0x65, 0x6e, 0x73, 0x69, 0x74, 0x79, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x0c, 0x64, 0x6f, 0x75, 0x67, 0x68, 0x44, 0x65, 0x6e, 0x73, 0x69,
+	0x74, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x79, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x73, 0x74, 0x79, 0x6c, 0x65, 0x22, 0x94, 0x01, 0x0a, 0x10, 0x53, 0x70, 0x6c,
 	0x69, 0x74, 0x49, 0x6e, 0x67, 0x72, 0x65, 0x64, 0x69, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x3c, 0x0a,
 	0x0b, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x5f, 0x64, 0x6f, 0x75, 0x67, 0x68, 0x18, 0x01, 0x20, 0x03,
 	0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x69, 0x6e, 0x67, 0x72, 0x65, 0x64, 0x69, 0x65, 0x6e, 0x74, 0x73,
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/http/envelope"
+)
+
+const AdminTokenHeader = "X-Admin-Token"
+
+type LevelController interface {
+	GetLevel() logrus.Level
+	SetLevel(level logrus.Level)
+}
+
+// LogLevelHandler reads and changes the log level of a running instance, so
+// debug logging can be switched on while investigating without a restart.
+type LogLevelHandler struct {
+	logger LevelController
+	token  string
+}
+
+func NewLogLevelHandler(logger LevelController, token string) *LogLevelHandler {
+	return &LogLevelHandler{
+		logger: logger,
+		token:  token,
+	}
+}
+
+type logLevelRequest struct {
+	Level string `json:"level" binding:"required"`
+}
+
+func (h *LogLevelHandler) RegisterRoutes(router *gin.Engine) {
+	admin := router.Group("/admin", RequireAdminToken(h.token))
+	admin.GET("/log-level", h.handleGetLevel)
+	admin.PUT("/log-level", h.handleSetLevel)
+}
+
+// RequireAdminToken guards the admin endpoints with the token expected in
+// the X-Admin-Token header. An empty token disables them: every request is
+// refused.
+func RequireAdminToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			envelope.Abort(c, http.StatusForbidden, "admin endpoints are disabled, admin.token is not set")
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader(AdminTokenHeader)), []byte(token)) != 1 {
+			envelope.Abort(c, http.StatusUnauthorized, "invalid admin token")
+			return
+		}
+		c.Next()
+	}
+}
+
+func (h *LogLevelHandler) handleGetLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"level": h.logger.GetLevel().String()})
+}
+
+func (h *LogLevelHandler) handleSetLevel(c *gin.Context) {
+	var request logLevelRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "level is required"})
+		return
+	}
+
+	level, err := logrus.ParseLevel(strings.TrimSpace(request.Level))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	previous := h.logger.GetLevel()
+	h.logger.SetLevel(level)
+	c.JSON(http.StatusOK, gin.H{
+		"level":    level.String(),
+		"previous": previous.String(),
+	})
+}
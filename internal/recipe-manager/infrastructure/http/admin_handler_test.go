@@ -0,0 +1,56 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogLevelHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		token          string
+		method         string
+		body           string
+		header         string
+		expectedStatus int
+		expectedLevel  logrus.Level
+	}{
+		{name: "reads the current level", token: "s3cret", header: "s3cret", method: http.MethodGet, expectedStatus: http.StatusOK, expectedLevel: logrus.InfoLevel},
+		{name: "changes the level", token: "s3cret", header: "s3cret", method: http.MethodPut, body: `{"level":"debug"}`, expectedStatus: http.StatusOK, expectedLevel: logrus.DebugLevel},
+		{name: "rejects unknown levels", token: "s3cret", header: "s3cret", method: http.MethodPut, body: `{"level":"loud"}`, expectedStatus: http.StatusBadRequest, expectedLevel: logrus.InfoLevel},
+		{name: "rejects missing level", token: "s3cret", header: "s3cret", method: http.MethodPut, body: `{}`, expectedStatus: http.StatusBadRequest, expectedLevel: logrus.InfoLevel},
+		{name: "rejects missing token", token: "s3cret", method: http.MethodGet, expectedStatus: http.StatusUnauthorized, expectedLevel: logrus.InfoLevel},
+		{name: "disabled without a token", method: http.MethodPut, body: `{"level":"debug"}`, expectedStatus: http.StatusForbidden, expectedLevel: logrus.InfoLevel},
+		{name: "disabled without a token whatever the header", header: "anything", method: http.MethodGet, expectedStatus: http.StatusForbidden, expectedLevel: logrus.InfoLevel},
+		{name: "rejects wrong token", token: "s3cret", header: "wrong", method: http.MethodPut, body: `{"level":"debug"}`, expectedStatus: http.StatusUnauthorized, expectedLevel: logrus.InfoLevel},
+		{name: "accepts valid token", token: "s3cret", header: "s3cret", method: http.MethodPut, body: `{"level":"warn"}`, expectedStatus: http.StatusOK, expectedLevel: logrus.WarnLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := logrus.New()
+			logger.SetLevel(logrus.InfoLevel)
+			router := gin.New()
+			NewLogLevelHandler(logger, tt.token).RegisterRoutes(router)
+
+			req := httptest.NewRequest(tt.method, "/admin/log-level", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.header != "" {
+				req.Header.Set(AdminTokenHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedLevel, logger.GetLevel())
+		})
+	}
+}
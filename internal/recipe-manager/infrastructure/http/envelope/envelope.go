@@ -0,0 +1,127 @@
+// Package envelope writes the response bodies of the HTTP API. Under Prefix
+// every body is enveloped: {"data": ..., "meta": {...}} on success and
+// {"error": {"code": ..., "message": ...}, "meta": {...}} on failure. The
+// legacy routes at the root keep their bare {"data": ...} and
+// {"error": "message"} bodies until they are removed.
+package envelope
+
+import (
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Prefix is the path the current version of the API is served under.
+const Prefix = "/api/v1"
+
+const startKey = "envelope.start"
+
+type Meta struct {
+	CorrelationId string      `json:"correlationId,omitempty"`
+	DurationMs    float64     `json:"durationMs"`
+	Pagination    *Pagination `json:"pagination,omitempty"`
+}
+
+type Pagination struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	Total  int `json:"total"`
+}
+
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type success struct {
+	Data any  `json:"data"`
+	Meta Meta `json:"meta"`
+}
+
+type failure struct {
+	Error Error `json:"error"`
+	Meta  Meta  `json:"meta"`
+}
+
+// Start records when the request arrived, for the durationMs of its meta.
+// Register it ahead of the other middleware.
+func Start() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(startKey, time.Now())
+		c.Next()
+	}
+}
+
+// Versioned reports whether the request was routed under Prefix.
+func Versioned(c *gin.Context) bool {
+	return c.Request != nil && strings.HasPrefix(c.Request.URL.Path, Prefix+"/")
+}
+
+func Respond(c *gin.Context, status int, data any) {
+	RespondPage(c, status, data, nil)
+}
+
+// RespondPage writes data along with the page of the collection it holds;
+// the legacy routes do not paginate and ignore it.
+func RespondPage(c *gin.Context, status int, data any, pagination *Pagination) {
+	c.JSON(status, Body(c, data, pagination))
+}
+
+// Body is the body RespondPage writes as JSON, for the handlers writing it
+// in another format.
+func Body(c *gin.Context, data any, pagination *Pagination) any {
+	if !Versioned(c) {
+		return gin.H{"data": data}
+	}
+	return success{Data: data, Meta: meta(c, pagination)}
+}
+
+// Abort writes the error and stops the handler chain.
+func Abort(c *gin.Context, status int, message string) {
+	if !Versioned(c) {
+		c.AbortWithStatusJSON(status, gin.H{"error": message})
+		return
+	}
+	c.AbortWithStatusJSON(status, failure{
+		Error: Error{Code: Code(status), Message: message},
+		Meta:  meta(c, nil),
+	})
+}
+
+// Code is the machine-readable code of an HTTP status, its reason phrase in
+// lowerCamelCase: "notFound" for 404, "preconditionFailed" for 412.
+func Code(status int) string {
+	text := strings.ReplaceAll(http.StatusText(status), "'", "")
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return "error"
+	}
+	var code strings.Builder
+	for i, word := range words {
+		if i == 0 {
+			code.WriteString(strings.ToLower(word))
+			continue
+		}
+		code.WriteString(strings.ToUpper(word[:1]) + strings.ToLower(word[1:]))
+	}
+	return code.String()
+}
+
+func meta(c *gin.Context, pagination *Pagination) Meta {
+	m := Meta{
+		// The access log middleware echoes the correlation ID it read or
+		// generated in the response headers.
+		CorrelationId: c.Writer.Header().Get("X-Correlation-ID"),
+		Pagination:    pagination,
+	}
+	if start, ok := c.Get(startKey); ok {
+		elapsed := time.Since(start.(time.Time))
+		m.DurationMs = float64(elapsed.Microseconds()) / 1000
+	}
+	return m
+}
@@ -0,0 +1,75 @@
+package envelope
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRespond(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Start(), func(c *gin.Context) {
+		c.Header("X-Correlation-ID", "corr-1")
+		c.Next()
+	})
+	handlers := map[string]gin.HandlerFunc{
+		"/items": func(c *gin.Context) {
+			RespondPage(c, http.StatusOK, []string{}, &Pagination{Limit: 10, Offset: 20, Total: 20})
+		},
+		"/items/:id": func(c *gin.Context) { Respond(c, http.StatusOK, gin.H{"id": c.Param("id")}) },
+		"/missing":   func(c *gin.Context) { Abort(c, http.StatusNotFound, "item not found") },
+	}
+	for path, handler := range handlers {
+		router.GET(path, handler)
+		router.GET(Prefix+path, handler)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		body   string
+	}{
+		{"envelopes data with its meta", "/api/v1/items/1", http.StatusOK, `{"data":{"id":"1"},"meta":{"correlationId":"corr-1","durationMs":0}}`},
+		{"envelopes pages with their pagination", "/api/v1/items", http.StatusOK, `{"data":[],"meta":{"correlationId":"corr-1","durationMs":0,"pagination":{"limit":10,"offset":20,"total":20}}}`},
+		{"envelopes errors with a code", "/api/v1/missing", http.StatusNotFound, `{"error":{"code":"notFound","message":"item not found"},"meta":{"correlationId":"corr-1","durationMs":0}}`},
+		{"keeps bare data on legacy routes", "/items", http.StatusOK, `{"data":[]}`},
+		{"keeps bare errors on legacy routes", "/missing", http.StatusNotFound, `{"error":"item not found"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.JSONEq(t, tt.body, zeroDuration(w.Body.String()))
+		})
+	}
+}
+
+func TestCode(t *testing.T) {
+	tests := map[int]string{
+		http.StatusNotFound:            "notFound",
+		http.StatusPreconditionFailed:  "preconditionFailed",
+		http.StatusUnprocessableEntity: "unprocessableEntity",
+		http.StatusTooManyRequests:     "tooManyRequests",
+		http.StatusTeapot:              "imATeapot",
+		599:                            "error",
+	}
+	for status, code := range tests {
+		assert.Equal(t, code, Code(status), "status %d", status)
+	}
+}
+
+var durationPattern = regexp.MustCompile(`"durationMs":[0-9.e-]+`)
+
+// zeroDuration blanks the measured duration, which varies from run to run.
+func zeroDuration(body string) string {
+	return durationPattern.ReplaceAllString(body, `"durationMs":0`)
+}
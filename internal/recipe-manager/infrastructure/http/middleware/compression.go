@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/http/negotiation"
+)
+
+const (
+	EncodingZstd = "zstd"
+	EncodingGzip = "gzip"
+)
+
+type CompressionConfig struct {
+	Enabled bool
+	// MinSize is the body size, in bytes, below which responses are sent
+	// uncompressed, as compressing them saves less than it costs.
+	MinSize int
+	// Encodings are the codings offered, the preferred first, among
+	// EncodingZstd and EncodingGzip.
+	Encodings []string
+}
+
+var encoders = map[string]*sync.Pool{
+	EncodingGzip: {New: func() any {
+		return gzip.NewWriter(io.Discard)
+	}},
+	EncodingZstd: {New: func() any {
+		encoder, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderConcurrency(1))
+		return encoder
+	}},
+}
+
+// encoder is a pooled gzip.Writer or zstd.Encoder.
+type encoder interface {
+	io.WriteCloser
+	Reset(io.Writer)
+	Flush() error
+}
+
+// Compression compresses the response bodies of at least MinSize bytes with
+// the coding Accept-Encoding prefers among the configured ones. Bodies are
+// buffered until they reach MinSize, so the headers are only settled once
+// it is known whether the body is compressed. Responses already encoded by
+// their handler, such as the gzipped Prometheus metrics, are left as they
+// are.
+func Compression(config CompressionConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !config.Enabled || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		accept := c.GetHeader("Accept-Encoding")
+		if accept == "" {
+			c.Next()
+			return
+		}
+		encoding, ok := negotiation.Negotiate(accept, config.Encodings...)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		writer := &compressWriter{ResponseWriter: c.Writer, encoding: encoding, minSize: config.MinSize}
+		c.Writer = writer
+		defer func() {
+			writer.finish()
+			c.Writer = writer.ResponseWriter
+		}()
+		c.Next()
+	}
+}
+
+// compressWriter buffers the body until it reaches minSize, then writes it
+// through an encoder. Smaller bodies are written as they are by finish.
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	minSize  int
+
+	buffer      bytes.Buffer
+	encoder     encoder
+	passthrough bool
+	// size counts the bytes of the body before compression.
+	size int
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	w.size += len(data)
+	switch {
+	case w.encoder != nil:
+		return w.encoder.Write(data)
+	case w.passthrough:
+		return w.ResponseWriter.Write(data)
+	}
+
+	if !w.compressible() {
+		w.passthrough = true
+		if err := w.flushBuffer(); err != nil {
+			return 0, err
+		}
+		return w.ResponseWriter.Write(data)
+	}
+	w.buffer.Write(data)
+	if w.buffer.Len() >= w.minSize {
+		if err := w.startEncoding(); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *compressWriter) WriteString(data string) (int, error) {
+	return w.Write([]byte(data))
+}
+
+// Size is the size of the body before compression, as the access log and
+// the metrics report it.
+func (w *compressWriter) Size() int {
+	if w.size == 0 {
+		return w.ResponseWriter.Size()
+	}
+	return w.size
+}
+
+func (w *compressWriter) Written() bool {
+	return w.size > 0 || w.ResponseWriter.Written()
+}
+
+// Flush sends what was written so far, compressed when the body already
+// reached minSize.
+func (w *compressWriter) Flush() {
+	if w.encoder != nil {
+		_ = w.encoder.Flush()
+	} else {
+		w.passthrough = true
+		_ = w.flushBuffer()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// compressible reports whether the body may be encoded: its handler did not
+// encode it already, and the status allows a body.
+func (w *compressWriter) compressible() bool {
+	status := w.Status()
+	return w.Header().Get("Content-Encoding") == "" &&
+		status != http.StatusNoContent && status != http.StatusNotModified && status >= http.StatusOK
+}
+
+func (w *compressWriter) startEncoding() error {
+	header := w.Header()
+	header.Set("Content-Encoding", w.encoding)
+	header.Del("Content-Length")
+
+	w.encoder = encoders[w.encoding].Get().(encoder)
+	w.encoder.Reset(w.ResponseWriter)
+	_, err := w.encoder.Write(w.buffer.Bytes())
+	w.buffer.Reset()
+	return err
+}
+
+func (w *compressWriter) flushBuffer() error {
+	if w.buffer.Len() == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.buffer.Bytes())
+	w.buffer.Reset()
+	return err
+}
+
+func (w *compressWriter) finish() {
+	if w.encoder == nil {
+		_ = w.flushBuffer()
+		return
+	}
+	_ = w.encoder.Close()
+	w.encoder.Reset(io.Discard)
+	encoders[w.encoding].Put(w.encoder)
+	w.encoder = nil
+}
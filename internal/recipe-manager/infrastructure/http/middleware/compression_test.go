@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompression(t *testing.T) {
+	large := strings.Repeat(`{"name":"flour","amount":500}`, 100)
+	config := CompressionConfig{Enabled: true, MinSize: 1024, Encodings: []string{EncodingZstd, EncodingGzip}}
+
+	gin.SetMode(gin.TestMode)
+	newRouter := func(config CompressionConfig) *gin.Engine {
+		router := gin.New()
+		router.Use(Compression(config))
+		router.GET("/large", func(c *gin.Context) {
+			// Written in pieces smaller than MinSize.
+			for i := 0; i < len(large); i += 100 {
+				c.Writer.WriteString(large[i:min(i+100, len(large))])
+			}
+		})
+		router.GET("/small", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+		router.GET("/encoded", func(c *gin.Context) {
+			c.Header("Content-Encoding", "br")
+			c.String(http.StatusOK, large)
+		})
+		router.GET("/empty", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+		return router
+	}
+
+	tests := []struct {
+		name           string
+		config         CompressionConfig
+		path           string
+		acceptEncoding string
+		encoding       string
+		body           string
+	}{
+		{"compresses with the preferred coding", config, "/large", "gzip, zstd", EncodingZstd, large},
+		{"compresses with the coding accepted", config, "/large", "gzip", EncodingGzip, large},
+		{"weighs the accepted codings", config, "/large", "zstd;q=0.5, gzip", EncodingGzip, large},
+		{"leaves bodies under the threshold", config, "/small", "gzip", "", "ok"},
+		{"leaves bodies without Accept-Encoding", config, "/large", "", "", large},
+		{"leaves bodies in codings not offered", config, "/large", "br", "", large},
+		{"leaves bodies already encoded", config, "/encoded", "gzip", "br", large},
+		{"leaves empty bodies", config, "/empty", "gzip", "", ""},
+		{"is disabled by config", CompressionConfig{MinSize: 1024, Encodings: []string{EncodingGzip}}, "/large", "gzip", "", large},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.acceptEncoding != "" {
+				request.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+			newRouter(tt.config).ServeHTTP(w, request)
+
+			assert.Equal(t, tt.encoding, w.Header().Get("Content-Encoding"))
+			if tt.config.Enabled {
+				assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+			}
+			assert.Equal(t, tt.body, decode(t, w.Header().Get("Content-Encoding"), w.Body))
+		})
+	}
+
+	t.Run("reports the size of the body before compression", func(t *testing.T) {
+		router := newRouter(config)
+		var size int
+		router.Use(func(c *gin.Context) {
+			c.Next()
+			size = c.Writer.Size()
+		})
+		router.GET("/measured", func(c *gin.Context) { c.String(http.StatusOK, large) })
+
+		request := httptest.NewRequest(http.MethodGet, "/measured", nil)
+		request.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, request)
+
+		assert.Equal(t, EncodingGzip, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, len(large), size)
+	})
+}
+
+func decode(t *testing.T, encoding string, body io.Reader) string {
+	var reader io.Reader
+	switch encoding {
+	case EncodingGzip:
+		gzipReader, err := gzip.NewReader(body)
+		require.NoError(t, err)
+		reader = gzipReader
+	case EncodingZstd:
+		zstdReader, err := zstd.NewReader(body)
+		require.NoError(t, err)
+		defer zstdReader.Close()
+		reader = zstdReader
+	default:
+		reader = body
+	}
+	decoded, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(decoded)
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSMiddleware answers preflight requests and sets the CORS headers for the
+// allowed origins, which can be replaced while serving.
+type CORSMiddleware struct {
+	allowedOrigins atomic.Pointer[[]string]
+}
+
+func NewCORSMiddleware(allowedOrigins []string) *CORSMiddleware {
+	m := &CORSMiddleware{}
+	m.SetAllowedOrigins(allowedOrigins)
+	return m
+}
+
+func (m *CORSMiddleware) SetAllowedOrigins(allowedOrigins []string) {
+	origins := slices.Clone(allowedOrigins)
+	m.allowedOrigins.Store(&origins)
+}
+
+func (m *CORSMiddleware) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if origin := m.allowedOrigin(c.GetHeader("Origin")); origin != "" {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+			c.Writer.Header().Add("Vary", "Origin")
+		}
+		c.Writer.Header().Set("Access-Control-Allow-Headers",
+			"Content-Type, Authorization, X-Correlation-ID, X-Admin-Token, If-Match, If-None-Match")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag, Location, Deprecation, Sunset, Link, X-Correlation-ID")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
+
+func (m *CORSMiddleware) allowedOrigin(origin string) string {
+	origins := *m.allowedOrigins.Load()
+	if slices.Contains(origins, "*") {
+		return "*"
+	}
+	if origin != "" && slices.Contains(origins, origin) {
+		return origin
+	}
+	return ""
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/http/envelope"
+)
+
+type DeprecationConfig struct {
+	// DeprecatedAt is when the routes were deprecated; zero leaves out the
+	// Deprecation header.
+	DeprecatedAt time.Time
+	// Sunset is when the routes stop being served; zero leaves out the
+	// Sunset header.
+	Sunset time.Time
+}
+
+// Deprecation marks the legacy routes at the root as deprecated: it sets the
+// Deprecation (RFC 9745) and Sunset (RFC 8594) headers, and links the same
+// route under the versioned API as their successor.
+func Deprecation(config DeprecationConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !config.DeprecatedAt.IsZero() {
+			c.Header("Deprecation", fmt.Sprintf("@%d", config.DeprecatedAt.Unix()))
+		}
+		if !config.Sunset.IsZero() {
+			c.Header("Sunset", config.Sunset.UTC().Format(http.TimeFormat))
+		}
+		c.Header("Link", fmt.Sprintf("<%s%s>; rel=\"successor-version\"", envelope.Prefix, c.Request.URL.Path))
+		c.Next()
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeprecation(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      DeprecationConfig
+		deprecation string
+		sunset      string
+	}{
+		{
+			name: "sets the deprecation and sunset dates",
+			config: DeprecationConfig{
+				DeprecatedAt: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+				Sunset:       time.Date(2027, 4, 19, 0, 0, 0, 0, time.UTC),
+			},
+			deprecation: "@1792368000",
+			sunset:      "Mon, 19 Apr 2027 00:00:00 GMT",
+		},
+		{
+			name:   "leaves out the dates not configured",
+			config: DeprecationConfig{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/recipes/:uuid", Deprecation(tt.config), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/recipes/abc", nil))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.deprecation, w.Header().Get("Deprecation"))
+			assert.Equal(t, tt.sunset, w.Header().Get("Sunset"))
+			assert.Equal(t, `</api/v1/recipes/abc>; rel="successor-version"`, w.Header().Get("Link"))
+		})
+	}
+}
@@ -1,11 +1,13 @@
 package middleware
 
 import (
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
 	domainMetrics "github.com/cfioretti/recipe-manager/internal/recipe-manager/domain/metrics"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/http/envelope"
 	infraMetrics "github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/metrics"
 )
 
@@ -65,40 +67,23 @@ func (m *MetricsMiddleware) recordRecipeMetrics(method, endpoint string, statusC
 	}
 }
 
-func isRecipeEndpoint(endpoint string) bool {
-	recipeEndpoints := []string{
-		"/api/v1/recipes",
-		"/api/v1/recipes/:id",
-		"/api/v1/recipes/search",
-		"/api/v1/recipes/aggregate",
-		"/api/v1/recipes/author/:author",
-	}
+// recipeRoute is the route of a recipe endpoint relative to the API root,
+// so the versioned and legacy routes are measured alike.
+func recipeRoute(endpoint string) string {
+	return strings.TrimPrefix(endpoint, envelope.Prefix)
+}
 
-	for _, recipeEndpoint := range recipeEndpoints {
-		if endpoint == recipeEndpoint {
-			return true
-		}
-	}
-	return false
+func isRecipeEndpoint(endpoint string) bool {
+	route := recipeRoute(endpoint)
+	return route == "/recipes" || strings.HasPrefix(route, "/recipes/")
 }
 
 func isRecipeRetrievalEndpoint(endpoint string) bool {
-	retrievalEndpoints := []string{
-		"/api/v1/recipes/:id",
-		"/api/v1/recipes/search",
-		"/api/v1/recipes/author/:author",
-	}
-
-	for _, retrievalEndpoint := range retrievalEndpoints {
-		if endpoint == retrievalEndpoint {
-			return true
-		}
-	}
-	return false
+	return recipeRoute(endpoint) == "/recipes/:uuid"
 }
 
 func isRecipeAggregationEndpoint(endpoint string) bool {
-	return endpoint == "/api/v1/recipes/aggregate"
+	return recipeRoute(endpoint) == "/recipes/:uuid/aggregate"
 }
 
 func getErrorType(statusCode int) string {
@@ -79,7 +79,7 @@ func TestMetricsMiddleware_HTTPMetricsMiddleware_Success(t *testing.T) {
 	router := gin.New()
 	router.Use(middleware.HTTPMetricsMiddleware())
 
-	router.GET("/api/v1/recipes/:id", func(c *gin.Context) {
+	router.GET("/api/v1/recipes/:uuid", func(c *gin.Context) {
 		c.JSON(200, gin.H{"id": c.Param("id")})
 	})
 
@@ -91,12 +91,12 @@ func TestMetricsMiddleware_HTTPMetricsMiddleware_Success(t *testing.T) {
 		t.Errorf("Expected status 200, got %d", w.Code)
 	}
 
-	if mockDomainMetrics.httpRequests["GET:/api/v1/recipes/:id"] != 1 {
-		t.Errorf("Expected 1 HTTP request, got %d", mockDomainMetrics.httpRequests["GET:/api/v1/recipes/:id"])
+	if mockDomainMetrics.httpRequests["GET:/api/v1/recipes/:uuid"] != 1 {
+		t.Errorf("Expected 1 HTTP request, got %d", mockDomainMetrics.httpRequests["GET:/api/v1/recipes/:uuid"])
 	}
 
-	if len(mockDomainMetrics.httpRequestDurations["GET:/api/v1/recipes/:id"]) != 1 {
-		t.Errorf("Expected 1 HTTP request duration, got %d", len(mockDomainMetrics.httpRequestDurations["GET:/api/v1/recipes/:id"]))
+	if len(mockDomainMetrics.httpRequestDurations["GET:/api/v1/recipes/:uuid"]) != 1 {
+		t.Errorf("Expected 1 HTTP request duration, got %d", len(mockDomainMetrics.httpRequestDurations["GET:/api/v1/recipes/:uuid"]))
 	}
 
 	if len(mockDomainMetrics.recipeRetrievalDurations) != 1 {
@@ -115,7 +115,7 @@ func TestMetricsMiddleware_HTTPMetricsMiddleware_Error(t *testing.T) {
 	router := gin.New()
 	router.Use(middleware.HTTPMetricsMiddleware())
 
-	router.GET("/api/v1/recipes/:id", func(c *gin.Context) {
+	router.GET("/api/v1/recipes/:uuid", func(c *gin.Context) {
 		c.JSON(404, gin.H{"error": "not found"})
 	})
 
@@ -127,8 +127,8 @@ func TestMetricsMiddleware_HTTPMetricsMiddleware_Error(t *testing.T) {
 		t.Errorf("Expected status 404, got %d", w.Code)
 	}
 
-	if mockDomainMetrics.httpRequests["GET:/api/v1/recipes/:id"] != 1 {
-		t.Errorf("Expected 1 HTTP request, got %d", mockDomainMetrics.httpRequests["GET:/api/v1/recipes/:id"])
+	if mockDomainMetrics.httpRequests["GET:/api/v1/recipes/:uuid"] != 1 {
+		t.Errorf("Expected 1 HTTP request, got %d", mockDomainMetrics.httpRequests["GET:/api/v1/recipes/:uuid"])
 	}
 
 	if mockDomainMetrics.recipeRetrievalErrors["client_error"] != 1 {
@@ -146,10 +146,11 @@ func TestIsRecipeEndpoint(t *testing.T) {
 		expected bool
 	}{
 		{"/api/v1/recipes", true},
-		{"/api/v1/recipes/:id", true},
-		{"/api/v1/recipes/search", true},
-		{"/api/v1/recipes/aggregate", true},
-		{"/api/v1/recipes/author/:author", true},
+		{"/api/v1/recipes/:uuid", true},
+		{"/api/v1/recipes/:uuid/aggregate", true},
+		{"/recipes", true},
+		{"/recipes/:uuid/archive", true},
+		{"/api/v1/recipes-archive", false},
 		{"/health", false},
 		{"/metrics", false},
 		{"/api/v1/other", false},
@@ -163,6 +164,29 @@ func TestIsRecipeEndpoint(t *testing.T) {
 	}
 }
 
+func TestRecipeEndpointKinds(t *testing.T) {
+	tests := []struct {
+		endpoint    string
+		retrieval   bool
+		aggregation bool
+	}{
+		{"/api/v1/recipes/:uuid", true, false},
+		{"/recipes/:uuid", true, false},
+		{"/api/v1/recipes/:uuid/aggregate", false, true},
+		{"/recipes/:uuid/aggregate", false, true},
+		{"/api/v1/recipes", false, false},
+	}
+
+	for _, test := range tests {
+		if result := isRecipeRetrievalEndpoint(test.endpoint); result != test.retrieval {
+			t.Errorf("isRecipeRetrievalEndpoint(%s) = %t, expected %t", test.endpoint, result, test.retrieval)
+		}
+		if result := isRecipeAggregationEndpoint(test.endpoint); result != test.aggregation {
+			t.Errorf("isRecipeAggregationEndpoint(%s) = %t, expected %t", test.endpoint, result, test.aggregation)
+		}
+	}
+}
+
 func TestGetErrorType(t *testing.T) {
 	tests := []struct {
 		statusCode int
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/http/envelope"
+)
+
+const clientIdleTimeout = 10 * time.Minute
+
+type RateLimitConfig struct {
+	Enabled           bool
+	RequestsPerSecond float64
+	Burst             int
+	ExemptPaths       []string
+}
+
+// RateLimiter applies a token bucket per client IP. Limits can be changed
+// while serving through Update, which also resizes the existing buckets.
+type RateLimiter struct {
+	mu        sync.Mutex
+	config    RateLimitConfig
+	clients   map[string]*clientLimiter
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func NewRateLimiter(config RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		config:    config,
+		clients:   make(map[string]*clientLimiter),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (r *RateLimiter) Update(config RateLimitConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.config = config
+	for _, client := range r.clients {
+		client.limiter.SetLimit(rate.Limit(config.RequestsPerSecond))
+		client.limiter.SetBurst(config.Burst)
+	}
+}
+
+func (r *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !r.allow(c.ClientIP(), c.Request.URL.Path) {
+			c.Header("Retry-After", "1")
+			envelope.Abort(c, http.StatusTooManyRequests, "rate limit exceeded")
+			return
+		}
+		c.Next()
+	}
+}
+
+func (r *RateLimiter) allow(clientIP, path string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.config.Enabled || slices.Contains(r.config.ExemptPaths, path) {
+		return true
+	}
+
+	now := r.now()
+	r.sweep(now)
+
+	client, ok := r.clients[clientIP]
+	if !ok {
+		client = &clientLimiter{limiter: rate.NewLimiter(rate.Limit(r.config.RequestsPerSecond), r.config.Burst)}
+		r.clients[clientIP] = client
+	}
+	client.lastSeen = now
+	return client.limiter.AllowN(now, 1)
+}
+
+// sweep drops buckets of clients not seen for a while so the map does not
+// grow with every address that ever called the service.
+func (r *RateLimiter) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < clientIdleTimeout {
+		return
+	}
+	for clientIP, client := range r.clients {
+		if now.Sub(client.lastSeen) >= clientIdleTimeout {
+			delete(r.clients, clientIP)
+		}
+	}
+	r.lastSweep = now
+}
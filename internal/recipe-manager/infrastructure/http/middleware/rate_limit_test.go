@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRateLimitedRouter(limiter *RateLimiter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(limiter.Middleware())
+	router.GET("/recipes", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func requestFrom(router *gin.Engine, path, clientIP string) int {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = clientIP + ":1234"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("limits each client to its burst", func(t *testing.T) {
+		limiter := NewRateLimiter(RateLimitConfig{Enabled: true, RequestsPerSecond: 1, Burst: 2})
+		now := time.Now()
+		limiter.now = func() time.Time { return now }
+		router := newRateLimitedRouter(limiter)
+
+		assert.Equal(t, http.StatusOK, requestFrom(router, "/recipes", "10.0.0.1"))
+		assert.Equal(t, http.StatusOK, requestFrom(router, "/recipes", "10.0.0.1"))
+		assert.Equal(t, http.StatusTooManyRequests, requestFrom(router, "/recipes", "10.0.0.1"))
+		assert.Equal(t, http.StatusOK, requestFrom(router, "/recipes", "10.0.0.2"))
+
+		now = now.Add(time.Second)
+		assert.Equal(t, http.StatusOK, requestFrom(router, "/recipes", "10.0.0.1"))
+	})
+
+	t.Run("skips exempt paths and disabled limiter", func(t *testing.T) {
+		limiter := NewRateLimiter(RateLimitConfig{Enabled: true, RequestsPerSecond: 1, Burst: 1, ExemptPaths: []string{"/health"}})
+		router := newRateLimitedRouter(limiter)
+
+		for i := 0; i < 5; i++ {
+			assert.Equal(t, http.StatusOK, requestFrom(router, "/health", "10.0.0.1"))
+		}
+
+		limiter.Update(RateLimitConfig{Enabled: false})
+		for i := 0; i < 5; i++ {
+			assert.Equal(t, http.StatusOK, requestFrom(router, "/recipes", "10.0.0.1"))
+		}
+	})
+
+	t.Run("applies updated limits to known clients", func(t *testing.T) {
+		limiter := NewRateLimiter(RateLimitConfig{Enabled: true, RequestsPerSecond: 1, Burst: 1})
+		now := time.Now()
+		limiter.now = func() time.Time { return now }
+		router := newRateLimitedRouter(limiter)
+
+		assert.Equal(t, http.StatusOK, requestFrom(router, "/recipes", "10.0.0.1"))
+		assert.Equal(t, http.StatusTooManyRequests, requestFrom(router, "/recipes", "10.0.0.1"))
+
+		limiter.Update(RateLimitConfig{Enabled: true, RequestsPerSecond: 100, Burst: 10})
+		now = now.Add(100 * time.Millisecond)
+		for i := 0; i < 5; i++ {
+			assert.Equal(t, http.StatusOK, requestFrom(router, "/recipes", "10.0.0.1"))
+		}
+	})
+
+	t.Run("forgets idle clients", func(t *testing.T) {
+		limiter := NewRateLimiter(RateLimitConfig{Enabled: true, RequestsPerSecond: 1, Burst: 1})
+		now := time.Now()
+		limiter.now = func() time.Time { return now }
+		router := newRateLimitedRouter(limiter)
+
+		requestFrom(router, "/recipes", "10.0.0.1")
+		now = now.Add(clientIdleTimeout)
+		requestFrom(router, "/recipes", "10.0.0.2")
+
+		assert.Len(t, limiter.clients, 1)
+		assert.Contains(t, limiter.clients, "10.0.0.2")
+	})
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cors := NewCORSMiddleware([]string{"http://localhost:3000"})
+	router := gin.New()
+	router.Use(cors.Middleware())
+	router.GET("/recipes", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(method, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/recipes", nil)
+		req.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request(http.MethodGet, "http://localhost:3000")
+	assert.Equal(t, "http://localhost:3000", w.Header().Get("Access-Control-Allow-Origin"))
+
+	w = request(http.MethodGet, "https://evil.example")
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+	cors.SetAllowedOrigins([]string{"https://evil.example"})
+	w = request(http.MethodOptions, "https://evil.example")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://evil.example", w.Header().Get("Access-Control-Allow-Origin"))
+}
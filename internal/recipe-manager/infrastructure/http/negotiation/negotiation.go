@@ -0,0 +1,88 @@
+// Package negotiation picks the representation of a response from the
+// Accept and Accept-Encoding headers of the request (RFC 9110, section 12).
+package negotiation
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Negotiate returns the offer the header prefers, with the offers in the
+// order the server prefers them breaking ties. Offers are media types such
+// as "application/json" for Accept, matched by "type/*" and "*/*", or
+// codings such as "gzip" for Accept-Encoding, matched by "*". An empty
+// header accepts the first offer; ok is false when no offer is acceptable.
+func Negotiate(header string, offers ...string) (offer string, ok bool) {
+	if strings.TrimSpace(header) == "" {
+		if len(offers) == 0 {
+			return "", false
+		}
+		return offers[0], true
+	}
+
+	ranges := parse(header)
+	best := 0.0
+	for _, candidate := range offers {
+		if q := quality(ranges, candidate); q > best {
+			offer, best = candidate, q
+		}
+	}
+	return offer, best > 0
+}
+
+type acceptRange struct {
+	value string
+	q     float64
+}
+
+func parse(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		value, params, _ := strings.Cut(part, ";")
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, raw, found := strings.Cut(param, "=")
+			if !found || strings.ToLower(strings.TrimSpace(name)) != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				parsed = 0
+			}
+			q = parsed
+		}
+		ranges = append(ranges, acceptRange{value: value, q: q})
+	}
+	return ranges
+}
+
+// quality is the q of the most specific range matching offer, 0 when none
+// does.
+func quality(ranges []acceptRange, offer string) float64 {
+	offer = strings.ToLower(offer)
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		if s := match(r.value, offer); s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
+
+// match returns how specific value is when it matches offer, -1 when it
+// does not: 2 for the offer itself, 1 for "type/*" and 0 for "*/*" or "*".
+func match(value, offer string) int {
+	switch {
+	case value == offer:
+		return 2
+	case value == "*" || value == "*/*":
+		return 0
+	case strings.HasSuffix(value, "/*") && strings.HasPrefix(offer, strings.TrimSuffix(value, "*")):
+		return 1
+	}
+	return -1
+}
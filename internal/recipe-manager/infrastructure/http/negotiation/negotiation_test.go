@@ -0,0 +1,45 @@
+package negotiation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	mediaTypes := []string{"application/json", "application/yaml", "text/csv"}
+	codings := []string{"zstd", "gzip"}
+
+	tests := []struct {
+		name   string
+		header string
+		offers []string
+		offer  string
+		ok     bool
+	}{
+		{"takes the first offer without a header", "", mediaTypes, "application/json", true},
+		{"takes the offer named", "text/csv", mediaTypes, "text/csv", true},
+		{"ignores case and spaces", " Application/YAML ; charset=utf-8", mediaTypes, "application/yaml", true},
+		{"prefers the higher quality", "application/json;q=0.5, text/csv", mediaTypes, "text/csv", true},
+		{"breaks ties by the order of the offers", "text/csv, application/yaml", mediaTypes, "application/yaml", true},
+		{"matches a type wildcard", "text/*", mediaTypes, "text/csv", true},
+		{"matches any media type", "*/*", mediaTypes, "application/json", true},
+		{"lets a specific range override a wildcard", "*/*, application/json;q=0", mediaTypes, "application/yaml", true},
+		{"rejects every offer", "image/png", mediaTypes, "", false},
+		{"rejects offers with a zero quality", "text/csv;q=0", mediaTypes, "", false},
+		{"treats an invalid quality as zero", "text/csv;q=2", mediaTypes, "", false},
+		{"picks a coding", "gzip, deflate, br, zstd", codings, "zstd", true},
+		{"weighs codings", "gzip;q=1.0, zstd;q=0.8", codings, "gzip", true},
+		{"matches any coding", "*", codings, "zstd", true},
+		{"rejects unknown codings", "br", codings, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offer, ok := Negotiate(tt.header, tt.offers...)
+
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.offer, offer)
+		})
+	}
+}
@@ -0,0 +1,279 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const redactedValue = "[REDACTED]"
+
+type AccessLogConfig struct {
+	// SingleLine logs only the completion line instead of a start and a
+	// completion line per request.
+	SingleLine bool
+	// CaptureBodies attaches request and response bodies to the completion
+	// line of requests answered with a 4xx or 5xx status.
+	CaptureBodies bool
+	MaxBodyBytes  int
+	// RedactClientIP masks the host part of the client address (last IPv4
+	// octet, last 80 bits of IPv6).
+	RedactClientIP   bool
+	IncludeUserAgent bool
+	// LogHeaders lists the request headers added to the completion line.
+	// Headers also present in RedactHeaders are logged as [REDACTED].
+	LogHeaders    []string
+	RedactHeaders []string
+	// RedactFields lists JSON keys, at any depth, masked in captured bodies.
+	RedactFields []string
+	// SampledPaths are logged only for a SampleRate fraction of their
+	// successful hits; failures are always logged.
+	SampledPaths []string
+	SampleRate   float64
+}
+
+func DefaultAccessLogConfig() AccessLogConfig {
+	return AccessLogConfig{
+		MaxBodyBytes:     4096,
+		IncludeUserAgent: true,
+		RedactHeaders:    []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key", "X-Admin-Token"},
+		RedactFields:     []string{"password", "secret", "token"},
+		SampleRate:       1,
+	}
+}
+
+func (l *Logger) GinMiddleware() gin.HandlerFunc {
+	return l.GinMiddlewareWithConfig(DefaultAccessLogConfig())
+}
+
+func (l *Logger) GinMiddlewareWithConfig(config AccessLogConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		correlationID := c.GetHeader("X-Correlation-ID")
+		if correlationID == "" {
+			correlationID = uuid.New().String()
+		}
+
+		ctx := NewContextWithCorrelationID(c.Request.Context(), correlationID)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Header("X-Correlation-ID", correlationID)
+
+		path := c.Request.URL.Path
+		sampled := !slices.Contains(config.SampledPaths, path) || rand.Float64() < config.SampleRate
+
+		var requestBody, responseBody *limitedBuffer
+		if config.CaptureBodies {
+			requestBody = &limitedBuffer{limit: config.MaxBodyBytes}
+			responseBody = &limitedBuffer{limit: config.MaxBodyBytes}
+			if c.Request.Body != nil {
+				c.Request.Body = teeReadCloser{Reader: io.TeeReader(c.Request.Body, requestBody), Closer: c.Request.Body}
+			}
+			c.Writer = &bodyCaptureWriter{ResponseWriter: c.Writer, body: responseBody}
+		}
+
+		start := time.Now()
+		if !config.SingleLine && sampled {
+			startFields := logrus.Fields{
+				"method":    c.Request.Method,
+				"path":      path,
+				"client_ip": config.clientIP(c.ClientIP()),
+			}
+			if config.IncludeUserAgent {
+				startFields["user_agent"] = c.Request.UserAgent()
+			}
+			l.WithContext(ctx).WithFields(startFields).Info("Request started")
+		}
+
+		c.Next()
+
+		statusCode := c.Writer.Status()
+		failed := statusCode >= http.StatusBadRequest
+		if !sampled && !failed {
+			return
+		}
+
+		duration := time.Since(start)
+		fields := logrus.Fields{
+			"method":        c.Request.Method,
+			"path":          path,
+			"status_code":   statusCode,
+			"duration_ms":   duration.Milliseconds(),
+			"response_size": c.Writer.Size(),
+		}
+		if config.SingleLine {
+			fields["client_ip"] = config.clientIP(c.ClientIP())
+			if config.IncludeUserAgent {
+				fields["user_agent"] = c.Request.UserAgent()
+			}
+		}
+		if headers := config.headers(c.Request.Header); len(headers) > 0 {
+			fields["headers"] = headers
+		}
+		if config.CaptureBodies && failed {
+			fields["request_body"] = config.body(requestBody)
+			fields["response_body"] = config.body(responseBody)
+		}
+
+		entry := l.WithContext(ctx).WithFields(fields)
+		switch {
+		case statusCode >= http.StatusInternalServerError:
+			entry.Error("Request completed")
+		case failed:
+			entry.Warn("Request completed")
+		default:
+			entry.Info("Request completed")
+		}
+	}
+}
+
+func (config AccessLogConfig) clientIP(address string) string {
+	if !config.RedactClientIP {
+		return address
+	}
+	return RedactIP(address)
+}
+
+func (config AccessLogConfig) headers(header http.Header) map[string]string {
+	if len(config.LogHeaders) == 0 {
+		return nil
+	}
+
+	headers := make(map[string]string, len(config.LogHeaders))
+	for _, name := range config.LogHeaders {
+		value := header.Get(name)
+		if value == "" {
+			continue
+		}
+		if containsFold(config.RedactHeaders, name) {
+			value = redactedValue
+		}
+		headers[http.CanonicalHeaderKey(name)] = value
+	}
+	return headers
+}
+
+func (config AccessLogConfig) body(buffer *limitedBuffer) string {
+	if buffer == nil || buffer.buf.Len() == 0 {
+		return ""
+	}
+
+	body := buffer.buf.Bytes()
+	if !buffer.truncated && len(config.RedactFields) > 0 {
+		var document interface{}
+		if err := json.Unmarshal(body, &document); err == nil {
+			if redacted, err := json.Marshal(redactFields(document, config.RedactFields)); err == nil {
+				return string(redacted)
+			}
+		}
+	}
+
+	if buffer.truncated {
+		// A truncated JSON document cannot be parsed, so sensitive fields
+		// cannot be masked reliably: drop it rather than leak them.
+		if len(config.RedactFields) > 0 && looksLikeJSON(body) {
+			return redactedValue + " (truncated)"
+		}
+		return string(body) + "...(truncated)"
+	}
+	return string(body)
+}
+
+// RedactIP masks the host part of an IP address so access logs can still be
+// grouped by network without storing personal data.
+func RedactIP(address string) string {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return redactedValue
+	}
+	if ipv4 := ip.To4(); ipv4 != nil {
+		return ipv4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return ip.Mask(net.CIDRMask(48, 128)).String()
+}
+
+func redactFields(document interface{}, fields []string) interface{} {
+	switch value := document.(type) {
+	case map[string]interface{}:
+		for key, nested := range value {
+			if containsFold(fields, key) {
+				value[key] = redactedValue
+				continue
+			}
+			value[key] = redactFields(nested, fields)
+		}
+		return value
+	case []interface{}:
+		for i, nested := range value {
+			value[i] = redactFields(nested, fields)
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func looksLikeJSON(body []byte) bool {
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
+
+// limitedBuffer keeps at most limit bytes and silently discards the rest, so
+// capturing a body never fails or blocks the request.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	remaining := b.limit - b.buf.Len()
+	switch {
+	case remaining <= 0:
+		b.truncated = b.truncated || len(p) > 0
+	case len(p) > remaining:
+		b.buf.Write(p[:remaining])
+		b.truncated = true
+	default:
+		b.buf.Write(p)
+	}
+	return len(p), nil
+}
+
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body *limitedBuffer
+}
+
+func (w *bodyCaptureWriter) Write(p []byte) (int, error) {
+	_, _ = w.body.Write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	_, _ = w.body.Write([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,193 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAccessLogRouter(t *testing.T, config AccessLogConfig) (*gin.Engine, *bytes.Buffer) {
+	t.Helper()
+	logger := NewLogger("test-service", "1.0.0")
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(logger.GinMiddlewareWithConfig(config))
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "healthy"})
+	})
+	router.POST("/recipes", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recipe", "received": len(body)})
+	})
+	router.GET("/boom", func(c *gin.Context) {
+		c.String(http.StatusInternalServerError, strings.Repeat("x", 64))
+	})
+	return router, &buf
+}
+
+func decodeLogs(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var logs []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		logs = append(logs, entry)
+	}
+	return logs
+}
+
+func TestAccessLogSingleLine(t *testing.T) {
+	config := DefaultAccessLogConfig()
+	config.SingleLine = true
+	config.IncludeUserAgent = false
+	router, buf := newAccessLogRouter(t, config)
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set("User-Agent", "curl/8.0")
+	req.RemoteAddr = "192.168.1.42:5555"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	logs := decodeLogs(t, buf)
+	require.Len(t, logs, 1)
+	assert.Equal(t, "Request completed", logs[0]["message"])
+	assert.Equal(t, "info", logs[0]["level"])
+	assert.Equal(t, "192.168.1.42", logs[0]["client_ip"])
+	assert.NotContains(t, logs[0], "user_agent")
+	assert.NotEmpty(t, logs[0][CorrelationIDKey])
+}
+
+func TestAccessLogRedaction(t *testing.T) {
+	config := DefaultAccessLogConfig()
+	config.SingleLine = true
+	config.RedactClientIP = true
+	config.LogHeaders = []string{"Authorization", "X-Request-Source"}
+	router, buf := newAccessLogRouter(t, config)
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	req.Header.Set("X-Request-Source", "frontend")
+	req.RemoteAddr = "192.168.1.42:5555"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	logs := decodeLogs(t, buf)
+	require.Len(t, logs, 1)
+	assert.Equal(t, "192.168.1.0", logs[0]["client_ip"])
+	assert.Equal(t, map[string]interface{}{
+		"Authorization":    "[REDACTED]",
+		"X-Request-Source": "frontend",
+	}, logs[0]["headers"])
+	assert.NotContains(t, buf.String(), "secret-token")
+}
+
+func TestAccessLogErrorBodies(t *testing.T) {
+	t.Run("captures and redacts bodies of failed requests", func(t *testing.T) {
+		config := DefaultAccessLogConfig()
+		config.SingleLine = true
+		config.CaptureBodies = true
+		router, buf := newAccessLogRouter(t, config)
+
+		w := performRequest(router, http.MethodPost, "/recipes", []byte(`{"name":"margherita","password":"hunter2"}`))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid recipe")
+
+		logs := decodeLogs(t, buf)
+		require.Len(t, logs, 1)
+		assert.Equal(t, "warning", logs[0]["level"])
+		assert.JSONEq(t, `{"name":"margherita","password":"[REDACTED]"}`, logs[0]["request_body"].(string))
+		assert.Contains(t, logs[0]["response_body"], "invalid recipe")
+		assert.NotContains(t, buf.String(), "hunter2")
+	})
+
+	t.Run("truncates bodies over the limit", func(t *testing.T) {
+		config := DefaultAccessLogConfig()
+		config.SingleLine = true
+		config.CaptureBodies = true
+		config.MaxBodyBytes = 16
+		router, buf := newAccessLogRouter(t, config)
+
+		w := performRequest(router, http.MethodGet, "/boom", nil)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Len(t, w.Body.String(), 64)
+
+		logs := decodeLogs(t, buf)
+		require.Len(t, logs, 1)
+		assert.Equal(t, "error", logs[0]["level"])
+		assert.Equal(t, strings.Repeat("x", 16)+"...(truncated)", logs[0]["response_body"])
+	})
+
+	t.Run("does not capture bodies of successful requests", func(t *testing.T) {
+		config := DefaultAccessLogConfig()
+		config.SingleLine = true
+		config.CaptureBodies = true
+		router, buf := newAccessLogRouter(t, config)
+
+		performRequest(router, http.MethodGet, "/health", nil)
+
+		logs := decodeLogs(t, buf)
+		require.Len(t, logs, 1)
+		assert.NotContains(t, logs[0], "response_body")
+	})
+}
+
+func TestAccessLogSampling(t *testing.T) {
+	tests := []struct {
+		name         string
+		rate         float64
+		path         string
+		method       string
+		expectedLogs int
+	}{
+		{name: "drops successful hits on sampled paths", rate: 0, path: "/health", method: http.MethodGet, expectedLogs: 0},
+		{name: "keeps failures on sampled paths", rate: 0, path: "/recipes", method: http.MethodPost, expectedLogs: 1},
+		{name: "keeps everything at full rate", rate: 1, path: "/health", method: http.MethodGet, expectedLogs: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultAccessLogConfig()
+			config.SingleLine = true
+			config.SampledPaths = []string{"/health", "/recipes"}
+			config.SampleRate = tt.rate
+			router, buf := newAccessLogRouter(t, config)
+
+			for i := 0; i < 10; i++ {
+				performRequest(router, tt.method, tt.path, nil)
+			}
+
+			assert.Len(t, decodeLogs(t, buf), tt.expectedLogs*10)
+		})
+	}
+}
+
+func TestRedactIP(t *testing.T) {
+	tests := []struct {
+		address  string
+		expected string
+	}{
+		{address: "203.0.113.77", expected: "203.0.113.0"},
+		{address: "2001:db8:abcd:12::1", expected: "2001:db8:abcd::"},
+		{address: "not-an-ip", expected: "[REDACTED]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			assert.Equal(t, tt.expected, RedactIP(tt.address))
+		})
+	}
+}
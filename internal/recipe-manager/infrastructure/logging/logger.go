@@ -5,7 +5,6 @@ import (
 	"os"
 	"time"
 
-	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"go.opentelemetry.io/otel/trace"
@@ -64,7 +63,7 @@ func NewLogger(serviceName, version string) *Logger {
 }
 
 func (l *Logger) WithContext(ctx context.Context) *logrus.Entry {
-	entry := l.Logger.WithFields(logrus.Fields{
+	entry := l.Logger.WithContext(ctx).WithFields(logrus.Fields{
 		ServiceNameKey: l.serviceName,
 		VersionKey:     l.version,
 	})
@@ -196,36 +195,3 @@ func (l *Logger) GRPCStreamInterceptor() grpc.StreamServerInterceptor {
 		return err
 	}
 }
-
-func (l *Logger) GinMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		correlationID := c.GetHeader("X-Correlation-ID")
-		if correlationID == "" {
-			correlationID = uuid.New().String()
-		}
-
-		ctx := NewContextWithCorrelationID(c.Request.Context(), correlationID)
-		c.Request = c.Request.WithContext(ctx)
-
-		c.Header("X-Correlation-ID", correlationID)
-
-		start := time.Now()
-		l.WithContext(ctx).WithFields(logrus.Fields{
-			"method":     c.Request.Method,
-			"path":       c.Request.URL.Path,
-			"client_ip":  c.ClientIP(),
-			"user_agent": c.Request.UserAgent(),
-		}).Info("Request started")
-
-		c.Next()
-
-		duration := time.Since(start)
-		l.WithContext(ctx).WithFields(logrus.Fields{
-			"method":        c.Request.Method,
-			"path":          c.Request.URL.Path,
-			"status_code":   c.Writer.Status(),
-			"duration_ms":   duration.Milliseconds(),
-			"response_size": c.Writer.Size(),
-		}).Info("Request completed")
-	}
-}
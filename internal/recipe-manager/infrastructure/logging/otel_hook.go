@@ -0,0 +1,99 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	otellog "go.opentelemetry.io/otel/log"
+)
+
+const otelInstrumentationName = "github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/logging"
+
+// OTelHook forwards logrus entries to an OpenTelemetry LoggerProvider so they
+// reach the collector alongside traces and metrics. The entry context, when
+// set through Logger.WithContext, links each record to the active span.
+type OTelHook struct {
+	logger otellog.Logger
+	levels []logrus.Level
+}
+
+func NewOTelHook(provider otellog.LoggerProvider) *OTelHook {
+	return &OTelHook{
+		logger: provider.Logger(otelInstrumentationName),
+		levels: logrus.AllLevels,
+	}
+}
+
+func (h *OTelHook) Levels() []logrus.Level {
+	return h.levels
+}
+
+func (h *OTelHook) Fire(entry *logrus.Entry) error {
+	var record otellog.Record
+	record.SetTimestamp(entry.Time)
+	record.SetObservedTimestamp(time.Now())
+	record.SetSeverity(toOTelSeverity(entry.Level))
+	record.SetSeverityText(entry.Level.String())
+	record.SetBody(otellog.StringValue(entry.Message))
+
+	attributes := make([]otellog.KeyValue, 0, len(entry.Data))
+	for key, value := range entry.Data {
+		attributes = append(attributes, otellog.KeyValue{Key: key, Value: toOTelValue(value)})
+	}
+	record.AddAttributes(attributes...)
+
+	ctx := entry.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	h.logger.Emit(ctx, record)
+	return nil
+}
+
+func toOTelSeverity(level logrus.Level) otellog.Severity {
+	switch level {
+	case logrus.TraceLevel:
+		return otellog.SeverityTrace
+	case logrus.DebugLevel:
+		return otellog.SeverityDebug
+	case logrus.InfoLevel:
+		return otellog.SeverityInfo
+	case logrus.WarnLevel:
+		return otellog.SeverityWarn
+	case logrus.ErrorLevel:
+		return otellog.SeverityError
+	case logrus.FatalLevel:
+		return otellog.SeverityFatal
+	case logrus.PanicLevel:
+		return otellog.SeverityFatal4
+	default:
+		return otellog.SeverityUndefined
+	}
+}
+
+func toOTelValue(value interface{}) otellog.Value {
+	switch v := value.(type) {
+	case string:
+		return otellog.StringValue(v)
+	case bool:
+		return otellog.BoolValue(v)
+	case int:
+		return otellog.IntValue(v)
+	case int32:
+		return otellog.Int64Value(int64(v))
+	case int64:
+		return otellog.Int64Value(v)
+	case float32:
+		return otellog.Float64Value(float64(v))
+	case float64:
+		return otellog.Float64Value(v)
+	case error:
+		return otellog.StringValue(v.Error())
+	case fmt.Stringer:
+		return otellog.StringValue(v.String())
+	default:
+		return otellog.StringValue(fmt.Sprint(v))
+	}
+}
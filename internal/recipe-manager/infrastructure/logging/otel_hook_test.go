@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+type recordingExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (e *recordingExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, record := range records {
+		e.records = append(e.records, record.Clone())
+	}
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(context.Context) error   { return nil }
+func (e *recordingExporter) ForceFlush(context.Context) error { return nil }
+
+func TestOTelHook(t *testing.T) {
+	exporter := &recordingExporter{}
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)))
+	defer provider.Shutdown(context.Background())
+
+	logger := NewLogger("test-service", "1.0.0")
+	logger.SetOutput(&bytes.Buffer{})
+	logger.AddHook(NewOTelHook(provider))
+
+	tracerProvider := sdktrace.NewTracerProvider()
+	ctx, span := tracerProvider.Tracer("test").Start(context.Background(), "operation")
+	defer span.End()
+
+	logger.WithContext(ctx).WithFields(logrus.Fields{
+		"attempt": 2,
+		"cause":   errors.New("boom"),
+	}).Warn("retrying request")
+
+	require.Len(t, exporter.records, 1)
+	record := exporter.records[0]
+
+	assert.Equal(t, "retrying request", record.Body().AsString())
+	assert.Equal(t, otellog.SeverityWarn, record.Severity())
+	assert.Equal(t, "warning", record.SeverityText())
+	assert.Equal(t, span.SpanContext().TraceID(), record.TraceID())
+
+	attributes := map[string]otellog.Value{}
+	record.WalkAttributes(func(kv otellog.KeyValue) bool {
+		attributes[kv.Key] = kv.Value
+		return true
+	})
+	assert.Equal(t, int64(2), attributes["attempt"].AsInt64())
+	assert.Equal(t, "boom", attributes["cause"].AsString())
+	assert.Equal(t, "test-service", attributes[ServiceNameKey].AsString())
+}
+
+func TestToOTelSeverity(t *testing.T) {
+	assert.Equal(t, otellog.SeverityDebug, toOTelSeverity(logrus.DebugLevel))
+	assert.Equal(t, otellog.SeverityInfo, toOTelSeverity(logrus.InfoLevel))
+	assert.Equal(t, otellog.SeverityError, toOTelSeverity(logrus.ErrorLevel))
+	assert.Equal(t, otellog.SeverityFatal, toOTelSeverity(logrus.FatalLevel))
+}
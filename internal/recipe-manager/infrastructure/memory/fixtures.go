@@ -0,0 +1,163 @@
+package memory
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+)
+
+// fixture is a recipe file, in YAML or JSON. Dough amounts default to "%"
+// and topping amounts to "g", as in the database. Steps are numbered in the
+// order they are listed.
+type fixture struct {
+	Uuid        string `yaml:"uuid" json:"uuid"`
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description"`
+	Author      string `yaml:"author" json:"author"`
+	// Style is one of domain.Styles; DoughDensity, in grams per cm²,
+	// overrides the one of the style.
+	Style        string  `yaml:"style" json:"style"`
+	DoughDensity float64 `yaml:"doughDensity" json:"doughDensity"`
+	// ServingWeight is the grams of dough of a serving.
+	ServingWeight float64 `yaml:"servingWeight" json:"servingWeight"`
+	Dough         struct {
+		PercentVariation float64             `yaml:"percentVariation" json:"percentVariation"`
+		Ingredients      []fixtureIngredient `yaml:"ingredients" json:"ingredients"`
+	} `yaml:"dough" json:"dough"`
+	Topping struct {
+		ReferenceArea float64             `yaml:"referenceArea" json:"referenceArea"`
+		Ingredients   []fixtureIngredient `yaml:"ingredients" json:"ingredients"`
+	} `yaml:"topping" json:"topping"`
+	Steps []string `yaml:"steps" json:"steps"`
+}
+
+type fixtureIngredient struct {
+	Name   string  `yaml:"name" json:"name"`
+	Amount float64 `yaml:"amount" json:"amount"`
+	Unit   string  `yaml:"unit" json:"unit"`
+	Notes  string  `yaml:"notes" json:"notes"`
+}
+
+// LoadFixtures reads one recipe from each .yml, .yaml and .json file at the
+// root of fsys, in file name order. Unknown keys are rejected so typos do not
+// go unnoticed.
+func LoadFixtures(fsys fs.FS) ([]domain.Recipe, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures: %w", err)
+	}
+
+	var recipes []domain.Recipe
+	files := make(map[uuid.UUID]string)
+	for _, entry := range entries {
+		extension := path.Ext(entry.Name())
+		if entry.IsDir() || (extension != ".yml" && extension != ".yaml" && extension != ".json") {
+			continue
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture %s: %w", entry.Name(), err)
+		}
+		recipe, err := parseFixture(content, extension == ".json")
+		if err != nil {
+			return nil, fmt.Errorf("fixture %s: %w", entry.Name(), err)
+		}
+		if other, taken := files[recipe.Uuid]; taken {
+			return nil, fmt.Errorf("fixture %s: uuid %s is already used by %s", entry.Name(), recipe.Uuid, other)
+		}
+		files[recipe.Uuid] = entry.Name()
+		recipes = append(recipes, recipe)
+	}
+	return recipes, nil
+}
+
+func parseFixture(content []byte, isJSON bool) (domain.Recipe, error) {
+	var f fixture
+	if isJSON {
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&f); err != nil {
+			return domain.Recipe{}, err
+		}
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+			return domain.Recipe{}, err
+		}
+	}
+
+	recipeUuid, err := uuid.Parse(f.Uuid)
+	if err != nil {
+		return domain.Recipe{}, fmt.Errorf("invalid uuid %q", f.Uuid)
+	}
+	if f.Name == "" {
+		return domain.Recipe{}, errors.New("name is required")
+	}
+
+	if f.Style != "" {
+		if _, err := domain.StyleByName(f.Style); err != nil {
+			return domain.Recipe{}, err
+		}
+	}
+	if f.DoughDensity < 0 || f.ServingWeight < 0 {
+		return domain.Recipe{}, errors.New("doughDensity and servingWeight must not be negative")
+	}
+
+	dough, err := fixtureIngredients(f.Dough.Ingredients, "%")
+	if err != nil {
+		return domain.Recipe{}, fmt.Errorf("dough: %w", err)
+	}
+	topping, err := fixtureIngredients(f.Topping.Ingredients, "g")
+	if err != nil {
+		return domain.Recipe{}, fmt.Errorf("topping: %w", err)
+	}
+	var steps domain.Steps
+	for i, description := range f.Steps {
+		if description == "" {
+			return domain.Recipe{}, fmt.Errorf("step %d: description is required", i+1)
+		}
+		steps.Steps = append(steps.Steps, domain.Step{StepNumber: i + 1, Description: description})
+	}
+
+	return domain.Recipe{
+		Uuid:          recipeUuid,
+		Name:          f.Name,
+		Description:   f.Description,
+		Author:        f.Author,
+		Dough:         domain.Dough{PercentVariation: f.Dough.PercentVariation, Ingredients: dough},
+		Topping:       domain.Topping{ReferenceArea: f.Topping.ReferenceArea, Ingredients: topping},
+		Steps:         steps,
+		Style:         f.Style,
+		DoughDensity:  f.DoughDensity,
+		ServingWeight: f.ServingWeight,
+	}, nil
+}
+
+func fixtureIngredients(items []fixtureIngredient, defaultUnit string) ([]domain.Ingredient, error) {
+	ingredients := make([]domain.Ingredient, 0, len(items))
+	for i, item := range items {
+		if item.Name == "" {
+			return nil, fmt.Errorf("ingredient %d: name is required", i+1)
+		}
+		if item.Amount < 0 {
+			return nil, fmt.Errorf("%s: amount must not be negative", item.Name)
+		}
+		unit := item.Unit
+		if unit == "" {
+			unit = defaultUnit
+		}
+		ingredients = append(ingredients, domain.Ingredient{Name: item.Name, Amount: item.Amount, Unit: unit, Notes: item.Notes})
+	}
+	return ingredients, nil
+}
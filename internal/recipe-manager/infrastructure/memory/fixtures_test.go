@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+	"github.com/cfioretti/recipe-manager/migrations"
+)
+
+func TestLoadFixtures(t *testing.T) {
+	t.Run("reads YAML and JSON files", func(t *testing.T) {
+		recipes, err := LoadFixtures(fstest.MapFS{
+			"margherita.yml": {Data: []byte(`
+uuid: "00000000-0000-0000-0000-000000000000"
+name: "Margherita"
+style: "neapolitan"
+dough:
+  percentVariation: 8
+  ingredients:
+    - { name: "flour", amount: 55.7 }
+topping:
+  referenceArea: 1200
+  ingredients:
+    - { name: "basil", amount: 10, notes: "fresh" }
+steps:
+  - "Knead the dough"
+  - "Bake"
+`)},
+			"marinara.json": {Data: []byte(`{"uuid": "00000000-0000-0000-0000-000000000001", "name": "Marinara",
+				"doughDensity": 0.4, "servingWeight": 280, "topping": {"ingredients": [{"name": "garlic", "amount": 1, "unit": "clove"}]}}`)},
+			"README.md": {Data: []byte("not a fixture")},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []domain.Recipe{
+			{
+				Uuid:  uuid.MustParse("00000000-0000-0000-0000-000000000000"),
+				Name:  "Margherita",
+				Style: domain.StyleNeapolitan,
+				Dough: domain.Dough{PercentVariation: 8, Ingredients: []domain.Ingredient{
+					{Name: "flour", Amount: 55.7, Unit: "%"},
+				}},
+				Topping: domain.Topping{ReferenceArea: 1200, Ingredients: []domain.Ingredient{
+					{Name: "basil", Amount: 10, Unit: "g", Notes: "fresh"},
+				}},
+				Steps: domain.Steps{Steps: []domain.Step{
+					{StepNumber: 1, Description: "Knead the dough"},
+					{StepNumber: 2, Description: "Bake"},
+				}},
+			},
+			{
+				Uuid:          uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+				Name:          "Marinara",
+				DoughDensity:  0.4,
+				ServingWeight: 280,
+				Dough:         domain.Dough{Ingredients: []domain.Ingredient{}},
+				Topping: domain.Topping{Ingredients: []domain.Ingredient{
+					{Name: "garlic", Amount: 1, Unit: "clove"},
+				}},
+			},
+		}, recipes)
+	})
+
+	tests := []struct {
+		name     string
+		files    fstest.MapFS
+		expected string
+	}{
+		{
+			name:     "unknown key",
+			files:    fstest.MapFS{"a.yml": {Data: []byte("uuid: \"00000000-0000-0000-0000-000000000000\"\nname: A\ntoppings: []\n")}},
+			expected: "fixture a.yml: yaml: unmarshal errors",
+		},
+		{
+			name:     "invalid uuid",
+			files:    fstest.MapFS{"a.json": {Data: []byte(`{"uuid": "margherita", "name": "A"}`)}},
+			expected: `fixture a.json: invalid uuid "margherita"`,
+		},
+		{
+			name:     "missing ingredient name",
+			files:    fstest.MapFS{"a.yml": {Data: []byte("uuid: \"00000000-0000-0000-0000-000000000000\"\nname: A\ndough:\n  ingredients:\n    - { amount: 1 }\n")}},
+			expected: "dough: ingredient 1: name is required",
+		},
+		{
+			name:     "empty step",
+			files:    fstest.MapFS{"a.yml": {Data: []byte("uuid: \"00000000-0000-0000-0000-000000000000\"\nname: A\nsteps:\n  - \"\"\n")}},
+			expected: "fixture a.yml: step 1: description is required",
+		},
+		{
+			name:     "unknown style",
+			files:    fstest.MapFS{"a.json": {Data: []byte(`{"uuid": "00000000-0000-0000-0000-000000000000", "name": "A", "style": "chicago"}`)}},
+			expected: `fixture a.json: unknown style "chicago"`,
+		},
+		{
+			name: "duplicate uuid",
+			files: fstest.MapFS{
+				"a.json": {Data: []byte(`{"uuid": "00000000-0000-0000-0000-000000000000", "name": "A"}`)},
+				"b.json": {Data: []byte(`{"uuid": "00000000-0000-0000-0000-000000000000", "name": "B"}`)},
+			},
+			expected: "fixture b.json: uuid 00000000-0000-0000-0000-000000000000 is already used by a.json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadFixtures(tt.files)
+			assert.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestLoadBundledFixtures(t *testing.T) {
+	recipes, err := LoadFixtures(migrations.Fixtures())
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(recipes))
+	for _, recipe := range recipes {
+		names = append(names, recipe.Name)
+	}
+	assert.Equal(t, []string{"Margherita", "Marinara"}, names)
+}
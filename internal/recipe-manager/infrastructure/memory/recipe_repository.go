@@ -0,0 +1,281 @@
+// Package memory keeps recipes in process, for tests and for the demo mode
+// that runs without a database.
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+)
+
+// RecipeRepository is safe for concurrent use. Recipes are copied in and
+// out, so callers may change what they get back; the catalogue entries the
+// ingredients link to are shared and must be treated as read only.
+type RecipeRepository struct {
+	mu        sync.RWMutex
+	recipes   map[uuid.UUID]domain.Recipe
+	lastId    int
+	catalogue map[string]*domain.CatalogueIngredient
+}
+
+type Option func(*RecipeRepository)
+
+// WithCatalogue links the ingredients of saved recipes to the catalogue
+// entry matching their name or one of its aliases, case-insensitively, and
+// renames them after the entry, as the database import does.
+func WithCatalogue(ingredients []domain.CatalogueIngredient) Option {
+	ingredients = slices.Clone(ingredients)
+	return func(rr *RecipeRepository) {
+		for i := range ingredients {
+			entry := &ingredients[i]
+			if entry.Id == 0 {
+				entry.Id = i + 1
+			}
+			for _, name := range append([]string{entry.Name}, entry.Aliases...) {
+				rr.catalogue[strings.ToLower(name)] = entry
+			}
+		}
+	}
+}
+
+func NewRecipeRepository(options ...Option) *RecipeRepository {
+	repository := &RecipeRepository{
+		recipes:   make(map[uuid.UUID]domain.Recipe),
+		catalogue: make(map[string]*domain.CatalogueIngredient),
+	}
+	for _, option := range options {
+		option(repository)
+	}
+	return repository
+}
+
+// Save stores recipe, replacing the one with the same UUID. Recipes without
+// an id get the next one, and those without a version start at 1.
+func (rr *RecipeRepository) Save(recipe domain.Recipe) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	rr.save(recipe)
+}
+
+func (rr *RecipeRepository) save(recipe domain.Recipe) {
+	recipe = cloneRecipe(recipe)
+	if recipe.Version == 0 {
+		recipe.Version = 1
+	}
+
+	if existing, found := rr.recipes[recipe.Uuid]; found && recipe.Id == 0 {
+		recipe.Id = existing.Id
+	}
+	if recipe.Id == 0 {
+		recipe.Id = rr.lastId + 1
+	}
+	rr.lastId = max(rr.lastId, recipe.Id)
+
+	rr.link(recipe.Dough.Ingredients)
+	rr.link(recipe.Topping.Ingredients)
+	rr.recipes[recipe.Uuid] = recipe
+}
+
+func (rr *RecipeRepository) GetRecipeByUuid(ctx context.Context, recipeUuid uuid.UUID) (*domain.Recipe, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	rr.mu.RLock()
+	defer rr.mu.RUnlock()
+
+	recipe, found := rr.recipes[recipeUuid]
+	if !found || recipe.DeletedAt != nil {
+		return nil, fmt.Errorf("recipe %s: %w", recipeUuid, domain.ErrRecipeNotFound)
+	}
+	recipe = cloneRecipe(recipe)
+	return &recipe, nil
+}
+
+// GetRecipesByUuids returns the recipes of recipeUuids that exist and are not
+// deleted, ordered by name. Missing recipes are left out.
+func (rr *RecipeRepository) GetRecipesByUuids(ctx context.Context, recipeUuids []uuid.UUID) ([]domain.Recipe, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	rr.mu.RLock()
+	defer rr.mu.RUnlock()
+
+	recipes := make([]domain.Recipe, 0, len(recipeUuids))
+	seen := make(map[uuid.UUID]bool, len(recipeUuids))
+	for _, recipeUuid := range recipeUuids {
+		recipe, found := rr.recipes[recipeUuid]
+		if found && recipe.DeletedAt == nil && !seen[recipeUuid] {
+			seen[recipeUuid] = true
+			recipes = append(recipes, cloneRecipe(recipe))
+		}
+	}
+	slices.SortFunc(recipes, func(a, b domain.Recipe) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Id, b.Id))
+	})
+	return recipes, nil
+}
+
+// ListRecipes returns the recipes selected by filter ordered by name.
+func (rr *RecipeRepository) ListRecipes(ctx context.Context, filter domain.RecipeFilter) ([]domain.Recipe, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	rr.mu.RLock()
+	defer rr.mu.RUnlock()
+
+	recipes := make([]domain.Recipe, 0, len(rr.recipes))
+	for _, recipe := range rr.recipes {
+		selected := recipe.DeletedAt == nil && (filter.IncludeArchived || recipe.ArchivedAt == nil)
+		if filter.Deleted {
+			selected = recipe.DeletedAt != nil
+		}
+		if selected {
+			recipes = append(recipes, cloneRecipe(recipe))
+		}
+	}
+	slices.SortFunc(recipes, func(a, b domain.Recipe) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Id, b.Id))
+	})
+	return recipes, nil
+}
+
+// CreateRecipe stores a new recipe at version 1.
+func (rr *RecipeRepository) CreateRecipe(ctx context.Context, recipe domain.Recipe) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	if _, found := rr.recipes[recipe.Uuid]; found {
+		return fmt.Errorf("recipe %s already exists", recipe.Uuid)
+	}
+	recipe.Id, recipe.Version = 0, 1
+	rr.save(recipe)
+	return nil
+}
+
+// UpdateRecipe replaces the recipe with the same UUID and bumps its version,
+// provided it is still at recipe.Version; a zero version matches any.
+func (rr *RecipeRepository) UpdateRecipe(ctx context.Context, recipe domain.Recipe) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	recipe = cloneRecipe(recipe)
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	current, err := rr.current(recipe.Uuid, recipe.Version)
+	if err != nil {
+		return err
+	}
+	recipe.Id = current.Id
+	recipe.Version = current.Version + 1
+	recipe.ArchivedAt = current.ArchivedAt
+	// Updates leave the steps alone, as in the database.
+	recipe.Steps = current.Steps
+	rr.link(recipe.Dough.Ingredients)
+	rr.link(recipe.Topping.Ingredients)
+	rr.recipes[recipe.Uuid] = recipe
+	return nil
+}
+
+// DeleteRecipe marks the recipe as deleted, provided it is still at
+// version; a zero version matches any.
+func (rr *RecipeRepository) DeleteRecipe(ctx context.Context, recipeUuid uuid.UUID, version int) error {
+	return rr.change(ctx, recipeUuid, version, func(recipe *domain.Recipe) {
+		now := time.Now().UTC()
+		recipe.DeletedAt = &now
+	})
+}
+
+// ArchiveRecipe archives the recipe or brings it back to the listings,
+// provided it is still at version; a zero version matches any.
+func (rr *RecipeRepository) ArchiveRecipe(ctx context.Context, recipeUuid uuid.UUID, version int, archived bool) error {
+	return rr.change(ctx, recipeUuid, version, func(recipe *domain.Recipe) {
+		switch {
+		case !archived:
+			recipe.ArchivedAt = nil
+		case recipe.ArchivedAt == nil:
+			now := time.Now().UTC()
+			recipe.ArchivedAt = &now
+		}
+	})
+}
+
+// RestoreRecipe brings back a deleted recipe.
+func (rr *RecipeRepository) RestoreRecipe(ctx context.Context, recipeUuid uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	recipe, found := rr.recipes[recipeUuid]
+	if !found || recipe.DeletedAt == nil {
+		return fmt.Errorf("deleted recipe %s: %w", recipeUuid, domain.ErrRecipeNotFound)
+	}
+	recipe.DeletedAt = nil
+	recipe.Version++
+	rr.recipes[recipeUuid] = recipe
+	return nil
+}
+
+func (rr *RecipeRepository) change(ctx context.Context, recipeUuid uuid.UUID, version int, apply func(*domain.Recipe)) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	recipe, err := rr.current(recipeUuid, version)
+	if err != nil {
+		return err
+	}
+	apply(&recipe)
+	recipe.Version++
+	rr.recipes[recipeUuid] = recipe
+	return nil
+}
+
+// current returns the stored recipe if it is at version and not deleted.
+// The caller must hold the lock.
+func (rr *RecipeRepository) current(recipeUuid uuid.UUID, version int) (domain.Recipe, error) {
+	recipe, found := rr.recipes[recipeUuid]
+	if !found || recipe.DeletedAt != nil {
+		return domain.Recipe{}, fmt.Errorf("recipe %s: %w", recipeUuid, domain.ErrRecipeNotFound)
+	}
+	if version != 0 && recipe.Version != version {
+		return domain.Recipe{}, fmt.Errorf("recipe %s is at version %d, not %d: %w",
+			recipeUuid, recipe.Version, version, domain.ErrVersionConflict)
+	}
+	return recipe, nil
+}
+
+// link sets the catalogue entry of each ingredient. Ingredients missing from
+// the catalogue get an empty entry, like those stored without catalogue data.
+func (rr *RecipeRepository) link(ingredients []domain.Ingredient) {
+	for i := range ingredients {
+		entry, found := rr.catalogue[strings.ToLower(ingredients[i].Name)]
+		if !found {
+			entry = &domain.CatalogueIngredient{Name: ingredients[i].Name}
+		}
+		ingredients[i].Name = entry.Name
+		ingredients[i].Catalogue = entry
+	}
+}
+
+func cloneRecipe(recipe domain.Recipe) domain.Recipe {
+	recipe.Dough.Ingredients = slices.Clone(recipe.Dough.Ingredients)
+	recipe.Topping.Ingredients = slices.Clone(recipe.Topping.Ingredients)
+	recipe.Steps.Steps = slices.Clone(recipe.Steps.Steps)
+	return recipe
+}
@@ -0,0 +1,223 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+)
+
+func TestRecipeRepository(t *testing.T) {
+	ctx := context.Background()
+	mozzarella := domain.CatalogueIngredient{
+		Name:      "mozzarellaCheese",
+		Aliases:   []string{"mozzarella"},
+		Category:  "dairy",
+		Allergens: []domain.Allergen{domain.AllergenMilk},
+	}
+	margherita := domain.Recipe{
+		Uuid: uuid.New(),
+		Name: "Margherita",
+		Dough: domain.Dough{
+			Ingredients: []domain.Ingredient{{Name: "flour", Amount: 60, Unit: "%"}},
+		},
+		Topping: domain.Topping{
+			ReferenceArea: 1200,
+			Ingredients:   []domain.Ingredient{{Name: "Mozzarella", Amount: 250, Unit: "g"}},
+		},
+	}
+	marinara := domain.Recipe{Uuid: uuid.New(), Name: "Marinara"}
+
+	repository := NewRecipeRepository(WithCatalogue([]domain.CatalogueIngredient{mozzarella}))
+	repository.Save(margherita)
+	repository.Save(marinara)
+
+	t.Run("links ingredients to the catalogue by alias", func(t *testing.T) {
+		recipe, err := repository.GetRecipeByUuid(ctx, margherita.Uuid)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, recipe.Id)
+		topping := recipe.Topping.Ingredients[0]
+		assert.Equal(t, "mozzarellaCheese", topping.Name)
+		assert.Equal(t, "dairy", topping.Catalogue.Category)
+		assert.Equal(t, []domain.Allergen{domain.AllergenMilk}, recipe.Labels().Allergens)
+		assert.Equal(t, []string{"flour"}, recipe.Labels().Unlabelled)
+	})
+
+	t.Run("returns copies", func(t *testing.T) {
+		recipe, err := repository.GetRecipeByUuid(ctx, margherita.Uuid)
+		require.NoError(t, err)
+		recipe.Dough.Ingredients[0].Amount = 0
+
+		stored, err := repository.GetRecipeByUuid(ctx, margherita.Uuid)
+		require.NoError(t, err)
+		assert.Equal(t, float64(60), stored.Dough.Ingredients[0].Amount)
+	})
+
+	t.Run("replaces a recipe with the same uuid", func(t *testing.T) {
+		renamed := marinara
+		renamed.Name = "Marinara DOC"
+		repository.Save(renamed)
+
+		recipe, err := repository.GetRecipeByUuid(ctx, marinara.Uuid)
+		require.NoError(t, err)
+		assert.Equal(t, 2, recipe.Id)
+		assert.Equal(t, "Marinara DOC", recipe.Name)
+	})
+
+	t.Run("lists recipes by name", func(t *testing.T) {
+		recipes, err := repository.ListRecipes(ctx, domain.RecipeFilter{})
+		require.NoError(t, err)
+
+		if assert.Len(t, recipes, 2) {
+			assert.Equal(t, "Margherita", recipes[0].Name)
+			assert.Equal(t, "Marinara DOC", recipes[1].Name)
+		}
+	})
+
+	t.Run("creates a recipe once", func(t *testing.T) {
+		bianca := domain.Recipe{Uuid: uuid.New(), Name: "Bianca", Version: 7}
+		require.NoError(t, repository.CreateRecipe(ctx, bianca))
+		assert.Error(t, repository.CreateRecipe(ctx, bianca))
+
+		recipe, err := repository.GetRecipeByUuid(ctx, bianca.Uuid)
+		require.NoError(t, err)
+		assert.Equal(t, 3, recipe.Id)
+		assert.Equal(t, 1, recipe.Version)
+		require.NoError(t, repository.DeleteRecipe(ctx, bianca.Uuid, 1))
+	})
+
+	t.Run("returns a batch of recipes leaving out the missing and deleted ones", func(t *testing.T) {
+		deleted := domain.Recipe{Uuid: uuid.New(), Name: "Capricciosa"}
+		require.NoError(t, repository.CreateRecipe(ctx, deleted))
+		require.NoError(t, repository.DeleteRecipe(ctx, deleted.Uuid, 1))
+
+		recipes, err := repository.GetRecipesByUuids(ctx, []uuid.UUID{marinara.Uuid, uuid.New(), deleted.Uuid, margherita.Uuid, marinara.Uuid})
+		require.NoError(t, err)
+
+		if assert.Len(t, recipes, 2) {
+			assert.Equal(t, "Margherita", recipes[0].Name)
+			assert.Equal(t, "Marinara DOC", recipes[1].Name)
+		}
+	})
+
+	t.Run("reports a missing recipe", func(t *testing.T) {
+		recipe, err := repository.GetRecipeByUuid(ctx, uuid.New())
+
+		assert.ErrorIs(t, err, domain.ErrRecipeNotFound)
+		assert.Nil(t, recipe)
+	})
+
+	t.Run("honours a cancelled context", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := repository.ListRecipes(cancelled, domain.RecipeFilter{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestRecipeRepositoryConcurrentAccess(t *testing.T) {
+	repository := NewRecipeRepository()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			repository.Save(domain.Recipe{Uuid: uuid.New(), Name: "Recipe", Dough: domain.Dough{
+				Ingredients: []domain.Ingredient{{Name: "flour", Amount: float64(i)}},
+			}})
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := repository.ListRecipes(ctx, domain.RecipeFilter{})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	recipes, err := repository.ListRecipes(ctx, domain.RecipeFilter{})
+	require.NoError(t, err)
+	assert.Len(t, recipes, 20)
+	ids := make(map[int]bool)
+	for _, recipe := range recipes {
+		ids[recipe.Id] = true
+	}
+	assert.Len(t, ids, 20)
+}
+
+func TestRecipeRepositoryVersions(t *testing.T) {
+	ctx := context.Background()
+	repository := NewRecipeRepository()
+	recipe := domain.Recipe{Uuid: uuid.New(), Name: "Margherita"}
+	repository.Save(recipe)
+
+	t.Run("only one of concurrent updates at the same version wins", func(t *testing.T) {
+		var wg sync.WaitGroup
+		errs := make(chan error, 10)
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				update := recipe
+				update.Version = 1
+				errs <- repository.UpdateRecipe(ctx, update)
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		succeeded := 0
+		for err := range errs {
+			if err == nil {
+				succeeded++
+				continue
+			}
+			assert.ErrorIs(t, err, domain.ErrVersionConflict)
+		}
+		assert.Equal(t, 1, succeeded)
+
+		stored, err := repository.GetRecipeByUuid(ctx, recipe.Uuid)
+		require.NoError(t, err)
+		assert.Equal(t, 2, stored.Version)
+	})
+
+	t.Run("deletes a recipe at the expected version", func(t *testing.T) {
+		assert.ErrorIs(t, repository.DeleteRecipe(ctx, recipe.Uuid, 1), domain.ErrVersionConflict)
+		require.NoError(t, repository.DeleteRecipe(ctx, recipe.Uuid, 2))
+		assert.ErrorIs(t, repository.DeleteRecipe(ctx, recipe.Uuid, 0), domain.ErrRecipeNotFound)
+
+		deleted, err := repository.ListRecipes(ctx, domain.RecipeFilter{Deleted: true})
+		require.NoError(t, err)
+		assert.Len(t, deleted, 1)
+	})
+
+	t.Run("restores a deleted recipe", func(t *testing.T) {
+		require.NoError(t, repository.RestoreRecipe(ctx, recipe.Uuid))
+		assert.ErrorIs(t, repository.RestoreRecipe(ctx, recipe.Uuid), domain.ErrRecipeNotFound)
+
+		stored, err := repository.GetRecipeByUuid(ctx, recipe.Uuid)
+		require.NoError(t, err)
+		assert.Equal(t, 4, stored.Version)
+	})
+
+	t.Run("archives a recipe out of the listings", func(t *testing.T) {
+		require.NoError(t, repository.ArchiveRecipe(ctx, recipe.Uuid, 4, true))
+
+		active, err := repository.ListRecipes(ctx, domain.RecipeFilter{})
+		require.NoError(t, err)
+		assert.Empty(t, active)
+		all, err := repository.ListRecipes(ctx, domain.RecipeFilter{IncludeArchived: true})
+		require.NoError(t, err)
+		if assert.Len(t, all, 1) {
+			assert.NotNil(t, all[0].ArchivedAt)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"time"
+
+	domainMetrics "github.com/cfioretti/recipe-manager/internal/recipe-manager/domain/metrics"
+)
+
+// CompositeMetrics fans every call out to several RecipeMetrics
+// implementations, e.g. Prometheus and OpenTelemetry while migrating
+// dashboards from one to the other.
+type CompositeMetrics struct {
+	delegates []domainMetrics.RecipeMetrics
+}
+
+func NewCompositeMetrics(delegates ...domainMetrics.RecipeMetrics) *CompositeMetrics {
+	return &CompositeMetrics{delegates: delegates}
+}
+
+func (c *CompositeMetrics) IncrementRecipeRetrievals(recipeUuid string) {
+	for _, d := range c.delegates {
+		d.IncrementRecipeRetrievals(recipeUuid)
+	}
+}
+
+func (c *CompositeMetrics) RecordRecipeRetrievalDuration(duration time.Duration) {
+	for _, d := range c.delegates {
+		d.RecordRecipeRetrievalDuration(duration)
+	}
+}
+
+func (c *CompositeMetrics) IncrementRecipeRetrievalErrors(errorType string) {
+	for _, d := range c.delegates {
+		d.IncrementRecipeRetrievalErrors(errorType)
+	}
+}
+
+func (c *CompositeMetrics) IncrementRecipeAggregations(recipeType string) {
+	for _, d := range c.delegates {
+		d.IncrementRecipeAggregations(recipeType)
+	}
+}
+
+func (c *CompositeMetrics) RecordRecipeAggregationDuration(duration time.Duration) {
+	for _, d := range c.delegates {
+		d.RecordRecipeAggregationDuration(duration)
+	}
+}
+
+func (c *CompositeMetrics) IncrementRecipeAggregationErrors(errorType string) {
+	for _, d := range c.delegates {
+		d.IncrementRecipeAggregationErrors(errorType)
+	}
+}
+
+func (c *CompositeMetrics) IncrementCalculatorServiceCalls(success bool) {
+	for _, d := range c.delegates {
+		d.IncrementCalculatorServiceCalls(success)
+	}
+}
+
+func (c *CompositeMetrics) RecordCalculatorServiceDuration(duration time.Duration) {
+	for _, d := range c.delegates {
+		d.RecordCalculatorServiceDuration(duration)
+	}
+}
+
+func (c *CompositeMetrics) IncrementBalancerServiceCalls(success bool) {
+	for _, d := range c.delegates {
+		d.IncrementBalancerServiceCalls(success)
+	}
+}
+
+func (c *CompositeMetrics) RecordBalancerServiceDuration(duration time.Duration) {
+	for _, d := range c.delegates {
+		d.RecordBalancerServiceDuration(duration)
+	}
+}
+
+func (c *CompositeMetrics) IncrementDatabaseOperations(operation string, success bool) {
+	for _, d := range c.delegates {
+		d.IncrementDatabaseOperations(operation, success)
+	}
+}
+
+func (c *CompositeMetrics) RecordDatabaseOperationDuration(operation string, duration time.Duration) {
+	for _, d := range c.delegates {
+		d.RecordDatabaseOperationDuration(operation, duration)
+	}
+}
+
+func (c *CompositeMetrics) IncrementHTTPRequests(method string, endpoint string, statusCode int) {
+	for _, d := range c.delegates {
+		d.IncrementHTTPRequests(method, endpoint, statusCode)
+	}
+}
+
+func (c *CompositeMetrics) RecordHTTPRequestDuration(method string, endpoint string, duration time.Duration) {
+	for _, d := range c.delegates {
+		d.RecordHTTPRequestDuration(method, endpoint, duration)
+	}
+}
+
+func (c *CompositeMetrics) SetActiveHTTPConnections(count int) {
+	for _, d := range c.delegates {
+		d.SetActiveHTTPConnections(count)
+	}
+}
+
+func (c *CompositeMetrics) IncrementRecipesByAuthor(author string) {
+	for _, d := range c.delegates {
+		d.IncrementRecipesByAuthor(author)
+	}
+}
+
+func (c *CompositeMetrics) RecordRecipeComplexity(complexity int) {
+	for _, d := range c.delegates {
+		d.RecordRecipeComplexity(complexity)
+	}
+}
+
+func (c *CompositeMetrics) IncrementPansSizes(panSize string) {
+	for _, d := range c.delegates {
+		d.IncrementPansSizes(panSize)
+	}
+}
+
+func (c *CompositeMetrics) RecordIngredientVariations(variationCount int) {
+	for _, d := range c.delegates {
+		d.RecordIngredientVariations(variationCount)
+	}
+}
+
+var _ domainMetrics.RecipeMetrics = (*CompositeMetrics)(nil)
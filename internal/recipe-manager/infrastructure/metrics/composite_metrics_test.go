@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompositeMetricsFansOut(t *testing.T) {
+	first := NewPrometheusMetricsWithRegistry(prometheus.NewRegistry())
+	second := NewPrometheusMetricsWithRegistry(prometheus.NewRegistry())
+
+	composite := NewCompositeMetrics(first, second)
+	composite.IncrementRecipeRetrievals("test-uuid")
+	composite.IncrementDatabaseOperations("SELECT", true)
+
+	for _, delegate := range []*PrometheusMetrics{first, second} {
+		assert.Equal(t, 1.0, testutil.ToFloat64(delegate.recipeRetrievalsTotal.WithLabelValues("test-uuid")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(delegate.databaseOperationsTotal.WithLabelValues("SELECT", "true")))
+	}
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type DBStatsProvider interface {
+	Stats() sql.DBStats
+}
+
+// DBStatsCollector exposes the connection pool statistics of a *sql.DB.
+// Values are read from DBStats on every scrape, so nothing has to be updated
+// in the request path.
+type DBStatsCollector struct {
+	db DBStatsProvider
+
+	maxOpenConnections *prometheus.Desc
+	openConnections    *prometheus.Desc
+	inUseConnections   *prometheus.Desc
+	idleConnections    *prometheus.Desc
+	waitCount          *prometheus.Desc
+	waitDuration       *prometheus.Desc
+	maxIdleClosed      *prometheus.Desc
+	maxIdleTimeClosed  *prometheus.Desc
+	maxLifetimeClosed  *prometheus.Desc
+}
+
+func NewDBStatsCollector(db DBStatsProvider, dbName string) *DBStatsCollector {
+	labels := prometheus.Labels{"db_name": dbName}
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("recipe_manager", "db", name), help, nil, labels)
+	}
+
+	return &DBStatsCollector{
+		db:                 db,
+		maxOpenConnections: desc("max_open_connections", "Maximum number of open connections to the database"),
+		openConnections:    desc("open_connections", "Number of established connections, in use and idle"),
+		inUseConnections:   desc("in_use_connections", "Number of connections currently in use"),
+		idleConnections:    desc("idle_connections", "Number of idle connections"),
+		waitCount:          desc("wait_count_total", "Total number of connections waited for"),
+		waitDuration:       desc("wait_duration_seconds_total", "Total time blocked waiting for a new connection"),
+		maxIdleClosed:      desc("max_idle_closed_total", "Total number of connections closed due to SetMaxIdleConns"),
+		maxIdleTimeClosed:  desc("max_idle_time_closed_total", "Total number of connections closed due to SetConnMaxIdleTime"),
+		maxLifetimeClosed:  desc("max_lifetime_closed_total", "Total number of connections closed due to SetConnMaxLifetime"),
+	}
+}
+
+func (c *DBStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxOpenConnections
+	ch <- c.openConnections
+	ch <- c.inUseConnections
+	ch <- c.idleConnections
+	ch <- c.waitCount
+	ch <- c.waitDuration
+	ch <- c.maxIdleClosed
+	ch <- c.maxIdleTimeClosed
+	ch <- c.maxLifetimeClosed
+}
+
+func (c *DBStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.db.Stats()
+
+	ch <- prometheus.MustNewConstMetric(c.maxOpenConnections, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.openConnections, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.inUseConnections, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(c.idleConnections, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.maxIdleClosed, prometheus.CounterValue, float64(stats.MaxIdleClosed))
+	ch <- prometheus.MustNewConstMetric(c.maxIdleTimeClosed, prometheus.CounterValue, float64(stats.MaxIdleTimeClosed))
+	ch <- prometheus.MustNewConstMetric(c.maxLifetimeClosed, prometheus.CounterValue, float64(stats.MaxLifetimeClosed))
+}
+
+var _ prometheus.Collector = (*DBStatsCollector)(nil)
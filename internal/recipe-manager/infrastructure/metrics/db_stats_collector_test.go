@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeDBStats struct {
+	stats sql.DBStats
+}
+
+func (f fakeDBStats) Stats() sql.DBStats {
+	return f.stats
+}
+
+func TestDBStatsCollector(t *testing.T) {
+	collector := NewDBStatsCollector(fakeDBStats{stats: sql.DBStats{
+		MaxOpenConnections: 25,
+		OpenConnections:    7,
+		InUse:              5,
+		Idle:               2,
+		WaitCount:          3,
+		WaitDuration:       1500 * time.Millisecond,
+		MaxIdleClosed:      4,
+		MaxIdleTimeClosed:  1,
+		MaxLifetimeClosed:  6,
+	}}, "pizzamaker")
+
+	expected := `
+# HELP recipe_manager_db_in_use_connections Number of connections currently in use
+# TYPE recipe_manager_db_in_use_connections gauge
+recipe_manager_db_in_use_connections{db_name="pizzamaker"} 5
+# HELP recipe_manager_db_open_connections Number of established connections, in use and idle
+# TYPE recipe_manager_db_open_connections gauge
+recipe_manager_db_open_connections{db_name="pizzamaker"} 7
+# HELP recipe_manager_db_wait_duration_seconds_total Total time blocked waiting for a new connection
+# TYPE recipe_manager_db_wait_duration_seconds_total counter
+recipe_manager_db_wait_duration_seconds_total{db_name="pizzamaker"} 1.5
+`
+	err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"recipe_manager_db_in_use_connections",
+		"recipe_manager_db_open_connections",
+		"recipe_manager_db_wait_duration_seconds_total",
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 9, testutil.CollectAndCount(collector))
+}
@@ -0,0 +1,216 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	domainMetrics "github.com/cfioretti/recipe-manager/internal/recipe-manager/domain/metrics"
+)
+
+// OTelMetrics records the same business and technical metrics as
+// PrometheusMetrics through an OpenTelemetry Meter, so they can be pushed to
+// an OTLP collector together with traces and logs. Instrument names mirror
+// the Prometheus ones without the unit and _total suffixes, which exporters
+// append on their own.
+type OTelMetrics struct {
+	// Recipe Operations
+	recipeRetrievalsTotal      metric.Int64Counter
+	recipeRetrievalDuration    metric.Float64Histogram
+	recipeRetrievalErrorsTotal metric.Int64Counter
+
+	// Recipe Aggregation
+	recipeAggregationsTotal      metric.Int64Counter
+	recipeAggregationDuration    metric.Float64Histogram
+	recipeAggregationErrorsTotal metric.Int64Counter
+
+	// External Service Calls
+	calculatorServiceCallsTotal metric.Int64Counter
+	calculatorServiceDuration   metric.Float64Histogram
+	balancerServiceCallsTotal   metric.Int64Counter
+	balancerServiceDuration     metric.Float64Histogram
+
+	// Database Operations
+	databaseOperationsTotal   metric.Int64Counter
+	databaseOperationDuration metric.Float64Histogram
+
+	// HTTP Request Metrics
+	httpRequestsTotal     metric.Int64Counter
+	httpRequestDuration   metric.Float64Histogram
+	activeHTTPConnections metric.Int64Gauge
+
+	// Business Domain Metrics
+	recipesByAuthor      metric.Int64Counter
+	recipeComplexity     metric.Int64Histogram
+	pansSizes            metric.Int64Counter
+	ingredientVariations metric.Int64Histogram
+}
+
+var (
+	fastOperationBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1.0, 2.5, 5.0, 10.0}
+	slowOperationBuckets = []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1.0, 2.5, 5.0, 10.0, 30.0, 60.0}
+	remoteCallBuckets    = []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1.0, 2.5, 5.0, 10.0, 30.0}
+	databaseBuckets      = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1.0, 2.5, 5.0}
+	complexityBuckets    = []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	variationBuckets     = []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 15, 20, 25, 30}
+)
+
+func NewOTelMetrics(meter metric.Meter) (*OTelMetrics, error) {
+	var errs []error
+	counter := func(name, description string) metric.Int64Counter {
+		instrument, err := meter.Int64Counter(name, metric.WithDescription(description))
+		errs = append(errs, err)
+		return instrument
+	}
+	duration := func(name, description string, buckets []float64) metric.Float64Histogram {
+		instrument, err := meter.Float64Histogram(name,
+			metric.WithDescription(description),
+			metric.WithUnit("s"),
+			metric.WithExplicitBucketBoundaries(buckets...),
+		)
+		errs = append(errs, err)
+		return instrument
+	}
+	histogram := func(name, description string, buckets []float64) metric.Int64Histogram {
+		instrument, err := meter.Int64Histogram(name,
+			metric.WithDescription(description),
+			metric.WithExplicitBucketBoundaries(buckets...),
+		)
+		errs = append(errs, err)
+		return instrument
+	}
+
+	activeHTTPConnections, err := meter.Int64Gauge(
+		"recipe_manager_active_http_connections",
+		metric.WithDescription("Number of active HTTP connections"),
+	)
+	errs = append(errs, err)
+
+	m := &OTelMetrics{
+		recipeRetrievalsTotal:      counter("recipe_manager_recipe_retrievals", "Total number of recipe retrievals by UUID"),
+		recipeRetrievalDuration:    duration("recipe_manager_recipe_retrieval_duration", "Duration of recipe retrieval operations", fastOperationBuckets),
+		recipeRetrievalErrorsTotal: counter("recipe_manager_recipe_retrieval_errors", "Total number of recipe retrieval errors by type"),
+
+		recipeAggregationsTotal:      counter("recipe_manager_recipe_aggregations", "Total number of recipe aggregations by type"),
+		recipeAggregationDuration:    duration("recipe_manager_recipe_aggregation_duration", "Duration of recipe aggregation operations", slowOperationBuckets),
+		recipeAggregationErrorsTotal: counter("recipe_manager_recipe_aggregation_errors", "Total number of recipe aggregation errors by type"),
+
+		calculatorServiceCallsTotal: counter("recipe_manager_calculator_service_calls", "Total number of calculator service calls"),
+		calculatorServiceDuration:   duration("recipe_manager_calculator_service_duration", "Duration of calculator service calls", remoteCallBuckets),
+		balancerServiceCallsTotal:   counter("recipe_manager_balancer_service_calls", "Total number of balancer service calls"),
+		balancerServiceDuration:     duration("recipe_manager_balancer_service_duration", "Duration of balancer service calls", remoteCallBuckets),
+
+		databaseOperationsTotal:   counter("recipe_manager_database_operations", "Total number of database operations"),
+		databaseOperationDuration: duration("recipe_manager_database_operation_duration", "Duration of database operations", databaseBuckets),
+
+		httpRequestsTotal:     counter("recipe_manager_http_requests", "Total number of HTTP requests"),
+		httpRequestDuration:   duration("recipe_manager_http_request_duration", "Duration of HTTP requests", fastOperationBuckets),
+		activeHTTPConnections: activeHTTPConnections,
+
+		recipesByAuthor:      counter("recipe_manager_recipes_by_author", "Total number of recipes by author"),
+		recipeComplexity:     histogram("recipe_manager_recipe_complexity", "Recipe complexity score", complexityBuckets),
+		pansSizes:            counter("recipe_manager_pans_sizes", "Total number of pans by size"),
+		ingredientVariations: histogram("recipe_manager_ingredient_variations", "Number of ingredient variations per recipe", variationBuckets),
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (o *OTelMetrics) IncrementRecipeRetrievals(recipeUuid string) {
+	o.recipeRetrievalsTotal.Add(context.Background(), 1, withAttributes(attribute.String("recipe_uuid", recipeUuid)))
+}
+
+func (o *OTelMetrics) RecordRecipeRetrievalDuration(duration time.Duration) {
+	o.recipeRetrievalDuration.Record(context.Background(), duration.Seconds())
+}
+
+func (o *OTelMetrics) IncrementRecipeRetrievalErrors(errorType string) {
+	o.recipeRetrievalErrorsTotal.Add(context.Background(), 1, withAttributes(attribute.String("error_type", errorType)))
+}
+
+func (o *OTelMetrics) IncrementRecipeAggregations(recipeType string) {
+	o.recipeAggregationsTotal.Add(context.Background(), 1, withAttributes(attribute.String("recipe_type", recipeType)))
+}
+
+func (o *OTelMetrics) RecordRecipeAggregationDuration(duration time.Duration) {
+	o.recipeAggregationDuration.Record(context.Background(), duration.Seconds())
+}
+
+func (o *OTelMetrics) IncrementRecipeAggregationErrors(errorType string) {
+	o.recipeAggregationErrorsTotal.Add(context.Background(), 1, withAttributes(attribute.String("error_type", errorType)))
+}
+
+func (o *OTelMetrics) IncrementCalculatorServiceCalls(success bool) {
+	o.calculatorServiceCallsTotal.Add(context.Background(), 1, withAttributes(attribute.Bool("success", success)))
+}
+
+func (o *OTelMetrics) RecordCalculatorServiceDuration(duration time.Duration) {
+	o.calculatorServiceDuration.Record(context.Background(), duration.Seconds())
+}
+
+func (o *OTelMetrics) IncrementBalancerServiceCalls(success bool) {
+	o.balancerServiceCallsTotal.Add(context.Background(), 1, withAttributes(attribute.Bool("success", success)))
+}
+
+func (o *OTelMetrics) RecordBalancerServiceDuration(duration time.Duration) {
+	o.balancerServiceDuration.Record(context.Background(), duration.Seconds())
+}
+
+func (o *OTelMetrics) IncrementDatabaseOperations(operation string, success bool) {
+	o.databaseOperationsTotal.Add(context.Background(), 1, withAttributes(
+		attribute.String("operation", operation),
+		attribute.Bool("success", success),
+	))
+}
+
+func (o *OTelMetrics) RecordDatabaseOperationDuration(operation string, duration time.Duration) {
+	o.databaseOperationDuration.Record(context.Background(), duration.Seconds(), withAttributes(attribute.String("operation", operation)))
+}
+
+func (o *OTelMetrics) IncrementHTTPRequests(method string, endpoint string, statusCode int) {
+	o.httpRequestsTotal.Add(context.Background(), 1, withAttributes(
+		attribute.String("method", method),
+		attribute.String("endpoint", endpoint),
+		attribute.String("status_code", strconv.Itoa(statusCode)),
+	))
+}
+
+func (o *OTelMetrics) RecordHTTPRequestDuration(method string, endpoint string, duration time.Duration) {
+	o.httpRequestDuration.Record(context.Background(), duration.Seconds(), withAttributes(
+		attribute.String("method", method),
+		attribute.String("endpoint", endpoint),
+	))
+}
+
+func (o *OTelMetrics) SetActiveHTTPConnections(count int) {
+	o.activeHTTPConnections.Record(context.Background(), int64(count))
+}
+
+func (o *OTelMetrics) IncrementRecipesByAuthor(author string) {
+	o.recipesByAuthor.Add(context.Background(), 1, withAttributes(attribute.String("author", author)))
+}
+
+func (o *OTelMetrics) RecordRecipeComplexity(complexity int) {
+	o.recipeComplexity.Record(context.Background(), int64(complexity))
+}
+
+func (o *OTelMetrics) IncrementPansSizes(panSize string) {
+	o.pansSizes.Add(context.Background(), 1, withAttributes(attribute.String("pan_size", panSize)))
+}
+
+func (o *OTelMetrics) RecordIngredientVariations(variationCount int) {
+	o.ingredientVariations.Record(context.Background(), int64(variationCount))
+}
+
+func withAttributes(attributes ...attribute.KeyValue) metric.MeasurementOption {
+	return metric.WithAttributes(attributes...)
+}
+
+var _ domainMetrics.RecipeMetrics = (*OTelMetrics)(nil)
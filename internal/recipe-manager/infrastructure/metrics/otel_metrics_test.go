@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestOTelMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+
+	metrics, err := NewOTelMetrics(provider.Meter("test"))
+	require.NoError(t, err)
+
+	metrics.IncrementRecipeRetrievals("test-uuid")
+	metrics.IncrementRecipeRetrievals("test-uuid")
+	metrics.RecordRecipeRetrievalDuration(10 * time.Millisecond)
+	metrics.IncrementCalculatorServiceCalls(true)
+	metrics.IncrementHTTPRequests("POST", "/recipes/:uuid/aggregate", 200)
+	metrics.SetActiveHTTPConnections(3)
+	metrics.RecordRecipeComplexity(5)
+
+	var collected metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &collected))
+	require.Len(t, collected.ScopeMetrics, 1)
+
+	byName := map[string]metricdata.Metrics{}
+	for _, m := range collected.ScopeMetrics[0].Metrics {
+		byName[m.Name] = m
+	}
+
+	retrievals, ok := byName["recipe_manager_recipe_retrievals"].Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	require.Len(t, retrievals.DataPoints, 1)
+	assert.Equal(t, int64(2), retrievals.DataPoints[0].Value)
+	uuidLabel, _ := retrievals.DataPoints[0].Attributes.Value("recipe_uuid")
+	assert.Equal(t, "test-uuid", uuidLabel.AsString())
+
+	duration, ok := byName["recipe_manager_recipe_retrieval_duration"].Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	assert.Equal(t, uint64(1), duration.DataPoints[0].Count)
+	assert.Equal(t, "s", byName["recipe_manager_recipe_retrieval_duration"].Unit)
+
+	connections, ok := byName["recipe_manager_active_http_connections"].Data.(metricdata.Gauge[int64])
+	require.True(t, ok)
+	assert.Equal(t, int64(3), connections.DataPoints[0].Value)
+
+	assert.Contains(t, byName, "recipe_manager_calculator_service_calls")
+	assert.Contains(t, byName, "recipe_manager_http_requests")
+	assert.Contains(t, byName, "recipe_manager_recipe_complexity")
+}
@@ -1,39 +0,0 @@
-package migrations
-
-import (
-	"database/sql"
-	"errors"
-	"fmt"
-
-	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/mysql"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
-)
-
-func RunMigrations(dsn, migrationsPath string, dbName string) error {
-	db, err := sql.Open("mysql", dsn)
-	if err != nil {
-		return fmt.Errorf("error connecting to the database: %v", err)
-	}
-	defer db.Close()
-
-	driver, err := mysql.WithInstance(db, &mysql.Config{})
-	if err != nil {
-		return fmt.Errorf("error creating the driver for migrations: %v", err)
-	}
-
-	m, err := migrate.NewWithDatabaseInstance(
-		fmt.Sprintf("file://%s", migrationsPath),
-		dbName,
-		driver,
-	)
-	if err != nil {
-		return fmt.Errorf("error configuring the migrations: %v", err)
-	}
-
-	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		return fmt.Errorf("error running migrations: %w", err)
-	}
-
-	return nil
-}
@@ -0,0 +1,76 @@
+// Package outbox publishes the domain events stored in the transactional
+// outbox. Events are stored as messages in the same transaction as the change
+// they describe, and the Relay hands them to a Sink until it accepts them, so
+// each is delivered at least once. Consumers drop redeliveries by message id.
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+)
+
+// Message is an event as stored and published.
+type Message struct {
+	// Id is the id of the event, the idempotency key of every delivery.
+	Id   uuid.UUID
+	Type domain.EventType
+	// Key is the UUID of the recipe, which brokers can partition by to keep
+	// the events of a recipe in order.
+	Key  string
+	Body []byte
+	// Attempts counts the failed deliveries so far.
+	Attempts int
+}
+
+type body struct {
+	Id         uuid.UUID        `json:"id"`
+	Type       domain.EventType `json:"type"`
+	OccurredAt time.Time        `json:"occurredAt"`
+	Recipe     recipe           `json:"recipe"`
+	Pans       []pan            `json:"pans,omitempty"`
+}
+
+type recipe struct {
+	Uuid     uuid.UUID `json:"uuid"`
+	Name     string    `json:"name"`
+	Author   string    `json:"author,omitempty"`
+	Version  int       `json:"version"`
+	Archived bool      `json:"archived"`
+	Deleted  bool      `json:"deleted"`
+}
+
+type pan struct {
+	Shape  string  `json:"shape"`
+	Area   float64 `json:"area"`
+	Slices int     `json:"slices,omitempty"`
+}
+
+// Encode turns an event into the message stored in the outbox.
+func Encode(event domain.Event) (Message, error) {
+	content := body{
+		Id:         event.Id,
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt,
+		Recipe: recipe{
+			Uuid:     event.Recipe.Uuid,
+			Name:     event.Recipe.Name,
+			Author:   event.Recipe.Author,
+			Version:  event.Recipe.Version,
+			Archived: event.Recipe.ArchivedAt != nil,
+			Deleted:  event.Recipe.DeletedAt != nil,
+		},
+	}
+	for _, p := range event.Pans.Pans {
+		content.Pans = append(content.Pans, pan{Shape: p.Shape, Area: p.Area, Slices: p.Slices})
+	}
+
+	encoded, err := json.Marshal(content)
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{Id: event.Id, Type: event.Type, Key: event.Recipe.Uuid.String(), Body: encoded}, nil
+}
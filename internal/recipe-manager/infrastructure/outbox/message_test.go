@@ -0,0 +1,61 @@
+package outbox
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+)
+
+func TestEncode(t *testing.T) {
+	recipeUuid := uuid.MustParse("4b8c3c4e-9a4f-4a43-9d0e-2d5f3c7b1a10")
+	eventId := uuid.MustParse("0f6f1a52-31a5-4c5e-8a3e-7b0c1a2d3e4f")
+	deletedAt := time.Date(2026, time.October, 1, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		event    domain.Event
+		expected string
+	}{
+		{
+			name: "deleted recipe",
+			event: domain.Event{
+				Id:         eventId,
+				Type:       domain.EventRecipeDeleted,
+				OccurredAt: deletedAt,
+				Recipe:     domain.Recipe{Id: 7, Uuid: recipeUuid, Name: "Marinara", Version: 4, DeletedAt: &deletedAt},
+			},
+			expected: `{"id":"0f6f1a52-31a5-4c5e-8a3e-7b0c1a2d3e4f","type":"recipe.deleted","occurredAt":"2026-10-01T09:30:00Z",
+				"recipe":{"uuid":"4b8c3c4e-9a4f-4a43-9d0e-2d5f3c7b1a10","name":"Marinara","version":4,"archived":false,"deleted":true}}`,
+		},
+		{
+			name: "aggregation with pans",
+			event: domain.Event{
+				Id:         eventId,
+				Type:       domain.EventRecipeAggregated,
+				OccurredAt: deletedAt,
+				Recipe:     domain.Recipe{Uuid: recipeUuid, Name: "Marinara", Author: "PizzaMaker", Version: 1},
+				Pans:       domain.Pans{Pans: []domain.Pan{{Shape: "round", Area: 706.5, Slices: 8}}, TotalArea: 706.5},
+			},
+			expected: `{"id":"0f6f1a52-31a5-4c5e-8a3e-7b0c1a2d3e4f","type":"recipe.aggregated","occurredAt":"2026-10-01T09:30:00Z",
+				"recipe":{"uuid":"4b8c3c4e-9a4f-4a43-9d0e-2d5f3c7b1a10","name":"Marinara","author":"PizzaMaker","version":1,"archived":false,"deleted":false},
+				"pans":[{"shape":"round","area":706.5,"slices":8}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := Encode(tt.event)
+			require.NoError(t, err)
+
+			assert.Equal(t, eventId, message.Id)
+			assert.Equal(t, tt.event.Type, message.Type)
+			assert.Equal(t, recipeUuid.String(), message.Key)
+			assert.JSONEq(t, tt.expected, string(message.Body))
+		})
+	}
+}
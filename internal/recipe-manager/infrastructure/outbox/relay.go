@@ -0,0 +1,156 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Store is the outbox the relay reads from.
+type Store interface {
+	// Pending returns up to limit messages neither published nor parked, in
+	// the order they were stored.
+	Pending(ctx context.Context, limit int) ([]Message, error)
+	MarkPublished(ctx context.Context, id uuid.UUID) error
+	MarkFailed(ctx context.Context, id uuid.UUID, cause error) error
+	// MarkParked counts a failed delivery like MarkFailed, and takes the
+	// message out of the pending ones.
+	MarkParked(ctx context.Context, id uuid.UUID, cause error) error
+}
+
+// Sink delivers messages to downstream systems. A message it accepted must
+// not be lost; one it rejected is offered again later, so Publish must accept
+// the same message twice.
+type Sink interface {
+	Publish(ctx context.Context, message Message) error
+}
+
+const (
+	defaultInterval    = time.Second
+	defaultBatchSize   = 100
+	defaultMaxAttempts = 100
+)
+
+// Relay moves messages from the outbox to a sink. Messages are marked
+// published only after the sink accepted them, so a crash in between
+// delivers them again: delivery is at least once. A message the sink
+// rejects every attempt is parked, so it stops holding back its recipe.
+type Relay struct {
+	store       Store
+	sink        Sink
+	logger      logrus.FieldLogger
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+}
+
+type RelayOption func(*Relay)
+
+// WithInterval sets how long the relay waits when the outbox is drained.
+func WithInterval(interval time.Duration) RelayOption {
+	return func(r *Relay) {
+		r.interval = interval
+	}
+}
+
+// WithBatchSize sets how many messages the relay reads at a time.
+func WithBatchSize(size int) RelayOption {
+	return func(r *Relay) {
+		r.batchSize = size
+	}
+}
+
+// WithMaxAttempts sets the attempts before a message is parked.
+func WithMaxAttempts(maxAttempts int) RelayOption {
+	return func(r *Relay) {
+		r.maxAttempts = maxAttempts
+	}
+}
+
+func NewRelay(store Store, sink Sink, logger logrus.FieldLogger, options ...RelayOption) *Relay {
+	relay := &Relay{
+		store:       store,
+		sink:        sink,
+		logger:      logger,
+		interval:    defaultInterval,
+		batchSize:   defaultBatchSize,
+		maxAttempts: defaultMaxAttempts,
+	}
+	for _, option := range options {
+		option(relay)
+	}
+	return relay
+}
+
+// Run relays messages until ctx is done.
+func (r *Relay) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		published, err := r.RelayOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.logger.WithError(err).Error("Failed to relay outbox events")
+		}
+		// A full batch likely leaves more behind, which is read at once.
+		wait := r.interval
+		if err == nil && published == r.batchSize {
+			wait = 0
+		}
+		timer.Reset(wait)
+	}
+}
+
+// RelayOnce publishes a batch of pending messages and returns how many the
+// sink accepted. A message the sink rejects holds back the later messages of
+// the same recipe until the next batch, so each recipe's events arrive in
+// order; once parked, it no longer holds them back.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	messages, err := r.store.Pending(ctx, r.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	held := make(map[string]bool)
+	for _, message := range messages {
+		if held[message.Key] {
+			continue
+		}
+		if err := r.sink.Publish(ctx, message); err != nil {
+			held[message.Key] = true
+			if err := r.fail(ctx, message, err); err != nil {
+				return published, err
+			}
+			continue
+		}
+		if err := r.store.MarkPublished(ctx, message.Id); err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, nil
+}
+
+func (r *Relay) fail(ctx context.Context, message Message, cause error) error {
+	attempts := message.Attempts + 1
+	logger := r.logger.WithError(cause).WithFields(logrus.Fields{
+		"event_id":   message.Id,
+		"event_type": message.Type,
+		"attempts":   attempts,
+	})
+	if attempts >= r.maxAttempts {
+		logger.Error("Outbox event failed every attempt, parked")
+		return r.store.MarkParked(ctx, message.Id, cause)
+	}
+	logger.Warn("Failed to publish outbox event")
+	return r.store.MarkFailed(ctx, message.Id, cause)
+}
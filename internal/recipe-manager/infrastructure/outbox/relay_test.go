@@ -0,0 +1,256 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+)
+
+// memoryStore is an outbox kept in a slice, in storage order.
+type memoryStore struct {
+	mu        sync.Mutex
+	messages  []Message
+	published map[uuid.UUID]bool
+	parked    map[uuid.UUID]bool
+}
+
+func newMemoryStore(messages ...Message) *memoryStore {
+	return &memoryStore{messages: messages, published: make(map[uuid.UUID]bool), parked: make(map[uuid.UUID]bool)}
+}
+
+func (s *memoryStore) Pending(_ context.Context, limit int) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pending []Message
+	for _, message := range s.messages {
+		if !s.published[message.Id] && !s.parked[message.Id] && len(pending) < limit {
+			pending = append(pending, message)
+		}
+	}
+	return pending, nil
+}
+
+func (s *memoryStore) MarkPublished(_ context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.published[id] = true
+	return nil
+}
+
+func (s *memoryStore) MarkFailed(_ context.Context, id uuid.UUID, _ error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.messages {
+		if s.messages[i].Id == id {
+			s.messages[i].Attempts++
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) MarkParked(ctx context.Context, id uuid.UUID, cause error) error {
+	_ = s.MarkFailed(ctx, id, cause)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.parked[id] = true
+	return nil
+}
+
+// sinkFunc adapts a function to Sink.
+type sinkFunc func(ctx context.Context, message Message) error
+
+func (f sinkFunc) Publish(ctx context.Context, message Message) error {
+	return f(ctx, message)
+}
+
+func message(t *testing.T, eventType domain.EventType, recipeUuid uuid.UUID) Message {
+	t.Helper()
+	encoded, err := Encode(domain.NewEvent(eventType, domain.Recipe{Uuid: recipeUuid, Version: 1}))
+	require.NoError(t, err)
+	return encoded
+}
+
+func quietLogger() logrus.FieldLogger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func TestRelayOnce(t *testing.T) {
+	ctx := context.Background()
+	margherita, marinara := uuid.New(), uuid.New()
+
+	t.Run("keeps the events of a recipe in order when one fails", func(t *testing.T) {
+		created := message(t, domain.EventRecipeCreated, margherita)
+		updated := message(t, domain.EventRecipeUpdated, margherita)
+		other := message(t, domain.EventRecipeCreated, marinara)
+		store := newMemoryStore(created, updated, other)
+
+		var delivered []uuid.UUID
+		fail := true
+		sink := sinkFunc(func(_ context.Context, message Message) error {
+			if message.Id == created.Id && fail {
+				return errors.New("menu board unavailable")
+			}
+			delivered = append(delivered, message.Id)
+			return nil
+		})
+		relay := NewRelay(store, sink, quietLogger())
+
+		published, err := relay.RelayOnce(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, published)
+		assert.Equal(t, []uuid.UUID{other.Id}, delivered)
+		assert.Equal(t, 1, store.messages[0].Attempts)
+
+		fail = false
+		published, err = relay.RelayOnce(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, published)
+		assert.Equal(t, []uuid.UUID{other.Id, created.Id, updated.Id}, delivered)
+	})
+
+	t.Run("parks an event failing every attempt", func(t *testing.T) {
+		created := message(t, domain.EventRecipeCreated, margherita)
+		updated := message(t, domain.EventRecipeUpdated, margherita)
+		store := newMemoryStore(created, updated)
+
+		var delivered []uuid.UUID
+		sink := sinkFunc(func(_ context.Context, message Message) error {
+			if message.Id == created.Id {
+				return errors.New("payload rejected")
+			}
+			delivered = append(delivered, message.Id)
+			return nil
+		})
+		relay := NewRelay(store, sink, quietLogger(), WithMaxAttempts(2))
+
+		for i := 0; i < 2; i++ {
+			published, err := relay.RelayOnce(ctx)
+			require.NoError(t, err)
+			assert.Zero(t, published)
+		}
+		assert.True(t, store.parked[created.Id])
+		assert.Equal(t, 2, store.messages[0].Attempts)
+
+		published, err := relay.RelayOnce(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, published)
+		assert.Equal(t, []uuid.UUID{updated.Id}, delivered)
+	})
+
+	t.Run("reads batches of the configured size", func(t *testing.T) {
+		store := newMemoryStore(
+			message(t, domain.EventRecipeCreated, margherita),
+			message(t, domain.EventRecipeUpdated, margherita),
+			message(t, domain.EventRecipeDeleted, margherita),
+		)
+		sink := sinkFunc(func(context.Context, Message) error { return nil })
+		relay := NewRelay(store, sink, quietLogger(), WithBatchSize(2))
+
+		published, err := relay.RelayOnce(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, published)
+		published, err = relay.RelayOnce(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, published)
+	})
+}
+
+func TestRelayRun(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		// The first delivery fails, so the event is delivered again with the
+		// same idempotency key.
+		received = append(received, r.Header.Get(IdempotencyKeyHeader))
+		if len(received) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, "recipe.updated", r.Header.Get(EventTypeHeader))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	updated := message(t, domain.EventRecipeUpdated, uuid.New())
+	store := newMemoryStore(updated)
+	relay := NewRelay(store, NewWebhookSink(server.URL, time.Second), quietLogger(), WithInterval(10*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		relay.Run(ctx)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return store.published[updated.Id]
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{updated.Id.String(), updated.Id.String()}, received)
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+
+	first := message(t, domain.EventRecipeCreated, uuid.New())
+	second := message(t, domain.EventRecipeDeleted, uuid.New())
+	require.NoError(t, sink.Publish(context.Background(), first))
+	require.NoError(t, sink.Publish(context.Background(), second))
+	require.NoError(t, sink.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.JSONEq(t, string(first.Body), lines[0])
+		assert.JSONEq(t, string(second.Body), lines[1])
+	}
+}
+
+func TestBrokerSink(t *testing.T) {
+	updated := message(t, domain.EventRecipeUpdated, uuid.New())
+	publisher := publisherFunc(func(_ context.Context, subject, key string, headers map[string]string, body []byte) error {
+		assert.Equal(t, "recipes.events", subject)
+		assert.Equal(t, updated.Key, key)
+		assert.Equal(t, updated.Id.String(), headers[IdempotencyKeyHeader])
+		assert.Equal(t, updated.Body, body)
+		return nil
+	})
+
+	assert.NoError(t, NewBrokerSink(publisher, "recipes.events").Publish(context.Background(), updated))
+}
+
+type publisherFunc func(ctx context.Context, subject, key string, headers map[string]string, body []byte) error
+
+func (f publisherFunc) Publish(ctx context.Context, subject, key string, headers map[string]string, body []byte) error {
+	return f(ctx, subject, key, headers, body)
+}
@@ -0,0 +1,105 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// IdempotencyKeyHeader carries the event id on webhook deliveries.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// EventTypeHeader carries the event type on webhook deliveries.
+	EventTypeHeader = "X-Event-Type"
+)
+
+// WebhookSink posts each message as JSON to a URL. Any 2xx response accepts
+// the message.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string, timeout time.Duration) *WebhookSink {
+	return &WebhookSink{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (s *WebhookSink) Publish(ctx context.Context, message Message) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(message.Body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(IdempotencyKeyHeader, message.Id.String())
+	request.Header.Set(EventTypeHeader, string(message.Type))
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to post event: %w", err)
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", response.Status)
+	}
+	return nil
+}
+
+// FileSink appends each message as a line of JSON to a file, for local
+// development or for a log shipper to pick up.
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event file: %w", err)
+	}
+	return &FileSink{file: file}, nil
+}
+
+func (s *FileSink) Publish(_ context.Context, message Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := fmt.Fprintf(s.file, "%s\n", message.Body); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// Publisher is the client of a message broker such as NATS JetStream or
+// Kafka. Publish returns once the broker acknowledged the message.
+type Publisher interface {
+	Publish(ctx context.Context, subject, key string, headers map[string]string, body []byte) error
+}
+
+// BrokerSink publishes messages to a broker subject, or topic, keyed by
+// recipe so the events of a recipe stay in order on a partitioned topic.
+type BrokerSink struct {
+	publisher Publisher
+	subject   string
+}
+
+func NewBrokerSink(publisher Publisher, subject string) *BrokerSink {
+	return &BrokerSink{publisher: publisher, subject: subject}
+}
+
+func (s *BrokerSink) Publish(ctx context.Context, message Message) error {
+	return s.publisher.Publish(ctx, s.subject, message.Key, map[string]string{
+		IdempotencyKeyHeader: message.Id.String(),
+		EventTypeHeader:      string(message.Type),
+	}, message.Body)
+}
@@ -0,0 +1,116 @@
+// Package sqlstore implements the recipe repository and the ingredient
+// catalogue on database/sql. Queries are written once with "?" placeholders
+// in the SQL shared by MySQL, PostgreSQL and SQLite; a Dialect covers what
+// differs between them.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect describes how to talk to one kind of database.
+type Dialect struct {
+	// Name is the value of the database.driver setting.
+	Name string
+	// System is the db.system attribute of the spans.
+	System string
+	// numbered placeholders are written $1, $2, ... instead of ?.
+	numbered bool
+	// returning databases report the id of an inserted or upserted row with
+	// RETURNING instead of LastInsertId.
+	returning bool
+	// upsertIngredient inserts an ingredient or updates the catalogue
+	// columns of the one with the same name.
+	upsertIngredient string
+	// ignoreConflict ends an insert that leaves a row with the same unique
+	// key as it is.
+	ignoreConflict string
+}
+
+var (
+	MySQL = Dialect{
+		Name:   "mysql",
+		System: "mysql",
+		// LAST_INSERT_ID(id) makes the id of an existing row available too.
+		upsertIngredient: `INSERT INTO ingredients (name, category, density, kcal, protein, carbohydrates, fat)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id), category = VALUES(category), density = VALUES(density),
+				kcal = VALUES(kcal), protein = VALUES(protein), carbohydrates = VALUES(carbohydrates), fat = VALUES(fat)`,
+		ignoreConflict: `ON DUPLICATE KEY UPDATE id = id`,
+	}
+	PostgreSQL = Dialect{
+		Name:             "postgres",
+		System:           "postgresql",
+		numbered:         true,
+		returning:        true,
+		upsertIngredient: upsertIngredientOnConflict,
+		ignoreConflict:   `ON CONFLICT DO NOTHING`,
+	}
+	SQLite = Dialect{
+		Name:             "sqlite",
+		System:           "sqlite",
+		returning:        true,
+		upsertIngredient: upsertIngredientOnConflict,
+		ignoreConflict:   `ON CONFLICT DO NOTHING`,
+	}
+)
+
+const upsertIngredientOnConflict = `INSERT INTO ingredients (name, category, density, kcal, protein, carbohydrates, fat)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (name) DO UPDATE SET category = excluded.category, density = excluded.density,
+		kcal = excluded.kcal, protein = excluded.protein, carbohydrates = excluded.carbohydrates, fat = excluded.fat`
+
+// DialectFor returns the dialect of a database.driver setting.
+func DialectFor(driver string) (Dialect, error) {
+	for _, dialect := range []Dialect{MySQL, PostgreSQL, SQLite} {
+		if dialect.Name == driver {
+			return dialect, nil
+		}
+	}
+	return Dialect{}, fmt.Errorf("unsupported database driver %q", driver)
+}
+
+// Rebind rewrites the ? placeholders of query in the style of the dialect.
+// Queries must not hold a literal question mark.
+func (d Dialect) Rebind(query string) string {
+	if !d.numbered {
+		return query
+	}
+	var builder strings.Builder
+	builder.Grow(len(query) + 8)
+	n := 0
+	for _, char := range query {
+		if char != '?' {
+			builder.WriteRune(char)
+			continue
+		}
+		n++
+		builder.WriteByte('$')
+		builder.WriteString(strconv.Itoa(n))
+	}
+	return builder.String()
+}
+
+// insertId runs an insert or upsert and returns the id of the row, adding a
+// RETURNING clause for the databases that need it.
+func (d Dialect) insertId(ctx context.Context, tx *sql.Tx, query string, args ...any) (int64, error) {
+	query = d.Rebind(query)
+	if d.returning {
+		var id int64
+		err := tx.QueryRowContext(ctx, query+" RETURNING id", args...).Scan(&id)
+		return id, err
+	}
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+func placeholders(count int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", count), ", ")
+}
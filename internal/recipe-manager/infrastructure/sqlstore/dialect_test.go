@@ -0,0 +1,26 @@
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialectRebind(t *testing.T) {
+	query := "SELECT name FROM ingredients WHERE id IN (?, ?) AND category = ?"
+
+	assert.Equal(t, query, MySQL.Rebind(query))
+	assert.Equal(t, query, SQLite.Rebind(query))
+	assert.Equal(t, "SELECT name FROM ingredients WHERE id IN ($1, $2) AND category = $3", PostgreSQL.Rebind(query))
+}
+
+func TestDialectFor(t *testing.T) {
+	for _, dialect := range []Dialect{MySQL, PostgreSQL, SQLite} {
+		found, err := DialectFor(dialect.Name)
+		assert.NoError(t, err)
+		assert.Equal(t, dialect.System, found.System)
+	}
+
+	_, err := DialectFor("oracle")
+	assert.EqualError(t, err, `unsupported database driver "oracle"`)
+}
@@ -0,0 +1,211 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+)
+
+// IngredientCatalogue maintains the catalogue data of the ingredients table,
+// which recipe_ingredients already links to.
+type IngredientCatalogue struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+func NewIngredientCatalogue(db *sql.DB, dialect Dialect) *IngredientCatalogue {
+	return &IngredientCatalogue{db: db, dialect: dialect}
+}
+
+// Import upserts ingredients by canonical name and replaces their display
+// names, aliases, allergens and diets. Ingredients stored under one of the aliases
+// are then merged into the canonical entry, moving their recipe links.
+// Entries missing from ingredients are left untouched.
+func (ic *IngredientCatalogue) Import(ctx context.Context, ingredients []domain.CatalogueIngredient) error {
+	tx, err := ic.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start catalogue import: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, ingredient := range ingredients {
+		if err := ic.importIngredient(ctx, tx, ingredient); err != nil {
+			return fmt.Errorf("failed to import ingredient %s: %w", ingredient.Name, err)
+		}
+	}
+
+	// Both statements only read ingredients through a derived table, which
+	// MySQL requires to change a table it also selects from.
+	mergeQueries := []string{
+		`UPDATE recipe_ingredients
+		SET ingredient_id = (
+			SELECT a.ingredient_id FROM ingredient_aliases a
+				JOIN ingredients duplicate ON LOWER(a.alias) = LOWER(duplicate.name)
+			WHERE duplicate.id = recipe_ingredients.ingredient_id)
+		WHERE ingredient_id IN (SELECT id FROM (` + duplicateIngredients + `) AS duplicates)`,
+		`DELETE FROM ingredients WHERE id IN (SELECT id FROM (` + duplicateIngredients + `) AS duplicates)`,
+	}
+	for _, query := range mergeQueries {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to merge ingredient aliases: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit catalogue import: %w", err)
+	}
+	return nil
+}
+
+// duplicateIngredients selects the ids of the ingredients stored under an
+// alias of another one.
+const duplicateIngredients = `SELECT duplicate.id FROM ingredients duplicate
+	JOIN ingredient_aliases a ON LOWER(a.alias) = LOWER(duplicate.name)
+	WHERE duplicate.id <> a.ingredient_id`
+
+func (ic *IngredientCatalogue) importIngredient(ctx context.Context, tx *sql.Tx, ingredient domain.CatalogueIngredient) error {
+	var density any
+	if ingredient.Density > 0 {
+		density = ingredient.Density
+	}
+	var kcal, protein, carbohydrates, fat any
+	if ingredient.Nutrition != nil {
+		kcal, protein, carbohydrates, fat = ingredient.Nutrition.Kcal, ingredient.Nutrition.Protein,
+			ingredient.Nutrition.Carbohydrates, ingredient.Nutrition.Fat
+	}
+
+	id, err := ic.dialect.insertId(ctx, tx, ic.dialect.upsertIngredient,
+		ingredient.Name, ingredient.Category, density, kcal, protein, carbohydrates, fat)
+	if err != nil {
+		return err
+	}
+
+	exec := func(query string, args ...any) error {
+		_, err := tx.ExecContext(ctx, ic.dialect.Rebind(query), args...)
+		return err
+	}
+	for _, table := range []string{"ingredient_display_names", "ingredient_aliases", "ingredient_allergens", "ingredient_diets"} {
+		if err := exec("DELETE FROM "+table+" WHERE ingredient_id = ?", id); err != nil {
+			return err
+		}
+	}
+
+	for _, locale := range slices.Sorted(maps.Keys(ingredient.DisplayNames)) {
+		if err := exec(`INSERT INTO ingredient_display_names (ingredient_id, locale, display_name) VALUES (?, ?, ?)`,
+			id, locale, ingredient.DisplayNames[locale]); err != nil {
+			return err
+		}
+	}
+	for _, alias := range ingredient.Aliases {
+		// An alias moves over when another ingredient held it before.
+		if err := exec(`DELETE FROM ingredient_aliases WHERE LOWER(alias) = LOWER(?)`, alias); err != nil {
+			return err
+		}
+		if err := exec(`INSERT INTO ingredient_aliases (alias, ingredient_id) VALUES (?, ?)`, alias, id); err != nil {
+			return err
+		}
+	}
+	for _, allergen := range ingredient.Allergens {
+		if err := exec(`INSERT INTO ingredient_allergens (ingredient_id, allergen) VALUES (?, ?)`,
+			id, string(allergen)); err != nil {
+			return err
+		}
+	}
+	for _, diet := range ingredient.Diets {
+		if err := exec(`INSERT INTO ingredient_diets (ingredient_id, diet) VALUES (?, ?)`,
+			id, string(diet)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// catalogueRow holds the catalogue columns of an ingredients row.
+type catalogueRow struct {
+	id                                int
+	category                          string
+	density                           sql.NullFloat64
+	kcal, protein, carbohydrates, fat sql.NullFloat64
+}
+
+func (r catalogueRow) toDomain(name string) *domain.CatalogueIngredient {
+	entry := &domain.CatalogueIngredient{
+		Id:       r.id,
+		Name:     name,
+		Category: r.category,
+		Density:  r.density.Float64,
+	}
+	if r.kcal.Valid && r.protein.Valid && r.carbohydrates.Valid && r.fat.Valid {
+		entry.Nutrition = &domain.Nutrition{
+			Kcal:          r.kcal.Float64,
+			Protein:       r.protein.Float64,
+			Carbohydrates: r.carbohydrates.Float64,
+			Fat:           r.fat.Float64,
+		}
+	}
+	return entry
+}
+
+// loadCatalogueDetails fills the display names, aliases, allergens and diets of
+// entries, keyed by ingredient id, with a single query.
+func (rr RecipeRepository) loadCatalogueDetails(ctx context.Context, entries map[int]*domain.CatalogueIngredient) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	ids := slices.Sorted(maps.Keys(entries))
+	inList := placeholders(len(ids))
+	args := make([]any, 0, 4*len(ids))
+	for range 4 {
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+
+	query := `SELECT ingredient_id, 'display_name', locale, display_name FROM ingredient_display_names WHERE ingredient_id IN (` + inList + `)
+		UNION ALL
+		SELECT ingredient_id, 'alias', '', alias FROM ingredient_aliases WHERE ingredient_id IN (` + inList + `)
+		UNION ALL
+		SELECT ingredient_id, 'allergen', '', allergen FROM ingredient_allergens WHERE ingredient_id IN (` + inList + `)
+		UNION ALL
+		SELECT ingredient_id, 'diet', '', diet FROM ingredient_diets WHERE ingredient_id IN (` + inList + `)
+		ORDER BY 1, 2, 4`
+	rows, err := conn(ctx, rr.db).QueryContext(ctx, rr.dialect.Rebind(query), args...)
+	if err != nil {
+		return rr.wrapTimeout(ctx, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var kind, locale, value string
+		if err := rows.Scan(&id, &kind, &locale, &value); err != nil {
+			return err
+		}
+		entry, found := entries[id]
+		if !found {
+			continue
+		}
+		switch kind {
+		case "display_name":
+			if entry.DisplayNames == nil {
+				entry.DisplayNames = make(map[string]string)
+			}
+			entry.DisplayNames[locale] = value
+		case "alias":
+			entry.Aliases = append(entry.Aliases, value)
+		case "allergen":
+			entry.Allergens = append(entry.Allergens, domain.Allergen(value))
+		case "diet":
+			entry.Diets = append(entry.Diets, domain.Diet(value))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return rr.wrapTimeout(ctx, err)
+	}
+	return nil
+}
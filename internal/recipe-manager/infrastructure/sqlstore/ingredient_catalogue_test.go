@@ -0,0 +1,112 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+)
+
+func TestIngredientCatalogueImport(t *testing.T) {
+	mozzarella := domain.CatalogueIngredient{
+		Name:         "mozzarellaCheese",
+		DisplayNames: map[string]string{"it": "Mozzarella", "en": "Mozzarella"},
+		Aliases:      []string{"mozzarella"},
+		Category:     "dairy",
+		Allergens:    []domain.Allergen{domain.AllergenMilk},
+		Diets:        []domain.Diet{domain.DietVegetarian},
+		Nutrition:    &domain.Nutrition{Kcal: 253, Protein: 18.7, Carbohydrates: 0.7, Fat: 19.5},
+	}
+
+	t.Run("upserts ingredients and merges aliases", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ingredients (name, category, density, kcal, protein, carbohydrates, fat)")).
+			WithArgs("mozzarellaCheese", "dairy", nil, 253.0, 18.7, 0.7, 19.5).
+			WillReturnResult(sqlmock.NewResult(7, 2))
+		for _, table := range []string{"ingredient_display_names", "ingredient_aliases", "ingredient_allergens", "ingredient_diets"} {
+			mock.ExpectExec(regexp.QuoteMeta("DELETE FROM " + table + " WHERE ingredient_id = ?")).
+				WithArgs(int64(7)).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ingredient_display_names")).
+			WithArgs(int64(7), "en", "Mozzarella").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ingredient_display_names")).
+			WithArgs(int64(7), "it", "Mozzarella").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM ingredient_aliases WHERE LOWER(alias) = LOWER(?)")).
+			WithArgs("mozzarella").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ingredient_aliases")).
+			WithArgs("mozzarella", int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ingredient_allergens")).
+			WithArgs(int64(7), "milk").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ingredient_diets")).
+			WithArgs(int64(7), "vegetarian").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE recipe_ingredients SET ingredient_id = (")).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM ingredients WHERE id IN (")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err = NewIngredientCatalogue(db, MySQL).Import(context.Background(), []domain.CatalogueIngredient{mozzarella})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("reads the upserted id with RETURNING", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("ON CONFLICT (name) DO UPDATE SET")).
+			WithArgs("basil", "herb", nil, nil, nil, nil, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(9)))
+		for _, table := range []string{"ingredient_display_names", "ingredient_aliases", "ingredient_allergens", "ingredient_diets"} {
+			mock.ExpectExec(regexp.QuoteMeta("DELETE FROM " + table + " WHERE ingredient_id = $1")).
+				WithArgs(int64(9)).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE recipe_ingredients")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM ingredients")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err = NewIngredientCatalogue(db, PostgreSQL).Import(context.Background(),
+			[]domain.CatalogueIngredient{{Name: "basil", Category: "herb"}})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rolls back on failure", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ingredients")).
+			WillReturnError(errors.New("data too long for column 'category'"))
+		mock.ExpectRollback()
+
+		err = NewIngredientCatalogue(db, MySQL).Import(context.Background(), []domain.CatalogueIngredient{mozzarella})
+
+		assert.ErrorContains(t, err, "failed to import ingredient mozzarellaCheese: data too long")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,159 @@
+package migrations
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/mysql"
+	"github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+const (
+	SchemaMigrationsTable = "schema_migrations"
+	SeedMigrationsTable   = "seed_migrations"
+)
+
+// Migrator applies one set of migrations, schema or seed data, each tracked
+// in its own migrations table so they can be moved independently.
+type Migrator struct {
+	migrate *migrate.Migrate
+	source  source.Driver
+}
+
+type Status struct {
+	Version uint
+	Dirty   bool
+	Applied []uint
+	Pending []uint
+}
+
+// NewMigrator opens a dedicated connection to dsn with the database driver
+// named by driver, one of mysql, postgres or sqlite, and reads the migrations
+// from the root of fsys. Close releases the connection.
+func NewMigrator(driver, dsn string, fsys fs.FS, table string) (*Migrator, error) {
+	databaseDriver, err := openDatabase(driver, dsn, table)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceDriver, err := iofs.New(fsys, ".")
+	if err != nil {
+		_ = databaseDriver.Close()
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", sourceDriver, driver, databaseDriver)
+	if err != nil {
+		_ = databaseDriver.Close()
+		return nil, fmt.Errorf("failed to configure migrations: %w", err)
+	}
+
+	return &Migrator{migrate: m, source: sourceDriver}, nil
+}
+
+func openDatabase(driver, dsn, table string) (database.Driver, error) {
+	driverName := driver
+	switch driver {
+	case "mysql":
+		// Migration files may hold more than one statement.
+		config, err := mysqlDriver.ParseDSN(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse database DSN: %w", err)
+		}
+		config.MultiStatements = true
+		dsn = config.FormatDSN()
+	case "postgres":
+		driverName = "pgx"
+	case "sqlite":
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
+	}
+
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the database: %w", err)
+	}
+
+	var databaseDriver database.Driver
+	switch driver {
+	case "mysql":
+		databaseDriver, err = mysql.WithInstance(db, &mysql.Config{MigrationsTable: table})
+	case "postgres":
+		databaseDriver, err = pgx.WithInstance(db, &pgx.Config{MigrationsTable: table})
+	case "sqlite":
+		databaseDriver, err = sqlite.WithInstance(db, &sqlite.Config{MigrationsTable: table})
+	}
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create the migrations driver: %w", err)
+	}
+	return databaseDriver, nil
+}
+
+func (m *Migrator) Up() error {
+	if err := m.migrate.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+	return nil
+}
+
+// Down reverts the last steps migrations, or all of them when steps is 0.
+func (m *Migrator) Down(steps int) error {
+	var err error
+	if steps > 0 {
+		err = m.migrate.Steps(-steps)
+	} else {
+		err = m.migrate.Down()
+	}
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to revert migrations: %w", err)
+	}
+	return nil
+}
+
+// Force sets the recorded version without running any migration and clears
+// the dirty flag left by a failed one. Version -1 means no migration applied.
+func (m *Migrator) Force(version int) error {
+	if err := m.migrate.Force(version); err != nil {
+		return fmt.Errorf("failed to force version %d: %w", version, err)
+	}
+	return nil
+}
+
+func (m *Migrator) Status() (Status, error) {
+	var status Status
+
+	version, dirty, err := m.migrate.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return status, fmt.Errorf("failed to read migration version: %w", err)
+	}
+	status.Version = version
+	status.Dirty = dirty
+
+	next, err := m.source.First()
+	for err == nil {
+		if status.Version != 0 && next <= status.Version {
+			status.Applied = append(status.Applied, next)
+		} else {
+			status.Pending = append(status.Pending, next)
+		}
+		next, err = m.source.Next(next)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return status, fmt.Errorf("failed to list migrations: %w", err)
+	}
+	return status, nil
+}
+
+func (m *Migrator) Close() error {
+	sourceErr, databaseErr := m.migrate.Close()
+	return errors.Join(sourceErr, databaseErr)
+}
@@ -0,0 +1,154 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/outbox"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/tracing"
+)
+
+// Outbox stores domain events in the outbox_events table, in the transaction
+// of the recipe change they describe, until the relay publishes them.
+type Outbox struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+func NewOutbox(db *sql.DB, dialect Dialect) *Outbox {
+	return &Outbox{db: db, dialect: dialect}
+}
+
+// Transaction runs fn in a transaction shared by the outbox and the
+// repositories using the same database.
+func (o *Outbox) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return WithTransaction(ctx, o.db, fn)
+}
+
+// Append stores events, in the transaction of ctx if there is one.
+func (o *Outbox) Append(ctx context.Context, events ...domain.Event) error {
+	ctx, span := o.start(ctx, "Outbox.Append", "INSERT")
+	defer span.End()
+
+	for _, event := range events {
+		message, err := outbox.Encode(event)
+		if err != nil {
+			recordError(span, err)
+			return fmt.Errorf("failed to encode event %s: %w", event.Id, err)
+		}
+		if _, err := conn(ctx, o.db).ExecContext(ctx, o.dialect.Rebind(
+			`INSERT INTO outbox_events (event_id, event_type, recipe_uuid, payload, occurred_at) VALUES (?, ?, ?, ?, ?)`),
+			message.Id, string(message.Type), message.Key, string(message.Body), event.OccurredAt.UTC()); err != nil {
+			recordError(span, err)
+			return fmt.Errorf("failed to store event %s: %w", event.Id, err)
+		}
+	}
+	return nil
+}
+
+// Pending returns up to limit messages neither published nor parked, in the
+// order they were stored.
+func (o *Outbox) Pending(ctx context.Context, limit int) ([]outbox.Message, error) {
+	ctx, span := o.start(ctx, "Outbox.Pending", "SELECT")
+	defer span.End()
+
+	rows, err := o.db.QueryContext(ctx, o.dialect.Rebind(
+		`SELECT event_id, event_type, recipe_uuid, payload, attempts FROM outbox_events
+		WHERE published_at IS NULL AND parked_at IS NULL ORDER BY id LIMIT ?`), limit)
+	if err != nil {
+		recordError(span, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []outbox.Message
+	for rows.Next() {
+		var message outbox.Message
+		var eventType string
+		if err := rows.Scan(&message.Id, &eventType, &message.Key, &message.Body, &message.Attempts); err != nil {
+			recordError(span, err)
+			return nil, err
+		}
+		message.Type = domain.EventType(eventType)
+		messages = append(messages, message)
+	}
+	if err := rows.Err(); err != nil {
+		recordError(span, err)
+		return nil, err
+	}
+	return messages, nil
+}
+
+// MarkPublished records that the sink accepted the message.
+func (o *Outbox) MarkPublished(ctx context.Context, id uuid.UUID) error {
+	ctx, span := o.start(ctx, "Outbox.MarkPublished", "UPDATE")
+	defer span.End()
+
+	_, err := o.db.ExecContext(ctx, o.dialect.Rebind(
+		`UPDATE outbox_events SET published_at = ?, last_error = NULL WHERE event_id = ?`), time.Now().UTC(), id)
+	if err != nil {
+		recordError(span, err)
+	}
+	return err
+}
+
+// MarkFailed counts a failed delivery of the message, which stays pending.
+func (o *Outbox) MarkFailed(ctx context.Context, id uuid.UUID, cause error) error {
+	ctx, span := o.start(ctx, "Outbox.MarkFailed", "UPDATE")
+	defer span.End()
+
+	_, err := o.db.ExecContext(ctx, o.dialect.Rebind(
+		`UPDATE outbox_events SET attempts = attempts + 1, last_error = ? WHERE event_id = ?`), cause.Error(), id)
+	if err != nil {
+		recordError(span, err)
+	}
+	return err
+}
+
+// MarkParked counts the last failed delivery of the message and parks it, so
+// it is no longer pending.
+func (o *Outbox) MarkParked(ctx context.Context, id uuid.UUID, cause error) error {
+	ctx, span := o.start(ctx, "Outbox.MarkParked", "UPDATE")
+	defer span.End()
+
+	_, err := o.db.ExecContext(ctx, o.dialect.Rebind(
+		`UPDATE outbox_events SET attempts = attempts + 1, last_error = ?, parked_at = ? WHERE event_id = ?`),
+		cause.Error(), time.Now().UTC(), id)
+	if err != nil {
+		recordError(span, err)
+	}
+	return err
+}
+
+// PurgePublished removes the messages published before the given time and
+// returns how many there were.
+func (o *Outbox) PurgePublished(ctx context.Context, publishedBefore time.Time) (int64, error) {
+	ctx, span := o.start(ctx, "Outbox.PurgePublished", "DELETE")
+	defer span.End()
+
+	result, err := o.db.ExecContext(ctx, o.dialect.Rebind(`DELETE FROM outbox_events WHERE published_at < ?`),
+		publishedBefore.UTC())
+	if err != nil {
+		recordError(span, err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (o *Outbox) start(ctx context.Context, name, operation string) (context.Context, trace.Span) {
+	return tracing.GetGlobalTracer(tracerName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", o.dialect.System),
+			attribute.String("db.operation", operation),
+			attribute.String("db.sql.table", "outbox_events"),
+		),
+	)
+}
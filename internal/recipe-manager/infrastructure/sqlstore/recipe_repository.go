@@ -0,0 +1,387 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/tracing"
+)
+
+const tracerName = "recipe-manager/sqlstore"
+
+const (
+	sectionDough   = "dough"
+	sectionTopping = "topping"
+)
+
+type RecipeRepository struct {
+	db           *sql.DB
+	dialect      Dialect
+	queryTimeout time.Duration
+}
+
+type Option func(*RecipeRepository)
+
+// WithQueryTimeout bounds every query with a deadline, on top of the
+// cancellation of the caller context, so a hung database cannot hold a request.
+func WithQueryTimeout(timeout time.Duration) Option {
+	return func(rr *RecipeRepository) {
+		rr.queryTimeout = timeout
+	}
+}
+
+func NewRecipeRepository(db *sql.DB, dialect Dialect, options ...Option) *RecipeRepository {
+	repository := &RecipeRepository{db: db, dialect: dialect}
+	for _, option := range options {
+		option(repository)
+	}
+	return repository
+}
+
+func (rr RecipeRepository) GetRecipeByUuid(ctx context.Context, recipeUuid uuid.UUID) (*domain.Recipe, error) {
+	ctx, span := tracing.GetGlobalTracer(tracerName).Start(ctx, "RecipeRepository.GetRecipeByUuid",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", rr.dialect.System),
+			attribute.String("db.operation", "SELECT"),
+			attribute.String("db.sql.table", "recipes"),
+			attribute.String("recipe.uuid", recipeUuid.String()),
+		),
+	)
+	defer span.End()
+
+	ctx, cancel := rr.withQueryTimeout(ctx)
+	defer cancel()
+
+	var response domain.Recipe
+
+	query := `SELECT ` + recipeSelection + ` FROM recipes WHERE uuid = ? AND deleted_at IS NULL`
+	err := scanRecipe(conn(ctx, rr.db).QueryRowContext(ctx, rr.dialect.Rebind(query), recipeUuid), &response)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = fmt.Errorf("recipe %s: %w", recipeUuid, domain.ErrRecipeNotFound)
+	}
+	if err != nil {
+		err = rr.wrapTimeout(ctx, err)
+		recordError(span, err)
+		return nil, err
+	}
+
+	if err := rr.loadIngredients(ctx, &response); err != nil {
+		recordError(span, err)
+		return nil, err
+	}
+	if err := rr.loadSteps(ctx, &response); err != nil {
+		recordError(span, err)
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// ListRecipes returns the recipes selected by filter with their ingredients,
+// ordered by name.
+func (rr RecipeRepository) ListRecipes(ctx context.Context, filter domain.RecipeFilter) ([]domain.Recipe, error) {
+	ctx, span := tracing.GetGlobalTracer(tracerName).Start(ctx, "RecipeRepository.ListRecipes",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", rr.dialect.System),
+			attribute.String("db.operation", "SELECT"),
+			attribute.String("db.sql.table", "recipes"),
+		),
+	)
+	defer span.End()
+
+	ctx, cancel := rr.withQueryTimeout(ctx)
+	defer cancel()
+
+	condition := "deleted_at IS NULL AND archived_at IS NULL"
+	switch {
+	case filter.Deleted:
+		condition = "deleted_at IS NOT NULL"
+	case filter.IncludeArchived:
+		condition = "deleted_at IS NULL"
+	}
+	query := `SELECT ` + recipeSelection + ` FROM recipes WHERE ` + condition + ` ORDER BY name, id`
+	recipes, err := rr.queryRecipes(ctx, query)
+	if err != nil {
+		recordError(span, err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("recipes.count", len(recipes)))
+	return recipes, nil
+}
+
+// GetRecipesByUuids returns the recipes of recipeUuids that exist and are not
+// deleted, with their ingredients, in two queries whatever their number.
+// Missing recipes are left out rather than reported.
+func (rr RecipeRepository) GetRecipesByUuids(ctx context.Context, recipeUuids []uuid.UUID) ([]domain.Recipe, error) {
+	ctx, span := tracing.GetGlobalTracer(tracerName).Start(ctx, "RecipeRepository.GetRecipesByUuids",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", rr.dialect.System),
+			attribute.String("db.operation", "SELECT"),
+			attribute.String("db.sql.table", "recipes"),
+			attribute.Int("recipes.requested", len(recipeUuids)),
+		),
+	)
+	defer span.End()
+
+	if len(recipeUuids) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := rr.withQueryTimeout(ctx)
+	defer cancel()
+
+	args := make([]any, len(recipeUuids))
+	for i, recipeUuid := range recipeUuids {
+		args[i] = recipeUuid
+	}
+	query := `SELECT ` + recipeSelection + ` FROM recipes WHERE uuid IN (` + placeholders(len(args)) + `) AND deleted_at IS NULL ORDER BY name, id`
+	recipes, err := rr.queryRecipes(ctx, rr.dialect.Rebind(query), args...)
+	if err != nil {
+		recordError(span, err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("recipes.count", len(recipes)))
+	return recipes, nil
+}
+
+// queryRecipes reads the recipes the query selects with recipeSelection,
+// along with their ingredients.
+func (rr RecipeRepository) queryRecipes(ctx context.Context, query string, args ...any) ([]domain.Recipe, error) {
+	rows, err := conn(ctx, rr.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, rr.wrapTimeout(ctx, err)
+	}
+	defer rows.Close()
+
+	var recipes []domain.Recipe
+	for rows.Next() {
+		var recipe domain.Recipe
+		if err := scanRecipe(rows, &recipe); err != nil {
+			return nil, err
+		}
+		recipes = append(recipes, recipe)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, rr.wrapTimeout(ctx, err)
+	}
+
+	pointers := make([]*domain.Recipe, len(recipes))
+	for i := range recipes {
+		pointers[i] = &recipes[i]
+	}
+	if err := rr.loadIngredients(ctx, pointers...); err != nil {
+		return nil, err
+	}
+	return recipes, nil
+}
+
+// recipeSelection lists the columns of recipes read by scanRecipe.
+const recipeSelection = `id, uuid, name, description, author, dough_percent_variation, topping_reference_area,
+	style, dough_density, serving_weight, version, archived_at, deleted_at`
+
+func scanRecipe(row interface{ Scan(...any) error }, recipe *domain.Recipe) error {
+	var style sql.NullString
+	var doughDensity, servingWeight sql.NullFloat64
+	var archivedAt, deletedAt sql.NullTime
+	err := row.Scan(
+		&recipe.Id,
+		&recipe.Uuid,
+		&recipe.Name,
+		&recipe.Description,
+		&recipe.Author,
+		&recipe.Dough.PercentVariation,
+		&recipe.Topping.ReferenceArea,
+		&style,
+		&doughDensity,
+		&servingWeight,
+		&recipe.Version,
+		&archivedAt,
+		&deletedAt,
+	)
+	recipe.Style = style.String
+	recipe.DoughDensity = doughDensity.Float64
+	recipe.ServingWeight = servingWeight.Float64
+	recipe.ArchivedAt = timeOrNil(archivedAt)
+	recipe.DeletedAt = timeOrNil(deletedAt)
+	return err
+}
+
+// styleColumns are the style, dough density and serving weight of recipe to
+// store, NULL when unset.
+func styleColumns(recipe domain.Recipe) (sql.NullString, sql.NullFloat64, sql.NullFloat64) {
+	return sql.NullString{String: recipe.Style, Valid: recipe.Style != ""},
+		sql.NullFloat64{Float64: recipe.DoughDensity, Valid: recipe.DoughDensity > 0},
+		sql.NullFloat64{Float64: recipe.ServingWeight, Valid: recipe.ServingWeight > 0}
+}
+
+func timeOrNil(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
+
+// loadIngredients fills the dough and topping ingredients of recipes in their
+// stored order, each linked to its catalogue entry, with a single query.
+func (rr RecipeRepository) loadIngredients(ctx context.Context, recipes ...*domain.Recipe) error {
+	if len(recipes) == 0 {
+		return nil
+	}
+
+	ctx, span := tracing.GetGlobalTracer(tracerName).Start(ctx, "RecipeRepository.loadIngredients",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", rr.dialect.System),
+			attribute.String("db.operation", "SELECT"),
+			attribute.String("db.sql.table", "recipe_ingredients"),
+		),
+	)
+	defer span.End()
+
+	byId := make(map[int]*domain.Recipe, len(recipes))
+	args := make([]any, 0, len(recipes))
+	for _, recipe := range recipes {
+		byId[recipe.Id] = recipe
+		args = append(args, recipe.Id)
+	}
+
+	query := `SELECT ri.recipe_id, ri.section, i.name, ri.amount, ri.unit, COALESCE(ri.notes, ''),
+			i.id, COALESCE(i.category, ''), i.density, i.kcal, i.protein, i.carbohydrates, i.fat
+		FROM recipe_ingredients ri
+		JOIN ingredients i ON i.id = ri.ingredient_id
+		WHERE ri.recipe_id IN (` + placeholders(len(args)) + `)
+		ORDER BY ri.recipe_id, ri.section, ri.position`
+	rows, err := conn(ctx, rr.db).QueryContext(ctx, rr.dialect.Rebind(query), args...)
+	if err != nil {
+		err = rr.wrapTimeout(ctx, err)
+		recordError(span, err)
+		return err
+	}
+	defer rows.Close()
+
+	count := 0
+	entries := make(map[int]*domain.CatalogueIngredient)
+	for rows.Next() {
+		var recipeId int
+		var section string
+		var ingredient domain.Ingredient
+		var entry catalogueRow
+		if err := rows.Scan(&recipeId, &section, &ingredient.Name, &ingredient.Amount, &ingredient.Unit, &ingredient.Notes,
+			&entry.id, &entry.category, &entry.density, &entry.kcal, &entry.protein, &entry.carbohydrates, &entry.fat); err != nil {
+			recordError(span, err)
+			return err
+		}
+		if _, found := entries[entry.id]; !found {
+			entries[entry.id] = entry.toDomain(ingredient.Name)
+		}
+		ingredient.Catalogue = entries[entry.id]
+
+		recipe, found := byId[recipeId]
+		if !found {
+			continue
+		}
+		switch section {
+		case sectionDough:
+			recipe.Dough.Ingredients = append(recipe.Dough.Ingredients, ingredient)
+		case sectionTopping:
+			recipe.Topping.Ingredients = append(recipe.Topping.Ingredients, ingredient)
+		default:
+			err := fmt.Errorf("unknown ingredient section %q", section)
+			recordError(span, err)
+			return err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		err = rr.wrapTimeout(ctx, err)
+		recordError(span, err)
+		return err
+	}
+	if err := rr.loadCatalogueDetails(ctx, entries); err != nil {
+		recordError(span, err)
+		return err
+	}
+
+	span.SetAttributes(attribute.Int("ingredients.count", count))
+	return nil
+}
+
+// loadSteps fills the steps of recipe in their order. Only single recipes
+// are read with their steps: the listings leave them out.
+func (rr RecipeRepository) loadSteps(ctx context.Context, recipe *domain.Recipe) error {
+	ctx, span := tracing.GetGlobalTracer(tracerName).Start(ctx, "RecipeRepository.loadSteps",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", rr.dialect.System),
+			attribute.String("db.operation", "SELECT"),
+			attribute.String("db.sql.table", "recipe_steps"),
+		),
+	)
+	defer span.End()
+
+	query := `SELECT id, step_number, description FROM recipe_steps WHERE recipe_id = ? ORDER BY step_number, id`
+	rows, err := conn(ctx, rr.db).QueryContext(ctx, rr.dialect.Rebind(query), recipe.Id)
+	if err != nil {
+		err = rr.wrapTimeout(ctx, err)
+		recordError(span, err)
+		return err
+	}
+	defer rows.Close()
+
+	recipe.Steps = domain.Steps{RecipeId: recipe.Id}
+	for rows.Next() {
+		var step domain.Step
+		if err := rows.Scan(&step.Id, &step.StepNumber, &step.Description); err != nil {
+			recordError(span, err)
+			return err
+		}
+		recipe.Steps.Steps = append(recipe.Steps.Steps, step)
+	}
+	if err := rows.Err(); err != nil {
+		err = rr.wrapTimeout(ctx, err)
+		recordError(span, err)
+		return err
+	}
+
+	span.SetAttributes(attribute.Int("steps.count", len(recipe.Steps.Steps)))
+	return nil
+}
+
+func (rr RecipeRepository) withQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if rr.queryTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, rr.queryTimeout)
+}
+
+// wrapTimeout reports err as a timeout once the query deadline has passed,
+// keeping the message of the driver when it is not the context error.
+func (rr RecipeRepository) wrapTimeout(ctx context.Context, err error) error {
+	if rr.queryTimeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		cause := ctx.Err()
+		if !errors.Is(err, cause) {
+			cause = errors.Join(cause, err)
+		}
+		return fmt.Errorf("query timed out after %s: %w", rr.queryTimeout, cause)
+	}
+	return err
+}
+
+func recordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
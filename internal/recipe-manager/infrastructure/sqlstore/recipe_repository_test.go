@@ -0,0 +1,294 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+)
+
+var (
+	recipeQuery           = regexp.QuoteMeta(`SELECT id, uuid, name, description, author, dough_percent_variation, topping_reference_area, style, dough_density, serving_weight, version, archived_at, deleted_at FROM recipes WHERE uuid = ? AND deleted_at IS NULL`)
+	ingredientsQuery      = regexp.QuoteMeta(`FROM recipe_ingredients ri JOIN ingredients i ON i.id = ri.ingredient_id WHERE ri.recipe_id IN (`)
+	catalogueDetailsQuery = regexp.QuoteMeta(`SELECT ingredient_id, 'display_name', locale, display_name FROM ingredient_display_names WHERE ingredient_id IN (`)
+	stepsQuery            = regexp.QuoteMeta(`SELECT id, step_number, description FROM recipe_steps WHERE recipe_id = ? ORDER BY step_number, id`)
+
+	recipeColumns           = []string{"id", "uuid", "name", "description", "author", "dough_percent_variation", "topping_reference_area", "style", "dough_density", "serving_weight", "version", "archived_at", "deleted_at"}
+	ingredientColumns       = []string{"recipe_id", "section", "name", "amount", "unit", "notes", "id", "category", "density", "kcal", "protein", "carbohydrates", "fat"}
+	catalogueDetailsColumns = []string{"ingredient_id", "kind", "locale", "value"}
+	stepColumns             = []string{"id", "step_number", "description"}
+)
+
+func TestGetRecipeByUuid(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRecipeRepository(db, MySQL)
+	newUuid := uuid.New()
+
+	t.Run("should return recipe successfully when found", func(t *testing.T) {
+		flour := &domain.CatalogueIngredient{
+			Id:           1,
+			Name:         "flour",
+			DisplayNames: map[string]string{"en": "Soft wheat flour type 00", "it": "Farina di grano tenero 00"},
+			Aliases:      []string{"farina", "wheatFlour"},
+			Category:     "flour",
+			Allergens:    []domain.Allergen{domain.AllergenGluten},
+			Diets:        []domain.Diet{domain.DietVegan},
+			Density:      0.593,
+			Nutrition:    &domain.Nutrition{Kcal: 340, Protein: 11, Carbohydrates: 72.7, Fat: 1.1},
+		}
+		water := &domain.CatalogueIngredient{Id: 2, Name: "water", Category: "water", Density: 1, Nutrition: &domain.Nutrition{}}
+		mozzarella := &domain.CatalogueIngredient{
+			Id:        3,
+			Name:      "mozzarellaCheese",
+			Category:  "dairy",
+			Allergens: []domain.Allergen{domain.AllergenMilk},
+			Nutrition: &domain.Nutrition{Kcal: 253, Protein: 18.7, Carbohydrates: 0.7, Fat: 19.5},
+		}
+		uncatalogued := &domain.CatalogueIngredient{Id: 4, Name: "referenceArea"}
+		expectedRecipe := &domain.Recipe{
+			Id:            1,
+			Uuid:          newUuid,
+			Name:          "Test Recipe",
+			Description:   "Test Recipe Description",
+			Author:        "Test Author",
+			Style:         domain.StyleNeapolitan,
+			DoughDensity:  0.38,
+			ServingWeight: 280,
+			Version:       3,
+			Dough: domain.Dough{
+				PercentVariation: -10,
+				Ingredients: []domain.Ingredient{
+					{Name: "flour", Amount: 60, Unit: "%", Catalogue: flour},
+					{Name: "water", Amount: 30, Unit: "%", Notes: "cold", Catalogue: water},
+				},
+			},
+			Topping: domain.Topping{
+				ReferenceArea: 1200,
+				Ingredients: []domain.Ingredient{
+					{Name: "mozzarellaCheese", Amount: 250, Unit: "g", Catalogue: mozzarella},
+					{Name: "referenceArea", Amount: 20, Unit: "g", Catalogue: uncatalogued},
+				},
+			},
+			Steps: domain.Steps{
+				RecipeId: 1,
+				Steps: []domain.Step{
+					{Id: 7, StepNumber: 1, Description: "Knead the dough"},
+					{Id: 8, StepNumber: 2, Description: "Bake"},
+				},
+			},
+		}
+
+		mock.ExpectQuery(recipeQuery).
+			WithArgs(newUuid).
+			WillReturnRows(sqlmock.NewRows(recipeColumns).
+				AddRow(1, newUuid, "Test Recipe", "Test Recipe Description", "Test Author", -10, 1200, "neapolitan", 0.38, 280, 3, nil, nil))
+		mock.ExpectQuery(ingredientsQuery).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(ingredientColumns).
+				AddRow(1, "dough", "flour", 60, "%", "", 1, "flour", 0.593, 340, 11, 72.7, 1.1).
+				AddRow(1, "dough", "water", 30, "%", "cold", 2, "water", 1, 0, 0, 0, 0).
+				AddRow(1, "topping", "mozzarellaCheese", 250, "g", "", 3, "dairy", nil, 253, 18.7, 0.7, 19.5).
+				AddRow(1, "topping", "referenceArea", 20, "g", "", 4, "", nil, nil, nil, nil, nil))
+		mock.ExpectQuery(catalogueDetailsQuery).
+			WithArgs(1, 2, 3, 4, 1, 2, 3, 4, 1, 2, 3, 4, 1, 2, 3, 4).
+			WillReturnRows(sqlmock.NewRows(catalogueDetailsColumns).
+				AddRow(1, "alias", "", "farina").
+				AddRow(1, "alias", "", "wheatFlour").
+				AddRow(1, "allergen", "", "gluten").
+				AddRow(1, "diet", "", "vegan").
+				AddRow(1, "display_name", "en", "Soft wheat flour type 00").
+				AddRow(1, "display_name", "it", "Farina di grano tenero 00").
+				AddRow(3, "allergen", "", "milk"))
+		mock.ExpectQuery(stepsQuery).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(stepColumns).
+				AddRow(7, 1, "Knead the dough").
+				AddRow(8, 2, "Bake"))
+
+		recipe, err := repo.GetRecipeByUuid(context.Background(), newUuid)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedRecipe, recipe)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when recipe is not found", func(t *testing.T) {
+		mock.ExpectQuery(recipeQuery).
+			WithArgs(newUuid).
+			WillReturnError(sql.ErrNoRows)
+
+		recipe, err := repo.GetRecipeByUuid(context.Background(), newUuid)
+
+		assert.ErrorIs(t, err, domain.ErrRecipeNotFound)
+		assert.Nil(t, recipe)
+	})
+
+	t.Run("should return error on DB failure", func(t *testing.T) {
+		mock.ExpectQuery(recipeQuery).
+			WithArgs(newUuid).
+			WillReturnError(sql.ErrConnDone)
+
+		recipe, err := repo.GetRecipeByUuid(context.Background(), newUuid)
+
+		assert.Error(t, err)
+		assert.Nil(t, recipe)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error on unknown ingredient section", func(t *testing.T) {
+		mock.ExpectQuery(recipeQuery).
+			WithArgs(newUuid).
+			WillReturnRows(sqlmock.NewRows(recipeColumns).AddRow(1, newUuid, "Test Recipe", "", "", 0, 0, nil, nil, nil, 1, nil, nil))
+		mock.ExpectQuery(ingredientsQuery).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(ingredientColumns).AddRow(1, "filling", "ricotta", 100, "g", "", 1, "dairy", nil, nil, nil, nil, nil))
+
+		recipe, err := repo.GetRecipeByUuid(context.Background(), newUuid)
+
+		assert.ErrorContains(t, err, `unknown ingredient section "filling"`)
+		assert.Nil(t, recipe)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListRecipes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	margheritaUuid, marinaraUuid := uuid.New(), uuid.New()
+	archivedAt := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM recipes WHERE deleted_at IS NULL ORDER BY name, id`)).
+		WillReturnRows(sqlmock.NewRows(recipeColumns).
+			AddRow(1, margheritaUuid, "Margherita", "", "", 0, 1200, nil, nil, nil, 1, nil, nil).
+			AddRow(2, marinaraUuid, "Marinara", "", "", 0, 1200, nil, nil, nil, 2, archivedAt, nil))
+	mock.ExpectQuery(ingredientsQuery).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows(ingredientColumns).
+			AddRow(1, "dough", "flour", 60, "%", "", 1, "flour", nil, nil, nil, nil, nil).
+			AddRow(1, "topping", "mozzarellaCheese", 250, "g", "", 2, "dairy", nil, nil, nil, nil, nil).
+			AddRow(2, "dough", "flour", 60, "%", "", 1, "flour", nil, nil, nil, nil, nil).
+			AddRow(2, "topping", "garlic", 5, "g", "", 3, "", nil, nil, nil, nil, nil))
+	mock.ExpectQuery(catalogueDetailsQuery).
+		WithArgs(1, 2, 3, 1, 2, 3, 1, 2, 3, 1, 2, 3).
+		WillReturnRows(sqlmock.NewRows(catalogueDetailsColumns).
+			AddRow(1, "allergen", "", "gluten").
+			AddRow(2, "allergen", "", "milk"))
+
+	recipes, err := NewRecipeRepository(db, MySQL).ListRecipes(context.Background(), domain.RecipeFilter{IncludeArchived: true})
+
+	assert.NoError(t, err)
+	if assert.Len(t, recipes, 2) {
+		assert.Equal(t, margheritaUuid, recipes[0].Uuid)
+		assert.Equal(t, []string{"flour"}, ingredientNames(recipes[0].Dough.Ingredients))
+		assert.Equal(t, []string{"mozzarellaCheese"}, ingredientNames(recipes[0].Topping.Ingredients))
+		assert.Equal(t, []domain.Allergen{domain.AllergenGluten, domain.AllergenMilk}, recipes[0].Labels().Allergens)
+		assert.Equal(t, []string{"garlic"}, recipes[1].Labels().Unlabelled)
+		assert.Nil(t, recipes[0].ArchivedAt)
+		assert.Equal(t, &archivedAt, recipes[1].ArchivedAt)
+		assert.Same(t, recipes[0].Dough.Ingredients[0].Catalogue, recipes[1].Dough.Ingredients[0].Catalogue)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func ingredientNames(ingredients []domain.Ingredient) []string {
+	names := make([]string, 0, len(ingredients))
+	for _, ingredient := range ingredients {
+		names = append(names, ingredient.Name)
+	}
+	return names
+}
+
+func TestGetRecipeByUuidTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	recipeUuid := uuid.New()
+	mock.ExpectQuery(recipeQuery).
+		WithArgs(recipeUuid).
+		WillReturnRows(sqlmock.NewRows(recipeColumns).AddRow(1, recipeUuid, "Test Recipe", "", "", 0, 0, nil, nil, nil, 1, nil, nil))
+	mock.ExpectQuery(ingredientsQuery).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(ingredientColumns).
+			AddRow(1, "dough", "flour", 60, "%", "", 1, "flour", nil, nil, nil, nil, nil).
+			AddRow(1, "topping", "basil", 10, "g", "", 2, "herb", nil, nil, nil, nil, nil))
+	mock.ExpectQuery(catalogueDetailsQuery).
+		WithArgs(1, 2, 1, 2, 1, 2, 1, 2).
+		WillReturnRows(sqlmock.NewRows(catalogueDetailsColumns))
+	mock.ExpectQuery(stepsQuery).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(stepColumns))
+
+	_, err = NewRecipeRepository(db, MySQL).GetRecipeByUuid(context.Background(), recipeUuid)
+	assert.NoError(t, err)
+
+	var spanNames []string
+	for _, span := range recorder.Ended() {
+		spanNames = append(spanNames, span.Name())
+	}
+	assert.ElementsMatch(t, []string{"RecipeRepository.GetRecipeByUuid", "RecipeRepository.loadIngredients", "RecipeRepository.loadSteps"}, spanNames)
+}
+
+func TestGetRecipeByUuidQueryTimeout(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRecipeRepository(db, MySQL, WithQueryTimeout(20*time.Millisecond))
+	recipeUuid := uuid.New()
+
+	rows := sqlmock.NewRows(recipeColumns).AddRow(1, recipeUuid, "Slow", "", "", 0, 0, nil, nil, nil, 1, nil, nil)
+	mock.ExpectQuery(recipeQuery).
+		WithArgs(recipeUuid).
+		WillDelayFor(time.Second).
+		WillReturnRows(rows)
+
+	start := time.Now()
+	recipe, err := repo.GetRecipeByUuid(context.Background(), recipeUuid)
+
+	assert.Nil(t, recipe)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "query timed out after 20ms")
+	assert.ErrorContains(t, err, "canceling query due to user request")
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestGetRecipeByUuidCancelledContext(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRecipeRepository(db, MySQL, WithQueryTimeout(time.Minute))
+	recipeUuid := uuid.New()
+
+	mock.ExpectQuery(recipeQuery).
+		WithArgs(recipeUuid).
+		WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	recipe, err := repo.GetRecipeByUuid(ctx, recipeUuid)
+
+	assert.Nil(t, recipe)
+	assert.ErrorIs(t, err, context.Canceled)
+}
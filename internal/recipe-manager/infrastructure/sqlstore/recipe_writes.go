@@ -0,0 +1,285 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/tracing"
+)
+
+// CreateRecipe stores a new recipe, with its ingredients, at version 1.
+func (rr RecipeRepository) CreateRecipe(ctx context.Context, recipe domain.Recipe) error {
+	ctx, span := rr.startWrite(ctx, "RecipeRepository.CreateRecipe", "INSERT", recipe.Uuid)
+	defer span.End()
+
+	ctx, cancel := rr.withQueryTimeout(ctx)
+	defer cancel()
+
+	err := rr.inTx(ctx, func(tx *sql.Tx) error {
+		style, doughDensity, servingWeight := styleColumns(recipe)
+		recipeId, err := rr.dialect.insertId(ctx, tx, `INSERT INTO recipes
+			(uuid, name, description, author, dough_percent_variation, topping_reference_area,
+				style, dough_density, serving_weight)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			recipe.Uuid, recipe.Name, recipe.Description, recipe.Author, recipe.Dough.PercentVariation, recipe.Topping.ReferenceArea,
+			style, doughDensity, servingWeight)
+		if err != nil {
+			return fmt.Errorf("failed to store recipe %s: %w", recipe.Uuid, err)
+		}
+		if err := rr.insertIngredients(ctx, tx, recipeId, sectionDough, "%", recipe.Dough.Ingredients); err != nil {
+			return err
+		}
+		return rr.insertIngredients(ctx, tx, recipeId, sectionTopping, "g", recipe.Topping.Ingredients)
+	})
+	if err != nil {
+		err = rr.wrapTimeout(ctx, err)
+		recordError(span, err)
+		return err
+	}
+	return nil
+}
+
+// UpdateRecipe replaces the details and the ingredients of the recipe with the
+// UUID of recipe and bumps its version, provided it is still at
+// recipe.Version; a zero version matches any. It fails with
+// domain.ErrVersionConflict when the recipe changed in the meantime.
+func (rr RecipeRepository) UpdateRecipe(ctx context.Context, recipe domain.Recipe) error {
+	ctx, span := rr.startWrite(ctx, "RecipeRepository.UpdateRecipe", "UPDATE", recipe.Uuid)
+	defer span.End()
+
+	ctx, cancel := rr.withQueryTimeout(ctx)
+	defer cancel()
+
+	err := rr.inTx(ctx, func(tx *sql.Tx) error {
+		style, doughDensity, servingWeight := styleColumns(recipe)
+		query, args := versioned(`UPDATE recipes
+			SET name = ?, description = ?, author = ?, dough_percent_variation = ?, topping_reference_area = ?,
+				style = ?, dough_density = ?, serving_weight = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP
+			WHERE uuid = ? AND deleted_at IS NULL`, recipe.Version,
+			recipe.Name, recipe.Description, recipe.Author, recipe.Dough.PercentVariation, recipe.Topping.ReferenceArea,
+			style, doughDensity, servingWeight, recipe.Uuid)
+		if err := rr.execOne(ctx, tx, recipe.Uuid, recipe.Version, query, args...); err != nil {
+			return err
+		}
+
+		var recipeId int64
+		if err := tx.QueryRowContext(ctx, rr.dialect.Rebind(`SELECT id FROM recipes WHERE uuid = ?`), recipe.Uuid).
+			Scan(&recipeId); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, rr.dialect.Rebind(`DELETE FROM recipe_ingredients WHERE recipe_id = ?`),
+			recipeId); err != nil {
+			return err
+		}
+		if err := rr.insertIngredients(ctx, tx, recipeId, sectionDough, "%", recipe.Dough.Ingredients); err != nil {
+			return err
+		}
+		return rr.insertIngredients(ctx, tx, recipeId, sectionTopping, "g", recipe.Topping.Ingredients)
+	})
+	if err != nil {
+		err = rr.wrapTimeout(ctx, err)
+		recordError(span, err)
+		return err
+	}
+	return nil
+}
+
+// DeleteRecipe marks the recipe as deleted, provided it is still at version;
+// a zero version matches any. The recipe is kept, with its steps, until
+// restored or purged.
+func (rr RecipeRepository) DeleteRecipe(ctx context.Context, recipeUuid uuid.UUID, version int) error {
+	return rr.changeState(ctx, "RecipeRepository.DeleteRecipe", recipeUuid, version,
+		`deleted_at = ?`, time.Now().UTC())
+}
+
+// ArchiveRecipe archives the recipe or brings it back to the listings,
+// provided it is still at version; a zero version matches any.
+func (rr RecipeRepository) ArchiveRecipe(ctx context.Context, recipeUuid uuid.UUID, version int, archived bool) error {
+	if !archived {
+		return rr.changeState(ctx, "RecipeRepository.ArchiveRecipe", recipeUuid, version, `archived_at = NULL`)
+	}
+	// An archived recipe keeps the time it was first archived.
+	return rr.changeState(ctx, "RecipeRepository.ArchiveRecipe", recipeUuid, version,
+		`archived_at = COALESCE(archived_at, ?)`, time.Now().UTC())
+}
+
+func (rr RecipeRepository) changeState(ctx context.Context, spanName string, recipeUuid uuid.UUID, version int, assignment string, args ...any) error {
+	ctx, span := rr.startWrite(ctx, spanName, "UPDATE", recipeUuid)
+	defer span.End()
+
+	ctx, cancel := rr.withQueryTimeout(ctx)
+	defer cancel()
+
+	err := rr.inTx(ctx, func(tx *sql.Tx) error {
+		query, args := versioned(`UPDATE recipes
+			SET `+assignment+`, version = version + 1, updated_at = CURRENT_TIMESTAMP
+			WHERE uuid = ? AND deleted_at IS NULL`, version, append(args, recipeUuid)...)
+		return rr.execOne(ctx, tx, recipeUuid, version, query, args...)
+	})
+	if err != nil {
+		err = rr.wrapTimeout(ctx, err)
+		recordError(span, err)
+		return err
+	}
+	return nil
+}
+
+// RestoreRecipe brings back a deleted recipe. It fails with
+// domain.ErrRecipeNotFound unless the recipe is deleted.
+func (rr RecipeRepository) RestoreRecipe(ctx context.Context, recipeUuid uuid.UUID) error {
+	ctx, span := rr.startWrite(ctx, "RecipeRepository.RestoreRecipe", "UPDATE", recipeUuid)
+	defer span.End()
+
+	ctx, cancel := rr.withQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := conn(ctx, rr.db).ExecContext(ctx, rr.dialect.Rebind(`UPDATE recipes
+		SET deleted_at = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE uuid = ? AND deleted_at IS NOT NULL`), recipeUuid)
+	if err == nil {
+		var affected int64
+		if affected, err = result.RowsAffected(); err == nil && affected == 0 {
+			err = fmt.Errorf("deleted recipe %s: %w", recipeUuid, domain.ErrRecipeNotFound)
+		}
+	}
+	if err != nil {
+		err = rr.wrapTimeout(ctx, err)
+		recordError(span, err)
+		return err
+	}
+	return nil
+}
+
+// PurgeRecipes removes for good, with their steps and ingredients, the
+// recipes deleted before the given time, and returns how many there were.
+func (rr RecipeRepository) PurgeRecipes(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	ctx, span := tracing.GetGlobalTracer(tracerName).Start(ctx, "RecipeRepository.PurgeRecipes",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", rr.dialect.System),
+			attribute.String("db.operation", "DELETE"),
+			attribute.String("db.sql.table", "recipes"),
+		),
+	)
+	defer span.End()
+
+	var purged int64
+	err := rr.inTx(ctx, func(tx *sql.Tx) error {
+		// recipe_steps has no cascade, recipe_ingredients has.
+		if _, err := tx.ExecContext(ctx, rr.dialect.Rebind(
+			`DELETE FROM recipe_steps WHERE recipe_id IN (SELECT id FROM recipes WHERE deleted_at < ?)`),
+			deletedBefore.UTC()); err != nil {
+			return err
+		}
+		result, err := tx.ExecContext(ctx, rr.dialect.Rebind(`DELETE FROM recipes WHERE deleted_at < ?`), deletedBefore.UTC())
+		if err != nil {
+			return err
+		}
+		purged, err = result.RowsAffected()
+		return err
+	})
+	if err != nil {
+		recordError(span, err)
+		return 0, err
+	}
+	span.SetAttributes(attribute.Int64("recipes.count", purged))
+	return purged, nil
+}
+
+// versioned appends the version condition to a query ending with its WHERE
+// clause, unless version is zero.
+func versioned(query string, version int, args ...any) (string, []any) {
+	if version == 0 {
+		return query, args
+	}
+	return query + ` AND version = ?`, append(args, version)
+}
+
+// execOne runs a conditional statement on a single recipe and, when it
+// changes nothing, tells a missing recipe from one at another version.
+func (rr RecipeRepository) execOne(ctx context.Context, tx *sql.Tx, recipeUuid uuid.UUID, version int, query string, args ...any) error {
+	result, err := tx.ExecContext(ctx, rr.dialect.Rebind(query), args...)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil || affected > 0 {
+		return err
+	}
+
+	var current int
+	err = tx.QueryRowContext(ctx, rr.dialect.Rebind(`SELECT version FROM recipes WHERE uuid = ? AND deleted_at IS NULL`),
+		recipeUuid).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("recipe %s: %w", recipeUuid, domain.ErrRecipeNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("recipe %s is at version %d, not %d: %w", recipeUuid, current, version, domain.ErrVersionConflict)
+}
+
+// insertIngredients stores the ingredients of one section in order. Each is
+// linked by name or alias to the ingredients table, which gets the unknown
+// ones without catalogue data.
+func (rr RecipeRepository) insertIngredients(ctx context.Context, tx *sql.Tx, recipeId int64, section, defaultUnit string, ingredients []domain.Ingredient) error {
+	for position, ingredient := range ingredients {
+		ingredientId, err := rr.ingredientId(ctx, tx, ingredient.Name)
+		if err != nil {
+			return fmt.Errorf("failed to resolve ingredient %s: %w", ingredient.Name, err)
+		}
+		unit := ingredient.Unit
+		if unit == "" {
+			unit = defaultUnit
+		}
+		var notes any
+		if ingredient.Notes != "" {
+			notes = ingredient.Notes
+		}
+		if _, err := tx.ExecContext(ctx, rr.dialect.Rebind(
+			`INSERT INTO recipe_ingredients (recipe_id, ingredient_id, section, position, amount, unit, notes)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`),
+			recipeId, ingredientId, section, position+1, ingredient.Amount, unit, notes); err != nil {
+			return fmt.Errorf("failed to store ingredient %s: %w", ingredient.Name, err)
+		}
+	}
+	return nil
+}
+
+func (rr RecipeRepository) ingredientId(ctx context.Context, tx *sql.Tx, name string) (int64, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx, rr.dialect.Rebind(
+		`SELECT id FROM ingredients WHERE name = ?
+		UNION ALL
+		SELECT ingredient_id FROM ingredient_aliases WHERE LOWER(alias) = LOWER(?)`), name, name).Scan(&id)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return id, err
+	}
+	return rr.dialect.insertId(ctx, tx, `INSERT INTO ingredients (name) VALUES (?)`, name)
+}
+
+func (rr RecipeRepository) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	return WithTransaction(ctx, rr.db, func(ctx context.Context) error {
+		return fn(ctx.Value(txKey{}).(*sql.Tx))
+	})
+}
+
+func (rr RecipeRepository) startWrite(ctx context.Context, name, operation string, recipeUuid uuid.UUID) (context.Context, trace.Span) {
+	return tracing.GetGlobalTracer(tracerName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", rr.dialect.System),
+			attribute.String("db.operation", operation),
+			attribute.String("db.sql.table", "recipes"),
+			attribute.String("recipe.uuid", recipeUuid.String()),
+		),
+	)
+}
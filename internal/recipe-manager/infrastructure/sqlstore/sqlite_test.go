@@ -0,0 +1,67 @@
+package sqlstore_test
+
+import (
+	"database/sql"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"github.com/cfioretti/recipe-manager/configs"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/sqlstore"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/sqlstore/migrations"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/sqlstore/sqlstoretest"
+	embedded "github.com/cfioretti/recipe-manager/migrations"
+)
+
+// SQLite runs in process, so the conformance tests run with the unit tests;
+// MySQL and PostgreSQL run them in test/integration.
+func TestSQLiteRecipeRepository(t *testing.T) {
+	config := configs.DBConfig{Driver: configs.DBDriverSQLite, Path: filepath.Join(t.TempDir(), "recipes.db")}
+	migrateSQLite(t, config, embedded.Schema, migrations.SchemaMigrationsTable)
+
+	db, err := sql.Open(config.DriverName(), config.DSN())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	sqlstoretest.TestRecipeRepository(t, db, sqlstore.SQLite)
+}
+
+// The seed data may run again over itself, as it does on databases created
+// before it was tracked.
+func TestSQLiteSeedRerun(t *testing.T) {
+	config := configs.DBConfig{Driver: configs.DBDriverSQLite, Path: filepath.Join(t.TempDir(), "recipes.db")}
+	migrateSQLite(t, config, embedded.Schema, migrations.SchemaMigrationsTable)
+	migrateSQLite(t, config, embedded.Seed, migrations.SeedMigrationsTable)
+
+	seed, err := embedded.Seed(config.Driver)
+	require.NoError(t, err)
+	migrator, err := migrations.NewMigrator(config.Driver, config.DSN(), seed, migrations.SeedMigrationsTable)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Force(-1))
+	require.NoError(t, migrator.Up())
+	require.NoError(t, migrator.Close())
+
+	db, err := sql.Open(config.DriverName(), config.DSN())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	for table, rows := range map[string]int{"recipes": 1, "recipe_ingredients": 10, "recipe_steps": 10} {
+		var count int
+		require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM "+table).Scan(&count))
+		assert.Equal(t, rows, count, table)
+	}
+}
+
+func migrateSQLite(t *testing.T, config configs.DBConfig, files func(driver string) (fs.FS, error), table string) {
+	t.Helper()
+	fsys, err := files(config.Driver)
+	require.NoError(t, err)
+	migrator, err := migrations.NewMigrator(config.Driver, config.DSN(), fsys, table)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up())
+	require.NoError(t, migrator.Close())
+}
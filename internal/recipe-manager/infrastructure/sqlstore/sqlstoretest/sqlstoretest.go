@@ -0,0 +1,606 @@
+// Package sqlstoretest holds the conformance tests every database supported
+// by sqlstore must pass, and the fixtures they rely on.
+package sqlstoretest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/catalogue"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/sqlstore"
+	"github.com/cfioretti/recipe-manager/migrations"
+)
+
+// InsertRecipe stores recipe with its ingredients in the normalized tables,
+// keeping the order of the Ingredients slices. Ingredients missing from the
+// ingredients table are added without catalogue data.
+func InsertRecipe(db *sql.DB, dialect sqlstore.Dialect, recipe domain.Recipe) error {
+	_, err := db.Exec(dialect.Rebind(
+		`INSERT INTO recipes (uuid, name, description, author, dough_percent_variation, topping_reference_area)
+		VALUES (?, ?, ?, ?, ?, ?)`),
+		recipe.Uuid, recipe.Name, recipe.Description, recipe.Author, recipe.Dough.PercentVariation, recipe.Topping.ReferenceArea)
+	if err != nil {
+		return err
+	}
+	var recipeId int64
+	if err := db.QueryRow(dialect.Rebind(`SELECT id FROM recipes WHERE uuid = ?`), recipe.Uuid).Scan(&recipeId); err != nil {
+		return err
+	}
+
+	insert := func(section, defaultUnit string, ingredients []domain.Ingredient) error {
+		for position, ingredient := range ingredients {
+			ingredientId, err := ingredientId(db, dialect, ingredient.Name)
+			if err != nil {
+				return err
+			}
+			unit := ingredient.Unit
+			if unit == "" {
+				unit = defaultUnit
+			}
+			var notes any
+			if ingredient.Notes != "" {
+				notes = ingredient.Notes
+			}
+			_, err = db.Exec(dialect.Rebind(
+				`INSERT INTO recipe_ingredients (recipe_id, ingredient_id, section, position, amount, unit, notes)
+				VALUES (?, ?, ?, ?, ?, ?, ?)`),
+				recipeId, ingredientId, section, position+1, ingredient.Amount, unit, notes)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := insert("dough", "%", recipe.Dough.Ingredients); err != nil {
+		return err
+	}
+	return insert("topping", "g", recipe.Topping.Ingredients)
+}
+
+func ingredientId(db *sql.DB, dialect sqlstore.Dialect, name string) (int64, error) {
+	query := dialect.Rebind(`SELECT id FROM ingredients WHERE name = ?`)
+	var id int64
+	err := db.QueryRow(query, name).Scan(&id)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return id, err
+	}
+	if _, err := db.Exec(dialect.Rebind(`INSERT INTO ingredients (name) VALUES (?)`), name); err != nil {
+		return 0, err
+	}
+	return id, db.QueryRow(query, name).Scan(&id)
+}
+
+// ImportCatalogue imports the bundled ingredient catalogue.
+func ImportCatalogue(db *sql.DB, dialect sqlstore.Dialect) error {
+	ingredients, err := catalogue.Parse(migrations.Catalogue())
+	if err != nil {
+		return err
+	}
+	return sqlstore.NewIngredientCatalogue(db, dialect).Import(context.Background(), ingredients)
+}
+
+// TestRecipeRepository checks the repository and the catalogue import
+// against db, which must hold the schema migrations and no data.
+func TestRecipeRepository(t *testing.T, db *sql.DB, dialect sqlstore.Dialect) {
+	ctx := context.Background()
+	repository := sqlstore.NewRecipeRepository(db, dialect)
+
+	marinara := domain.Recipe{
+		Uuid:        uuid.New(),
+		Name:        "Marinara",
+		Description: "Tomato, garlic and oregano",
+		Author:      "PizzaMaker",
+		Dough: domain.Dough{
+			PercentVariation: -10,
+			Ingredients: []domain.Ingredient{
+				{Name: "flour", Amount: 55.7},
+				{Name: "acqua", Amount: 41.6, Notes: "cold"},
+				{Name: "salt", Amount: 1.1},
+			},
+		},
+		Topping: domain.Topping{
+			ReferenceArea: 1200,
+			Ingredients: []domain.Ingredient{
+				{Name: "peeledTomatoes", Amount: 300},
+				{Name: "garlic", Amount: 5},
+				{Name: "oregano", Amount: 2},
+			},
+		},
+	}
+	margherita := domain.Recipe{
+		Uuid: uuid.New(),
+		Name: "Margherita",
+		Dough: domain.Dough{
+			Ingredients: []domain.Ingredient{{Name: "flour", Amount: 60}},
+		},
+		Topping: domain.Topping{
+			ReferenceArea: 1200,
+			Ingredients:   []domain.Ingredient{{Name: "mozzarella", Amount: 250}},
+		},
+	}
+	// Recipes are stored first so the import has aliases to merge.
+	require.NoError(t, InsertRecipe(db, dialect, marinara))
+	require.NoError(t, InsertRecipe(db, dialect, margherita))
+
+	t.Run("imports the catalogue again without changes", func(t *testing.T) {
+		require.NoError(t, ImportCatalogue(db, dialect))
+		require.NoError(t, ImportCatalogue(db, dialect))
+	})
+
+	t.Run("returns a recipe with its ingredients in order", func(t *testing.T) {
+		recipe, err := repository.GetRecipeByUuid(ctx, marinara.Uuid)
+		require.NoError(t, err)
+
+		assert.Equal(t, marinara.Uuid, recipe.Uuid)
+		assert.Equal(t, marinara.Name, recipe.Name)
+		assert.Equal(t, marinara.Description, recipe.Description)
+		assert.Equal(t, marinara.Author, recipe.Author)
+		assert.InDelta(t, -10, recipe.Dough.PercentVariation, 1e-9)
+		assert.InDelta(t, 1200, recipe.Topping.ReferenceArea, 1e-9)
+		assert.Equal(t, []string{"flour", "water", "salt"}, names(recipe.Dough.Ingredients))
+		assert.Equal(t, []string{"peeledTomatoes", "garlic", "oregano"}, names(recipe.Topping.Ingredients))
+
+		water := recipe.Dough.Ingredients[1]
+		assert.InDelta(t, 41.6, water.Amount, 1e-9)
+		assert.Equal(t, "%", water.Unit)
+		assert.Equal(t, "cold", water.Notes)
+		assert.Equal(t, "g", recipe.Topping.Ingredients[0].Unit)
+	})
+
+	t.Run("links ingredients to the catalogue", func(t *testing.T) {
+		recipe, err := repository.GetRecipeByUuid(ctx, marinara.Uuid)
+		require.NoError(t, err)
+
+		flour := recipe.Dough.Ingredients[0].Catalogue
+		require.NotNil(t, flour)
+		assert.Equal(t, "flour", flour.Category)
+		assert.Equal(t, "Farina di grano tenero 00", flour.DisplayNames["it"])
+		assert.Contains(t, flour.Aliases, "farina")
+		assert.Equal(t, []domain.Allergen{domain.AllergenGluten}, flour.Allergens)
+		assert.ElementsMatch(t, []domain.Diet{domain.DietVegetarian, domain.DietVegan}, flour.Diets)
+		assert.InDelta(t, 0.593, flour.Density, 1e-9)
+		if assert.NotNil(t, flour.Nutrition) {
+			assert.InDelta(t, 340, flour.Nutrition.Kcal, 1e-9)
+		}
+
+		garlic := recipe.Topping.Ingredients[1].Catalogue
+		require.NotNil(t, garlic)
+		assert.False(t, garlic.Catalogued())
+	})
+
+	t.Run("merges ingredients stored under an alias", func(t *testing.T) {
+		recipe, err := repository.GetRecipeByUuid(ctx, margherita.Uuid)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"mozzarellaCheese"}, names(recipe.Topping.Ingredients))
+		assert.Equal(t, []domain.Allergen{domain.AllergenGluten, domain.AllergenMilk}, recipe.Labels().Allergens)
+	})
+
+	t.Run("reports a missing recipe", func(t *testing.T) {
+		recipe, err := repository.GetRecipeByUuid(ctx, uuid.New())
+
+		assert.ErrorIs(t, err, domain.ErrRecipeNotFound)
+		assert.Nil(t, recipe)
+	})
+
+	t.Run("returns a batch of recipes leaving out the missing ones", func(t *testing.T) {
+		recipes, err := repository.GetRecipesByUuids(ctx, []uuid.UUID{marinara.Uuid, uuid.New(), margherita.Uuid})
+		require.NoError(t, err)
+
+		if assert.Len(t, recipes, 2) {
+			assert.Equal(t, margherita.Uuid, recipes[0].Uuid)
+			assert.Equal(t, marinara.Uuid, recipes[1].Uuid)
+			assert.Equal(t, []string{"flour", "water", "salt"}, names(recipes[1].Dough.Ingredients))
+		}
+	})
+
+	t.Run("lists recipes by name", func(t *testing.T) {
+		recipes, err := repository.ListRecipes(ctx, domain.RecipeFilter{})
+		require.NoError(t, err)
+
+		if assert.Len(t, recipes, 2) {
+			assert.Equal(t, margherita.Uuid, recipes[0].Uuid)
+			assert.Equal(t, marinara.Uuid, recipes[1].Uuid)
+			assert.Equal(t, []string{"flour", "water", "salt"}, names(recipes[1].Dough.Ingredients))
+		}
+	})
+
+	t.Run("updates a recipe at the expected version", func(t *testing.T) {
+		recipe, err := repository.GetRecipeByUuid(ctx, marinara.Uuid)
+		require.NoError(t, err)
+		require.Equal(t, 1, recipe.Version)
+
+		recipe.Name = "Marinara DOC"
+		recipe.Style, recipe.DoughDensity, recipe.ServingWeight = domain.StyleRomanTeglia, 0.5, 320
+		recipe.Dough.Ingredients = recipe.Dough.Ingredients[:2]
+		recipe.Topping.Ingredients = []domain.Ingredient{
+			{Name: "peeledTomatoes", Amount: 320},
+			{Name: "mozzarella", Amount: 100},
+			{Name: "anchovies", Amount: 20, Unit: "pcs"},
+		}
+		require.NoError(t, repository.UpdateRecipe(ctx, *recipe))
+
+		updated, err := repository.GetRecipeByUuid(ctx, marinara.Uuid)
+		require.NoError(t, err)
+		assert.Equal(t, 2, updated.Version)
+		assert.Equal(t, "Marinara DOC", updated.Name)
+		assert.Equal(t, domain.StyleRomanTeglia, updated.Style)
+		assert.Equal(t, 0.5, updated.DoughDensity)
+		assert.Equal(t, 320.0, updated.ServingWeight)
+		assert.Equal(t, []string{"flour", "water"}, names(updated.Dough.Ingredients))
+		assert.Equal(t, []string{"peeledTomatoes", "mozzarellaCheese", "anchovies"}, names(updated.Topping.Ingredients))
+		assert.Equal(t, "g", updated.Topping.Ingredients[0].Unit)
+		assert.Equal(t, "pcs", updated.Topping.Ingredients[2].Unit)
+	})
+
+	t.Run("rejects an update based on a stale version", func(t *testing.T) {
+		stale := marinara
+		stale.Version = 1
+		stale.Name = "Marinara again"
+
+		assert.ErrorIs(t, repository.UpdateRecipe(ctx, stale), domain.ErrVersionConflict)
+
+		recipe, err := repository.GetRecipeByUuid(ctx, marinara.Uuid)
+		require.NoError(t, err)
+		assert.Equal(t, "Marinara DOC", recipe.Name)
+		assert.Equal(t, 2, recipe.Version)
+	})
+
+	t.Run("reports a missing recipe on update", func(t *testing.T) {
+		missing := marinara
+		missing.Uuid = uuid.New()
+		missing.Version = 1
+
+		assert.ErrorIs(t, repository.UpdateRecipe(ctx, missing), domain.ErrRecipeNotFound)
+	})
+
+	t.Run("archives a recipe out of the listings", func(t *testing.T) {
+		assert.ErrorIs(t, repository.ArchiveRecipe(ctx, marinara.Uuid, 1, true), domain.ErrVersionConflict)
+		require.NoError(t, repository.ArchiveRecipe(ctx, marinara.Uuid, 2, true))
+
+		recipe, err := repository.GetRecipeByUuid(ctx, marinara.Uuid)
+		require.NoError(t, err)
+		assert.Equal(t, 3, recipe.Version)
+		require.NotNil(t, recipe.ArchivedAt)
+		assert.WithinDuration(t, time.Now(), *recipe.ArchivedAt, time.Minute)
+
+		recipes, err := repository.ListRecipes(ctx, domain.RecipeFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{margherita.Uuid}, uuids(recipes))
+		recipes, err = repository.ListRecipes(ctx, domain.RecipeFilter{IncludeArchived: true})
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{margherita.Uuid, marinara.Uuid}, uuids(recipes))
+
+		require.NoError(t, repository.ArchiveRecipe(ctx, marinara.Uuid, 0, false))
+		recipe, err = repository.GetRecipeByUuid(ctx, marinara.Uuid)
+		require.NoError(t, err)
+		assert.Nil(t, recipe.ArchivedAt)
+	})
+
+	t.Run("deletes a recipe at the expected version", func(t *testing.T) {
+		_, err := db.Exec(dialect.Rebind(`INSERT INTO recipe_steps (recipe_id, step_number, description)
+			SELECT id, 1, 'Knead' FROM recipes WHERE uuid = ?`), margherita.Uuid)
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, repository.DeleteRecipe(ctx, margherita.Uuid, 2), domain.ErrVersionConflict)
+		require.NoError(t, repository.DeleteRecipe(ctx, margherita.Uuid, 1))
+
+		_, err = repository.GetRecipeByUuid(ctx, margherita.Uuid)
+		assert.ErrorIs(t, err, domain.ErrRecipeNotFound)
+		assert.ErrorIs(t, repository.DeleteRecipe(ctx, margherita.Uuid, 0), domain.ErrRecipeNotFound)
+		assert.ErrorIs(t, repository.UpdateRecipe(ctx, margherita), domain.ErrRecipeNotFound)
+
+		recipes, err := repository.ListRecipes(ctx, domain.RecipeFilter{Deleted: true})
+		require.NoError(t, err)
+		if assert.Len(t, recipes, 1) {
+			assert.Equal(t, margherita.Uuid, recipes[0].Uuid)
+			assert.NotNil(t, recipes[0].DeletedAt)
+		}
+	})
+
+	t.Run("restores a deleted recipe", func(t *testing.T) {
+		require.NoError(t, repository.RestoreRecipe(ctx, margherita.Uuid))
+		assert.ErrorIs(t, repository.RestoreRecipe(ctx, margherita.Uuid), domain.ErrRecipeNotFound)
+
+		recipe, err := repository.GetRecipeByUuid(ctx, margherita.Uuid)
+		require.NoError(t, err)
+		assert.Equal(t, 3, recipe.Version)
+		assert.Nil(t, recipe.DeletedAt)
+		if assert.Len(t, recipe.Steps.Steps, 1) {
+			assert.Equal(t, 1, recipe.Steps.Steps[0].StepNumber)
+			assert.Equal(t, "Knead", recipe.Steps.Steps[0].Description)
+		}
+	})
+
+	t.Run("purges the recipes deleted before the retention", func(t *testing.T) {
+		require.NoError(t, repository.DeleteRecipe(ctx, margherita.Uuid, 3))
+
+		purged, err := repository.PurgeRecipes(ctx, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Zero(t, purged)
+
+		purged, err = repository.PurgeRecipes(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+		assert.ErrorIs(t, repository.RestoreRecipe(ctx, margherita.Uuid), domain.ErrRecipeNotFound)
+
+		_, err = repository.GetRecipeByUuid(ctx, marinara.Uuid)
+		assert.NoError(t, err)
+	})
+
+	bianca := domain.Recipe{
+		Uuid:   uuid.New(),
+		Name:   "Bianca",
+		Author: "PizzaMaker",
+		Dough: domain.Dough{
+			Ingredients: []domain.Ingredient{{Name: "farina", Amount: 60}, {Name: "water", Amount: 38, Notes: "cold"}},
+		},
+		Topping: domain.Topping{
+			ReferenceArea: 1200,
+			Ingredients:   []domain.Ingredient{{Name: "rosemary", Amount: 3, Unit: "sprigs"}},
+		},
+	}
+
+	t.Run("creates a recipe with its ingredients", func(t *testing.T) {
+		require.NoError(t, repository.CreateRecipe(ctx, bianca))
+		assert.Error(t, repository.CreateRecipe(ctx, bianca))
+
+		recipe, err := repository.GetRecipeByUuid(ctx, bianca.Uuid)
+		require.NoError(t, err)
+		assert.Equal(t, 1, recipe.Version)
+		assert.Equal(t, "PizzaMaker", recipe.Author)
+		assert.Equal(t, []string{"flour", "water"}, names(recipe.Dough.Ingredients))
+		assert.Equal(t, "cold", recipe.Dough.Ingredients[1].Notes)
+		assert.Equal(t, "sprigs", recipe.Topping.Ingredients[0].Unit)
+	})
+
+	outbox := sqlstore.NewOutbox(db, dialect)
+
+	t.Run("stores events with the changes of their transaction", func(t *testing.T) {
+		committed := domain.NewEvent(domain.EventRecipeUpdated, bianca)
+		require.NoError(t, outbox.Transaction(ctx, func(ctx context.Context) error {
+			recipe, err := repository.GetRecipeByUuid(ctx, bianca.Uuid)
+			if err != nil {
+				return err
+			}
+			recipe.Name = "Bianca al rosmarino"
+			if err := repository.UpdateRecipe(ctx, *recipe); err != nil {
+				return err
+			}
+			return outbox.Append(ctx, committed)
+		}))
+
+		rollback := errors.New("rollback")
+		err := outbox.Transaction(ctx, func(ctx context.Context) error {
+			if err := repository.ArchiveRecipe(ctx, bianca.Uuid, 0, true); err != nil {
+				return err
+			}
+			if err := outbox.Append(ctx, domain.NewEvent(domain.EventRecipeUpdated, bianca)); err != nil {
+				return err
+			}
+			return rollback
+		})
+		assert.ErrorIs(t, err, rollback)
+
+		recipe, err := repository.GetRecipeByUuid(ctx, bianca.Uuid)
+		require.NoError(t, err)
+		assert.Equal(t, "Bianca al rosmarino", recipe.Name)
+		assert.Equal(t, 2, recipe.Version)
+		assert.Nil(t, recipe.ArchivedAt)
+
+		pending, err := outbox.Pending(ctx, 10)
+		require.NoError(t, err)
+		if assert.Len(t, pending, 1) {
+			assert.Equal(t, committed.Id, pending[0].Id)
+			assert.Equal(t, domain.EventRecipeUpdated, pending[0].Type)
+			assert.Equal(t, bianca.Uuid.String(), pending[0].Key)
+			assert.Contains(t, string(pending[0].Body), `"name":"Bianca"`)
+		}
+	})
+
+	t.Run("keeps events pending until published", func(t *testing.T) {
+		second := domain.NewEvent(domain.EventRecipeDeleted, bianca)
+		require.NoError(t, outbox.Append(ctx, second))
+
+		pending, err := outbox.Pending(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, pending, 2)
+		first := pending[0]
+		assert.Equal(t, second.Id, pending[1].Id)
+
+		require.NoError(t, outbox.MarkFailed(ctx, first.Id, errors.New("menu board unavailable")))
+		pending, err = outbox.Pending(ctx, 1)
+		require.NoError(t, err)
+		if assert.Len(t, pending, 1) {
+			assert.Equal(t, first.Id, pending[0].Id)
+			assert.Equal(t, 1, pending[0].Attempts)
+		}
+
+		require.NoError(t, outbox.MarkPublished(ctx, first.Id))
+		pending, err = outbox.Pending(ctx, 10)
+		require.NoError(t, err)
+		assert.Len(t, pending, 1)
+
+		purged, err := outbox.PurgePublished(ctx, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Zero(t, purged)
+		purged, err = outbox.PurgePublished(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+	})
+
+	t.Run("leaves parked events out of the pending ones", func(t *testing.T) {
+		pending, err := outbox.Pending(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+
+		require.NoError(t, outbox.MarkParked(ctx, pending[0].Id, errors.New("payload rejected")))
+		pending, err = outbox.Pending(ctx, 10)
+		require.NoError(t, err)
+		assert.Empty(t, pending)
+
+		// Parked events are kept, not purged with the published ones.
+		purged, err := outbox.PurgePublished(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		assert.Zero(t, purged)
+	})
+
+	webhooks := sqlstore.NewWebhookStore(db, dialect)
+	menuBoard := domain.Subscription{
+		Id:         uuid.New(),
+		URL:        "https://menu-board.example/hooks",
+		EventTypes: []domain.EventType{domain.EventRecipeCreated, domain.EventRecipeUpdated},
+		Secret:     "menu-board-secret",
+		CreatedAt:  time.Now().UTC().Truncate(time.Microsecond),
+	}
+	pos := domain.Subscription{
+		Id:        uuid.New(),
+		URL:       "https://pos.example/hooks",
+		Secret:    "pos-secret",
+		CreatedAt: menuBoard.CreatedAt.Add(time.Second),
+	}
+
+	t.Run("stores webhook subscriptions", func(t *testing.T) {
+		require.NoError(t, webhooks.CreateSubscription(ctx, menuBoard))
+		require.NoError(t, webhooks.CreateSubscription(ctx, pos))
+
+		subscriptions, err := webhooks.Subscriptions(ctx)
+		require.NoError(t, err)
+		if assert.Len(t, subscriptions, 2) {
+			assert.Equal(t, menuBoard.Id, subscriptions[0].Id)
+			assert.Equal(t, menuBoard.EventTypes, subscriptions[0].EventTypes)
+			assert.Empty(t, subscriptions[1].EventTypes)
+		}
+
+		subscription, err := webhooks.Subscription(ctx, pos.Id)
+		require.NoError(t, err)
+		assert.Equal(t, "pos-secret", subscription.Secret)
+		assert.True(t, pos.CreatedAt.Equal(subscription.CreatedAt))
+
+		_, err = webhooks.Subscription(ctx, uuid.New())
+		assert.ErrorIs(t, err, domain.ErrSubscriptionNotFound)
+	})
+
+	t.Run("delivers each event once per subscription", func(t *testing.T) {
+		now := time.Now().UTC().Truncate(time.Second)
+		event := uuid.New()
+		delivery := func(subscription uuid.UUID, createdAt time.Time) domain.Delivery {
+			return domain.Delivery{
+				Id:             uuid.New(),
+				SubscriptionId: subscription,
+				EventId:        event,
+				EventType:      domain.EventRecipeCreated,
+				Payload:        []byte(`{"type":"recipe.created"}`),
+				NextAttemptAt:  createdAt,
+				CreatedAt:      createdAt,
+			}
+		}
+		first, second := delivery(menuBoard.Id, now.Add(-time.Minute)), delivery(pos.Id, now)
+		require.NoError(t, webhooks.EnqueueDeliveries(ctx, first, second))
+		require.NoError(t, webhooks.EnqueueDeliveries(ctx, delivery(menuBoard.Id, now)))
+
+		due, err := webhooks.DueDeliveries(ctx, now, 10)
+		require.NoError(t, err)
+		if assert.Len(t, due, 2) {
+			assert.Equal(t, first.Id, due[0].Id)
+			assert.Equal(t, domain.DeliveryPending, due[0].Status)
+			assert.JSONEq(t, `{"type":"recipe.created"}`, string(due[0].Payload))
+		}
+
+		failed := due[0]
+		failed.Attempts, failed.LastError, failed.ResponseStatus = 1, "receiver answered 503", 503
+		failed.NextAttemptAt = now.Add(time.Minute)
+		require.NoError(t, webhooks.RecordAttempt(ctx, failed))
+		delivered := due[1]
+		deliveredAt := now
+		delivered.Attempts, delivered.Status, delivered.ResponseStatus, delivered.DeliveredAt = 1, domain.DeliveryDelivered, 204, &deliveredAt
+		require.NoError(t, webhooks.RecordAttempt(ctx, delivered))
+
+		due, err = webhooks.DueDeliveries(ctx, now, 10)
+		require.NoError(t, err)
+		assert.Empty(t, due)
+
+		stored, err := webhooks.Delivery(ctx, failed.Id)
+		require.NoError(t, err)
+		assert.Equal(t, 1, stored.Attempts)
+		assert.Equal(t, "receiver answered 503", stored.LastError)
+		assert.Equal(t, 503, stored.ResponseStatus)
+		assert.Nil(t, stored.DeliveredAt)
+
+		history, err := webhooks.Deliveries(ctx, domain.DeliveryFilter{SubscriptionId: pos.Id})
+		require.NoError(t, err)
+		if assert.Len(t, history, 1) {
+			assert.Equal(t, domain.DeliveryDelivered, history[0].Status)
+			assert.True(t, now.Equal(*history[0].DeliveredAt))
+		}
+	})
+
+	t.Run("replays a dead delivery", func(t *testing.T) {
+		history, err := webhooks.Deliveries(ctx, domain.DeliveryFilter{SubscriptionId: menuBoard.Id})
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		dead := history[0]
+		dead.Status, dead.Attempts = domain.DeliveryDead, 8
+		require.NoError(t, webhooks.RecordAttempt(ctx, dead))
+
+		deadLetters, err := webhooks.Deliveries(ctx, domain.DeliveryFilter{Status: domain.DeliveryDead, Limit: 10})
+		require.NoError(t, err)
+		if assert.Len(t, deadLetters, 1) {
+			assert.Equal(t, dead.Id, deadLetters[0].Id)
+		}
+
+		now := time.Now().UTC()
+		require.NoError(t, webhooks.ReplayDelivery(ctx, dead.Id, now))
+		due, err := webhooks.DueDeliveries(ctx, now, 10)
+		require.NoError(t, err)
+		if assert.Len(t, due, 1) {
+			assert.Equal(t, dead.Id, due[0].Id)
+			assert.Zero(t, due[0].Attempts)
+			assert.Nil(t, due[0].DeliveredAt)
+		}
+
+		assert.ErrorIs(t, webhooks.ReplayDelivery(ctx, uuid.New(), now), domain.ErrDeliveryNotFound)
+		_, err = webhooks.Delivery(ctx, uuid.New())
+		assert.ErrorIs(t, err, domain.ErrDeliveryNotFound)
+	})
+
+	t.Run("purges delivered deliveries and unsubscribes", func(t *testing.T) {
+		purged, err := webhooks.PurgeDeliveries(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+
+		require.NoError(t, webhooks.DeleteSubscription(ctx, menuBoard.Id))
+		assert.ErrorIs(t, webhooks.DeleteSubscription(ctx, menuBoard.Id), domain.ErrSubscriptionNotFound)
+
+		deliveries, err := webhooks.Deliveries(ctx, domain.DeliveryFilter{})
+		require.NoError(t, err)
+		assert.Empty(t, deliveries)
+		subscriptions, err := webhooks.Subscriptions(ctx)
+		require.NoError(t, err)
+		assert.Len(t, subscriptions, 1)
+	})
+}
+
+func uuids(recipes []domain.Recipe) []uuid.UUID {
+	uuids := make([]uuid.UUID, 0, len(recipes))
+	for _, recipe := range recipes {
+		uuids = append(uuids, recipe.Uuid)
+	}
+	return uuids
+}
+
+func names(ingredients []domain.Ingredient) []string {
+	names := make([]string, 0, len(ingredients))
+	for _, ingredient := range ingredients {
+		names = append(names, ingredient.Name)
+	}
+	return names
+}
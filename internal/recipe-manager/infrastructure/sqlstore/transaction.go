@@ -0,0 +1,47 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// querier is what the repositories need from a database or a transaction.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type txKey struct{}
+
+// WithTransaction runs fn in a transaction carried by its context, in which
+// the repositories sharing db take part, and commits it unless fn fails. It
+// joins the transaction of ctx, if there is one.
+func WithTransaction(ctx context.Context, db *sql.DB, fn func(ctx context.Context) error) error {
+	if _, found := ctx.Value(txKey{}).(*sql.Tx); found {
+		return fn(ctx)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// conn returns the transaction of ctx, if there is one, or db.
+func conn(ctx context.Context, db *sql.DB) querier {
+	if tx, found := ctx.Value(txKey{}).(*sql.Tx); found {
+		return tx
+	}
+	return db
+}
@@ -0,0 +1,318 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/tracing"
+)
+
+// WebhookStore keeps the webhook subscriptions and their deliveries.
+type WebhookStore struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+func NewWebhookStore(db *sql.DB, dialect Dialect) *WebhookStore {
+	return &WebhookStore{db: db, dialect: dialect}
+}
+
+const deliverySelection = `id, subscription_id, event_id, event_type, payload, status, attempts, last_error,
+	response_status, next_attempt_at, created_at, delivered_at`
+
+func (ws *WebhookStore) CreateSubscription(ctx context.Context, subscription domain.Subscription) error {
+	ctx, span := ws.start(ctx, "WebhookStore.CreateSubscription", "INSERT", "webhook_subscriptions")
+	defer span.End()
+
+	_, err := ws.db.ExecContext(ctx, ws.dialect.Rebind(
+		`INSERT INTO webhook_subscriptions (id, url, event_types, secret, created_at) VALUES (?, ?, ?, ?, ?)`),
+		subscription.Id, subscription.URL, joinEventTypes(subscription.EventTypes), subscription.Secret,
+		subscription.CreatedAt.UTC())
+	if err != nil {
+		recordError(span, err)
+		return fmt.Errorf("failed to store subscription: %w", err)
+	}
+	return nil
+}
+
+// Subscriptions returns every subscription, oldest first.
+func (ws *WebhookStore) Subscriptions(ctx context.Context) ([]domain.Subscription, error) {
+	ctx, span := ws.start(ctx, "WebhookStore.Subscriptions", "SELECT", "webhook_subscriptions")
+	defer span.End()
+
+	rows, err := ws.db.QueryContext(ctx,
+		`SELECT id, url, event_types, secret, created_at FROM webhook_subscriptions ORDER BY created_at, id`)
+	if err != nil {
+		recordError(span, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []domain.Subscription
+	for rows.Next() {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
+			recordError(span, err)
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	if err := rows.Err(); err != nil {
+		recordError(span, err)
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (ws *WebhookStore) Subscription(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	ctx, span := ws.start(ctx, "WebhookStore.Subscription", "SELECT", "webhook_subscriptions")
+	defer span.End()
+
+	subscription, err := scanSubscription(ws.db.QueryRowContext(ctx, ws.dialect.Rebind(
+		`SELECT id, url, event_types, secret, created_at FROM webhook_subscriptions WHERE id = ?`), id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("subscription %s: %w", id, domain.ErrSubscriptionNotFound)
+	}
+	if err != nil {
+		recordError(span, err)
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// DeleteSubscription removes a subscription with its deliveries.
+func (ws *WebhookStore) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	ctx, span := ws.start(ctx, "WebhookStore.DeleteSubscription", "DELETE", "webhook_subscriptions")
+	defer span.End()
+
+	err := WithTransaction(ctx, ws.db, func(ctx context.Context) error {
+		tx := conn(ctx, ws.db)
+		if _, err := tx.ExecContext(ctx, ws.dialect.Rebind(`DELETE FROM webhook_deliveries WHERE subscription_id = ?`), id); err != nil {
+			return err
+		}
+		result, err := tx.ExecContext(ctx, ws.dialect.Rebind(`DELETE FROM webhook_subscriptions WHERE id = ?`), id)
+		if err != nil {
+			return err
+		}
+		if affected, err := result.RowsAffected(); err != nil || affected > 0 {
+			return err
+		}
+		return fmt.Errorf("subscription %s: %w", id, domain.ErrSubscriptionNotFound)
+	})
+	if err != nil && !errors.Is(err, domain.ErrSubscriptionNotFound) {
+		recordError(span, err)
+	}
+	return err
+}
+
+// EnqueueDeliveries stores new deliveries, skipping those of an event the
+// subscription already has, so an event relayed twice is delivered once.
+func (ws *WebhookStore) EnqueueDeliveries(ctx context.Context, deliveries ...domain.Delivery) error {
+	ctx, span := ws.start(ctx, "WebhookStore.EnqueueDeliveries", "INSERT", "webhook_deliveries")
+	defer span.End()
+
+	for _, delivery := range deliveries {
+		if _, err := ws.db.ExecContext(ctx, ws.dialect.Rebind(
+			`INSERT INTO webhook_deliveries
+			(id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, 0, ?, ?) `+ws.dialect.ignoreConflict),
+			delivery.Id, delivery.SubscriptionId, delivery.EventId, string(delivery.EventType), string(delivery.Payload),
+			string(domain.DeliveryPending), delivery.NextAttemptAt.UTC(), delivery.CreatedAt.UTC()); err != nil {
+			recordError(span, err)
+			return fmt.Errorf("failed to store delivery of event %s: %w", delivery.EventId, err)
+		}
+	}
+	return nil
+}
+
+// DueDeliveries returns up to limit pending deliveries due at now, the most
+// overdue first.
+func (ws *WebhookStore) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]domain.Delivery, error) {
+	return ws.queryDeliveries(ctx, "WebhookStore.DueDeliveries",
+		`SELECT `+deliverySelection+` FROM webhook_deliveries
+		WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at, created_at LIMIT ?`,
+		string(domain.DeliveryPending), now.UTC(), limit)
+}
+
+// Deliveries returns the deliveries selected by filter, newest first.
+func (ws *WebhookStore) Deliveries(ctx context.Context, filter domain.DeliveryFilter) ([]domain.Delivery, error) {
+	var conditions []string
+	var args []any
+	if filter.SubscriptionId != uuid.Nil {
+		conditions = append(conditions, "subscription_id = ?")
+		args = append(args, filter.SubscriptionId)
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, string(filter.Status))
+	}
+	query := `SELECT ` + deliverySelection + ` FROM webhook_deliveries`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY created_at DESC, id`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+	return ws.queryDeliveries(ctx, "WebhookStore.Deliveries", query, args...)
+}
+
+func (ws *WebhookStore) Delivery(ctx context.Context, id uuid.UUID) (*domain.Delivery, error) {
+	deliveries, err := ws.queryDeliveries(ctx, "WebhookStore.Delivery",
+		`SELECT `+deliverySelection+` FROM webhook_deliveries WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(deliveries) == 0 {
+		return nil, fmt.Errorf("delivery %s: %w", id, domain.ErrDeliveryNotFound)
+	}
+	return &deliveries[0], nil
+}
+
+// RecordAttempt stores the outcome of an attempt: the status, attempts,
+// error, response status and times of delivery.
+func (ws *WebhookStore) RecordAttempt(ctx context.Context, delivery domain.Delivery) error {
+	ctx, span := ws.start(ctx, "WebhookStore.RecordAttempt", "UPDATE", "webhook_deliveries")
+	defer span.End()
+
+	var lastError, responseStatus, deliveredAt any
+	if delivery.LastError != "" {
+		lastError = delivery.LastError
+	}
+	if delivery.ResponseStatus != 0 {
+		responseStatus = delivery.ResponseStatus
+	}
+	if delivery.DeliveredAt != nil {
+		deliveredAt = delivery.DeliveredAt.UTC()
+	}
+	_, err := ws.db.ExecContext(ctx, ws.dialect.Rebind(
+		`UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, last_error = ?, response_status = ?, next_attempt_at = ?, delivered_at = ?
+		WHERE id = ?`),
+		string(delivery.Status), delivery.Attempts, lastError, responseStatus, delivery.NextAttemptAt.UTC(), deliveredAt,
+		delivery.Id)
+	if err != nil {
+		recordError(span, err)
+	}
+	return err
+}
+
+// ReplayDelivery schedules a delivery again at now, whatever its status,
+// with a fresh count of attempts. The last error and response status are
+// kept until the next attempt.
+func (ws *WebhookStore) ReplayDelivery(ctx context.Context, id uuid.UUID, now time.Time) error {
+	ctx, span := ws.start(ctx, "WebhookStore.ReplayDelivery", "UPDATE", "webhook_deliveries")
+	defer span.End()
+
+	result, err := ws.db.ExecContext(ctx, ws.dialect.Rebind(
+		`UPDATE webhook_deliveries SET status = ?, attempts = 0, next_attempt_at = ?, delivered_at = NULL WHERE id = ?`),
+		string(domain.DeliveryPending), now.UTC(), id)
+	if err == nil {
+		var affected int64
+		if affected, err = result.RowsAffected(); err == nil && affected == 0 {
+			return fmt.Errorf("delivery %s: %w", id, domain.ErrDeliveryNotFound)
+		}
+	}
+	if err != nil {
+		recordError(span, err)
+	}
+	return err
+}
+
+// PurgeDeliveries removes the deliveries that succeeded before the given
+// time and returns how many there were.
+func (ws *WebhookStore) PurgeDeliveries(ctx context.Context, deliveredBefore time.Time) (int64, error) {
+	ctx, span := ws.start(ctx, "WebhookStore.PurgeDeliveries", "DELETE", "webhook_deliveries")
+	defer span.End()
+
+	result, err := ws.db.ExecContext(ctx, ws.dialect.Rebind(
+		`DELETE FROM webhook_deliveries WHERE status = ? AND delivered_at < ?`),
+		string(domain.DeliveryDelivered), deliveredBefore.UTC())
+	if err != nil {
+		recordError(span, err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (ws *WebhookStore) queryDeliveries(ctx context.Context, spanName, query string, args ...any) ([]domain.Delivery, error) {
+	ctx, span := ws.start(ctx, spanName, "SELECT", "webhook_deliveries")
+	defer span.End()
+
+	rows, err := ws.db.QueryContext(ctx, ws.dialect.Rebind(query), args...)
+	if err != nil {
+		recordError(span, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []domain.Delivery
+	for rows.Next() {
+		var delivery domain.Delivery
+		var eventType, status string
+		var lastError sql.NullString
+		var responseStatus sql.NullInt64
+		var deliveredAt sql.NullTime
+		if err := rows.Scan(&delivery.Id, &delivery.SubscriptionId, &delivery.EventId, &eventType, &delivery.Payload,
+			&status, &delivery.Attempts, &lastError, &responseStatus, &delivery.NextAttemptAt, &delivery.CreatedAt,
+			&deliveredAt); err != nil {
+			recordError(span, err)
+			return nil, err
+		}
+		delivery.EventType = domain.EventType(eventType)
+		delivery.Status = domain.DeliveryStatus(status)
+		delivery.LastError = lastError.String
+		delivery.ResponseStatus = int(responseStatus.Int64)
+		delivery.DeliveredAt = timeOrNil(deliveredAt)
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		recordError(span, err)
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func scanSubscription(row interface{ Scan(...any) error }) (domain.Subscription, error) {
+	var subscription domain.Subscription
+	var eventTypes string
+	if err := row.Scan(&subscription.Id, &subscription.URL, &eventTypes, &subscription.Secret,
+		&subscription.CreatedAt); err != nil {
+		return domain.Subscription{}, err
+	}
+	if eventTypes != "" {
+		for _, eventType := range strings.Split(eventTypes, ",") {
+			subscription.EventTypes = append(subscription.EventTypes, domain.EventType(eventType))
+		}
+	}
+	return subscription, nil
+}
+
+func joinEventTypes(eventTypes []domain.EventType) string {
+	names := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		names = append(names, string(eventType))
+	}
+	return strings.Join(names, ",")
+}
+
+func (ws *WebhookStore) start(ctx context.Context, name, operation, table string) (context.Context, trace.Span) {
+	return tracing.GetGlobalTracer(tracerName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", ws.dialect.System),
+			attribute.String("db.operation", operation),
+			attribute.String("db.sql.table", table),
+		),
+	)
+}
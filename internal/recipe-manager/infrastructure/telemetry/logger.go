@@ -0,0 +1,85 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"google.golang.org/grpc/credentials"
+)
+
+type LogsConfig struct {
+	ExporterConfig
+	Enabled bool
+}
+
+func NewLoggerProvider(ctx context.Context, config LogsConfig, res *resource.Resource) (*sdklog.LoggerProvider, error) {
+	exporter, err := newLogExporter(ctx, config.ExporterConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return sdklog.NewLoggerProvider(
+		sdklog.WithResource(res),
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
+	), nil
+}
+
+func newLogExporter(ctx context.Context, config ExporterConfig) (sdklog.Exporter, error) {
+	switch config.Exporter {
+	case ExporterOTLPHTTP, "":
+		options := []otlploghttp.Option{
+			otlploghttp.WithEndpoint(config.Endpoint),
+			otlploghttp.WithHeaders(config.Headers),
+		}
+		if config.URLPath != "" {
+			options = append(options, otlploghttp.WithURLPath(config.URLPath))
+		}
+		if config.Insecure {
+			options = append(options, otlploghttp.WithInsecure())
+		} else {
+			tlsConfig, err := NewTLSConfig(config.TLS)
+			if err != nil {
+				return nil, err
+			}
+			options = append(options, otlploghttp.WithTLSClientConfig(tlsConfig))
+		}
+		exporter, err := otlploghttp.New(ctx, options...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP log exporter: %w", err)
+		}
+		return exporter, nil
+	case ExporterOTLPGRPC:
+		options := []otlploggrpc.Option{
+			otlploggrpc.WithEndpoint(config.Endpoint),
+			otlploggrpc.WithHeaders(config.Headers),
+		}
+		if config.Insecure {
+			options = append(options, otlploggrpc.WithInsecure())
+		} else {
+			tlsConfig, err := NewTLSConfig(config.TLS)
+			if err != nil {
+				return nil, err
+			}
+			options = append(options, otlploggrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+		}
+		exporter, err := otlploggrpc.New(ctx, options...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP gRPC log exporter: %w", err)
+		}
+		return exporter, nil
+	case ExporterStdout:
+		exporter, err := stdoutlog.New(stdoutlog.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout log exporter: %w", err)
+		}
+		return exporter, nil
+	default:
+		return nil, fmt.Errorf("unsupported log exporter %q", config.Exporter)
+	}
+}
@@ -0,0 +1,117 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	MetricsBackendPrometheus = "prometheus"
+	MetricsBackendOTLP       = "otlp"
+	MetricsBackendBoth       = "both"
+)
+
+type MetricsConfig struct {
+	ExporterConfig
+	Backend  string
+	Interval time.Duration
+}
+
+// UsesPrometheus reports whether metrics must be exposed on /metrics.
+func (c MetricsConfig) UsesPrometheus() bool {
+	return c.Backend == "" || c.Backend == MetricsBackendPrometheus || c.Backend == MetricsBackendBoth
+}
+
+// UsesOTLP reports whether metrics must be pushed through a MeterProvider.
+func (c MetricsConfig) UsesOTLP() bool {
+	return c.Backend == MetricsBackendOTLP || c.Backend == MetricsBackendBoth
+}
+
+func (c MetricsConfig) Validate() error {
+	switch c.Backend {
+	case "", MetricsBackendPrometheus, MetricsBackendOTLP, MetricsBackendBoth:
+		return nil
+	default:
+		return fmt.Errorf("unsupported metrics backend %q", c.Backend)
+	}
+}
+
+func NewMeterProvider(ctx context.Context, config MetricsConfig, res *resource.Resource) (*sdkmetric.MeterProvider, error) {
+	exporter, err := newMetricExporter(ctx, config.ExporterConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var readerOptions []sdkmetric.PeriodicReaderOption
+	if config.Interval > 0 {
+		readerOptions = append(readerOptions, sdkmetric.WithInterval(config.Interval))
+	}
+
+	return sdkmetric.NewMeterProvider(
+		sdkmetric.WithResource(res),
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, readerOptions...)),
+	), nil
+}
+
+func newMetricExporter(ctx context.Context, config ExporterConfig) (sdkmetric.Exporter, error) {
+	switch config.Exporter {
+	case ExporterOTLPHTTP, "":
+		options := []otlpmetrichttp.Option{
+			otlpmetrichttp.WithEndpoint(config.Endpoint),
+			otlpmetrichttp.WithHeaders(config.Headers),
+		}
+		if config.URLPath != "" {
+			options = append(options, otlpmetrichttp.WithURLPath(config.URLPath))
+		}
+		if config.Insecure {
+			options = append(options, otlpmetrichttp.WithInsecure())
+		} else {
+			tlsConfig, err := NewTLSConfig(config.TLS)
+			if err != nil {
+				return nil, err
+			}
+			options = append(options, otlpmetrichttp.WithTLSClientConfig(tlsConfig))
+		}
+		exporter, err := otlpmetrichttp.New(ctx, options...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP metric exporter: %w", err)
+		}
+		return exporter, nil
+	case ExporterOTLPGRPC:
+		options := []otlpmetricgrpc.Option{
+			otlpmetricgrpc.WithEndpoint(config.Endpoint),
+			otlpmetricgrpc.WithHeaders(config.Headers),
+		}
+		if config.Insecure {
+			options = append(options, otlpmetricgrpc.WithInsecure())
+		} else {
+			tlsConfig, err := NewTLSConfig(config.TLS)
+			if err != nil {
+				return nil, err
+			}
+			options = append(options, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+		}
+		exporter, err := otlpmetricgrpc.New(ctx, options...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP gRPC metric exporter: %w", err)
+		}
+		return exporter, nil
+	case ExporterStdout:
+		exporter, err := stdoutmetric.New(stdoutmetric.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout metric exporter: %w", err)
+		}
+		return exporter, nil
+	default:
+		return nil, fmt.Errorf("unsupported metric exporter %q", config.Exporter)
+	}
+}
@@ -0,0 +1,52 @@
+package telemetry
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// ResourceConfig describes the service emitting telemetry. Traces, metrics
+// and logs are all built from the same ResourceConfig so a collector can
+// correlate the three signals.
+type ResourceConfig struct {
+	ServiceName    string
+	ServiceVersion string
+	Environment    string
+	Attributes     map[string]string
+}
+
+func NewResource(ctx context.Context, config ResourceConfig) (*resource.Resource, error) {
+	attributes := []attribute.KeyValue{
+		semconv.ServiceNameKey.String(config.ServiceName),
+		semconv.ServiceVersionKey.String(config.ServiceVersion),
+		semconv.DeploymentEnvironmentKey.String(config.Environment),
+	}
+	for key, value := range config.Attributes {
+		attributes = append(attributes, attribute.String(key, value))
+	}
+
+	return resource.New(
+		ctx,
+		resource.WithAttributes(attributes...),
+		resource.WithFromEnv(),
+	)
+}
+
+// ParseResourceAttributes parses "key=value" pairs, the same format used by
+// OTEL_RESOURCE_ATTRIBUTES, into a map. Malformed pairs are ignored.
+func ParseResourceAttributes(pairs []string) map[string]string {
+	attributes := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, found := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			continue
+		}
+		attributes[key] = strings.TrimSpace(value)
+	}
+	return attributes
+}
@@ -0,0 +1,104 @@
+package telemetry
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestNewResource(t *testing.T) {
+	res, err := NewResource(context.Background(), ResourceConfig{
+		ServiceName:    "test-service",
+		ServiceVersion: "1.2.3",
+		Environment:    "test",
+		Attributes:     map[string]string{"team": "dough"},
+	})
+	require.NoError(t, err)
+
+	value, ok := res.Set().Value(attribute.Key("team"))
+	assert.True(t, ok)
+	assert.Equal(t, "dough", value.AsString())
+
+	value, ok = res.Set().Value(attribute.Key("service.version"))
+	assert.True(t, ok)
+	assert.Equal(t, "1.2.3", value.AsString())
+}
+
+func TestParseResourceAttributes(t *testing.T) {
+	attributes := ParseResourceAttributes([]string{"team=dough", " region = eu-south ", "malformed", "=empty"})
+
+	assert.Equal(t, map[string]string{"team": "dough", "region": "eu-south"}, attributes)
+}
+
+func TestMetricsConfig(t *testing.T) {
+	tests := []struct {
+		backend        string
+		usesPrometheus bool
+		usesOTLP       bool
+		valid          bool
+	}{
+		{backend: "", usesPrometheus: true, valid: true},
+		{backend: MetricsBackendPrometheus, usesPrometheus: true, valid: true},
+		{backend: MetricsBackendOTLP, usesOTLP: true, valid: true},
+		{backend: MetricsBackendBoth, usesPrometheus: true, usesOTLP: true, valid: true},
+		{backend: "statsd", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			config := MetricsConfig{Backend: tt.backend}
+
+			assert.Equal(t, tt.usesPrometheus, config.UsesPrometheus())
+			assert.Equal(t, tt.usesOTLP, config.UsesOTLP())
+			assert.Equal(t, tt.valid, config.Validate() == nil)
+		})
+	}
+}
+
+func TestNewMeterProvider(t *testing.T) {
+	res, err := NewResource(context.Background(), ResourceConfig{ServiceName: "test-service"})
+	require.NoError(t, err)
+
+	for _, exporter := range []string{ExporterOTLPHTTP, ExporterOTLPGRPC, ExporterStdout} {
+		t.Run(exporter, func(t *testing.T) {
+			config := MetricsConfig{ExporterConfig: ExporterConfig{Exporter: exporter, Endpoint: "localhost:4318", Insecure: true}}
+
+			provider, err := NewMeterProvider(context.Background(), config, res)
+
+			require.NoError(t, err)
+			assert.NotNil(t, provider.Meter("test"))
+		})
+	}
+
+	_, err = NewMeterProvider(context.Background(), MetricsConfig{ExporterConfig: ExporterConfig{Exporter: "statsd"}}, res)
+	assert.Error(t, err)
+}
+
+func TestNewLoggerProvider(t *testing.T) {
+	res, err := NewResource(context.Background(), ResourceConfig{ServiceName: "test-service"})
+	require.NoError(t, err)
+
+	for _, exporter := range []string{ExporterOTLPHTTP, ExporterOTLPGRPC, ExporterStdout} {
+		t.Run(exporter, func(t *testing.T) {
+			config := LogsConfig{ExporterConfig: ExporterConfig{Exporter: exporter, Endpoint: "localhost:4318", Insecure: true}}
+
+			provider, err := NewLoggerProvider(context.Background(), config, res)
+
+			require.NoError(t, err)
+			assert.NoError(t, provider.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	tlsConfig, err := NewTLSConfig(TLSConfig{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	assert.True(t, tlsConfig.InsecureSkipVerify)
+
+	_, err = NewTLSConfig(TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/telemetry"
+)
+
+// newExporter builds the span exporter selected by config.Exporter. The
+// returned close function releases resources owned by the exporter itself
+// (e.g. the output file) and must be called after the provider shut down.
+// A nil exporter means spans are not exported at all.
+func newExporter(ctx context.Context, config *TracerConfig) (trace.SpanExporter, func() error, error) {
+	noClose := func() error { return nil }
+
+	switch config.Exporter {
+	case telemetry.ExporterOTLPHTTP, "":
+		exporter, err := newOTLPHTTPExporter(ctx, config)
+		return exporter, noClose, err
+	case telemetry.ExporterOTLPGRPC:
+		exporter, err := newOTLPGRPCExporter(ctx, config)
+		return exporter, noClose, err
+	case telemetry.ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+		return exporter, noClose, nil
+	case telemetry.ExporterFile:
+		file, err := os.OpenFile(config.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open trace file %q: %w", config.FilePath, err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			_ = file.Close()
+			return nil, nil, fmt.Errorf("failed to create file exporter: %w", err)
+		}
+		return exporter, file.Close, nil
+	case telemetry.ExporterNone:
+		return nil, noClose, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported trace exporter %q", config.Exporter)
+	}
+}
+
+func newOTLPHTTPExporter(ctx context.Context, config *TracerConfig) (trace.SpanExporter, error) {
+	options := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(config.JaegerEndpoint),
+		otlptracehttp.WithURLPath(config.URLPath),
+		otlptracehttp.WithHeaders(config.Headers),
+	}
+
+	if config.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	} else {
+		tlsConfig, err := telemetry.NewTLSConfig(config.TLS)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, otlptracehttp.WithTLSClientConfig(tlsConfig))
+	}
+
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+	return exporter, nil
+}
+
+func newOTLPGRPCExporter(ctx context.Context, config *TracerConfig) (trace.SpanExporter, error) {
+	options := []otlptracegrpc.Option{
+		otlptracegrpc.WithEndpoint(config.JaegerEndpoint),
+		otlptracegrpc.WithHeaders(config.Headers),
+	}
+
+	if config.Insecure {
+		options = append(options, otlptracegrpc.WithInsecure())
+	} else {
+		tlsConfig, err := telemetry.NewTLSConfig(config.TLS)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+	}
+
+	exporter, err := otlptracegrpc.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP gRPC exporter: %w", err)
+	}
+	return exporter, nil
+}
@@ -2,17 +2,17 @@ package tracing
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"time"
 
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/propagation"
-	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/telemetry"
 )
 
 type TracerConfig struct {
@@ -20,7 +20,15 @@ type TracerConfig struct {
 	ServiceVersion     string
 	JaegerEndpoint     string
 	Environment        string
+	Exporter           string
+	URLPath            string
+	Insecure           bool
+	TLS                telemetry.TLSConfig
+	Headers            map[string]string
+	FilePath           string
 	SamplingRatio      float64
+	ParentBased        bool
+	ResourceAttributes map[string]string
 	BatchTimeout       time.Duration
 	MaxExportBatchSize int
 }
@@ -31,7 +39,14 @@ func DefaultTracerConfig() *TracerConfig {
 		ServiceVersion:     getEnvOrDefault("OTEL_SERVICE_VERSION", "1.0.0"),
 		JaegerEndpoint:     getEnvOrDefault("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4318"),
 		Environment:        getEnvOrDefault("ENVIRONMENT", "development"),
+		Exporter:           telemetry.ExporterOTLPHTTP,
+		URLPath:            "/v1/traces",
+		Insecure:           true, // Use HTTP instead of HTTPS for local development
+		Headers:            map[string]string{},
+		FilePath:           "traces.json",
 		SamplingRatio:      1.0, // Sample all traces in development
+		ParentBased:        true,
+		ResourceAttributes: map[string]string{},
 		BatchTimeout:       5 * time.Second,
 		MaxExportBatchSize: 512,
 	}
@@ -43,36 +58,30 @@ type TracerProvider struct {
 }
 
 func NewTracerProvider(config *TracerConfig) (*TracerProvider, error) {
-	exporter, err := otlptracehttp.New(
-		context.Background(),
-		otlptracehttp.WithEndpoint(config.JaegerEndpoint),
-		otlptracehttp.WithInsecure(), // Use HTTP instead of HTTPS for local development
-		otlptracehttp.WithURLPath("/v1/traces"),
-	)
+	ctx := context.Background()
+
+	exporter, closeExporter, err := newExporter(ctx, config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		return nil, err
 	}
 
-	res, err := resource.New(
-		context.Background(),
-		resource.WithAttributes(
-			semconv.ServiceNameKey.String(config.ServiceName),
-			semconv.ServiceVersionKey.String(config.ServiceVersion),
-			semconv.DeploymentEnvironmentKey.String(config.Environment),
-		),
-	)
+	res, err := telemetry.NewResource(ctx, config.Resource())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create resource: %w", err)
 	}
 
-	tp := trace.NewTracerProvider(
-		trace.WithBatcher(exporter,
+	options := []trace.TracerProviderOption{
+		trace.WithResource(res),
+		trace.WithSampler(newSampler(config)),
+	}
+	if exporter != nil {
+		options = append(options, trace.WithBatcher(exporter,
 			trace.WithBatchTimeout(config.BatchTimeout),
 			trace.WithMaxExportBatchSize(config.MaxExportBatchSize),
-		),
-		trace.WithResource(res),
-		trace.WithSampler(trace.TraceIDRatioBased(config.SamplingRatio)),
-	)
+		))
+	}
+
+	tp := trace.NewTracerProvider(options...)
 
 	otel.SetTracerProvider(tp)
 
@@ -84,11 +93,22 @@ func NewTracerProvider(config *TracerConfig) (*TracerProvider, error) {
 	return &TracerProvider{
 		provider: tp,
 		cleanup: func(ctx context.Context) error {
-			return tp.Shutdown(ctx)
+			return errors.Join(tp.Shutdown(ctx), closeExporter())
 		},
 	}, nil
 }
 
+// Resource returns the resource description shared with the metric and log
+// providers.
+func (c *TracerConfig) Resource() telemetry.ResourceConfig {
+	return telemetry.ResourceConfig{
+		ServiceName:    c.ServiceName,
+		ServiceVersion: c.ServiceVersion,
+		Environment:    c.Environment,
+		Attributes:     c.ResourceAttributes,
+	}
+}
+
 func (tp *TracerProvider) GetTracer(name string) oteltrace.Tracer {
 	return tp.provider.Tracer(name)
 }
@@ -97,6 +117,22 @@ func (tp *TracerProvider) Shutdown(ctx context.Context) error {
 	return tp.cleanup(ctx)
 }
 
+// newSampler honours the sampling decision of an incoming parent span when
+// ParentBased is set, so a trace is either kept or dropped as a whole across
+// services. The "none" exporter never samples: spans still carry valid IDs for
+// log correlation but nothing is recorded.
+func newSampler(config *TracerConfig) trace.Sampler {
+	if config.Exporter == telemetry.ExporterNone {
+		return trace.NeverSample()
+	}
+
+	sampler := trace.TraceIDRatioBased(config.SamplingRatio)
+	if config.ParentBased {
+		return trace.ParentBased(sampler)
+	}
+	return sampler
+}
+
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -3,6 +3,7 @@ package tracing
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -10,7 +11,10 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/telemetry"
 )
 
 func TestDefaultTracerConfig(t *testing.T) {
@@ -173,3 +177,131 @@ func TestGetEnvOrDefault(t *testing.T) {
 	result = getEnvOrDefault("NON_EXISTING_VAR", "default_value")
 	assert.Equal(t, "default_value", result)
 }
+
+func TestNewTracerProviderExporters(t *testing.T) {
+	tests := []struct {
+		name     string
+		exporter string
+	}{
+		{name: "otlp http", exporter: telemetry.ExporterOTLPHTTP},
+		{name: "otlp grpc", exporter: telemetry.ExporterOTLPGRPC},
+		{name: "stdout", exporter: telemetry.ExporterStdout},
+		{name: "file", exporter: telemetry.ExporterFile},
+		{name: "none", exporter: telemetry.ExporterNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultTracerConfig()
+			config.Exporter = tt.exporter
+			config.FilePath = filepath.Join(t.TempDir(), "traces.json")
+
+			tp, err := NewTracerProvider(config)
+			require.NoError(t, err)
+
+			_, span := tp.GetTracer("test-tracer").Start(context.Background(), "test-operation")
+			assert.True(t, span.SpanContext().HasTraceID())
+
+			// The span is left open so no export to an unreachable collector is attempted.
+			assert.NoError(t, tp.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestNewTracerProviderUnsupportedExporter(t *testing.T) {
+	config := DefaultTracerConfig()
+	config.Exporter = "carrier-pigeon"
+
+	tp, err := NewTracerProvider(config)
+
+	assert.Error(t, err)
+	assert.Nil(t, tp)
+}
+
+func TestFileExporterWritesSpans(t *testing.T) {
+	config := DefaultTracerConfig()
+	config.Exporter = telemetry.ExporterFile
+	config.FilePath = filepath.Join(t.TempDir(), "traces.json")
+
+	tp, err := NewTracerProvider(config)
+	require.NoError(t, err)
+
+	_, span := tp.GetTracer("test-tracer").Start(context.Background(), "file-operation")
+	span.End()
+	require.NoError(t, tp.Shutdown(context.Background()))
+
+	content, err := os.ReadFile(config.FilePath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "file-operation")
+}
+
+func TestNewTracerProviderWithInvalidCAFile(t *testing.T) {
+	config := DefaultTracerConfig()
+	config.Insecure = false
+	config.TLS.CAFile = filepath.Join(t.TempDir(), "missing.pem")
+
+	_, err := NewTracerProvider(config)
+
+	assert.Error(t, err)
+}
+
+func TestNewSampler(t *testing.T) {
+	config := DefaultTracerConfig()
+
+	config.SamplingRatio = 0.5
+	assert.Contains(t, newSampler(config).Description(), "ParentBased")
+
+	config.ParentBased = false
+	assert.Equal(t, "TraceIDRatioBased{0.5}", newSampler(config).Description())
+
+	config.Exporter = telemetry.ExporterNone
+	assert.Equal(t, "AlwaysOffSampler", newSampler(config).Description())
+}
+
+func TestSamplerRespectsParentDecision(t *testing.T) {
+	parent := func(flags trace.TraceFlags) context.Context {
+		return trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{0x01},
+			SpanID:     trace.SpanID{0x01},
+			TraceFlags: flags,
+			Remote:     true,
+		}))
+	}
+
+	config := DefaultTracerConfig()
+	config.SamplingRatio = 0
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(newSampler(config)))
+	defer tp.Shutdown(context.Background())
+
+	_, child := tp.Tracer("test-tracer").Start(parent(trace.FlagsSampled), "child")
+	defer child.End()
+	assert.True(t, child.SpanContext().IsSampled())
+	assert.Equal(t, trace.TraceID{0x01}, child.SpanContext().TraceID())
+
+	_, root := tp.Tracer("test-tracer").Start(context.Background(), "root")
+	defer root.End()
+	assert.False(t, root.SpanContext().IsSampled())
+
+	config.SamplingRatio = 1
+	tp = sdktrace.NewTracerProvider(sdktrace.WithSampler(newSampler(config)))
+	defer tp.Shutdown(context.Background())
+
+	_, dropped := tp.Tracer("test-tracer").Start(parent(0), "child")
+	defer dropped.End()
+	assert.False(t, dropped.SpanContext().IsSampled())
+}
+
+func TestNoneExporterDropsSpans(t *testing.T) {
+	config := DefaultTracerConfig()
+	config.Exporter = telemetry.ExporterNone
+	tp, err := NewTracerProvider(config)
+	require.NoError(t, err)
+	defer tp.Shutdown(context.Background())
+
+	_, span := tp.GetTracer("test-tracer").Start(context.Background(), "dropped")
+	defer span.End()
+
+	assert.True(t, span.SpanContext().IsValid())
+	assert.False(t, span.SpanContext().IsSampled())
+	assert.False(t, span.IsRecording())
+}
@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/outbox"
+)
+
+// Store keeps the subscriptions and their deliveries.
+type Store interface {
+	Subscriptions(ctx context.Context) ([]domain.Subscription, error)
+	// EnqueueDeliveries skips the deliveries of an event the subscription
+	// already has.
+	EnqueueDeliveries(ctx context.Context, deliveries ...domain.Delivery) error
+	DueDeliveries(ctx context.Context, now time.Time, limit int) ([]domain.Delivery, error)
+	RecordAttempt(ctx context.Context, delivery domain.Delivery) error
+}
+
+// Dispatcher is the outbox sink of the webhook subscriptions. Publishing a
+// message only stores its deliveries, which the Worker then sends, so a slow
+// or failing subscriber holds back neither the outbox nor the others.
+type Dispatcher struct {
+	store Store
+	now   func() time.Time
+}
+
+func NewDispatcher(store Store) *Dispatcher {
+	return &Dispatcher{store: store, now: time.Now}
+}
+
+func (d *Dispatcher) Publish(ctx context.Context, message outbox.Message) error {
+	subscriptions, err := d.store.Subscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := d.now().UTC()
+	var deliveries []domain.Delivery
+	for _, subscription := range subscriptions {
+		if !subscription.Wants(message.Type) {
+			continue
+		}
+		deliveries = append(deliveries, domain.Delivery{
+			Id:             uuid.New(),
+			SubscriptionId: subscription.Id,
+			EventId:        message.Id,
+			EventType:      message.Type,
+			Payload:        message.Body,
+			Status:         domain.DeliveryPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return d.store.EnqueueDeliveries(ctx, deliveries...)
+}
@@ -0,0 +1,42 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"type":"recipe.created"}`)
+	signedAt := time.Unix(1760857556, 0)
+	header := Sign("menu-board-secret", signedAt, body)
+
+	tests := []struct {
+		name     string
+		secret   string
+		header   string
+		body     []byte
+		now      time.Time
+		expected error
+	}{
+		{name: "valid", secret: "menu-board-secret", header: header, body: body, now: signedAt.Add(time.Minute)},
+		{name: "wrong secret", secret: "guessed", header: header, body: body, now: signedAt, expected: ErrInvalidSignature},
+		{name: "tampered body", secret: "menu-board-secret", header: header, body: []byte(`{"type":"recipe.deleted"}`), now: signedAt, expected: ErrInvalidSignature},
+		{name: "too old", secret: "menu-board-secret", header: header, body: body, now: signedAt.Add(time.Hour), expected: ErrInvalidSignature},
+		{name: "malformed", secret: "menu-board-secret", header: "v1=abc", body: body, now: signedAt, expected: ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.header, tt.body, 5*time.Minute, tt.now)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.expected)
+			}
+		})
+	}
+
+	assert.Regexp(t, `^t=1760857556,v1=[0-9a-f]{64}$`, header)
+}
@@ -0,0 +1,212 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/outbox"
+)
+
+const (
+	defaultInterval       = time.Second
+	defaultBatchSize      = 50
+	defaultTimeout        = 5 * time.Second
+	defaultMaxAttempts    = 8
+	defaultInitialBackoff = 30 * time.Second
+	defaultMaxBackoff     = time.Hour
+)
+
+// Worker posts the due deliveries to their subscriptions. A failed attempt
+// is retried after a backoff that doubles with each attempt, up to a
+// maximum; a delivery failing every attempt is moved to the dead-letter
+// list, from which it can be replayed.
+type Worker struct {
+	store          Store
+	client         *http.Client
+	logger         logrus.FieldLogger
+	interval       time.Duration
+	batchSize      int
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	now            func() time.Time
+}
+
+type WorkerOption func(*Worker)
+
+// WithInterval sets how long the worker waits when nothing is due.
+func WithInterval(interval time.Duration) WorkerOption {
+	return func(w *Worker) {
+		w.interval = interval
+	}
+}
+
+// WithBatchSize sets how many due deliveries the worker reads at a time.
+func WithBatchSize(size int) WorkerOption {
+	return func(w *Worker) {
+		w.batchSize = size
+	}
+}
+
+// WithTimeout bounds each attempt.
+func WithTimeout(timeout time.Duration) WorkerOption {
+	return func(w *Worker) {
+		w.client.Timeout = timeout
+	}
+}
+
+// WithRetries sets the attempts before a delivery is dead, and the backoff
+// after the first failed attempt and its maximum.
+func WithRetries(maxAttempts int, initialBackoff, maxBackoff time.Duration) WorkerOption {
+	return func(w *Worker) {
+		w.maxAttempts = maxAttempts
+		w.initialBackoff = initialBackoff
+		w.maxBackoff = maxBackoff
+	}
+}
+
+func NewWorker(store Store, logger logrus.FieldLogger, options ...WorkerOption) *Worker {
+	worker := &Worker{
+		store:          store,
+		client:         &http.Client{Timeout: defaultTimeout},
+		logger:         logger,
+		interval:       defaultInterval,
+		batchSize:      defaultBatchSize,
+		maxAttempts:    defaultMaxAttempts,
+		initialBackoff: defaultInitialBackoff,
+		maxBackoff:     defaultMaxBackoff,
+		now:            time.Now,
+	}
+	for _, option := range options {
+		option(worker)
+	}
+	return worker
+}
+
+// Run delivers until ctx is done.
+func (w *Worker) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		attempted, err := w.DeliverDue(ctx)
+		if err != nil && ctx.Err() == nil {
+			w.logger.WithError(err).Error("Failed to deliver webhooks")
+		}
+		wait := w.interval
+		if err == nil && attempted == w.batchSize {
+			wait = 0
+		}
+		timer.Reset(wait)
+	}
+}
+
+// DeliverDue makes an attempt at each delivery due now, up to the batch
+// size, and returns how many it attempted.
+func (w *Worker) DeliverDue(ctx context.Context) (int, error) {
+	deliveries, err := w.store.DueDeliveries(ctx, w.now(), w.batchSize)
+	if err != nil || len(deliveries) == 0 {
+		return 0, err
+	}
+	subscriptions, err := w.store.Subscriptions(ctx)
+	if err != nil {
+		return 0, err
+	}
+	byId := make(map[uuid.UUID]domain.Subscription, len(subscriptions))
+	for _, subscription := range subscriptions {
+		byId[subscription.Id] = subscription
+	}
+
+	for i, delivery := range deliveries {
+		subscription, found := byId[delivery.SubscriptionId]
+		if !found {
+			// Unsubscribed since the batch was read.
+			continue
+		}
+		if err := w.store.RecordAttempt(ctx, w.attempt(ctx, subscription, delivery)); err != nil {
+			return i, err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// attempt posts delivery to subscription and returns it updated with the
+// outcome.
+func (w *Worker) attempt(ctx context.Context, subscription domain.Subscription, delivery domain.Delivery) domain.Delivery {
+	status, err := w.post(ctx, subscription, delivery)
+	now := w.now().UTC()
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+
+	if err == nil {
+		delivery.Status = domain.DeliveryDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		return delivery
+	}
+
+	delivery.LastError = err.Error()
+	logger := w.logger.WithError(err).WithFields(logrus.Fields{
+		"delivery_id":     delivery.Id,
+		"subscription_id": subscription.Id,
+		"event_type":      delivery.EventType,
+		"attempts":        delivery.Attempts,
+	})
+	if delivery.Attempts >= w.maxAttempts {
+		delivery.Status = domain.DeliveryDead
+		logger.Error("Webhook delivery failed every attempt, moved to the dead-letter list")
+		return delivery
+	}
+	delivery.NextAttemptAt = now.Add(w.backoff(delivery.Attempts))
+	logger.WithField("next_attempt_at", delivery.NextAttemptAt).Warn("Webhook delivery failed, will retry")
+	return delivery
+}
+
+func (w *Worker) post(ctx context.Context, subscription domain.Subscription, delivery domain.Delivery) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "recipe-manager-webhooks")
+	request.Header.Set(SignatureHeader, Sign(subscription.Secret, w.now(), delivery.Payload))
+	request.Header.Set(DeliveryHeader, delivery.Id.String())
+	request.Header.Set(outbox.IdempotencyKeyHeader, delivery.EventId.String())
+	request.Header.Set(outbox.EventTypeHeader, string(delivery.EventType))
+
+	response, err := w.client.Do(request)
+	if err != nil {
+		return 0, fmt.Errorf("failed to post delivery: %w", err)
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("receiver answered %s", response.Status)
+	}
+	return response.StatusCode, nil
+}
+
+// backoff is the wait after the given number of failed attempts:
+// the initial backoff, doubled for each further attempt, up to the maximum.
+func (w *Worker) backoff(attempts int) time.Duration {
+	wait := w.initialBackoff
+	for i := 1; i < attempts && wait < w.maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, w.maxBackoff)
+}
@@ -0,0 +1,221 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/outbox"
+)
+
+// memoryStore keeps subscriptions and deliveries in maps.
+type memoryStore struct {
+	mu            sync.Mutex
+	subscriptions []domain.Subscription
+	deliveries    map[uuid.UUID]domain.Delivery
+}
+
+func newMemoryStore(subscriptions ...domain.Subscription) *memoryStore {
+	return &memoryStore{subscriptions: subscriptions, deliveries: make(map[uuid.UUID]domain.Delivery)}
+}
+
+func (s *memoryStore) Subscriptions(context.Context) ([]domain.Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]domain.Subscription(nil), s.subscriptions...), nil
+}
+
+func (s *memoryStore) EnqueueDeliveries(_ context.Context, deliveries ...domain.Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, delivery := range deliveries {
+		if s.has(delivery.SubscriptionId, delivery.EventId) {
+			continue
+		}
+		s.deliveries[delivery.Id] = delivery
+	}
+	return nil
+}
+
+func (s *memoryStore) has(subscription, event uuid.UUID) bool {
+	for _, delivery := range s.deliveries {
+		if delivery.SubscriptionId == subscription && delivery.EventId == event {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *memoryStore) DueDeliveries(_ context.Context, now time.Time, limit int) ([]domain.Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []domain.Delivery
+	for _, delivery := range s.deliveries {
+		if delivery.Status == domain.DeliveryPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(due[j].NextAttemptAt) })
+	return due[:min(limit, len(due))], nil
+}
+
+func (s *memoryStore) RecordAttempt(_ context.Context, delivery domain.Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries[delivery.Id] = delivery
+	return nil
+}
+
+func (s *memoryStore) all() []domain.Delivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var deliveries []domain.Delivery
+	for _, delivery := range s.deliveries {
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries
+}
+
+func quietLogger() logrus.FieldLogger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func message(t *testing.T, eventType domain.EventType) outbox.Message {
+	t.Helper()
+	encoded, err := outbox.Encode(domain.NewEvent(eventType, domain.Recipe{Uuid: uuid.New(), Name: "Bianca", Version: 1}))
+	require.NoError(t, err)
+	return encoded
+}
+
+func TestDispatcherPublish(t *testing.T) {
+	menuBoard := domain.Subscription{Id: uuid.New(), EventTypes: []domain.EventType{domain.EventRecipeCreated}}
+	pos := domain.Subscription{Id: uuid.New()}
+	store := newMemoryStore(menuBoard, pos)
+	dispatcher := NewDispatcher(store)
+
+	created := message(t, domain.EventRecipeCreated)
+	require.NoError(t, dispatcher.Publish(context.Background(), created))
+	// The relay publishes an event again when it crashed before marking it.
+	require.NoError(t, dispatcher.Publish(context.Background(), created))
+	require.NoError(t, dispatcher.Publish(context.Background(), message(t, domain.EventRecipeAggregated)))
+
+	subscribers := map[domain.EventType][]uuid.UUID{}
+	for _, delivery := range store.all() {
+		assert.Equal(t, domain.DeliveryPending, delivery.Status)
+		subscribers[delivery.EventType] = append(subscribers[delivery.EventType], delivery.SubscriptionId)
+	}
+	assert.ElementsMatch(t, []uuid.UUID{menuBoard.Id, pos.Id}, subscribers[domain.EventRecipeCreated])
+	assert.Equal(t, []uuid.UUID{pos.Id}, subscribers[domain.EventRecipeAggregated])
+}
+
+func TestWorkerDeliverDue(t *testing.T) {
+	now := time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	t.Run("posts signed deliveries", func(t *testing.T) {
+		received := make(chan *http.Request, 1)
+		var body []byte
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ = io.ReadAll(r.Body)
+			assert.NoError(t, Verify("menu-board-secret", r.Header.Get(SignatureHeader), body, time.Minute, now))
+			received <- r
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer receiver.Close()
+
+		subscription := domain.Subscription{Id: uuid.New(), URL: receiver.URL, Secret: "menu-board-secret"}
+		store := newMemoryStore(subscription)
+		dispatcher := NewDispatcher(store)
+		dispatcher.now = clock
+		created := message(t, domain.EventRecipeCreated)
+		require.NoError(t, dispatcher.Publish(context.Background(), created))
+
+		worker := NewWorker(store, quietLogger())
+		worker.now = clock
+		attempted, err := worker.DeliverDue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempted)
+
+		request := <-received
+		assert.Equal(t, created.Id.String(), request.Header.Get(outbox.IdempotencyKeyHeader))
+		assert.Equal(t, "recipe.created", request.Header.Get(outbox.EventTypeHeader))
+		assert.JSONEq(t, string(created.Body), string(body))
+
+		delivery := store.all()[0]
+		assert.Equal(t, delivery.Id.String(), request.Header.Get(DeliveryHeader))
+		assert.Equal(t, domain.DeliveryDelivered, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.Equal(t, http.StatusNoContent, delivery.ResponseStatus)
+		assert.Equal(t, now, *delivery.DeliveredAt)
+	})
+
+	t.Run("retries with backoff, then gives up", func(t *testing.T) {
+		attempts := 0
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer receiver.Close()
+
+		store := newMemoryStore(domain.Subscription{Id: uuid.New(), URL: receiver.URL, Secret: "pos-secret"})
+		dispatcher := NewDispatcher(store)
+		dispatcher.now = clock
+		require.NoError(t, dispatcher.Publish(context.Background(), message(t, domain.EventRecipeUpdated)))
+
+		current := now
+		worker := NewWorker(store, quietLogger(), WithRetries(4, time.Minute, 3*time.Minute))
+		worker.now = func() time.Time { return current }
+
+		var waits []time.Duration
+		for {
+			attempted, err := worker.DeliverDue(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, 1, attempted)
+
+			delivery := store.all()[0]
+			assert.Equal(t, http.StatusServiceUnavailable, delivery.ResponseStatus)
+			assert.Contains(t, delivery.LastError, "503")
+			if delivery.Status == domain.DeliveryDead {
+				break
+			}
+			require.Equal(t, domain.DeliveryPending, delivery.Status)
+			waits = append(waits, delivery.NextAttemptAt.Sub(current))
+
+			// Nothing is due before the backoff has passed.
+			current = delivery.NextAttemptAt.Add(-time.Second)
+			attempted, err = worker.DeliverDue(context.Background())
+			require.NoError(t, err)
+			require.Zero(t, attempted)
+			current = delivery.NextAttemptAt
+		}
+
+		assert.Equal(t, []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute}, waits)
+		assert.Equal(t, 4, attempts)
+		assert.Equal(t, 4, store.all()[0].Attempts)
+	})
+
+	t.Run("skips deliveries of removed subscriptions", func(t *testing.T) {
+		store := newMemoryStore()
+		delivery := domain.Delivery{Id: uuid.New(), SubscriptionId: uuid.New(), Status: domain.DeliveryPending, NextAttemptAt: now}
+		require.NoError(t, store.EnqueueDeliveries(context.Background(), delivery))
+
+		worker := NewWorker(store, quietLogger())
+		worker.now = clock
+		_, err := worker.DeliverDue(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, store.all()[0].Attempts)
+	})
+}
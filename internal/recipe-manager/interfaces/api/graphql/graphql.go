@@ -0,0 +1,107 @@
+// Package graphql serves the recipes and their aggregates over GraphQL, with
+// the schema kept in schema.graphql next to this file. Recipes requested
+// while resolving a query are read from the repository in batches.
+package graphql
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	gql "github.com/graph-gophers/graphql-go"
+	gqlotel "github.com/graph-gophers/graphql-go/trace/otel"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/tracing"
+)
+
+//go:embed schema.graphql
+var schema string
+
+const (
+	tracerName = "recipe-manager/graphql"
+	// maxDepth keeps a query from nesting recipe { aggregate { recipe { ... } } }
+	// without end, each level balancing the recipe again.
+	maxDepth = 8
+)
+
+type RecipeService interface {
+	RecipesByUuids(context.Context, []uuid.UUID) ([]domain.Recipe, error)
+	SearchRecipes(ctx context.Context, filter domain.RecipeFilter, allergens []domain.Allergen, text string) ([]domain.Recipe, error)
+	Aggregate(context.Context, domain.Recipe, domain.Pans) (*domain.RecipeAggregate, error)
+}
+
+// Request is a GraphQL query, sent as the JSON body of a POST or as the
+// query parameters of a GET, with the variables as a JSON object.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+type Handler struct {
+	schema  *gql.Schema
+	service RecipeService
+}
+
+func NewHandler(service RecipeService) (*Handler, error) {
+	parsed, err := gql.ParseSchema(schema, &resolver{service: service},
+		gql.UseStringDescriptions(),
+		gql.MaxDepth(maxDepth),
+		gql.Tracer(&gqlotel.Tracer{Tracer: tracing.GetGlobalTracer(tracerName)}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{schema: parsed, service: service}, nil
+}
+
+func (h *Handler) RegisterRoutes(router *gin.Engine) {
+	router.GET("/graphql", h.handleGet)
+	router.POST("/graphql", h.handlePost)
+}
+
+func (h *Handler) handleGet(c *gin.Context) {
+	request := Request{
+		Query:         c.Query("query"),
+		OperationName: c.Query("operationName"),
+	}
+	if variables := c.Query("variables"); variables != "" {
+		if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+			requestError(c, "variables must be a JSON object: "+err.Error())
+			return
+		}
+	}
+	h.execute(c, request)
+}
+
+func (h *Handler) handlePost(c *gin.Context) {
+	var request Request
+	if err := c.ShouldBindJSON(&request); err != nil {
+		requestError(c, err.Error())
+		return
+	}
+	h.execute(c, request)
+}
+
+// execute answers 200 with the data and the errors of the query, as GraphQL
+// reports failed fields alongside the ones that resolved.
+func (h *Handler) execute(c *gin.Context, request Request) {
+	if request.Query == "" {
+		requestError(c, "query is required")
+		return
+	}
+	ctx := withLoader(c.Request.Context(), newRecipeLoader(h.service.RecipesByUuids))
+	c.JSON(http.StatusOK, h.schema.Exec(ctx, request.Query, request.OperationName, request.Variables))
+}
+
+// requestError answers 400 to a request that is not a GraphQL query at all,
+// in the shape of a GraphQL response.
+func requestError(c *gin.Context, message string) {
+	c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+		"errors": []gin.H{{"message": message}},
+	})
+}
@@ -0,0 +1,319 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+)
+
+type MockRecipeService struct {
+	mock.Mock
+}
+
+func (m *MockRecipeService) RecipesByUuids(ctx context.Context, recipeUuids []uuid.UUID) ([]domain.Recipe, error) {
+	args := m.Called(ctx, recipeUuids)
+	return args.Get(0).([]domain.Recipe), args.Error(1)
+}
+
+func (m *MockRecipeService) SearchRecipes(ctx context.Context, filter domain.RecipeFilter, allergens []domain.Allergen, text string) ([]domain.Recipe, error) {
+	args := m.Called(ctx, filter, allergens, text)
+	return args.Get(0).([]domain.Recipe), args.Error(1)
+}
+
+func (m *MockRecipeService) Aggregate(ctx context.Context, recipe domain.Recipe, pans domain.Pans) (*domain.RecipeAggregate, error) {
+	args := m.Called(ctx, recipe, pans)
+	return args.Get(0).(*domain.RecipeAggregate), args.Error(1)
+}
+
+var (
+	margherita = domain.Recipe{
+		Uuid:    uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+		Name:    "Margherita",
+		Author:  "Raffaele",
+		Version: 2,
+		Dough: domain.Dough{Name: "Pizza dough", Ingredients: []domain.Ingredient{
+			{Name: "flour", Amount: 60, Unit: "%"},
+			{Name: "water", Amount: 40, Unit: "%"},
+		}},
+		Topping: domain.Topping{Name: "Margherita", ReferenceArea: 100, Ingredients: []domain.Ingredient{
+			{Name: "tomato", Amount: 100, Unit: "g"},
+		}},
+		Steps: domain.Steps{Steps: []domain.Step{{StepNumber: 1, Description: "Knead"}}},
+	}
+	bianca = domain.Recipe{
+		Uuid: uuid.MustParse("22222222-2222-2222-2222-222222222222"),
+		Name: "Bianca",
+	}
+)
+
+func setupRouter(t *testing.T, service RecipeService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler, err := NewHandler(service)
+	require.NoError(t, err)
+	router := gin.New()
+	handler.RegisterRoutes(router)
+	return router
+}
+
+func post(router *gin.Engine, query string, variables map[string]any) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(Request{Query: query, Variables: variables})
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRecipeQuery(t *testing.T) {
+	t.Run("reads the recipes of a query in one batch", func(t *testing.T) {
+		service := new(MockRecipeService)
+		missing := uuid.New()
+		service.On("RecipesByUuids", mock.Anything, mock.MatchedBy(func(recipeUuids []uuid.UUID) bool {
+			return len(recipeUuids) == 3 &&
+				slices.Contains(recipeUuids, margherita.Uuid) &&
+				slices.Contains(recipeUuids, bianca.Uuid) &&
+				slices.Contains(recipeUuids, missing)
+		})).Return([]domain.Recipe{bianca, margherita}, nil).Once()
+		router := setupRouter(t, service)
+
+		w := post(router, `query($a: ID!, $b: ID!, $missing: ID!) {
+			a: recipe(uuid: $a) { name version dough { total } steps { number description } }
+			b: recipe(uuid: $b) { name }
+			again: recipe(uuid: $a) { author }
+			missing: recipe(uuid: $missing) { name }
+		}`, map[string]any{"a": margherita.Uuid.String(), "b": bianca.Uuid.String(), "missing": missing.String()})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"data": {
+			"a": {"name": "Margherita", "version": 2, "dough": {"total": 100}, "steps": [{"number": 1, "description": "Knead"}]},
+			"b": {"name": "Bianca"},
+			"again": {"author": "Raffaele"},
+			"missing": null
+		}}`, w.Body.String())
+		service.AssertExpectations(t)
+	})
+
+	t.Run("reports a failed read on the field", func(t *testing.T) {
+		service := new(MockRecipeService)
+		service.On("RecipesByUuids", mock.Anything, []uuid.UUID{margherita.Uuid}).
+			Return([]domain.Recipe{}, errors.New("database unavailable"))
+		router := setupRouter(t, service)
+
+		w := post(router, `{ recipe(uuid: "`+margherita.Uuid.String()+`") { name } }`, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data   map[string]any
+			Errors []struct{ Message string }
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Nil(t, response.Data["recipe"])
+		require.Len(t, response.Errors, 1)
+		assert.Equal(t, "database unavailable", response.Errors[0].Message)
+	})
+
+	t.Run("rejects an invalid UUID", func(t *testing.T) {
+		router := setupRouter(t, new(MockRecipeService))
+
+		w := post(router, `{ recipe(uuid: "not-a-uuid") { name } }`, nil)
+
+		assert.Contains(t, w.Body.String(), `invalid UUID \"not-a-uuid\"`)
+	})
+}
+
+func TestRecipesQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		filter    domain.RecipeFilter
+		allergens []domain.Allergen
+		text      string
+		result    []domain.Recipe
+		response  string
+	}{
+		{
+			name:     "lists every recipe by default",
+			query:    `{ recipes { name } }`,
+			result:   []domain.Recipe{bianca, margherita},
+			response: `{"data": {"recipes": [{"name": "Bianca"}, {"name": "Margherita"}]}}`,
+		},
+		{
+			name:      "searches the recipes free from allergens",
+			query:     `{ recipes(search: "mar", freeFrom: ["Gluten"], includeArchived: true) { name } }`,
+			filter:    domain.RecipeFilter{IncludeArchived: true},
+			allergens: []domain.Allergen{domain.AllergenGluten},
+			text:      "mar",
+			result:    []domain.Recipe{margherita},
+			response:  `{"data": {"recipes": [{"name": "Margherita"}]}}`,
+		},
+		{
+			name:     "pages the recipes",
+			query:    `{ recipes(limit: 1, offset: 1) { name } }`,
+			result:   []domain.Recipe{bianca, margherita},
+			response: `{"data": {"recipes": [{"name": "Margherita"}]}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := new(MockRecipeService)
+			service.On("SearchRecipes", mock.Anything, tt.filter, tt.allergens, tt.text).Return(tt.result, nil)
+			router := setupRouter(t, service)
+
+			w := post(router, tt.query, nil)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.JSONEq(t, tt.response, w.Body.String())
+		})
+	}
+
+	t.Run("rejects unknown allergens and out of range pages", func(t *testing.T) {
+		router := setupRouter(t, new(MockRecipeService))
+
+		for query, message := range map[string]string{
+			`{ recipes(freeFrom: ["pineapple"]) { name } }`: `unknown allergen \"pineapple\"`,
+			`{ recipes(limit: 0) { name } }`:                `limit must be between 1 and 500, got 0`,
+			`{ recipes(offset: -1) { name } }`:              `offset must be non-negative, got -1`,
+		} {
+			w := post(router, query, nil)
+			assert.Contains(t, w.Body.String(), message, query)
+		}
+	})
+}
+
+func TestAggregateField(t *testing.T) {
+	diameter, edge := 30, 20
+	measured := domain.Pans{
+		Pans: []domain.Pan{
+			{Shape: "round", Name: "round 30 cm", Area: 600, DoughWeight: 252, Slices: 6, Measures: domain.Measures{Diameter: &diameter}},
+			{Shape: "square", Name: "square 20 cm", Area: 400, DoughWeight: 168, Measures: domain.Measures{Edge: &edge}},
+		},
+		TotalArea: 1000,
+	}
+	aggregate := &domain.RecipeAggregate{
+		Recipe: margherita,
+		SplitIngredients: domain.SplitIngredients{SplitDough: []domain.Dough{
+			{Name: "round 30 cm", Ingredients: []domain.Ingredient{{Name: "flour", Amount: 180.04}, {Name: "water", Amount: 120}}},
+			{Name: "square 20 cm", Ingredients: []domain.Ingredient{{Name: "flour", Amount: 102}, {Name: "water", Amount: 68}}},
+		}},
+		Nutrition: []domain.PanNutrition{
+			{Pan: "round 30 cm", Slices: 6, Total: domain.Nutrition{Kcal: 654.4, Protein: 21.04}, PerSlice: domain.Nutrition{Kcal: 109.07}, Unknown: []string{"tomato"}},
+			{Pan: "square 20 cm", Slices: 8},
+		},
+		Pans: measured,
+	}
+
+	t.Run("balances the resolved recipe for the pans", func(t *testing.T) {
+		service := new(MockRecipeService)
+		service.On("RecipesByUuids", mock.Anything, []uuid.UUID{margherita.Uuid}).Return([]domain.Recipe{margherita}, nil)
+		service.On("Aggregate", mock.Anything, margherita, mock.MatchedBy(func(pans domain.Pans) bool {
+			return len(pans.Pans) == 2 && pans.Style == domain.StyleNeapolitan && pans.Thickness == 4 &&
+				*pans.Pans[0].Measures.Diameter == 30 && pans.Pans[0].Slices == 6 &&
+				*pans.Pans[1].Measures.Edge == 20 && pans.Pans[1].Slices == 0
+		})).Return(aggregate, nil)
+		router := setupRouter(t, service)
+
+		w := post(router, `query($uuid: ID!) {
+			recipe(uuid: $uuid) {
+				aggregate(pans: [{shape: round, diameter: 30, slices: 6}, {shape: square, edge: 20}], style: "neapolitan", thickness: 4) {
+					recipe { name }
+					pans {
+						pan { shape name area doughWeight slices diameter edge }
+						dough { total ingredients { name amount } }
+						topping { ingredients { name amount } }
+						nutrition { total { kcal protein } perSlice { kcal } unknownIngredients }
+					}
+				}
+			}
+		}`, map[string]any{"uuid": margherita.Uuid.String()})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"data": {"recipe": {"aggregate": {
+			"recipe": {"name": "Margherita"},
+			"pans": [
+				{
+					"pan": {"shape": "round", "name": "round 30 cm", "area": 600, "doughWeight": 252, "slices": 6, "diameter": 30, "edge": null},
+					"dough": {"total": 300, "ingredients": [{"name": "flour", "amount": 180.04}, {"name": "water", "amount": 120}]},
+					"topping": {"ingredients": [{"name": "tomato", "amount": 600}]},
+					"nutrition": {"total": {"kcal": 654, "protein": 21}, "perSlice": {"kcal": 109}, "unknownIngredients": ["tomato"]}
+				},
+				{
+					"pan": {"shape": "square", "name": "square 20 cm", "area": 400, "doughWeight": 168, "slices": 8, "diameter": null, "edge": 20},
+					"dough": {"total": 170, "ingredients": [{"name": "flour", "amount": 102}, {"name": "water", "amount": 68}]},
+					"topping": {"ingredients": [{"name": "tomato", "amount": 400}]},
+					"nutrition": {"total": {"kcal": 0, "protein": 0}, "perSlice": {"kcal": 0}, "unknownIngredients": []}
+				}
+			]
+		}}}}`, w.Body.String())
+	})
+
+	t.Run("requires the measures of the shape", func(t *testing.T) {
+		service := new(MockRecipeService)
+		service.On("RecipesByUuids", mock.Anything, []uuid.UUID{margherita.Uuid}).Return([]domain.Recipe{margherita}, nil)
+		router := setupRouter(t, service)
+
+		w := post(router, `{ recipe(uuid: "`+margherita.Uuid.String()+`") {
+			aggregate(pans: [{shape: round, diameter: 30}, {shape: rectangular, width: 20}]) { pans { pan { name } } }
+		} }`, nil)
+
+		assert.Contains(t, w.Body.String(), "pan 2: a positive length is required for rectangular pans")
+		service.AssertNotCalled(t, "Aggregate", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandler(t *testing.T) {
+	service := new(MockRecipeService)
+	service.On("SearchRecipes", mock.Anything, domain.RecipeFilter{}, []domain.Allergen(nil), "").
+		Return([]domain.Recipe{bianca}, nil)
+	router := setupRouter(t, service)
+
+	tests := []struct {
+		name     string
+		request  *http.Request
+		status   int
+		response string
+	}{
+		{
+			name:     "runs queries sent as GET",
+			request:  httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(`query($n: Int!) { recipes(limit: $n) { name } }`)+"&variables="+url.QueryEscape(`{"n": 1}`), nil),
+			status:   http.StatusOK,
+			response: `{"data": {"recipes": [{"name": "Bianca"}]}}`,
+		},
+		{
+			name:     "rejects variables that are not JSON",
+			request:  httptest.NewRequest(http.MethodGet, "/graphql?query=%7B__typename%7D&variables=nope", nil),
+			status:   http.StatusBadRequest,
+			response: `{"errors": [{"message": "variables must be a JSON object: invalid character 'o' in literal null (expecting 'u')"}]}`,
+		},
+		{
+			name:     "rejects requests without a query",
+			request:  httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewBufferString(`{}`)),
+			status:   http.StatusBadRequest,
+			response: `{"errors": [{"message": "query is required"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, tt.request)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.JSONEq(t, tt.response, w.Body.String())
+		})
+	}
+}
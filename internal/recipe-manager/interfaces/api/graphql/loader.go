@@ -0,0 +1,120 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+)
+
+const (
+	// batchWait is how long a batch collects the recipes the fields resolved
+	// in parallel ask for before it is read.
+	batchWait = 2 * time.Millisecond
+	// maxBatch caps the placeholders of the query reading a batch.
+	maxBatch = 100
+)
+
+type loaderKey struct{}
+
+// recipeLoader reads the recipes a query asks for in batches, once each:
+// every recipe is cached for the rest of the request, whether found or not.
+type recipeLoader struct {
+	fetch func(context.Context, []uuid.UUID) ([]domain.Recipe, error)
+
+	mu      sync.Mutex
+	loaded  map[uuid.UUID]*load
+	pending *batch
+}
+
+type load struct {
+	recipe *domain.Recipe
+	err    error
+	done   chan struct{}
+}
+
+type batch struct {
+	uuids []uuid.UUID
+	loads []*load
+}
+
+func newRecipeLoader(fetch func(context.Context, []uuid.UUID) ([]domain.Recipe, error)) *recipeLoader {
+	return &recipeLoader{fetch: fetch, loaded: make(map[uuid.UUID]*load)}
+}
+
+func withLoader(ctx context.Context, loader *recipeLoader) context.Context {
+	return context.WithValue(ctx, loaderKey{}, loader)
+}
+
+func loaderFrom(ctx context.Context) *recipeLoader {
+	return ctx.Value(loaderKey{}).(*recipeLoader)
+}
+
+// Load returns the recipe, or nil when there is none.
+func (l *recipeLoader) Load(ctx context.Context, recipeUuid uuid.UUID) (*domain.Recipe, error) {
+	l.mu.Lock()
+	current, found := l.loaded[recipeUuid]
+	if !found {
+		current = &load{done: make(chan struct{})}
+		l.loaded[recipeUuid] = current
+		l.enqueue(ctx, recipeUuid, current)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-current.done:
+		return current.recipe, current.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// enqueue adds a recipe to the pending batch, opening one when there is
+// none. It must be called with the lock held.
+func (l *recipeLoader) enqueue(ctx context.Context, recipeUuid uuid.UUID, current *load) {
+	if l.pending == nil {
+		pending := &batch{}
+		l.pending = pending
+		time.AfterFunc(batchWait, func() {
+			l.mu.Lock()
+			if l.pending == pending {
+				l.pending = nil
+			}
+			l.mu.Unlock()
+			l.dispatch(ctx, pending)
+		})
+	}
+	l.pending.uuids = append(l.pending.uuids, recipeUuid)
+	l.pending.loads = append(l.pending.loads, current)
+	if len(l.pending.uuids) == maxBatch {
+		full := l.pending
+		l.pending = nil
+		go l.dispatch(ctx, full)
+	}
+}
+
+func (l *recipeLoader) dispatch(ctx context.Context, b *batch) {
+	// A full batch is dispatched before its timer fires, which then finds
+	// it already read.
+	l.mu.Lock()
+	if b.loads == nil {
+		l.mu.Unlock()
+		return
+	}
+	uuids, loads := b.uuids, b.loads
+	b.uuids, b.loads = nil, nil
+	l.mu.Unlock()
+
+	recipes, err := l.fetch(ctx, uuids)
+	byUuid := make(map[uuid.UUID]*domain.Recipe, len(recipes))
+	for i := range recipes {
+		byUuid[recipes[i].Uuid] = &recipes[i]
+	}
+	for i, current := range loads {
+		current.recipe, current.err = byUuid[uuids[i]], err
+		close(current.done)
+	}
+}
@@ -0,0 +1,393 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+	gql "github.com/graph-gophers/graphql-go"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+)
+
+const maxLimit = 500
+
+type resolver struct {
+	service RecipeService
+}
+
+func (r *resolver) Recipe(ctx context.Context, args struct{ Uuid gql.ID }) (*recipeResolver, error) {
+	recipeUuid, err := uuid.Parse(string(args.Uuid))
+	if err != nil {
+		return nil, fmt.Errorf("invalid UUID %q", args.Uuid)
+	}
+	recipe, err := loaderFrom(ctx).Load(ctx, recipeUuid)
+	if err != nil || recipe == nil {
+		return nil, err
+	}
+	return &recipeResolver{recipe: *recipe, service: r.service}, nil
+}
+
+type recipesArgs struct {
+	Search          *string
+	FreeFrom        *[]string
+	IncludeArchived bool
+	Limit           int32
+	Offset          int32
+}
+
+func (r *resolver) Recipes(ctx context.Context, args recipesArgs) ([]*recipeResolver, error) {
+	limit, offset := args.Limit, args.Offset
+	if limit < 1 || limit > maxLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d, got %d", maxLimit, limit)
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("offset must be non-negative, got %d", offset)
+	}
+
+	var allergens []domain.Allergen
+	if args.FreeFrom != nil {
+		for _, value := range *args.FreeFrom {
+			allergen := domain.Allergen(strings.ToLower(strings.TrimSpace(value)))
+			if !allergen.Valid() {
+				return nil, fmt.Errorf("unknown allergen %q", value)
+			}
+			allergens = append(allergens, allergen)
+		}
+	}
+	var search string
+	if args.Search != nil {
+		search = *args.Search
+	}
+
+	filter := domain.RecipeFilter{IncludeArchived: args.IncludeArchived}
+	recipes, err := r.service.SearchRecipes(ctx, filter, allergens, search)
+	if err != nil {
+		return nil, err
+	}
+
+	start := min(int(offset), len(recipes))
+	end := min(start+int(limit), len(recipes))
+	resolvers := make([]*recipeResolver, 0, end-start)
+	for _, recipe := range recipes[start:end] {
+		resolvers = append(resolvers, &recipeResolver{recipe: recipe, service: r.service})
+	}
+	return resolvers, nil
+}
+
+type recipeResolver struct {
+	recipe  domain.Recipe
+	service RecipeService
+}
+
+func (r *recipeResolver) Uuid() gql.ID        { return gql.ID(r.recipe.Uuid.String()) }
+func (r *recipeResolver) Name() string        { return r.recipe.Name }
+func (r *recipeResolver) Description() string { return r.recipe.Description }
+func (r *recipeResolver) Author() string      { return r.recipe.Author }
+func (r *recipeResolver) Version() int32      { return int32(r.recipe.Version) }
+
+func (r *recipeResolver) ArchivedAt() *gql.Time {
+	if r.recipe.ArchivedAt == nil {
+		return nil
+	}
+	return &gql.Time{Time: *r.recipe.ArchivedAt}
+}
+
+func (r *recipeResolver) Dough() *doughResolver {
+	return &doughResolver{dough: r.recipe.Dough}
+}
+
+func (r *recipeResolver) Topping() *toppingResolver {
+	return &toppingResolver{topping: r.recipe.Topping}
+}
+
+func (r *recipeResolver) Steps() []*stepResolver {
+	steps := make([]*stepResolver, len(r.recipe.Steps.Steps))
+	for i, step := range r.recipe.Steps.Steps {
+		steps[i] = &stepResolver{step: step}
+	}
+	return steps
+}
+
+func (r *recipeResolver) Labels() *labelsResolver {
+	return &labelsResolver{labels: r.recipe.Labels()}
+}
+
+func (r *recipeResolver) Style() *string {
+	if r.recipe.Style == "" {
+		return nil
+	}
+	return &r.recipe.Style
+}
+
+func (r *recipeResolver) DoughDensity() *float64 {
+	if r.recipe.DoughDensity == 0 {
+		return nil
+	}
+	return &r.recipe.DoughDensity
+}
+
+func (r *recipeResolver) ServingWeight() *float64 {
+	if r.recipe.ServingWeight == 0 {
+		return nil
+	}
+	return &r.recipe.ServingWeight
+}
+
+// Aggregate balances the recipe already resolved, so the aggregates of a
+// listing do not read each recipe again.
+func (r *recipeResolver) Aggregate(ctx context.Context, args struct {
+	Pans         []panInput
+	Style        *string
+	Thickness    *float64
+	DoughDensity *float64
+}) (*aggregateResolver, error) {
+	if len(args.Pans) == 0 {
+		return nil, fmt.Errorf("at least one pan is required")
+	}
+	pans := domain.Pans{Pans: make([]domain.Pan, len(args.Pans))}
+	if args.Style != nil {
+		pans.Style = *args.Style
+	}
+	if args.Thickness != nil {
+		pans.Thickness = *args.Thickness
+	}
+	if args.DoughDensity != nil {
+		pans.DoughDensity = *args.DoughDensity
+	}
+	for i, input := range args.Pans {
+		pan, err := input.toDomain()
+		if err != nil {
+			return nil, fmt.Errorf("pan %d: %w", i+1, err)
+		}
+		pans.Pans[i] = pan
+	}
+
+	aggregate, err := r.service.Aggregate(ctx, r.recipe, pans)
+	if err != nil {
+		return nil, err
+	}
+	return &aggregateResolver{aggregate: *aggregate, service: r.service}, nil
+}
+
+type panInput struct {
+	Shape    string
+	Diameter *int32
+	Edge     *int32
+	Width    *int32
+	Length   *int32
+	Slices   *int32
+}
+
+// toDomain requires the measures of the shape of the pan, as the REST API
+// does.
+func (p panInput) toDomain() (domain.Pan, error) {
+	pan := domain.Pan{Shape: p.Shape}
+	measure := func(name string, value *int32) (*int, error) {
+		if value == nil || *value <= 0 {
+			return nil, fmt.Errorf("a positive %s is required for %s pans", name, p.Shape)
+		}
+		converted := int(*value)
+		return &converted, nil
+	}
+
+	var err error
+	switch p.Shape {
+	case "round":
+		pan.Measures.Diameter, err = measure("diameter", p.Diameter)
+	case "square":
+		pan.Measures.Edge, err = measure("edge", p.Edge)
+	case "rectangular":
+		if pan.Measures.Width, err = measure("width", p.Width); err == nil {
+			pan.Measures.Length, err = measure("length", p.Length)
+		}
+	}
+	if err != nil {
+		return domain.Pan{}, err
+	}
+
+	if p.Slices != nil {
+		if *p.Slices < 1 || *p.Slices > 64 {
+			return domain.Pan{}, fmt.Errorf("slices must be between 1 and 64, got %d", *p.Slices)
+		}
+		pan.Slices = int(*p.Slices)
+	}
+	return pan, nil
+}
+
+type doughResolver struct {
+	dough domain.Dough
+}
+
+func (r *doughResolver) Name() string              { return r.dough.Name }
+func (r *doughResolver) PercentVariation() float64 { return r.dough.PercentVariation }
+func (r *doughResolver) Total() float64            { return math.Round(r.dough.Total()*10) / 10 }
+
+func (r *doughResolver) Ingredients() []*ingredientResolver {
+	return ingredientResolvers(r.dough.Ingredients)
+}
+
+type toppingResolver struct {
+	topping domain.Topping
+}
+
+func (r *toppingResolver) Name() string           { return r.topping.Name }
+func (r *toppingResolver) ReferenceArea() float64 { return r.topping.ReferenceArea }
+
+func (r *toppingResolver) Ingredients() []*ingredientResolver {
+	return ingredientResolvers(r.topping.Ingredients)
+}
+
+type ingredientResolver struct {
+	ingredient domain.Ingredient
+}
+
+func ingredientResolvers(ingredients []domain.Ingredient) []*ingredientResolver {
+	resolvers := make([]*ingredientResolver, len(ingredients))
+	for i, ingredient := range ingredients {
+		resolvers[i] = &ingredientResolver{ingredient: ingredient}
+	}
+	return resolvers
+}
+
+func (r *ingredientResolver) Name() string    { return r.ingredient.Name }
+func (r *ingredientResolver) Amount() float64 { return r.ingredient.Amount }
+func (r *ingredientResolver) Unit() string    { return r.ingredient.Unit }
+func (r *ingredientResolver) Notes() string   { return r.ingredient.Notes }
+
+type stepResolver struct {
+	step domain.Step
+}
+
+func (r *stepResolver) Number() int32       { return int32(r.step.StepNumber) }
+func (r *stepResolver) Description() string { return r.step.Description }
+
+type labelsResolver struct {
+	labels domain.Labels
+}
+
+func (r *labelsResolver) Allergens() []string {
+	allergens := make([]string, len(r.labels.Allergens))
+	for i, allergen := range r.labels.Allergens {
+		allergens[i] = string(allergen)
+	}
+	return allergens
+}
+
+func (r *labelsResolver) Diets() []string {
+	diets := make([]string, len(r.labels.Diets))
+	for i, diet := range r.labels.Diets {
+		diets[i] = string(diet)
+	}
+	return diets
+}
+
+func (r *labelsResolver) UnlabelledIngredients() []string {
+	return append([]string{}, r.labels.Unlabelled...)
+}
+
+type aggregateResolver struct {
+	aggregate domain.RecipeAggregate
+	service   RecipeService
+}
+
+func (r *aggregateResolver) Recipe() *recipeResolver {
+	return &recipeResolver{recipe: r.aggregate.Recipe, service: r.service}
+}
+
+func (r *aggregateResolver) Pans() []*panSplitResolver {
+	splits := make([]*panSplitResolver, len(r.aggregate.Pans.Pans))
+	for i := range splits {
+		splits[i] = &panSplitResolver{aggregate: &r.aggregate, index: i}
+	}
+	return splits
+}
+
+// panSplitResolver resolves the share of the aggregate balanced for the pan
+// at index.
+type panSplitResolver struct {
+	aggregate *domain.RecipeAggregate
+	index     int
+}
+
+func (r *panSplitResolver) Pan() *panResolver {
+	return &panResolver{pan: r.aggregate.Pans.Pans[r.index]}
+}
+
+func (r *panSplitResolver) Dough() *doughResolver {
+	var dough domain.Dough
+	if r.index < len(r.aggregate.SplitIngredients.SplitDough) {
+		dough = r.aggregate.SplitIngredients.SplitDough[r.index]
+	}
+	return &doughResolver{dough: dough}
+}
+
+func (r *panSplitResolver) Topping() *toppingResolver {
+	topping := r.aggregate.Recipe.Topping
+	topping.Ingredients = r.aggregate.PanTopping(r.index)
+	return &toppingResolver{topping: topping}
+}
+
+func (r *panSplitResolver) Nutrition() *panNutritionResolver {
+	var nutrition domain.PanNutrition
+	if r.index < len(r.aggregate.Nutrition) {
+		nutrition = r.aggregate.Nutrition[r.index]
+	}
+	return &panNutritionResolver{nutrition: nutrition}
+}
+
+type panResolver struct {
+	pan domain.Pan
+}
+
+func (r *panResolver) Shape() string    { return r.pan.Shape }
+func (r *panResolver) Name() string     { return r.pan.Name }
+func (r *panResolver) Area() float64    { return r.pan.Area }
+func (r *panResolver) Diameter() *int32 { return measure(r.pan.Measures.Diameter) }
+func (r *panResolver) Edge() *int32     { return measure(r.pan.Measures.Edge) }
+func (r *panResolver) Width() *int32    { return measure(r.pan.Measures.Width) }
+func (r *panResolver) Length() *int32   { return measure(r.pan.Measures.Length) }
+
+func (r *panResolver) Slices() int32 {
+	if r.pan.Slices <= 0 {
+		return domain.DefaultSlices
+	}
+	return int32(r.pan.Slices)
+}
+
+func (r *panResolver) DoughWeight() float64 { return r.pan.DoughWeight }
+
+func measure(value *int) *int32 {
+	if value == nil {
+		return nil
+	}
+	converted := int32(*value)
+	return &converted
+}
+
+type panNutritionResolver struct {
+	nutrition domain.PanNutrition
+}
+
+func (r *panNutritionResolver) Total() *nutritionResolver {
+	return &nutritionResolver{nutrition: r.nutrition.Total.Rounded()}
+}
+
+func (r *panNutritionResolver) PerSlice() *nutritionResolver {
+	return &nutritionResolver{nutrition: r.nutrition.PerSlice.Rounded()}
+}
+
+func (r *panNutritionResolver) UnknownIngredients() []string {
+	return append([]string{}, r.nutrition.Unknown...)
+}
+
+type nutritionResolver struct {
+	nutrition domain.Nutrition
+}
+
+func (r *nutritionResolver) Kcal() float64          { return r.nutrition.Kcal }
+func (r *nutritionResolver) Protein() float64       { return r.nutrition.Protein }
+func (r *nutritionResolver) Carbohydrates() float64 { return r.nutrition.Carbohydrates }
+func (r *nutritionResolver) Fat() float64           { return r.nutrition.Fat }
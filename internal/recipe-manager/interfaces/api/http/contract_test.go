@@ -0,0 +1,555 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+	httpHandlers "github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/http"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/http/envelope"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/http/middleware"
+	apigraphql "github.com/cfioretti/recipe-manager/internal/recipe-manager/interfaces/api/graphql"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/interfaces/api/openapi"
+)
+
+const documentURL = "https://recipe-manager.example/openapi.json"
+
+// contract validates requests and responses against the OpenAPI document.
+type contract struct {
+	document map[string]any
+	compiler *jsonschema.Compiler
+}
+
+func loadContract(t *testing.T) *contract {
+	t.Helper()
+	raw, err := openapi.Document()
+	require.NoError(t, err)
+
+	var document map[string]any
+	require.NoError(t, json.Unmarshal(raw, &document))
+	resource, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	require.NoError(t, err)
+
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	compiler.AssertFormat()
+	require.NoError(t, compiler.AddResource(documentURL, resource))
+	return &contract{document: document, compiler: compiler}
+}
+
+var pathParameter = regexp.MustCompile(`:(\w+)`)
+
+// operation returns the documented operation of a gin route and its JSON
+// pointer in the document. The document is served under /api/v1 but for the
+// paths overriding its servers, which are served at the root.
+func (c *contract) operation(method, route string) (map[string]any, string, bool) {
+	versioned := strings.HasPrefix(route, envelope.Prefix+"/")
+	path := pathParameter.ReplaceAllString(strings.TrimPrefix(route, envelope.Prefix), "{$1}")
+	item, _ := c.paths()[path].(map[string]any)
+	if _, unversioned := item["servers"]; unversioned == versioned {
+		return nil, "", false
+	}
+	operation, found := item[strings.ToLower(method)].(map[string]any)
+	return operation, "/paths/" + escape(path) + "/" + strings.ToLower(method), found
+}
+
+func (c *contract) paths() map[string]any {
+	paths, _ := c.document["paths"].(map[string]any)
+	return paths
+}
+
+// resolve follows the $ref of a response or request body, returning the
+// object and its pointer.
+func (c *contract) resolve(object map[string]any, pointer string) (map[string]any, string) {
+	ref, isRef := object["$ref"].(string)
+	if !isRef {
+		return object, pointer
+	}
+	pointer = strings.TrimPrefix(ref, "#")
+	resolved := any(c.document)
+	for _, token := range strings.Split(pointer, "/")[1:] {
+		resolved = resolved.(map[string]any)[unescape(token)]
+	}
+	return resolved.(map[string]any), pointer
+}
+
+// validate checks body against the schema of the given media type in content,
+// a response or request body.
+func (c *contract) validate(t *testing.T, object map[string]any, pointer, contentType string, body []byte) {
+	t.Helper()
+	object, pointer = c.resolve(object, pointer)
+	content, _ := object["content"].(map[string]any)
+	if len(body) == 0 && content == nil {
+		return
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	require.NoError(t, err, "content type %q", contentType)
+	if _, documented := content[mediaType]; !documented {
+		assert.Fail(t, "undocumented content type", "%s has no %s content", pointer, mediaType)
+		return
+	}
+	switch mediaType {
+	case "application/json":
+	case "application/yaml":
+		// YAML bodies hold the same data as the JSON ones, and are validated
+		// against the same schemas.
+		var decoded any
+		require.NoError(t, yaml.Unmarshal(body, &decoded), "body is not YAML: %s", body)
+		body, err = json.Marshal(decoded)
+		require.NoError(t, err)
+	default:
+		return
+	}
+
+	schema, err := c.compiler.Compile(documentURL + "#" + pointer + "/content/" + escape(mediaType) + "/schema")
+	require.NoError(t, err)
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	require.NoError(t, err, "body is not JSON: %s", body)
+	assert.NoError(t, schema.Validate(instance), "%s", body)
+}
+
+// enum returns the values of an enum schema in the components.
+func (c *contract) enum(name string) []string {
+	schema, _ := c.resolve(map[string]any{"$ref": "#/components/schemas/" + name}, "")
+	var values []string
+	for _, value := range schema["enum"].([]any) {
+		values = append(values, value.(string))
+	}
+	return values
+}
+
+func escape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func unescape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+func TestContract(t *testing.T) {
+	contract := loadContract(t)
+	recipeService, webhookService := new(MockRecipeService), new(MockWebhookService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	var route string
+	router.Use(func(c *gin.Context) {
+		c.Next()
+		route = c.FullPath()
+	})
+	recipeHandler, webhookHandler := NewRecipeHandler(recipeService), NewWebhookHandler(webhookService)
+	deprecation := middleware.DeprecationConfig{
+		DeprecatedAt: time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+		Sunset:       time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC),
+	}
+	for _, group := range []*gin.RouterGroup{router.Group(envelope.Prefix), router.Group("", middleware.Deprecation(deprecation))} {
+		recipeHandler.RegisterRoutes(group)
+		admin := group.Group("/admin")
+		recipeHandler.RegisterAdminRoutes(admin)
+		webhookHandler.RegisterAdminRoutes(admin)
+	}
+	httpHandlers.NewHealthHandler().RegisterRoutes(router)
+	httpHandlers.NewMetricsHandler().RegisterRoutes(router)
+	httpHandlers.NewLogLevelHandler(logrus.New(), "s3cret").RegisterRoutes(router)
+	openAPIHandler, err := openapi.NewHandler()
+	require.NoError(t, err)
+	openAPIHandler.RegisterRoutes(router)
+	graphQLHandler, err := apigraphql.NewHandler(recipeService)
+	require.NoError(t, err)
+	graphQLHandler.RegisterRoutes(router)
+
+	t.Run("documents every route", func(t *testing.T) {
+		routes := router.Routes()
+		registered := map[string]bool{}
+		for _, route := range routes {
+			_, pointer, found := contract.operation(route.Method, route.Path)
+			if !found && !strings.HasPrefix(route.Path, envelope.Prefix+"/") {
+				// A legacy route, documented by its successor under /api/v1.
+				_, _, found = contract.operation(route.Method, envelope.Prefix+route.Path)
+				assert.True(t, slices.ContainsFunc(routes, func(successor gin.RouteInfo) bool {
+					return successor.Method == route.Method && successor.Path == envelope.Prefix+route.Path
+				}), "%s %s has no successor under %s", route.Method, route.Path, envelope.Prefix)
+			}
+			assert.True(t, found, "%s %s is not documented", route.Method, route.Path)
+			registered[pointer] = true
+		}
+		for path, item := range contract.paths() {
+			for method := range item.(map[string]any) {
+				if method == "parameters" || method == "servers" {
+					continue
+				}
+				pointer := "/paths/" + escape(path) + "/" + method
+				assert.True(t, registered[pointer], "%s %s is documented but not served", strings.ToUpper(method), path)
+			}
+		}
+	})
+
+	t.Run("keeps the enums of the domain", func(t *testing.T) {
+		var allergens, eventTypes []string
+		for _, allergen := range domain.Allergens {
+			allergens = append(allergens, string(allergen))
+		}
+		for _, eventType := range domain.EventTypes {
+			eventTypes = append(eventTypes, string(eventType))
+		}
+		assert.Equal(t, allergens, contract.enum("Allergen"))
+		assert.Equal(t, eventTypes, contract.enum("EventType"))
+		assert.ElementsMatch(t, []string{string(domain.DeliveryPending), string(domain.DeliveryDelivered), string(domain.DeliveryDead)},
+			contract.enum("DeliveryStatus"))
+	})
+
+	recipeUuid, subscriptionId, deliveryId := uuid.New(), uuid.New(), uuid.New()
+	at := time.Date(2026, time.October, 19, 7, 0, 0, 0, time.UTC)
+	flour := domain.Ingredient{Name: "flour", Amount: 60, Unit: "%", Catalogue: &domain.CatalogueIngredient{
+		Id: 1, Name: "flour", Category: "flour", Allergens: []domain.Allergen{domain.AllergenGluten}, Diets: []domain.Diet{domain.DietVegan, domain.DietVegetarian},
+	}}
+	recipe := domain.Recipe{
+		Uuid:        recipeUuid,
+		Name:        "Margherita",
+		Description: "The classic",
+		Author:      "PizzaMaker",
+		Dough:       domain.Dough{Ingredients: []domain.Ingredient{flour, {Name: "water", Amount: 40, Unit: "%"}}},
+		Topping:     domain.Topping{Ingredients: []domain.Ingredient{{Name: "tomato", Amount: 120, Unit: "g"}}},
+		Version:     3,
+	}
+	archived := recipe
+	archived.ArchivedAt = &at
+	aggregate := domain.RecipeAggregate{
+		Recipe: recipe,
+		SplitIngredients: domain.SplitIngredients{SplitDough: []domain.Dough{
+			{Name: "round", Ingredients: []domain.Ingredient{{Name: "flour", Amount: 240}, {Name: "water", Amount: 160}}},
+		}},
+		Nutrition: []domain.PanNutrition{{
+			Pan: "round", Slices: 8, Total: domain.Nutrition{Kcal: 1090, Protein: 31.2}, PerSlice: domain.Nutrition{Kcal: 136.25},
+			Unknown: []string{"water"},
+		}},
+		Labels: recipe.Labels(),
+	}
+	rounded := aggregate
+	rounded.Rounding = &domain.RoundingReport{Ingredients: []domain.RoundingDrift{
+		{Component: domain.ComponentDough, Ingredient: "flour", Step: 5, Exact: 238.4, Rounded: 240},
+	}}
+	fermented := aggregate
+	fermented.Fermentation = &domain.Fermentation{
+		Yeast: domain.YeastFresh, YeastPercent: 0.31,
+		Schedule: domain.FermentationPlan{Duration: 8 * time.Hour, RoomTemperature: 21, BakeAt: at}.Schedule(),
+	}
+	balls := aggregate
+	balls.Balls = &domain.Balls{Count: 4, Weight: 250, DoughWeight: 1030.93, Loss: 3}
+	scaled := aggregate
+	scaled.Scale = &domain.Scale{Servings: 40, ServingWeight: 250, DoughWeight: 10000}
+	scaled.ServingWeight = 250
+	styled := aggregate
+	styled.Style, styled.DoughDensity = domain.StyleDetroit, 0.62
+	subscription := domain.Subscription{
+		Id: subscriptionId, URL: "https://menu-board.example/hooks", EventTypes: []domain.EventType{domain.EventRecipeCreated},
+		Secret: "menu-board-secret", CreatedAt: at,
+	}
+	pending := domain.Delivery{
+		Id: deliveryId, SubscriptionId: subscriptionId, EventId: uuid.New(), EventType: domain.EventRecipeCreated,
+		Payload: []byte(`{"id":"8d6f0a5c-2f7e-4a53-8d6e-0f1c2b3a4d5e","type":"recipe.created"}`), Status: domain.DeliveryPending,
+		NextAttemptAt: at, CreatedAt: at,
+	}
+	dead := pending
+	dead.Status, dead.Attempts, dead.LastError, dead.ResponseStatus = domain.DeliveryDead, 8, "receiver answered 503", 503
+
+	recipeBody := `{"name": "Margherita", "author": "PizzaMaker",
+		"dough": {"ingredients": [{"name": "flour", "amount": 60}, {"name": "water", "amount": 40, "notes": "cold"}]},
+		"topping": {"referenceArea": 1000, "ingredients": [{"name": "tomato", "amount": 120, "unit": "g"}]}}`
+	recipePath := envelope.Prefix + "/recipes/" + recipeUuid.String()
+	admin := envelope.Prefix + "/admin"
+	adminToken := map[string]string{httpHandlers.AdminTokenHeader: "s3cret"}
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		headers map[string]string
+		setup   func()
+		status  int
+	}{
+		{name: "list recipes", method: http.MethodGet, path: envelope.Prefix + "/recipes?freeFrom=milk&includeArchived=true", status: http.StatusOK,
+			setup: func() {
+				recipeService.On("RecipesFreeFrom", mock.Anything, mock.Anything, mock.Anything).Return([]domain.Recipe{recipe, archived}, nil).Once()
+			}},
+		{name: "list a page of recipes", method: http.MethodGet, path: envelope.Prefix + "/recipes?limit=1&offset=1", status: http.StatusOK,
+			setup: func() {
+				recipeService.On("RecipesFreeFrom", mock.Anything, mock.Anything, mock.Anything).Return([]domain.Recipe{recipe, archived}, nil).Once()
+			}},
+		{name: "list recipes from a negative offset", method: http.MethodGet, path: envelope.Prefix + "/recipes?offset=-1", status: http.StatusBadRequest},
+		{name: "list recipes free from an unknown allergen", method: http.MethodGet, path: envelope.Prefix + "/recipes?freeFrom=wood", status: http.StatusBadRequest},
+		{name: "create a recipe", method: http.MethodPost, path: envelope.Prefix + "/recipes", body: recipeBody, status: http.StatusCreated,
+			setup: func() {
+				recipeService.On("CreateRecipe", mock.Anything, mock.Anything).Return(&recipe, nil).Once()
+			}},
+		{name: "create a recipe without name", method: http.MethodPost, path: envelope.Prefix + "/recipes", body: `{}`, status: http.StatusBadRequest},
+		{name: "get a recipe", method: http.MethodGet, path: recipePath, status: http.StatusOK,
+			setup: func() { recipeService.On("Recipe", mock.Anything, recipeUuid).Return(&recipe, nil).Once() }},
+		{name: "get an unchanged recipe", method: http.MethodGet, path: recipePath, headers: map[string]string{"If-None-Match": `"3"`},
+			status: http.StatusNotModified,
+			setup:  func() { recipeService.On("Recipe", mock.Anything, recipeUuid).Return(&recipe, nil).Once() }},
+		{name: "get a missing recipe", method: http.MethodGet, path: recipePath, status: http.StatusNotFound,
+			setup: func() {
+				recipeService.On("Recipe", mock.Anything, recipeUuid).Return((*domain.Recipe)(nil), domain.ErrRecipeNotFound).Once()
+			}},
+		{name: "update a recipe", method: http.MethodPut, path: recipePath, body: recipeBody, headers: map[string]string{"If-Match": `"3"`},
+			status: http.StatusOK,
+			setup:  func() { recipeService.On("UpdateRecipe", mock.Anything, mock.Anything).Return(&recipe, nil).Once() }},
+		{name: "update a recipe without If-Match", method: http.MethodPut, path: recipePath, body: recipeBody,
+			status: http.StatusPreconditionRequired},
+		{name: "update a changed recipe", method: http.MethodPut, path: recipePath, body: recipeBody, headers: map[string]string{"If-Match": `"2"`},
+			status: http.StatusPreconditionFailed,
+			setup: func() {
+				recipeService.On("UpdateRecipe", mock.Anything, mock.Anything).Return((*domain.Recipe)(nil), domain.ErrVersionConflict).Once()
+			}},
+		{name: "delete a recipe", method: http.MethodDelete, path: recipePath, headers: map[string]string{"If-Match": "*"},
+			status: http.StatusNoContent,
+			setup:  func() { recipeService.On("DeleteRecipe", mock.Anything, recipeUuid, 0).Return(nil).Once() }},
+		{name: "archive a recipe", method: http.MethodPost, path: recipePath + "/archive", headers: map[string]string{"If-Match": `"3"`},
+			status: http.StatusOK,
+			setup: func() {
+				recipeService.On("ArchiveRecipe", mock.Anything, recipeUuid, 3, true).Return(&archived, nil).Once()
+			}},
+		{name: "unarchive a recipe", method: http.MethodPost, path: recipePath + "/unarchive", headers: map[string]string{"If-Match": `"4"`},
+			status: http.StatusOK,
+			setup: func() {
+				recipeService.On("ArchiveRecipe", mock.Anything, recipeUuid, 4, false).Return(&recipe, nil).Once()
+			}},
+		{name: "aggregate a recipe", method: http.MethodPost, path: recipePath + "/aggregate",
+			body:   `{"pans": [{"shape": "round", "measures": {"diameter": "30"}, "slices": 8}]}`,
+			status: http.StatusOK,
+			setup: func() {
+				recipeService.On("Handle", mock.Anything, recipeUuid, mock.Anything).Return(&aggregate, nil).Once()
+			}},
+		{name: "aggregate a recipe with its rounding drift", method: http.MethodPost, path: recipePath + "/aggregate",
+			body:   `{"pans": [{"shape": "round", "measures": {"diameter": "30"}}]}`,
+			status: http.StatusOK,
+			setup: func() {
+				recipeService.On("Handle", mock.Anything, recipeUuid, mock.Anything).Return(&rounded, nil).Once()
+			}},
+		{name: "aggregate a recipe with its fermentation schedule", method: http.MethodPost, path: recipePath + "/aggregate",
+			body: `{"pans": [{"shape": "round", "measures": {"diameter": "30"}}],
+				"fermentation": {"yeast": "fresh", "hours": 8, "roomTemperature": 21, "bakeAt": "2026-10-24T19:30:00Z"}}`,
+			status: http.StatusOK,
+			setup: func() {
+				recipeService.On("Schedule", mock.Anything, recipeUuid, mock.Anything, mock.Anything).Return(&fermented, nil).Once()
+			}},
+		{name: "aggregate a recipe at the thickness of the request", method: http.MethodPost, path: recipePath + "/aggregate",
+			body:   `{"pans": [{"shape": "rectangular", "measures": {"width": "25", "length": "35"}}], "style": "detroit", "thickness": 6}`,
+			status: http.StatusOK,
+			setup: func() {
+				recipeService.On("Handle", mock.Anything, recipeUuid, mock.Anything).Return(&styled, nil).Once()
+			}},
+		{name: "aggregate a recipe in an unknown style", method: http.MethodPost, path: recipePath + "/aggregate",
+			body:   `{"pans": [{"shape": "round", "measures": {"diameter": "30"}}], "style": "chicago"}`,
+			status: http.StatusBadRequest},
+		{name: "split a recipe into dough balls", method: http.MethodPost, path: recipePath + "/aggregate",
+			body:   `{"balls": {"count": 4, "weight": 250, "loss": 3}}`,
+			status: http.StatusOK,
+			setup: func() {
+				recipeService.On("Balls", mock.Anything, recipeUuid, domain.Balls{Count: 4, Weight: 250, Loss: 3}, (*domain.FermentationPlan)(nil)).Return(&balls, nil).Once()
+			}},
+		{name: "scale a recipe to servings", method: http.MethodPost, path: recipePath + "/aggregate",
+			body:   `{"scale": {"servings": 40}}`,
+			status: http.StatusOK,
+			setup: func() {
+				recipeService.On("Scale", mock.Anything, recipeUuid, domain.Scale{Servings: 40}, (*domain.FermentationPlan)(nil)).Return(&scaled, nil).Once()
+			}},
+		{name: "scale a recipe and split it into pans at once", method: http.MethodPost, path: recipePath + "/aggregate",
+			body:   `{"pans": [{"shape": "round", "measures": {"diameter": "30"}}], "scale": {"servings": 4}}`,
+			status: http.StatusBadRequest},
+		{name: "split a recipe into pans and dough balls at once", method: http.MethodPost, path: recipePath + "/aggregate",
+			body:   `{"pans": [{"shape": "round", "measures": {"diameter": "30"}}], "balls": {"count": 4}}`,
+			status: http.StatusBadRequest},
+		{name: "aggregate a recipe as YAML", method: http.MethodPost, path: recipePath + "/aggregate",
+			body:    `{"pans": [{"shape": "round", "measures": {"diameter": "30"}}]}`,
+			headers: map[string]string{"Accept": "application/yaml"},
+			status:  http.StatusOK,
+			setup: func() {
+				recipeService.On("Handle", mock.Anything, recipeUuid, mock.Anything).Return(&aggregate, nil).Once()
+			}},
+		{name: "aggregate a recipe as CSV", method: http.MethodPost, path: recipePath + "/aggregate",
+			body:    `{"pans": [{"shape": "round", "measures": {"diameter": "30"}}]}`,
+			headers: map[string]string{"Accept": "text/csv"},
+			status:  http.StatusOK,
+			setup: func() {
+				recipeService.On("Handle", mock.Anything, recipeUuid, mock.Anything).Return(&aggregate, nil).Once()
+			}},
+		{name: "aggregate a recipe as protobuf", method: http.MethodPost, path: recipePath + "/aggregate",
+			body:    `{"pans": [{"shape": "round", "measures": {"diameter": "30"}}]}`,
+			headers: map[string]string{"Accept": "application/x-protobuf"},
+			status:  http.StatusOK,
+			setup: func() {
+				recipeService.On("Handle", mock.Anything, recipeUuid, mock.Anything).Return(&aggregate, nil).Once()
+			}},
+		{name: "aggregate a recipe in a representation not offered", method: http.MethodPost, path: recipePath + "/aggregate",
+			body:    `{"pans": [{"shape": "round", "measures": {"diameter": "30"}}]}`,
+			headers: map[string]string{"Accept": "application/xml"},
+			status:  http.StatusNotAcceptable},
+		{name: "print the card of a recipe", method: http.MethodGet, path: recipePath + "/card?pans=round:30/8",
+			status: http.StatusOK,
+			setup: func() {
+				recipeService.On("Handle", mock.Anything, recipeUuid, mock.Anything).Return(&aggregate, nil).Once()
+			}},
+		{name: "print the card of a recipe as Markdown", method: http.MethodGet, path: recipePath + "/card?pans=round:30",
+			headers: map[string]string{"Accept": "text/markdown"},
+			status:  http.StatusOK,
+			setup: func() {
+				recipeService.On("Handle", mock.Anything, recipeUuid, mock.Anything).Return(&aggregate, nil).Once()
+			}},
+		{name: "print the card of a recipe as PDF", method: http.MethodGet, path: recipePath + "/card?pans=round:30&format=pdf",
+			status: http.StatusOK,
+			setup: func() {
+				recipeService.On("Handle", mock.Anything, recipeUuid, mock.Anything).Return(&aggregate, nil).Once()
+			}},
+		{name: "print the card of a recipe without pans", method: http.MethodGet, path: recipePath + "/card",
+			status: http.StatusBadRequest},
+		{name: "print the card of a recipe in a format not offered", method: http.MethodGet, path: recipePath + "/card?pans=round:30",
+			headers: map[string]string{"Accept": "application/json"},
+			status:  http.StatusNotAcceptable},
+		{name: "list the deleted recipes", method: http.MethodGet, path: admin + "/recipes/deleted", status: http.StatusOK,
+			setup: func() {
+				deleted := recipe
+				deleted.DeletedAt = &at
+				recipeService.On("DeletedRecipes", mock.Anything).Return([]domain.Recipe{deleted}, nil).Once()
+			}},
+		{name: "restore a recipe", method: http.MethodPost, path: admin + "/recipes/" + recipeUuid.String() + "/restore", status: http.StatusOK,
+			setup: func() { recipeService.On("RestoreRecipe", mock.Anything, recipeUuid).Return(&recipe, nil).Once() }},
+		{name: "get the log level", method: http.MethodGet, path: "/admin/log-level", status: http.StatusOK, headers: adminToken},
+		{name: "set the log level", method: http.MethodPut, path: "/admin/log-level", body: `{"level": "debug"}`, status: http.StatusOK, headers: adminToken},
+		{name: "get the log level without the admin token", method: http.MethodGet, path: "/admin/log-level", status: http.StatusUnauthorized},
+		{name: "subscribe", method: http.MethodPost, path: admin + "/webhooks/subscriptions",
+			body:   `{"url": "https://menu-board.example/hooks", "events": ["recipe.created"]}`,
+			status: http.StatusCreated,
+			setup: func() {
+				webhookService.On("Subscribe", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&subscription, nil).Once()
+			}},
+		{name: "list subscriptions", method: http.MethodGet, path: admin + "/webhooks/subscriptions", status: http.StatusOK,
+			setup: func() {
+				webhookService.On("Subscriptions", mock.Anything).Return([]domain.Subscription{subscription}, nil).Once()
+			}},
+		{name: "get a subscription", method: http.MethodGet, path: admin + "/webhooks/subscriptions/" + subscriptionId.String(),
+			status: http.StatusOK,
+			setup: func() {
+				webhookService.On("Subscription", mock.Anything, subscriptionId).Return(&subscription, nil).Once()
+			}},
+		{name: "unsubscribe", method: http.MethodDelete, path: admin + "/webhooks/subscriptions/" + subscriptionId.String(),
+			status: http.StatusNoContent,
+			setup:  func() { webhookService.On("Unsubscribe", mock.Anything, subscriptionId).Return(nil).Once() }},
+		{name: "list the deliveries of a subscription", method: http.MethodGet,
+			path:   admin + "/webhooks/subscriptions/" + subscriptionId.String() + "/deliveries",
+			status: http.StatusOK,
+			setup: func() {
+				webhookService.On("Subscription", mock.Anything, subscriptionId).Return(&subscription, nil).Once()
+				webhookService.On("Deliveries", mock.Anything, mock.Anything).Return([]domain.Delivery{pending}, nil).Once()
+			}},
+		{name: "list the dead-letter list", method: http.MethodGet, path: admin + "/webhooks/deliveries?status=dead", status: http.StatusOK,
+			setup: func() {
+				webhookService.On("Deliveries", mock.Anything, mock.Anything).Return([]domain.Delivery{dead}, nil).Once()
+			}},
+		{name: "replay a delivery", method: http.MethodPost, path: admin + "/webhooks/deliveries/" + deliveryId.String() + "/replay",
+			status: http.StatusAccepted,
+			setup: func() {
+				webhookService.On("ReplayDelivery", mock.Anything, deliveryId).Return(&pending, nil).Once()
+			}},
+		{name: "get a recipe on the legacy route", method: http.MethodGet, path: "/recipes/" + recipeUuid.String(), status: http.StatusOK,
+			setup: func() { recipeService.On("Recipe", mock.Anything, recipeUuid).Return(&recipe, nil).Once() }},
+		{name: "get a missing recipe on the legacy route", method: http.MethodGet, path: "/recipes/" + recipeUuid.String(), status: http.StatusNotFound,
+			setup: func() {
+				recipeService.On("Recipe", mock.Anything, recipeUuid).Return((*domain.Recipe)(nil), domain.ErrRecipeNotFound).Once()
+			}},
+		{name: "health", method: http.MethodGet, path: "/health", status: http.StatusOK},
+		{name: "metrics", method: http.MethodGet, path: "/metrics", status: http.StatusOK},
+		{name: "OpenAPI document", method: http.MethodGet, path: "/openapi.json", status: http.StatusOK},
+		{name: "Swagger UI", method: http.MethodGet, path: "/docs", status: http.StatusOK},
+		{name: "GraphQL query", method: http.MethodPost, path: "/graphql", status: http.StatusOK,
+			body: `{"query": "query($search: String) { recipes(search: $search) { uuid name labels { allergens } } }", "variables": {"search": "marg"}}`,
+			setup: func() {
+				recipeService.On("SearchRecipes", mock.Anything, domain.RecipeFilter{}, []domain.Allergen(nil), "marg").
+					Return([]domain.Recipe{recipe}, nil).Once()
+			}},
+		{name: "GraphQL query sent as GET", method: http.MethodGet, path: "/graphql?query=" + url.QueryEscape(`{ recipe(uuid: "not-a-uuid") { name } }`),
+			status: http.StatusOK},
+		{name: "GraphQL request without a query", method: http.MethodPost, path: "/graphql", body: `{}`, status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+			request := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.body != "" {
+				request.Header.Set("Content-Type", "application/json")
+			}
+			for header, value := range tt.headers {
+				request.Header.Set(header, value)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+			require.Equal(t, tt.status, recorder.Code, recorder.Body.String())
+
+			operation, pointer, found := contract.operation(tt.method, route)
+			if !found && !strings.HasPrefix(route, envelope.Prefix+"/") {
+				// The legacy routes keep their bare bodies, not documented,
+				// and announce their successor.
+				assert.Equal(t, "@1792368000", recorder.Header().Get("Deprecation"))
+				assert.Equal(t, "Mon, 19 Apr 2027 00:00:00 GMT", recorder.Header().Get("Sunset"))
+				assert.Equal(t, "<"+envelope.Prefix+tt.path+`>; rel="successor-version"`, recorder.Header().Get("Link"))
+				assert.NotContains(t, recorder.Body.String(), `"meta"`)
+				return
+			}
+			require.True(t, found, "%s %s is not documented", tt.method, route)
+			if tt.body != "" && tt.status < http.StatusBadRequest {
+				requestBody, _ := operation["requestBody"].(map[string]any)
+				require.NotNil(t, requestBody, "%s documents no request body", pointer)
+				contract.validate(t, requestBody, pointer+"/requestBody", "application/json", []byte(tt.body))
+			}
+
+			responses := operation["responses"].(map[string]any)
+			code := strconv.Itoa(recorder.Code)
+			response, documented := responses[code].(map[string]any)
+			require.True(t, documented, "%s documents no %s response", pointer, code)
+			body, err := io.ReadAll(recorder.Body)
+			require.NoError(t, err)
+			contract.validate(t, response, pointer+"/responses/"+code, recorder.Header().Get("Content-Type"), body)
+
+			for header := range responseHeaders(contract, response, pointer+"/responses/"+code) {
+				if slices.Contains([]string{"ETag", "Location"}, header) {
+					assert.NotEmpty(t, recorder.Header().Get(header), "documented header %s", header)
+				}
+			}
+		})
+	}
+
+	recipeService.AssertExpectations(t)
+	webhookService.AssertExpectations(t)
+}
+
+// responseHeaders returns the headers a response documents.
+func responseHeaders(contract *contract, response map[string]any, pointer string) map[string]any {
+	response, _ = contract.resolve(response, pointer)
+	headers, _ := response["headers"].(map[string]any)
+	return headers
+}
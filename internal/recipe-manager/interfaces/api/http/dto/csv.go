@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"strconv"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+)
+
+var csvHeader = []string{"pan", "component", "ingredient", "amount", "unit"}
+
+// AggregateToCSV writes the ingredient table of an aggregate: one row per
+// dough and topping ingredient of each pan, in grams rounded to one
+// decimal.
+func AggregateToCSV(r domain.RecipeAggregate) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	if err := writer.Write(csvHeader); err != nil {
+		return nil, err
+	}
+
+	write := func(pan, component string, ingredients []domain.Ingredient) error {
+		for _, ingredient := range ingredients {
+			if err := writer.Write([]string{pan, component, ingredient.Name, formatAmount(ingredient.Amount), "g"}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for i, dough := range r.SplitIngredients.SplitDough {
+		pan := dough.Name
+		if i < len(r.Pans.Pans) && r.Pans.Pans[i].Name != "" {
+			pan = r.Pans.Pans[i].Name
+		}
+		if err := write(pan, "dough", dough.Ingredients); err != nil {
+			return nil, err
+		}
+		if i < len(r.Pans.Pans) {
+			if err := write(pan, "topping", r.PanTopping(i)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	writer.Flush()
+	return buffer.Bytes(), writer.Error()
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(math.Round(amount*10)/10, 'f', -1, 64)
+}
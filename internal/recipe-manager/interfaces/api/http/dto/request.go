@@ -1,17 +1,96 @@
 package dto
 
 import (
-	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+	"errors"
+	"fmt"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
 )
 
 type PanRequest struct {
-	Pans []Pan `json:"pans" binding:"required,dive"`
+	Pans []Pan `json:"pans" binding:"required_without_all=Balls Scale,excluded_with=Balls Scale,dive"`
+	// Style, Thickness in millimetres or DoughDensity in grams per cm² set
+	// the dough of the pans, over the one of the recipe; only one of
+	// Thickness and DoughDensity can be given.
+	Style        string  `json:"style,omitempty" binding:"omitempty,oneof=neapolitan roman-teglia detroit sicilian focaccia"`
+	Thickness    float64 `json:"thickness,omitempty" binding:"gte=0,lte=50"`
+	DoughDensity float64 `json:"doughDensity,omitempty" binding:"gte=0,lte=5"`
+	// Balls splits the dough into balls instead of pans.
+	Balls *BallsRequest `json:"balls,omitempty" binding:"excluded_with=Scale"`
+	// Scale scales the recipe to servings or a dough weight instead of pans.
+	Scale *ScaleRequest `json:"scale,omitempty"`
+	// Fermentation adjusts the yeast to the plan and schedules the stages,
+	// when set.
+	Fermentation *FermentationRequest `json:"fermentation,omitempty"`
+}
+
+// BallsRequest asks for count balls of weight grams, or for doughWeight
+// grams of dough divided into count balls or balls of weight, with loss
+// percent of the dough lost while making them.
+type BallsRequest struct {
+	Count       int     `json:"count,omitempty" binding:"gte=0,lte=1000"`
+	Weight      float64 `json:"weight,omitempty" binding:"gte=0"`
+	DoughWeight float64 `json:"doughWeight,omitempty" binding:"gte=0"`
+	Loss        float64 `json:"loss,omitempty" binding:"gte=0,lt=50"`
+}
+
+func (r BallsRequest) ToDomain() domain.Balls {
+	return domain.Balls{Count: r.Count, Weight: r.Weight, DoughWeight: r.DoughWeight, Loss: r.Loss}
+}
+
+// ScaleRequest asks for servings of the recipe, or for doughWeight grams of
+// dough.
+type ScaleRequest struct {
+	Servings    float64 `json:"servings,omitempty" binding:"gte=0,lte=10000"`
+	DoughWeight float64 `json:"doughWeight,omitempty" binding:"gte=0,lte=1000000"`
+}
+
+func (r ScaleRequest) ToDomain() domain.Scale {
+	return domain.Scale{Servings: r.Servings, DoughWeight: r.DoughWeight}
+}
+
+// FermentationRequest is a fermentation plan, in hours and °C.
+type FermentationRequest struct {
+	Yeast           string  `json:"yeast" binding:"required,oneof=fresh instant dry sourdough"`
+	Hours           float64 `json:"hours" binding:"required,gte=1,lte=96"`
+	RoomTemperature float64 `json:"roomTemperature" binding:"required,gte=10,lte=35"`
+	// FridgeHours are the part of Hours spent in the fridge, at
+	// FridgeTemperature, 4 °C unless set.
+	FridgeHours       float64   `json:"fridgeHours,omitempty" binding:"gte=0,ltfield=Hours"`
+	FridgeTemperature *float64  `json:"fridgeTemperature,omitempty" binding:"omitempty,gte=0,lte=12"`
+	BakeAt            time.Time `json:"bakeAt" binding:"required"`
+}
+
+const defaultFridgeTemperature = 4.0
+
+func (r FermentationRequest) ToDomain() domain.FermentationPlan {
+	fridgeTemperature := defaultFridgeTemperature
+	if r.FridgeTemperature != nil {
+		fridgeTemperature = *r.FridgeTemperature
+	}
+	return domain.FermentationPlan{
+		Yeast:             domain.YeastType(r.Yeast),
+		Duration:          hours(r.Hours),
+		RoomTemperature:   r.RoomTemperature,
+		FridgeDuration:    hours(r.FridgeHours),
+		FridgeTemperature: fridgeTemperature,
+		BakeAt:            r.BakeAt,
+	}
+}
+
+func hours(value float64) time.Duration {
+	return time.Duration(value * float64(time.Hour)).Round(time.Minute)
 }
 
 type Pan struct {
 	Shape    string   `json:"shape" binding:"required,oneof=round square rectangular"`
 	Measures Measures `json:"measures" binding:"required"`
+	Slices   int      `json:"slices,omitempty" binding:"omitempty,min=1,max=64"`
 }
 
 type Measures struct {
@@ -27,6 +106,7 @@ func (r PanRequest) ToDomain() domain.Pans {
 		pan := domain.Pan{
 			Shape:    p.Shape,
 			Measures: domain.Measures{},
+			Slices:   p.Slices,
 		}
 
 		switch p.Shape {
@@ -47,6 +127,138 @@ func (r PanRequest) ToDomain() domain.Pans {
 	}
 
 	return domain.Pans{
-		Pans: pans,
+		Pans:         pans,
+		Style:        r.Style,
+		Thickness:    r.Thickness,
+		DoughDensity: r.DoughDensity,
+	}
+}
+
+// ParsePans reads pans written as a query parameter: comma separated
+// shape:measures items, the measures in centimetres being the diameter of a
+// round pan, the edge of a square one and widthxlength of a rectangular one,
+// optionally followed by /slices. For example round:30,rectangular:30x40/8.
+func ParsePans(value string) (domain.Pans, error) {
+	if strings.TrimSpace(value) == "" {
+		return domain.Pans{}, errors.New("at least one pan is required")
+	}
+
+	var pans []domain.Pan
+	for _, item := range strings.Split(value, ",") {
+		pan, err := parsePan(strings.TrimSpace(item))
+		if err != nil {
+			return domain.Pans{}, fmt.Errorf("pan %q: %w", item, err)
+		}
+		pans = append(pans, pan)
+	}
+	return domain.Pans{Pans: pans}, nil
+}
+
+func parsePan(item string) (domain.Pan, error) {
+	shape, measures, found := strings.Cut(item, ":")
+	if !found {
+		return domain.Pan{}, errors.New("expected shape:measures")
+	}
+	pan := domain.Pan{Shape: shape}
+
+	measures, slices, hasSlices := strings.Cut(measures, "/")
+	if hasSlices {
+		count, err := strconv.Atoi(slices)
+		if err != nil || count < 1 || count > 64 {
+			return domain.Pan{}, fmt.Errorf("slices must be between 1 and 64, got %q", slices)
+		}
+		pan.Slices = count
+	}
+
+	var err error
+	switch shape {
+	case "round":
+		pan.Measures.Diameter, err = parseMeasure("diameter", measures)
+	case "square":
+		pan.Measures.Edge, err = parseMeasure("edge", measures)
+	case "rectangular":
+		width, length, found := strings.Cut(measures, "x")
+		if !found {
+			return domain.Pan{}, errors.New("rectangular pans are measured as widthxlength")
+		}
+		if pan.Measures.Width, err = parseMeasure("width", width); err == nil {
+			pan.Measures.Length, err = parseMeasure("length", length)
+		}
+	default:
+		return domain.Pan{}, fmt.Errorf("unknown shape %q, expected round, square or rectangular", shape)
+	}
+	return pan, err
+}
+
+func parseMeasure(name, value string) (*int, error) {
+	measure, err := strconv.Atoi(value)
+	if err != nil || measure <= 0 {
+		return nil, fmt.Errorf("%s must be a positive number of centimetres, got %q", name, value)
+	}
+	return &measure, nil
+}
+
+// RecipeRequest replaces the details and the ingredients of a recipe. Dough
+// amounts default to "%" and topping amounts to "g".
+type RecipeRequest struct {
+	Name        string         `json:"name" binding:"required"`
+	Description string         `json:"description"`
+	Author      string         `json:"author"`
+	Dough       DoughRequest   `json:"dough"`
+	Topping     ToppingRequest `json:"topping"`
+	// Style is the pizza style of the recipe and DoughDensity, in grams per
+	// cm², overrides the one of the style; both set the dough of the pans
+	// the recipe is balanced for, unless the pan request does.
+	Style        string  `json:"style,omitempty" binding:"omitempty,oneof=neapolitan roman-teglia detroit sicilian focaccia"`
+	DoughDensity float64 `json:"doughDensity,omitempty" binding:"gte=0,lte=5"`
+	// ServingWeight is the grams of dough of a serving, for scaling the
+	// recipe to servings.
+	ServingWeight float64 `json:"servingWeight,omitempty" binding:"gte=0,lte=5000"`
+}
+
+type DoughRequest struct {
+	PercentVariation float64             `json:"percentVariation"`
+	Ingredients      []IngredientRequest `json:"ingredients" binding:"dive"`
+}
+
+type ToppingRequest struct {
+	ReferenceArea float64             `json:"referenceArea" binding:"min=0"`
+	Ingredients   []IngredientRequest `json:"ingredients" binding:"dive"`
+}
+
+type IngredientRequest struct {
+	Name   string  `json:"name" binding:"required"`
+	Amount float64 `json:"amount" binding:"min=0"`
+	Unit   string  `json:"unit,omitempty"`
+	Notes  string  `json:"notes,omitempty"`
+}
+
+// ToDomain returns the recipe to store under recipeUuid if it is still at
+// version.
+func (r RecipeRequest) ToDomain(recipeUuid uuid.UUID, version int) domain.Recipe {
+	return domain.Recipe{
+		Uuid:          recipeUuid,
+		Name:          r.Name,
+		Description:   r.Description,
+		Author:        r.Author,
+		Style:         r.Style,
+		DoughDensity:  r.DoughDensity,
+		ServingWeight: r.ServingWeight,
+		Dough:         domain.Dough{PercentVariation: r.Dough.PercentVariation, Ingredients: ingredientsToDomain(r.Dough.Ingredients)},
+		Topping:       domain.Topping{ReferenceArea: r.Topping.ReferenceArea, Ingredients: ingredientsToDomain(r.Topping.Ingredients)},
+		Version:       version,
+	}
+}
+
+func ingredientsToDomain(ingredients []IngredientRequest) []domain.Ingredient {
+	domainIngredients := make([]domain.Ingredient, len(ingredients))
+	for i, ingredient := range ingredients {
+		domainIngredients[i] = domain.Ingredient{
+			Name:   ingredient.Name,
+			Amount: ingredient.Amount,
+			Unit:   ingredient.Unit,
+			Notes:  ingredient.Notes,
+		}
 	}
+	return domainIngredients
 }
@@ -2,6 +2,7 @@ package dto
 
 import (
 	"math"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -11,6 +12,15 @@ import (
 type RecipeAggregateResponse struct {
 	Recipe
 	SplitIngredients SplitIngredients `json:"splitIngredients"`
+	Nutrition        []PanNutrition   `json:"nutrition"`
+	// Rounding is reported only when the split amounts were rounded.
+	Rounding *Rounding `json:"rounding,omitempty"`
+	// Fermentation is reported only when the request had a plan.
+	Fermentation *Fermentation `json:"fermentation,omitempty"`
+	// Balls are reported only when the request asked for balls.
+	Balls *Balls `json:"balls,omitempty"`
+	// Scale is reported only when the request asked for a scale.
+	Scale *Scale `json:"scale,omitempty"`
 }
 
 type Recipe struct {
@@ -21,6 +31,23 @@ type Recipe struct {
 	Dough       DoughResponse `json:"dough"`
 	Topping     Topping       `json:"topping"`
 	Steps       Steps         `json:"steps"`
+	Labels      Labels        `json:"labels"`
+	Version     int           `json:"version"`
+	ArchivedAt  *time.Time    `json:"archivedAt,omitempty"`
+	DeletedAt   *time.Time    `json:"deletedAt,omitempty"`
+	// Style, DoughDensity in grams per cm² and ServingWeight in grams are
+	// reported only when set.
+	Style         string  `json:"style,omitempty"`
+	DoughDensity  float64 `json:"doughDensity,omitempty"`
+	ServingWeight float64 `json:"servingWeight,omitempty"`
+}
+
+type Labels struct {
+	Allergens []string `json:"allergens"`
+	Diets     []string `json:"diets"`
+	// UnlabelledIngredients are missing from the catalogue, so the allergen
+	// declaration is incomplete while any is listed.
+	UnlabelledIngredients []string `json:"unlabelledIngredients,omitempty"`
 }
 
 type SplitIngredients struct {
@@ -44,42 +71,240 @@ type DoughResponse struct {
 }
 
 type Dough struct {
-	Ingredients []Ingredient
+	Ingredients []Ingredient `json:"ingredients"`
 }
 
 type Topping struct {
-	Ingredients []Ingredient
+	Ingredients []Ingredient `json:"ingredients"`
 }
 
 type Ingredient struct {
-	Name   string
-	Amount float64
+	Name   string  `json:"name"`
+	Amount float64 `json:"amount"`
 }
 
 type Steps struct{}
 
+type PanNutrition struct {
+	Pan      string    `json:"pan"`
+	Slices   int       `json:"slices"`
+	Total    Nutrition `json:"total"`
+	PerSlice Nutrition `json:"perSlice"`
+	// UnknownIngredients have no nutrition data and are not counted.
+	UnknownIngredients []string `json:"unknownIngredients,omitempty"`
+}
+
+type Nutrition struct {
+	Kcal          float64 `json:"kcal"`
+	Protein       float64 `json:"protein"`
+	Carbohydrates float64 `json:"carbohydrates"`
+	Fat           float64 `json:"fat"`
+}
+
+// Rounding reports how far rounding moved the split amounts, in grams, from
+// the balanced ones.
+type Rounding struct {
+	Drift       float64         `json:"drift"`
+	Ingredients []RoundingDrift `json:"ingredients"`
+}
+
+type RoundingDrift struct {
+	Component string  `json:"component"`
+	Name      string  `json:"name"`
+	Step      float64 `json:"step"`
+	Exact     float64 `json:"exact"`
+	Rounded   float64 `json:"rounded"`
+	Drift     float64 `json:"drift"`
+	// Adjusted counts the pans rounded away from the nearest step so they
+	// add up to the rounded total.
+	Adjusted int `json:"adjusted"`
+}
+
+// Balls are the dough balls the recipe was split into, weights in grams.
+type Balls struct {
+	Count       int     `json:"count"`
+	Weight      float64 `json:"weight"`
+	DoughWeight float64 `json:"doughWeight"`
+	Loss        float64 `json:"loss"`
+}
+
+// Scale is the servings and dough weight the recipe was scaled to, weights
+// in grams.
+type Scale struct {
+	Servings      float64 `json:"servings"`
+	ServingWeight float64 `json:"servingWeight"`
+	DoughWeight   float64 `json:"doughWeight"`
+}
+
+type Fermentation struct {
+	Yeast string `json:"yeast"`
+	// YeastPercent is the yeast on the flour.
+	YeastPercent float64          `json:"yeastPercent"`
+	Schedule     []ScheduledStage `json:"schedule"`
+}
+
+type ScheduledStage struct {
+	Stage string    `json:"stage"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// Temperature is where the dough rests, in °C; none for the bake.
+	Temperature *float64 `json:"temperature,omitempty"`
+}
+
 func DomainToDTO(r domain.RecipeAggregate) RecipeAggregateResponse {
+	recipe := RecipeToDTO(r.Recipe)
+	recipe.Labels = mapLabelsToDTO(r.Labels)
+
 	return RecipeAggregateResponse{
-		Recipe: Recipe{
-			Uuid:        r.Recipe.Uuid,
-			Name:        r.Recipe.Name,
-			Description: r.Recipe.Description,
-			Author:      r.Recipe.Author,
-			Dough: DoughResponse{
-				Total: calculateTotal(r.Dough),
-				Dough: Dough{
-					Ingredients: mapIngredientsToDTO(r.Recipe.Dough.Ingredients),
-				},
-			},
-			Topping: Topping{
-				Ingredients: mapIngredientsToDTO(r.Recipe.Topping.Ingredients),
-			},
-			Steps: Steps{},
-		},
+		Recipe: recipe,
 		SplitIngredients: SplitIngredients{
 			SplitDough:   mapDoughListToDTO(r.SplitIngredients.SplitDough),
-			SplitTopping: []SplitTopping{},
+			SplitTopping: mapToppingListToDTO(r.SplitIngredients.SplitTopping),
+		},
+		Nutrition:    mapNutritionToDTO(r.Nutrition),
+		Rounding:     mapRoundingToDTO(r.Rounding),
+		Fermentation: mapFermentationToDTO(r.Fermentation),
+		Balls:        mapBallsToDTO(r.Balls),
+		Scale:        mapScaleToDTO(r.Scale),
+	}
+}
+
+func mapBallsToDTO(balls *domain.Balls) *Balls {
+	if balls == nil {
+		return nil
+	}
+	return &Balls{
+		Count:       balls.Count,
+		Weight:      math.Round(balls.Weight*10) / 10,
+		DoughWeight: math.Round(balls.DoughWeight*10) / 10,
+		Loss:        balls.Loss,
+	}
+}
+
+func mapScaleToDTO(scale *domain.Scale) *Scale {
+	if scale == nil {
+		return nil
+	}
+	return &Scale{
+		Servings:      math.Round(scale.Servings*10) / 10,
+		ServingWeight: math.Round(scale.ServingWeight*10) / 10,
+		DoughWeight:   math.Round(scale.DoughWeight*10) / 10,
+	}
+}
+
+func mapFermentationToDTO(fermentation *domain.Fermentation) *Fermentation {
+	if fermentation == nil {
+		return nil
+	}
+	result := &Fermentation{
+		Yeast:        string(fermentation.Yeast),
+		YeastPercent: math.Round(fermentation.YeastPercent*1000) / 1000,
+		Schedule:     make([]ScheduledStage, len(fermentation.Schedule)),
+	}
+	for i, stage := range fermentation.Schedule {
+		result.Schedule[i] = ScheduledStage{Stage: string(stage.Stage), Start: stage.Start, End: stage.End}
+		if stage.Stage != domain.StageBake {
+			temperature := stage.Temperature
+			result.Schedule[i].Temperature = &temperature
+		}
+	}
+	return result
+}
+
+func mapRoundingToDTO(report *domain.RoundingReport) *Rounding {
+	if report == nil {
+		return nil
+	}
+	rounding := &Rounding{
+		Drift:       roundDrift(report.Drift()),
+		Ingredients: make([]RoundingDrift, len(report.Ingredients)),
+	}
+	for i, ingredient := range report.Ingredients {
+		rounding.Ingredients[i] = RoundingDrift{
+			Component: ingredient.Component,
+			Name:      ingredient.Ingredient,
+			Step:      ingredient.Step,
+			Exact:     roundDrift(ingredient.Exact),
+			Rounded:   ingredient.Rounded,
+			Drift:     roundDrift(ingredient.Drift()),
+			Adjusted:  ingredient.Adjusted,
+		}
+	}
+	return rounding
+}
+
+// roundDrift keeps the hundredths of a gram, finer than any rounding step.
+func roundDrift(grams float64) float64 {
+	return math.Round(grams*100) / 100
+}
+
+func mapNutritionToDTO(nutrition []domain.PanNutrition) []PanNutrition {
+	dtoList := make([]PanNutrition, len(nutrition))
+	for i, n := range nutrition {
+		dtoList[i] = PanNutrition{
+			Pan:                n.Pan,
+			Slices:             n.Slices,
+			Total:              roundNutrition(n.Total),
+			PerSlice:           roundNutrition(n.PerSlice),
+			UnknownIngredients: n.Unknown,
+		}
+	}
+	return dtoList
+}
+
+func roundNutrition(n domain.Nutrition) Nutrition {
+	return Nutrition(n.Rounded())
+}
+
+// RecipeToDTO maps a recipe as stored, labelled from the catalogue entries
+// of its ingredients.
+func RecipeToDTO(r domain.Recipe) Recipe {
+	return Recipe{
+		Uuid:        r.Uuid,
+		Name:        r.Name,
+		Description: r.Description,
+		Author:      r.Author,
+		Dough: DoughResponse{
+			Total: calculateTotal(r.Dough),
+			Dough: Dough{
+				Ingredients: mapIngredientsToDTO(r.Dough.Ingredients),
+			},
+		},
+		Topping: Topping{
+			Ingredients: mapIngredientsToDTO(r.Topping.Ingredients),
 		},
+		Steps:         Steps{},
+		Labels:        mapLabelsToDTO(r.Labels()),
+		Version:       r.Version,
+		ArchivedAt:    r.ArchivedAt,
+		DeletedAt:     r.DeletedAt,
+		Style:         r.Style,
+		DoughDensity:  r.DoughDensity,
+		ServingWeight: r.ServingWeight,
+	}
+}
+
+func RecipesToDTO(recipes []domain.Recipe) []Recipe {
+	dtoList := make([]Recipe, len(recipes))
+	for i, recipe := range recipes {
+		dtoList[i] = RecipeToDTO(recipe)
+	}
+	return dtoList
+}
+
+func mapLabelsToDTO(labels domain.Labels) Labels {
+	allergens := make([]string, len(labels.Allergens))
+	for i, allergen := range labels.Allergens {
+		allergens[i] = string(allergen)
+	}
+	diets := make([]string, len(labels.Diets))
+	for i, diet := range labels.Diets {
+		diets[i] = string(diet)
+	}
+	return Labels{
+		Allergens:             allergens,
+		Diets:                 diets,
+		UnlabelledIngredients: labels.Unlabelled,
 	}
 }
 
@@ -100,6 +325,19 @@ func mapDoughListToDTO(doughList []domain.Dough) []SplitDough {
 	return dtoList
 }
 
+func mapToppingListToDTO(toppingList []domain.Topping) []SplitTopping {
+	dtoList := make([]SplitTopping, len(toppingList))
+	for i, t := range toppingList {
+		dtoList[i] = SplitTopping{
+			Topping: Topping{
+				Ingredients: mapIngredientsToDTO(t.Ingredients),
+			},
+			Shape: t.Name,
+		}
+	}
+	return dtoList
+}
+
 func mapIngredientsToDTO(ingredients []domain.Ingredient) []Ingredient {
 	dtoIngredients := make([]Ingredient, len(ingredients))
 	for i, ing := range ingredients {
@@ -112,9 +350,5 @@ func mapIngredientsToDTO(ingredients []domain.Ingredient) []Ingredient {
 }
 
 func calculateTotal(d domain.Dough) float64 {
-	totalWeight := 0.0
-	for _, ingredient := range d.Ingredients {
-		totalWeight += ingredient.Amount
-	}
-	return math.Round(totalWeight*10) / 10
+	return math.Round(d.Total()*10) / 10
 }
@@ -0,0 +1,101 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+)
+
+// SubscriptionRequest subscribes url to the listed event types, or to all of
+// them when events is empty. A secret is generated when none is given.
+type SubscriptionRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret" binding:"omitempty,min=16,max=128"`
+}
+
+func (r SubscriptionRequest) EventTypes() []domain.EventType {
+	eventTypes := make([]domain.EventType, 0, len(r.Events))
+	for _, event := range r.Events {
+		eventTypes = append(eventTypes, domain.EventType(event))
+	}
+	return eventTypes
+}
+
+// Subscription leaves out the secret, which is only shown on creation.
+type Subscription struct {
+	Id        uuid.UUID `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type Delivery struct {
+	Id             uuid.UUID       `json:"id"`
+	SubscriptionId uuid.UUID       `json:"subscriptionId"`
+	EventId        uuid.UUID       `json:"eventId"`
+	EventType      string          `json:"eventType"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	LastError      string          `json:"lastError,omitempty"`
+	ResponseStatus int             `json:"responseStatus,omitempty"`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+}
+
+func SubscriptionToDTO(subscription domain.Subscription) Subscription {
+	events := make([]string, 0, len(subscription.EventTypes))
+	for _, eventType := range subscription.EventTypes {
+		events = append(events, string(eventType))
+	}
+	return Subscription{
+		Id:        subscription.Id,
+		URL:       subscription.URL,
+		Events:    events,
+		CreatedAt: subscription.CreatedAt,
+	}
+}
+
+func SubscriptionsToDTO(subscriptions []domain.Subscription) []Subscription {
+	response := make([]Subscription, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		response = append(response, SubscriptionToDTO(subscription))
+	}
+	return response
+}
+
+func DeliveryToDTO(delivery domain.Delivery) Delivery {
+	response := Delivery{
+		Id:             delivery.Id,
+		SubscriptionId: delivery.SubscriptionId,
+		EventId:        delivery.EventId,
+		EventType:      string(delivery.EventType),
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		LastError:      delivery.LastError,
+		ResponseStatus: delivery.ResponseStatus,
+		CreatedAt:      delivery.CreatedAt,
+		DeliveredAt:    delivery.DeliveredAt,
+		Payload:        json.RawMessage(delivery.Payload),
+	}
+	// Only a pending delivery has a next attempt.
+	if delivery.Status == domain.DeliveryPending {
+		nextAttemptAt := delivery.NextAttemptAt
+		response.NextAttemptAt = &nextAttemptAt
+	}
+	return response
+}
+
+func DeliveriesToDTO(deliveries []domain.Delivery) []Delivery {
+	response := make([]Delivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		response = append(response, DeliveryToDTO(delivery))
+	}
+	return response
+}
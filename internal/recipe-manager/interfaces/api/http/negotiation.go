@@ -0,0 +1,158 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/grpc/client"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/http/envelope"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/http/negotiation"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/interfaces/api/http/dto"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/interfaces/card"
+)
+
+const (
+	mediaTypeJSON     = "application/json"
+	mediaTypeYAML     = "application/yaml"
+	mediaTypeCSV      = "text/csv"
+	mediaTypeProtobuf = "application/x-protobuf"
+	mediaTypeHTML     = "text/html"
+	mediaTypeMarkdown = "text/markdown"
+	mediaTypePDF      = "application/pdf"
+)
+
+// aggregateMediaTypes are the representations of an aggregate, JSON first as
+// the default.
+var aggregateMediaTypes = []string{mediaTypeJSON, mediaTypeYAML, mediaTypeCSV, mediaTypeProtobuf}
+
+// cardMediaTypes are the media types of the card formats, offered in the
+// order of card.Formats.
+var cardMediaTypes = map[string]string{
+	card.FormatHTML:     mediaTypeHTML,
+	card.FormatMarkdown: mediaTypeMarkdown,
+	card.FormatPDF:      mediaTypePDF,
+}
+
+// negotiateMediaType returns the offer the Accept header of the request
+// prefers, answering 406 when it accepts none.
+func negotiateMediaType(ctx *gin.Context, offers ...string) (string, bool) {
+	ctx.Header("Vary", "Accept")
+	mediaType, ok := negotiation.Negotiate(ctx.GetHeader("Accept"), offers...)
+	if !ok {
+		errorResponse(ctx, http.StatusNotAcceptable, fmt.Sprintf("Accept must allow one of %s", strings.Join(offers, ", ")))
+	}
+	return mediaType, ok
+}
+
+// negotiateCardFormat returns the card format of the format query parameter
+// or, without it, the one the Accept header prefers.
+func negotiateCardFormat(ctx *gin.Context) (string, bool) {
+	if format := ctx.Query("format"); format != "" {
+		if _, found := cardMediaTypes[format]; !found {
+			errorResponse(ctx, http.StatusBadRequest, fmt.Sprintf("format must be one of %s", strings.Join(card.Formats, ", ")))
+			return "", false
+		}
+		return format, true
+	}
+
+	offers := make([]string, len(card.Formats))
+	for i, format := range card.Formats {
+		offers[i] = cardMediaTypes[format]
+	}
+	mediaType, ok := negotiateMediaType(ctx, offers...)
+	if !ok {
+		return "", false
+	}
+	for format, offer := range cardMediaTypes {
+		if offer == mediaType {
+			return format, true
+		}
+	}
+	return "", false
+}
+
+// respondCard writes the prep sheet of an aggregate in format. PDFs are
+// named after the recipe UUID for the browsers that save them.
+func respondCard(ctx *gin.Context, format string, aggregate domain.RecipeAggregate) {
+	var body bytes.Buffer
+	if err := card.Render(&body, format, aggregate); err != nil {
+		errorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	mediaType := cardMediaTypes[format]
+	if format == card.FormatPDF {
+		ctx.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s.pdf"`, aggregate.Uuid))
+	} else {
+		mediaType += "; charset=utf-8"
+	}
+	ctx.Data(http.StatusOK, mediaType, body.Bytes())
+}
+
+// respondAggregate writes an aggregate as JSON or YAML, both enveloped, as
+// the CSV table of its ingredients, or as the protobuf RecipeAggregate
+// message of the balancer.
+func respondAggregate(ctx *gin.Context, mediaType string, aggregate domain.RecipeAggregate) {
+	var (
+		body []byte
+		err  error
+	)
+	switch mediaType {
+	case mediaTypeYAML:
+		body, err = toYAML(envelope.Body(ctx, dto.DomainToDTO(aggregate), nil))
+		mediaType += "; charset=utf-8"
+	case mediaTypeCSV:
+		body, err = dto.AggregateToCSV(aggregate)
+		mediaType += "; charset=utf-8; header=present"
+	case mediaTypeProtobuf:
+		body, err = proto.Marshal(client.ToProtoRecipeAggregate(aggregate))
+	default:
+		aggregateResponse := dto.DomainToDTO(aggregate)
+		envelope.Respond(ctx, http.StatusOK, &aggregateResponse)
+		return
+	}
+	if err != nil {
+		errorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+	ctx.Data(http.StatusOK, mediaType, body)
+}
+
+// toYAML writes value as YAML with the keys of its JSON encoding, in the
+// same order.
+func toYAML(value any) ([]byte, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	// JSON is YAML, so the node keeps the order of the keys; only its flow
+	// and quoting styles are reset to the block ones.
+	var node yaml.Node
+	if err := yaml.Unmarshal(encoded, &node); err != nil {
+		return nil, err
+	}
+	resetStyle(&node)
+
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), encoder.Close()
+}
+
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
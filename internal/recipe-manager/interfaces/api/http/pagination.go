@@ -0,0 +1,66 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/http/envelope"
+)
+
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 500
+)
+
+// page is the slice of a collection the limit and offset query parameters
+// select. Only the versioned API paginates; the legacy routes keep returning
+// every item.
+type page struct {
+	limit     int
+	offset    int
+	paginated bool
+}
+
+// parsePage reads the page of the request, answering 400 when its limit or
+// offset is out of range.
+func parsePage(ctx *gin.Context) (page, bool) {
+	if !envelope.Versioned(ctx) {
+		return page{}, true
+	}
+	p := page{limit: defaultPageLimit, paginated: true}
+	if value := ctx.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			errorResponse(ctx, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d, got %q", maxPageLimit, value))
+			return page{}, false
+		}
+		p.limit = limit
+	}
+	if value := ctx.Query("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			errorResponse(ctx, http.StatusBadRequest, fmt.Sprintf("offset must be a non-negative integer, got %q", value))
+			return page{}, false
+		}
+		p.offset = offset
+	}
+	return p, true
+}
+
+// respondPage writes the items of p along with its pagination meta.
+func respondPage[T any](ctx *gin.Context, p page, items []T) {
+	if !p.paginated {
+		envelope.Respond(ctx, http.StatusOK, items)
+		return
+	}
+	start := min(p.offset, len(items))
+	end := min(start+p.limit, len(items))
+	envelope.RespondPage(ctx, http.StatusOK, items[start:end], &envelope.Pagination{
+		Limit:  p.limit,
+		Offset: p.offset,
+		Total:  len(items),
+	})
+}
@@ -2,17 +2,33 @@ package http
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
 	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/http/envelope"
 	"github.com/cfioretti/recipe-manager/internal/recipe-manager/interfaces/api/http/dto"
 )
 
 type RecipeService interface {
 	Handle(context.Context, uuid.UUID, domain.Pans) (*domain.RecipeAggregate, error)
+	Schedule(context.Context, uuid.UUID, domain.Pans, domain.FermentationPlan) (*domain.RecipeAggregate, error)
+	Balls(context.Context, uuid.UUID, domain.Balls, *domain.FermentationPlan) (*domain.RecipeAggregate, error)
+	Scale(context.Context, uuid.UUID, domain.Scale, *domain.FermentationPlan) (*domain.RecipeAggregate, error)
+	Recipe(context.Context, uuid.UUID) (*domain.Recipe, error)
+	RecipesFreeFrom(context.Context, domain.RecipeFilter, []domain.Allergen) ([]domain.Recipe, error)
+	CreateRecipe(context.Context, domain.Recipe) (*domain.Recipe, error)
+	UpdateRecipe(context.Context, domain.Recipe) (*domain.Recipe, error)
+	ArchiveRecipe(ctx context.Context, recipeUuid uuid.UUID, version int, archived bool) (*domain.Recipe, error)
+	DeleteRecipe(ctx context.Context, recipeUuid uuid.UUID, version int) error
+	RestoreRecipe(context.Context, uuid.UUID) (*domain.Recipe, error)
+	DeletedRecipes(context.Context) ([]domain.Recipe, error)
 }
 
 type RecipeHandler struct {
@@ -23,12 +39,38 @@ func NewRecipeHandler(recipeService RecipeService) *RecipeHandler {
 	return &RecipeHandler{recipeService: recipeService}
 }
 
+func (rc *RecipeHandler) RegisterRoutes(router gin.IRouter) {
+	router.GET("/recipes", rc.ListRecipes)
+	router.POST("/recipes", rc.CreateRecipe)
+	router.GET("/recipes/:uuid", rc.RetrieveRecipe)
+	router.PUT("/recipes/:uuid", rc.UpdateRecipe)
+	router.DELETE("/recipes/:uuid", rc.DeleteRecipe)
+	router.POST("/recipes/:uuid/archive", rc.ArchiveRecipe)
+	router.POST("/recipes/:uuid/unarchive", rc.UnarchiveRecipe)
+	router.POST("/recipes/:uuid/aggregate", rc.RetrieveRecipeAggregate)
+	router.GET("/recipes/:uuid/card", rc.RetrieveRecipeCard)
+}
+
+// RegisterAdminRoutes registers the routes over deleted recipes on router,
+// which is expected to require the admin token.
+func (rc *RecipeHandler) RegisterAdminRoutes(router gin.IRouter) {
+	router.GET("/recipes/deleted", rc.ListDeletedRecipes)
+	router.POST("/recipes/:uuid/restore", rc.RestoreRecipe)
+}
+
+// RetrieveRecipeAggregate splits the recipe into the pans or the dough balls
+// of the request, or scales it, and answers in the representation the
+// Accept header prefers among aggregateMediaTypes.
 func (rc *RecipeHandler) RetrieveRecipeAggregate(ctx *gin.Context) {
 	recipeUuid, uuidErr := uuid.Parse(ctx.Param("uuid"))
 	if uuidErr != nil {
 		errorResponse(ctx, http.StatusBadRequest, "invalid UUID")
 		return
 	}
+	mediaType, ok := negotiateMediaType(ctx, aggregateMediaTypes...)
+	if !ok {
+		return
+	}
 
 	var requestBody dto.PanRequest
 	if err := ctx.ShouldBindJSON(&requestBody); err != nil {
@@ -36,22 +78,324 @@ func (rc *RecipeHandler) RetrieveRecipeAggregate(ctx *gin.Context) {
 		return
 	}
 
-	recipe, err := rc.recipeService.Handle(ctx.Request.Context(), recipeUuid, requestBody.ToDomain())
+	var (
+		recipe *domain.RecipeAggregate
+		plan   *domain.FermentationPlan
+		err    error
+	)
+	if requestBody.Fermentation != nil {
+		fermentation := requestBody.Fermentation.ToDomain()
+		plan = &fermentation
+	}
+	switch {
+	case requestBody.Balls != nil:
+		recipe, err = rc.recipeService.Balls(ctx.Request.Context(), recipeUuid, requestBody.Balls.ToDomain(), plan)
+	case requestBody.Scale != nil:
+		recipe, err = rc.recipeService.Scale(ctx.Request.Context(), recipeUuid, requestBody.Scale.ToDomain(), plan)
+	case requestBody.Fermentation != nil:
+		recipe, err = rc.recipeService.Schedule(ctx.Request.Context(), recipeUuid, requestBody.ToDomain(), *plan)
+	default:
+		recipe, err = rc.recipeService.Handle(ctx.Request.Context(), recipeUuid, requestBody.ToDomain())
+	}
 	if err != nil {
 		errorResponse(ctx, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	aggregateResponse := dto.DomainToDTO(*recipe)
-	ctx.JSON(
-		http.StatusOK,
-		gin.H{"data": &aggregateResponse},
-	)
+	respondAggregate(ctx, mediaType, *recipe)
+}
+
+// RetrieveRecipeCard answers with the prep sheet of the recipe balanced for
+// the pans of the pans query parameter, as ParsePans reads them. The format
+// query parameter picks the format, for links to print from; otherwise the
+// Accept header does, among cardMediaTypes.
+func (rc *RecipeHandler) RetrieveRecipeCard(ctx *gin.Context) {
+	recipeUuid, uuidErr := uuid.Parse(ctx.Param("uuid"))
+	if uuidErr != nil {
+		errorResponse(ctx, http.StatusBadRequest, "invalid UUID")
+		return
+	}
+	format, ok := negotiateCardFormat(ctx)
+	if !ok {
+		return
+	}
+	pans, err := dto.ParsePans(ctx.Query("pans"))
+	if err != nil {
+		errorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	aggregate, err := rc.recipeService.Handle(ctx.Request.Context(), recipeUuid, pans)
+	if errors.Is(err, domain.ErrRecipeNotFound) {
+		errorResponse(ctx, http.StatusNotFound, "recipe not found")
+		return
+	}
+	if err != nil {
+		errorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondCard(ctx, format, *aggregate)
+}
+
+// RetrieveRecipe answers with the version of the recipe as its ETag, and
+// with 304 when it matches If-None-Match.
+func (rc *RecipeHandler) RetrieveRecipe(ctx *gin.Context) {
+	recipeUuid, uuidErr := uuid.Parse(ctx.Param("uuid"))
+	if uuidErr != nil {
+		errorResponse(ctx, http.StatusBadRequest, "invalid UUID")
+		return
+	}
+
+	recipe, err := rc.recipeService.Recipe(ctx.Request.Context(), recipeUuid)
+	if errors.Is(err, domain.ErrRecipeNotFound) {
+		errorResponse(ctx, http.StatusNotFound, "recipe not found")
+		return
+	}
+	if err != nil {
+		errorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	tag := entityTag(recipe.Version)
+	ctx.Header("ETag", tag)
+	if noneMatch := ctx.GetHeader("If-None-Match"); noneMatch != "" && matchesAny(noneMatch, tag) {
+		ctx.AbortWithStatus(http.StatusNotModified)
+		return
+	}
+	envelope.Respond(ctx, http.StatusOK, dto.RecipeToDTO(*recipe))
+}
+
+// CreateRecipe stores a new recipe under a new UUID and answers with its
+// location and ETag.
+func (rc *RecipeHandler) CreateRecipe(ctx *gin.Context) {
+	var requestBody dto.RecipeRequest
+	if err := ctx.ShouldBindJSON(&requestBody); err != nil {
+		errorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	recipe, err := rc.recipeService.CreateRecipe(ctx.Request.Context(), requestBody.ToDomain(uuid.New(), 0))
+	if err != nil {
+		errorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.Header("Location", strings.TrimSuffix(ctx.Request.URL.Path, "/")+"/"+recipe.Uuid.String())
+	ctx.Header("ETag", entityTag(recipe.Version))
+	envelope.Respond(ctx, http.StatusCreated, dto.RecipeToDTO(*recipe))
+}
+
+// UpdateRecipe replaces a recipe. The If-Match header must hold the ETag the
+// change is based on, or "*", so concurrent edits cannot overwrite each other.
+func (rc *RecipeHandler) UpdateRecipe(ctx *gin.Context) {
+	recipeUuid, uuidErr := uuid.Parse(ctx.Param("uuid"))
+	if uuidErr != nil {
+		errorResponse(ctx, http.StatusBadRequest, "invalid UUID")
+		return
+	}
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		return
+	}
+
+	var requestBody dto.RecipeRequest
+	if err := ctx.ShouldBindJSON(&requestBody); err != nil {
+		errorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	recipe, err := rc.recipeService.UpdateRecipe(ctx.Request.Context(), requestBody.ToDomain(recipeUuid, version))
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	ctx.Header("ETag", entityTag(recipe.Version))
+	envelope.Respond(ctx, http.StatusOK, dto.RecipeToDTO(*recipe))
+}
+
+// DeleteRecipe deletes a recipe, with the same If-Match precondition as
+// UpdateRecipe. It stays restorable by admins until it is purged.
+func (rc *RecipeHandler) DeleteRecipe(ctx *gin.Context) {
+	recipeUuid, uuidErr := uuid.Parse(ctx.Param("uuid"))
+	if uuidErr != nil {
+		errorResponse(ctx, http.StatusBadRequest, "invalid UUID")
+		return
+	}
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		return
+	}
+
+	if err := rc.recipeService.DeleteRecipe(ctx.Request.Context(), recipeUuid, version); err != nil {
+		writeError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ArchiveRecipe takes a recipe out of the listings, with the same If-Match
+// precondition as UpdateRecipe.
+func (rc *RecipeHandler) ArchiveRecipe(ctx *gin.Context) {
+	rc.archive(ctx, true)
+}
+
+// UnarchiveRecipe brings an archived recipe back to the listings.
+func (rc *RecipeHandler) UnarchiveRecipe(ctx *gin.Context) {
+	rc.archive(ctx, false)
+}
+
+func (rc *RecipeHandler) archive(ctx *gin.Context, archived bool) {
+	recipeUuid, uuidErr := uuid.Parse(ctx.Param("uuid"))
+	if uuidErr != nil {
+		errorResponse(ctx, http.StatusBadRequest, "invalid UUID")
+		return
+	}
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		return
+	}
+
+	recipe, err := rc.recipeService.ArchiveRecipe(ctx.Request.Context(), recipeUuid, version, archived)
+	if err != nil {
+		writeError(ctx, err)
+		return
+	}
+
+	ctx.Header("ETag", entityTag(recipe.Version))
+	envelope.Respond(ctx, http.StatusOK, dto.RecipeToDTO(*recipe))
+}
+
+// ListDeletedRecipes returns the deleted recipes that are not purged yet.
+func (rc *RecipeHandler) ListDeletedRecipes(ctx *gin.Context) {
+	p, ok := parsePage(ctx)
+	if !ok {
+		return
+	}
+
+	recipes, err := rc.recipeService.DeletedRecipes(ctx.Request.Context())
+	if err != nil {
+		errorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondPage(ctx, p, dto.RecipesToDTO(recipes))
+}
+
+// RestoreRecipe brings back a deleted recipe.
+func (rc *RecipeHandler) RestoreRecipe(ctx *gin.Context) {
+	recipeUuid, uuidErr := uuid.Parse(ctx.Param("uuid"))
+	if uuidErr != nil {
+		errorResponse(ctx, http.StatusBadRequest, "invalid UUID")
+		return
+	}
+
+	recipe, err := rc.recipeService.RestoreRecipe(ctx.Request.Context(), recipeUuid)
+	if errors.Is(err, domain.ErrRecipeNotFound) {
+		errorResponse(ctx, http.StatusNotFound, "deleted recipe not found")
+		return
+	}
+	if err != nil {
+		errorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.Header("ETag", entityTag(recipe.Version))
+	envelope.Respond(ctx, http.StatusOK, dto.RecipeToDTO(*recipe))
+}
+
+// ListRecipes returns the recipes free from the comma separated allergens of
+// the freeFrom query parameter, or every recipe without it. Archived recipes
+// are only listed with includeArchived=true.
+func (rc *RecipeHandler) ListRecipes(ctx *gin.Context) {
+	p, ok := parsePage(ctx)
+	if !ok {
+		return
+	}
+
+	var filter domain.RecipeFilter
+	if value := ctx.Query("includeArchived"); value != "" {
+		includeArchived, err := strconv.ParseBool(value)
+		if err != nil {
+			errorResponse(ctx, http.StatusBadRequest, fmt.Sprintf("includeArchived must be true or false, got %q", value))
+			return
+		}
+		filter.IncludeArchived = includeArchived
+	}
+
+	var allergens []domain.Allergen
+	for _, value := range strings.Split(ctx.Query("freeFrom"), ",") {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+		allergen := domain.Allergen(value)
+		if !allergen.Valid() {
+			errorResponse(ctx, http.StatusBadRequest, fmt.Sprintf("unknown allergen %q", value))
+			return
+		}
+		allergens = append(allergens, allergen)
+	}
+
+	recipes, err := rc.recipeService.RecipesFreeFrom(ctx.Request.Context(), filter, allergens)
+	if err != nil {
+		errorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondPage(ctx, p, dto.RecipesToDTO(recipes))
+}
+
+// entityTag is the strong ETag of a recipe version.
+func entityTag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatchVersion returns the version the If-Match header of the request
+// requires, 0 for "*". It answers 428 when the header is missing and 412 when
+// it names no version, as weak tags never match.
+func ifMatchVersion(ctx *gin.Context) (int, bool) {
+	ifMatch := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if ifMatch == "" {
+		errorResponse(ctx, http.StatusPreconditionRequired, "If-Match header is required")
+		return 0, false
+	}
+	if ifMatch == "*" {
+		return 0, true
+	}
+	version, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(ifMatch, `"`), `"`))
+	if err != nil || version < 1 || !strings.HasPrefix(ifMatch, `"`) {
+		errorResponse(ctx, http.StatusPreconditionFailed, "recipe version does not match If-Match")
+		return 0, false
+	}
+	return version, true
+}
+
+// matchesAny reports whether an If-None-Match header lists tag, comparing
+// weakly as RFC 9110 requires for that header.
+func matchesAny(header, tag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeError maps the errors of a conditional change to their status.
+func writeError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrRecipeNotFound):
+		errorResponse(ctx, http.StatusNotFound, "recipe not found")
+	case errors.Is(err, domain.ErrVersionConflict):
+		errorResponse(ctx, http.StatusPreconditionFailed, "recipe version does not match If-Match")
+	default:
+		errorResponse(ctx, http.StatusInternalServerError, err.Error())
+	}
 }
 
 func errorResponse(ctx *gin.Context, statusCode int, errorMsg string) {
-	ctx.AbortWithStatusJSON(
-		statusCode,
-		gin.H{"error": errorMsg},
-	)
+	envelope.Abort(ctx, statusCode, errorMsg)
 }
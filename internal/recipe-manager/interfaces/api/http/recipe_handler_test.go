@@ -3,17 +3,25 @@ package http
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+	pb "github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/grpc/proto/generated"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/http/envelope"
 )
 
 type MockRecipeService struct {
@@ -25,6 +33,76 @@ func (m *MockRecipeService) Handle(ctx context.Context, recipeUuid uuid.UUID, re
 	return args.Get(0).(*domain.RecipeAggregate), args.Error(1)
 }
 
+func (m *MockRecipeService) Schedule(ctx context.Context, recipeUuid uuid.UUID, requestBody domain.Pans, plan domain.FermentationPlan) (*domain.RecipeAggregate, error) {
+	args := m.Called(ctx, recipeUuid, requestBody, plan)
+	return args.Get(0).(*domain.RecipeAggregate), args.Error(1)
+}
+
+func (m *MockRecipeService) Balls(ctx context.Context, recipeUuid uuid.UUID, balls domain.Balls, plan *domain.FermentationPlan) (*domain.RecipeAggregate, error) {
+	args := m.Called(ctx, recipeUuid, balls, plan)
+	return args.Get(0).(*domain.RecipeAggregate), args.Error(1)
+}
+
+func (m *MockRecipeService) Scale(ctx context.Context, recipeUuid uuid.UUID, scale domain.Scale, plan *domain.FermentationPlan) (*domain.RecipeAggregate, error) {
+	args := m.Called(ctx, recipeUuid, scale, plan)
+	return args.Get(0).(*domain.RecipeAggregate), args.Error(1)
+}
+
+func (m *MockRecipeService) Recipe(ctx context.Context, recipeUuid uuid.UUID) (*domain.Recipe, error) {
+	args := m.Called(ctx, recipeUuid)
+	return args.Get(0).(*domain.Recipe), args.Error(1)
+}
+
+func (m *MockRecipeService) RecipesFreeFrom(ctx context.Context, filter domain.RecipeFilter, allergens []domain.Allergen) ([]domain.Recipe, error) {
+	args := m.Called(ctx, filter, allergens)
+	return args.Get(0).([]domain.Recipe), args.Error(1)
+}
+
+func (m *MockRecipeService) CreateRecipe(ctx context.Context, recipe domain.Recipe) (*domain.Recipe, error) {
+	args := m.Called(ctx, recipe)
+	return args.Get(0).(*domain.Recipe), args.Error(1)
+}
+
+func (m *MockRecipeService) UpdateRecipe(ctx context.Context, recipe domain.Recipe) (*domain.Recipe, error) {
+	args := m.Called(ctx, recipe)
+	return args.Get(0).(*domain.Recipe), args.Error(1)
+}
+
+func (m *MockRecipeService) ArchiveRecipe(ctx context.Context, recipeUuid uuid.UUID, version int, archived bool) (*domain.Recipe, error) {
+	args := m.Called(ctx, recipeUuid, version, archived)
+	return args.Get(0).(*domain.Recipe), args.Error(1)
+}
+
+func (m *MockRecipeService) DeleteRecipe(ctx context.Context, recipeUuid uuid.UUID, version int) error {
+	args := m.Called(ctx, recipeUuid, version)
+	return args.Error(0)
+}
+
+func (m *MockRecipeService) RestoreRecipe(ctx context.Context, recipeUuid uuid.UUID) (*domain.Recipe, error) {
+	args := m.Called(ctx, recipeUuid)
+	return args.Get(0).(*domain.Recipe), args.Error(1)
+}
+
+func (m *MockRecipeService) DeletedRecipes(ctx context.Context) ([]domain.Recipe, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.Recipe), args.Error(1)
+}
+
+func (m *MockRecipeService) RecipesByUuids(ctx context.Context, recipeUuids []uuid.UUID) ([]domain.Recipe, error) {
+	args := m.Called(ctx, recipeUuids)
+	return args.Get(0).([]domain.Recipe), args.Error(1)
+}
+
+func (m *MockRecipeService) SearchRecipes(ctx context.Context, filter domain.RecipeFilter, allergens []domain.Allergen, text string) ([]domain.Recipe, error) {
+	args := m.Called(ctx, filter, allergens, text)
+	return args.Get(0).([]domain.Recipe), args.Error(1)
+}
+
+func (m *MockRecipeService) Aggregate(ctx context.Context, recipe domain.Recipe, pans domain.Pans) (*domain.RecipeAggregate, error) {
+	args := m.Called(ctx, recipe, pans)
+	return args.Get(0).(*domain.RecipeAggregate), args.Error(1)
+}
+
 func TestRetrieveRecipeAggregate(t *testing.T) {
 	recipeUuid := uuid.New()
 
@@ -49,6 +127,196 @@ func TestRetrieveRecipeAggregate(t *testing.T) {
 		assert.Equal(t, 200, ctx.Writer.Status())
 	})
 
+	t.Run("reports the rounded topping of each pan", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Params = append(ctx.Params, gin.Param{Key: "uuid", Value: recipeUuid.String()})
+		body := `{"pans": [{"shape": "square","measures": {"edge": "20"}}, {"shape": "rectangular","measures": {"width": "20", "length": "40"}}]}`
+		ctx.Request = httptest.NewRequest(
+			http.MethodPost,
+			"/recipes/"+recipeUuid.String()+"/aggregate",
+			bytes.NewBuffer([]byte(body)),
+		)
+		ctx.Request.Header.Set("Content-Type", "application/json")
+
+		recipeAggregate := domain.RecipeAggregate{
+			Recipe: domain.Recipe{Uuid: recipeUuid, Topping: domain.Topping{
+				Ingredients: []domain.Ingredient{{Name: "tomato", Amount: 100}},
+			}},
+			Pans: domain.Pans{Pans: []domain.Pan{
+				{Shape: "square", Name: "square 20 cm", Area: 400},
+				{Shape: "rectangular", Name: "rectangular 20x40 cm", Area: 800},
+			}, TotalArea: 1200},
+		}
+		report := domain.RoundingPolicy{Default: 5}.Round(&recipeAggregate, recipeAggregate.Recipe)
+		recipeAggregate.Rounding = &report
+		mockRecipeService := new(MockRecipeService)
+		mockRecipeService.On("Handle", mock.Anything, recipeUuid, mock.Anything).Return(&recipeAggregate, nil)
+
+		handler := NewRecipeHandler(mockRecipeService)
+		handler.RetrieveRecipeAggregate(ctx)
+
+		assert.Equal(t, 200, ctx.Writer.Status())
+		assert.Contains(t, recorder.Body.String(), `"splitTopping":[`+
+			`{"shape":"square 20 cm","topping":{"ingredients":[{"name":"tomato","amount":35}]}},`+
+			`{"shape":"rectangular 20x40 cm","topping":{"ingredients":[{"name":"tomato","amount":65}]}}]`)
+	})
+
+	t.Run("schedules the fermentation of the request", func(t *testing.T) {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Params = append(ctx.Params, gin.Param{Key: "uuid", Value: recipeUuid.String()})
+		body := `{"pans": [{"shape": "round","measures": {"diameter": "30"}}],
+			"fermentation": {"yeast": "dry", "hours": 24.5, "roomTemperature": 21, "fridgeHours": 18, "bakeAt": "2026-10-24T19:30:00+02:00"}}`
+		ctx.Request = httptest.NewRequest(
+			http.MethodPost,
+			"/recipes/"+recipeUuid.String()+"/aggregate",
+			bytes.NewBuffer([]byte(body)),
+		)
+		ctx.Request.Header.Set("Content-Type", "application/json")
+
+		bakeAt := time.Date(2026, 10, 24, 19, 30, 0, 0, time.FixedZone("", 2*60*60))
+		plan := domain.FermentationPlan{
+			Yeast: domain.YeastDry, Duration: 24*time.Hour + 30*time.Minute, RoomTemperature: 21,
+			FridgeDuration: 18 * time.Hour, FridgeTemperature: 4, BakeAt: bakeAt,
+		}
+		recipeAggregate := domain.RecipeAggregate{Recipe: domain.Recipe{Uuid: recipeUuid}}
+		mockRecipeService := new(MockRecipeService)
+		mockRecipeService.On("Schedule", mock.Anything, recipeUuid, mock.Anything, mock.MatchedBy(func(actual domain.FermentationPlan) bool {
+			return actual.BakeAt.Equal(bakeAt) && actual.Yeast == plan.Yeast && actual.Duration == plan.Duration &&
+				actual.RoomTemperature == plan.RoomTemperature && actual.FridgeDuration == plan.FridgeDuration &&
+				actual.FridgeTemperature == plan.FridgeTemperature
+		})).Return(&recipeAggregate, nil)
+
+		handler := NewRecipeHandler(mockRecipeService)
+		handler.RetrieveRecipeAggregate(ctx)
+
+		assert.Equal(t, 200, ctx.Writer.Status())
+		mockRecipeService.AssertExpectations(t)
+	})
+
+	t.Run("splits the recipe into the dough balls of the request", func(t *testing.T) {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Params = append(ctx.Params, gin.Param{Key: "uuid", Value: recipeUuid.String()})
+		body := `{"balls": {"doughWeight": 2000, "weight": 280, "loss": 2.5},
+			"fermentation": {"yeast": "fresh", "hours": 8, "roomTemperature": 21, "bakeAt": "2026-10-24T19:30:00Z"}}`
+		ctx.Request = httptest.NewRequest(
+			http.MethodPost,
+			"/recipes/"+recipeUuid.String()+"/aggregate",
+			bytes.NewBuffer([]byte(body)),
+		)
+		ctx.Request.Header.Set("Content-Type", "application/json")
+
+		recipeAggregate := domain.RecipeAggregate{Recipe: domain.Recipe{Uuid: recipeUuid}}
+		mockRecipeService := new(MockRecipeService)
+		mockRecipeService.On("Balls", mock.Anything, recipeUuid, domain.Balls{Weight: 280, DoughWeight: 2000, Loss: 2.5},
+			mock.MatchedBy(func(plan *domain.FermentationPlan) bool {
+				return plan != nil && plan.Yeast == domain.YeastFresh && plan.Duration == 8*time.Hour
+			})).Return(&recipeAggregate, nil)
+
+		handler := NewRecipeHandler(mockRecipeService)
+		handler.RetrieveRecipeAggregate(ctx)
+
+		assert.Equal(t, 200, ctx.Writer.Status())
+		mockRecipeService.AssertExpectations(t)
+	})
+
+	t.Run("passes the style and thickness of the request", func(t *testing.T) {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Params = append(ctx.Params, gin.Param{Key: "uuid", Value: recipeUuid.String()})
+		body := `{"pans": [{"shape": "rectangular","measures": {"width": "30", "length": "40"}}],
+			"style": "detroit", "thickness": 6}`
+		ctx.Request = httptest.NewRequest(
+			http.MethodPost,
+			"/recipes/"+recipeUuid.String()+"/aggregate",
+			bytes.NewBuffer([]byte(body)),
+		)
+		ctx.Request.Header.Set("Content-Type", "application/json")
+
+		recipeAggregate := domain.RecipeAggregate{Recipe: domain.Recipe{Uuid: recipeUuid}}
+		mockRecipeService := new(MockRecipeService)
+		mockRecipeService.On("Handle", mock.Anything, recipeUuid, mock.MatchedBy(func(pans domain.Pans) bool {
+			return pans.Style == domain.StyleDetroit && pans.Thickness == 6 && pans.DoughDensity == 0 && len(pans.Pans) == 1
+		})).Return(&recipeAggregate, nil)
+
+		handler := NewRecipeHandler(mockRecipeService)
+		handler.RetrieveRecipeAggregate(ctx)
+
+		assert.Equal(t, 200, ctx.Writer.Status())
+		mockRecipeService.AssertExpectations(t)
+	})
+
+	t.Run("HTTP Status 400 on an unknown style", func(t *testing.T) {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Params = append(ctx.Params, gin.Param{Key: "uuid", Value: recipeUuid.String()})
+		body := `{"pans": [{"shape": "round","measures": {"diameter": "30"}}], "style": "chicago"}`
+		ctx.Request = httptest.NewRequest(
+			http.MethodPost,
+			"/recipes/"+recipeUuid.String()+"/aggregate",
+			bytes.NewBuffer([]byte(body)),
+		)
+
+		handler := NewRecipeHandler(new(MockRecipeService))
+		handler.RetrieveRecipeAggregate(ctx)
+
+		assert.Equal(t, 400, ctx.Writer.Status())
+	})
+
+	t.Run("scales the recipe to the servings of the request", func(t *testing.T) {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Params = append(ctx.Params, gin.Param{Key: "uuid", Value: recipeUuid.String()})
+		body := `{"scale": {"servings": 40}}`
+		ctx.Request = httptest.NewRequest(
+			http.MethodPost,
+			"/recipes/"+recipeUuid.String()+"/aggregate",
+			bytes.NewBuffer([]byte(body)),
+		)
+		ctx.Request.Header.Set("Content-Type", "application/json")
+
+		recipeAggregate := domain.RecipeAggregate{Recipe: domain.Recipe{Uuid: recipeUuid}}
+		mockRecipeService := new(MockRecipeService)
+		mockRecipeService.On("Scale", mock.Anything, recipeUuid, domain.Scale{Servings: 40}, (*domain.FermentationPlan)(nil)).
+			Return(&recipeAggregate, nil)
+
+		handler := NewRecipeHandler(mockRecipeService)
+		handler.RetrieveRecipeAggregate(ctx)
+
+		assert.Equal(t, 200, ctx.Writer.Status())
+		mockRecipeService.AssertExpectations(t)
+	})
+
+	t.Run("HTTP Status 400 on a scale along with dough balls", func(t *testing.T) {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Params = append(ctx.Params, gin.Param{Key: "uuid", Value: recipeUuid.String()})
+		body := `{"scale": {"doughWeight": 10000}, "balls": {"count": 4}}`
+		ctx.Request = httptest.NewRequest(
+			http.MethodPost,
+			"/recipes/"+recipeUuid.String()+"/aggregate",
+			bytes.NewBuffer([]byte(body)),
+		)
+
+		handler := NewRecipeHandler(new(MockRecipeService))
+		handler.RetrieveRecipeAggregate(ctx)
+
+		assert.Equal(t, 400, ctx.Writer.Status())
+	})
+
+	t.Run("HTTP Status 400 on a fermentation longer in the fridge than in total", func(t *testing.T) {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Params = append(ctx.Params, gin.Param{Key: "uuid", Value: recipeUuid.String()})
+		body := `{"pans": [{"shape": "round","measures": {"diameter": "30"}}],
+			"fermentation": {"yeast": "fresh", "hours": 8, "roomTemperature": 21, "fridgeHours": 12, "bakeAt": "2026-10-24T19:30:00Z"}}`
+		ctx.Request = httptest.NewRequest(
+			http.MethodPost,
+			"/recipes/"+recipeUuid.String()+"/aggregate",
+			bytes.NewBuffer([]byte(body)),
+		)
+
+		handler := NewRecipeHandler(new(MockRecipeService))
+		handler.RetrieveRecipeAggregate(ctx)
+
+		assert.Equal(t, 400, ctx.Writer.Status())
+	})
+
 	t.Run("HTTP Status 400 on validation error", func(t *testing.T) {
 		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
 		ctx.Params = append(ctx.Params, gin.Param{Key: "uuid", Value: recipeUuid.String()})
@@ -100,3 +368,642 @@ func TestRetrieveRecipeAggregate(t *testing.T) {
 		handler.RetrieveRecipeAggregate(ctx)
 	})
 }
+
+func TestAggregateRepresentations(t *testing.T) {
+	recipeUuid := uuid.MustParse("0e5f3c2a-8d41-4b7e-9a6c-1f2d3e4a5b6c")
+	diameter := 30
+	aggregate := domain.RecipeAggregate{
+		Recipe: domain.Recipe{Uuid: recipeUuid, Name: "Margherita", Topping: domain.Topping{
+			Ingredients: []domain.Ingredient{{Name: "tomato", Amount: 100}},
+		}},
+		SplitIngredients: domain.SplitIngredients{SplitDough: []domain.Dough{
+			{Name: "round", Ingredients: []domain.Ingredient{{Name: "flour", Amount: 240.04999}, {Name: "water", Amount: 160}}},
+		}},
+		Pans: domain.Pans{Pans: []domain.Pan{{Shape: "round", Name: "round 30 cm", Area: 706.5, Measures: domain.Measures{Diameter: &diameter}}}, TotalArea: 706.5},
+	}
+
+	tests := []struct {
+		name        string
+		accept      string
+		status      int
+		contentType string
+		check       func(t *testing.T, body []byte)
+	}{
+		{
+			name:        "JSON by default",
+			status:      http.StatusOK,
+			contentType: "application/json; charset=utf-8",
+			check: func(t *testing.T, body []byte) {
+				assert.Contains(t, string(body), `"data":{"uuid":"`+recipeUuid.String()+`"`)
+			},
+		},
+		{
+			name:        "YAML with the keys of the JSON body",
+			accept:      "application/yaml",
+			status:      http.StatusOK,
+			contentType: "application/yaml; charset=utf-8",
+			check: func(t *testing.T, body []byte) {
+				assert.True(t, strings.HasPrefix(string(body), "data:\n  uuid: "+recipeUuid.String()+"\n  name: Margherita\n"), string(body))
+				assert.Contains(t, string(body), "    splitDough:\n      - shape: round\n")
+				assert.Contains(t, string(body), "meta:\n  durationMs: ")
+			},
+		},
+		{
+			name:        "CSV ingredient table per pan",
+			accept:      "text/csv, application/json;q=0.5",
+			status:      http.StatusOK,
+			contentType: "text/csv; charset=utf-8; header=present",
+			check: func(t *testing.T, body []byte) {
+				assert.Equal(t, "pan,component,ingredient,amount,unit\n"+
+					"round 30 cm,dough,flour,240,g\n"+
+					"round 30 cm,dough,water,160,g\n"+
+					"round 30 cm,topping,tomato,100,g\n", string(body))
+			},
+		},
+		{
+			name:        "protobuf RecipeAggregate message",
+			accept:      "application/x-protobuf",
+			status:      http.StatusOK,
+			contentType: "application/x-protobuf",
+			check: func(t *testing.T, body []byte) {
+				var message pb.RecipeAggregate
+				require.NoError(t, proto.Unmarshal(body, &message))
+				assert.Equal(t, recipeUuid.String(), message.GetRecipe().GetUuid())
+				assert.Equal(t, 240.04999, message.GetSplitIngredients().GetSplitDough()[0].GetIngredients()[0].GetAmount())
+			},
+		},
+		{
+			name:        "406 for representations not offered",
+			accept:      "application/xml",
+			status:      http.StatusNotAcceptable,
+			contentType: "application/json; charset=utf-8",
+			check: func(t *testing.T, body []byte) {
+				assert.Contains(t, string(body), `"message":"Accept must allow one of application/json, application/yaml, text/csv, application/x-protobuf"`)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRecipeService := new(MockRecipeService)
+			mockRecipeService.On("Handle", mock.Anything, recipeUuid, mock.Anything).Return(&aggregate, nil).Maybe()
+			router := gin.New()
+			NewRecipeHandler(mockRecipeService).RegisterRoutes(router.Group(envelope.Prefix))
+
+			request := httptest.NewRequest(http.MethodPost, envelope.Prefix+"/recipes/"+recipeUuid.String()+"/aggregate",
+				strings.NewReader(`{"pans": [{"shape": "round", "measures": {"diameter": "30"}}]}`))
+			request.Header.Set("Content-Type", "application/json")
+			if tt.accept != "" {
+				request.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, request)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, "Accept", w.Header().Get("Vary"))
+			tt.check(t, w.Body.Bytes())
+		})
+	}
+}
+
+func TestRetrieveRecipeCard(t *testing.T) {
+	recipeUuid := uuid.MustParse("0e5f3c2a-8d41-4b7e-9a6c-1f2d3e4a5b6c")
+	diameter, width, length := 30, 30, 40
+	pans := domain.Pans{Pans: []domain.Pan{
+		{Shape: "round", Measures: domain.Measures{Diameter: &diameter}},
+		{Shape: "rectangular", Measures: domain.Measures{Width: &width, Length: &length}, Slices: 8},
+	}}
+	aggregate := domain.RecipeAggregate{
+		Recipe: domain.Recipe{Uuid: recipeUuid, Name: "Margherita", Steps: domain.Steps{Steps: []domain.Step{
+			{StepNumber: 1, Description: "Knead the dough"},
+		}}},
+		SplitIngredients: domain.SplitIngredients{SplitDough: []domain.Dough{
+			{Name: "round", Ingredients: []domain.Ingredient{{Name: "flour", Amount: 240.04999}}},
+			{Name: "rectangular", Ingredients: []domain.Ingredient{{Name: "flour", Amount: 400}}},
+		}},
+		Pans: pans,
+	}
+
+	tests := []struct {
+		name        string
+		query       string
+		accept      string
+		status      int
+		contentType string
+		body        string
+	}{
+		{
+			name:        "HTML by default",
+			query:       "?pans=round:30,rectangular:30x40/8",
+			status:      http.StatusOK,
+			contentType: "text/html; charset=utf-8",
+			body:        "<h2>Pan 2: rectangular, 30 × 40 cm, 8 slices</h2>",
+		},
+		{
+			name:        "Markdown by Accept",
+			query:       "?pans=round:30,rectangular:30x40/8",
+			accept:      "text/markdown",
+			status:      http.StatusOK,
+			contentType: "text/markdown; charset=utf-8",
+			body:        "| flour | 240 g |\n",
+		},
+		{
+			name:        "PDF by the format parameter",
+			query:       "?pans=round:30,rectangular:30x40/8&format=pdf",
+			accept:      "text/html",
+			status:      http.StatusOK,
+			contentType: "application/pdf",
+			body:        "(1.) Tj",
+		},
+		{
+			name:        "400 for an unknown format",
+			query:       "?pans=round:30&format=docx",
+			status:      http.StatusBadRequest,
+			contentType: "application/json; charset=utf-8",
+			body:        `"message":"format must be one of html, markdown, pdf"`,
+		},
+		{
+			name:        "400 without pans",
+			status:      http.StatusBadRequest,
+			contentType: "application/json; charset=utf-8",
+			body:        `"message":"at least one pan is required"`,
+		},
+		{
+			name:        "400 for invalid measures",
+			query:       "?pans=round:30,rectangular:30",
+			status:      http.StatusBadRequest,
+			contentType: "application/json; charset=utf-8",
+			body:        `"message":"pan \"rectangular:30\": rectangular pans are measured as widthxlength"`,
+		},
+		{
+			name:        "406 for formats not offered",
+			query:       "?pans=round:30",
+			accept:      "application/json",
+			status:      http.StatusNotAcceptable,
+			contentType: "application/json; charset=utf-8",
+			body:        `"message":"Accept must allow one of text/html, text/markdown, application/pdf"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRecipeService := new(MockRecipeService)
+			mockRecipeService.On("Handle", mock.Anything, recipeUuid, pans).Return(&aggregate, nil).Maybe()
+			router := gin.New()
+			NewRecipeHandler(mockRecipeService).RegisterRoutes(router.Group(envelope.Prefix))
+
+			request := httptest.NewRequest(http.MethodGet, envelope.Prefix+"/recipes/"+recipeUuid.String()+"/card"+tt.query, nil)
+			if tt.accept != "" {
+				request.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, request)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"))
+			assert.Contains(t, w.Body.String(), tt.body)
+			mockRecipeService.AssertExpectations(t)
+		})
+	}
+
+	t.Run("404 for unknown recipes", func(t *testing.T) {
+		mockRecipeService := new(MockRecipeService)
+		mockRecipeService.On("Handle", mock.Anything, recipeUuid, mock.Anything).
+			Return((*domain.RecipeAggregate)(nil), fmt.Errorf("recipe %s: %w", recipeUuid, domain.ErrRecipeNotFound))
+		router := gin.New()
+		NewRecipeHandler(mockRecipeService).RegisterRoutes(router)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/recipes/"+recipeUuid.String()+"/card?pans=square:25", nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestRetrieveRecipe(t *testing.T) {
+	recipeUuid := uuid.New()
+	flour := &domain.CatalogueIngredient{
+		Name:      "flour",
+		Category:  "flour",
+		Allergens: []domain.Allergen{domain.AllergenGluten},
+		Diets:     []domain.Diet{domain.DietVegetarian, domain.DietVegan},
+	}
+	water := &domain.CatalogueIngredient{Name: "water", Category: "water", Diets: []domain.Diet{domain.DietVegetarian, domain.DietVegan}}
+
+	tests := []struct {
+		name           string
+		uuid           string
+		recipe         *domain.Recipe
+		err            error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "labelled recipe",
+			uuid: recipeUuid.String(),
+			recipe: &domain.Recipe{Uuid: recipeUuid, Name: "Focaccia", Dough: domain.Dough{Ingredients: []domain.Ingredient{
+				{Name: "flour", Amount: 60, Catalogue: flour},
+				{Name: "water", Amount: 40, Catalogue: water},
+			}}},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"labels":{"allergens":["gluten"],"diets":["vegetarian","vegan"]}`,
+		},
+		{
+			name:           "not found",
+			uuid:           recipeUuid.String(),
+			recipe:         (*domain.Recipe)(nil),
+			err:            fmt.Errorf("recipe %s: %w", recipeUuid, domain.ErrRecipeNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"recipe not found"}`,
+		},
+		{
+			name:           "invalid UUID",
+			uuid:           "not-a-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid UUID"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Params = append(ctx.Params, gin.Param{Key: "uuid", Value: tt.uuid})
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/recipes/"+tt.uuid, nil)
+
+			mockRecipeService := new(MockRecipeService)
+			if tt.recipe != nil || tt.err != nil {
+				mockRecipeService.On("Recipe", mock.Anything, recipeUuid).Return(tt.recipe, tt.err)
+			}
+
+			NewRecipeHandler(mockRecipeService).RetrieveRecipe(ctx)
+
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestListRecipes(t *testing.T) {
+	t.Run("filters by allergens", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/recipes?freeFrom=Gluten,%20milk", nil)
+
+		mockRecipeService := new(MockRecipeService)
+		mockRecipeService.On("RecipesFreeFrom", mock.Anything, domain.RecipeFilter{}, []domain.Allergen{domain.AllergenGluten, domain.AllergenMilk}).
+			Return([]domain.Recipe{{Name: "Salad"}}, nil)
+
+		NewRecipeHandler(mockRecipeService).ListRecipes(ctx)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), `"name":"Salad"`)
+		mockRecipeService.AssertExpectations(t)
+	})
+
+	t.Run("rejects unknown allergens", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/recipes?freeFrom=wheat", nil)
+
+		NewRecipeHandler(new(MockRecipeService)).ListRecipes(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.JSONEq(t, `{"error":"unknown allergen \"wheat\""}`, recorder.Body.String())
+	})
+
+	t.Run("includes archived recipes on request", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/recipes?includeArchived=true", nil)
+
+		mockRecipeService := new(MockRecipeService)
+		mockRecipeService.On("RecipesFreeFrom", mock.Anything, domain.RecipeFilter{IncludeArchived: true}, []domain.Allergen(nil)).
+			Return([]domain.Recipe{{Name: "Pumpkin", ArchivedAt: &time.Time{}}}, nil)
+
+		NewRecipeHandler(mockRecipeService).ListRecipes(ctx)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), `"archivedAt":"0001-01-01T00:00:00Z"`)
+		mockRecipeService.AssertExpectations(t)
+	})
+
+	t.Run("rejects an invalid includeArchived", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/recipes?includeArchived=maybe", nil)
+
+		NewRecipeHandler(new(MockRecipeService)).ListRecipes(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("paginates under /api/v1", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/api/v1/recipes?limit=2&offset=1", nil)
+
+		mockRecipeService := new(MockRecipeService)
+		mockRecipeService.On("RecipesFreeFrom", mock.Anything, domain.RecipeFilter{}, []domain.Allergen(nil)).
+			Return([]domain.Recipe{{Name: "Margherita"}, {Name: "Marinara"}, {Name: "Diavola"}, {Name: "Napoli"}}, nil)
+
+		NewRecipeHandler(mockRecipeService).ListRecipes(ctx)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		var body struct {
+			Data []struct{ Name string }
+			Meta struct{ Pagination map[string]int }
+		}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		assert.Len(t, body.Data, 2)
+		assert.Equal(t, "Marinara", body.Data[0].Name)
+		assert.Equal(t, map[string]int{"limit": 2, "offset": 1, "total": 4}, body.Meta.Pagination)
+	})
+
+	t.Run("rejects a limit out of range under /api/v1", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/api/v1/recipes?limit=501", nil)
+
+		NewRecipeHandler(new(MockRecipeService)).ListRecipes(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), `"code":"badRequest"`)
+	})
+}
+
+func TestConditionalRequests(t *testing.T) {
+	recipeUuid := uuid.New()
+	stored := &domain.Recipe{Uuid: recipeUuid, Name: "Margherita", Version: 3}
+	body := `{"name":"Margherita DOC","topping":{"referenceArea":1200,"ingredients":[{"name":"mozzarella","amount":250}]}}`
+	update := domain.Recipe{
+		Uuid:    recipeUuid,
+		Name:    "Margherita DOC",
+		Dough:   domain.Dough{Ingredients: []domain.Ingredient{}},
+		Topping: domain.Topping{ReferenceArea: 1200, Ingredients: []domain.Ingredient{{Name: "mozzarella", Amount: 250}}},
+		Version: 3,
+	}
+	conflict := fmt.Errorf("recipe %s is at version 4, not 3: %w", recipeUuid, domain.ErrVersionConflict)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		headers        map[string]string
+		body           string
+		setup          func(*MockRecipeService)
+		expectedStatus int
+		expectedETag   string
+		expectedBody   string
+	}{
+		{
+			name:           "read sets the ETag",
+			method:         http.MethodGet,
+			setup:          func(m *MockRecipeService) { m.On("Recipe", mock.Anything, recipeUuid).Return(stored, nil) },
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"3"`,
+			expectedBody:   `"version":3`,
+		},
+		{
+			name:           "read of an unchanged recipe",
+			method:         http.MethodGet,
+			headers:        map[string]string{"If-None-Match": `"2", W/"3"`},
+			setup:          func(m *MockRecipeService) { m.On("Recipe", mock.Anything, recipeUuid).Return(stored, nil) },
+			expectedStatus: http.StatusNotModified,
+			expectedETag:   `"3"`,
+		},
+		{
+			name:           "read of a changed recipe",
+			method:         http.MethodGet,
+			headers:        map[string]string{"If-None-Match": `"2"`},
+			setup:          func(m *MockRecipeService) { m.On("Recipe", mock.Anything, recipeUuid).Return(stored, nil) },
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"3"`,
+		},
+		{
+			name:    "update at the current version",
+			method:  http.MethodPut,
+			headers: map[string]string{"If-Match": `"3"`},
+			body:    body,
+			setup: func(m *MockRecipeService) {
+				m.On("UpdateRecipe", mock.Anything, update).Return(&domain.Recipe{Uuid: recipeUuid, Name: "Margherita DOC", Version: 4}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"4"`,
+			expectedBody:   `"version":4`,
+		},
+		{
+			name:    "update at a stale version",
+			method:  http.MethodPut,
+			headers: map[string]string{"If-Match": `"3"`},
+			body:    body,
+			setup: func(m *MockRecipeService) {
+				m.On("UpdateRecipe", mock.Anything, update).Return((*domain.Recipe)(nil), conflict)
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedBody:   `{"error":"recipe version does not match If-Match"}`,
+		},
+		{
+			name:           "update without If-Match",
+			method:         http.MethodPut,
+			body:           body,
+			expectedStatus: http.StatusPreconditionRequired,
+			expectedBody:   `{"error":"If-Match header is required"}`,
+		},
+		{
+			name:           "update with a weak ETag",
+			method:         http.MethodPut,
+			headers:        map[string]string{"If-Match": `W/"3"`},
+			body:           body,
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:           "update without a name",
+			method:         http.MethodPut,
+			headers:        map[string]string{"If-Match": `"3"`},
+			body:           `{"description":"no name"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "delete of any version",
+			method:         http.MethodDelete,
+			headers:        map[string]string{"If-Match": "*"},
+			setup:          func(m *MockRecipeService) { m.On("DeleteRecipe", mock.Anything, recipeUuid, 0).Return(nil) },
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "delete at a stale version",
+			method:         http.MethodDelete,
+			headers:        map[string]string{"If-Match": `"3"`},
+			setup:          func(m *MockRecipeService) { m.On("DeleteRecipe", mock.Anything, recipeUuid, 3).Return(conflict) },
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:    "archive at the current version",
+			method:  http.MethodPost,
+			path:    "/archive",
+			headers: map[string]string{"If-Match": `"3"`},
+			setup: func(m *MockRecipeService) {
+				m.On("ArchiveRecipe", mock.Anything, recipeUuid, 3, true).Return(&domain.Recipe{Uuid: recipeUuid, Version: 4}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"4"`,
+		},
+		{
+			name:    "unarchive at a stale version",
+			method:  http.MethodPost,
+			path:    "/unarchive",
+			headers: map[string]string{"If-Match": `"3"`},
+			setup: func(m *MockRecipeService) {
+				m.On("ArchiveRecipe", mock.Anything, recipeUuid, 3, false).Return((*domain.Recipe)(nil), conflict)
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:           "archive without If-Match",
+			method:         http.MethodPost,
+			path:           "/archive",
+			expectedStatus: http.StatusPreconditionRequired,
+		},
+		{
+			name:    "delete of a missing recipe",
+			method:  http.MethodDelete,
+			headers: map[string]string{"If-Match": `"3"`},
+			setup: func(m *MockRecipeService) {
+				m.On("DeleteRecipe", mock.Anything, recipeUuid, 3).Return(fmt.Errorf("recipe %s: %w", recipeUuid, domain.ErrRecipeNotFound))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"recipe not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRecipeService := new(MockRecipeService)
+			if tt.setup != nil {
+				tt.setup(mockRecipeService)
+			}
+			router := gin.New()
+			NewRecipeHandler(mockRecipeService).RegisterRoutes(router)
+
+			request := httptest.NewRequest(tt.method, "/recipes/"+recipeUuid.String()+tt.path, bytes.NewBufferString(tt.body))
+			request.Header.Set("Content-Type", "application/json")
+			for key, value := range tt.headers {
+				request.Header.Set(key, value)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedETag, recorder.Header().Get("ETag"))
+			assert.Contains(t, recorder.Body.String(), tt.expectedBody)
+			mockRecipeService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateRecipe(t *testing.T) {
+	body := `{"name":"Marinara","style":"roman-teglia","doughDensity":0.5,"dough":{"ingredients":[{"name":"flour","amount":60}]}}`
+
+	t.Run("HTTP Status 201 with the location of the new recipe", func(t *testing.T) {
+		var created domain.Recipe
+		mockRecipeService := new(MockRecipeService)
+		mockRecipeService.On("CreateRecipe", mock.Anything, mock.MatchedBy(func(recipe domain.Recipe) bool {
+			return recipe.Name == "Marinara" && recipe.Uuid != uuid.Nil && recipe.Version == 0 &&
+				recipe.Style == domain.StyleRomanTeglia && recipe.DoughDensity == 0.5
+		})).Run(func(args mock.Arguments) {
+			created = args.Get(1).(domain.Recipe)
+			created.Version = 1
+		}).Return(&created, nil)
+		router := gin.New()
+		NewRecipeHandler(mockRecipeService).RegisterRoutes(router)
+
+		request := httptest.NewRequest(http.MethodPost, "/recipes", bytes.NewBufferString(body))
+		request.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		assert.Equal(t, "/recipes/"+created.Uuid.String(), recorder.Header().Get("Location"))
+		assert.Equal(t, `"1"`, recorder.Header().Get("ETag"))
+		assert.Contains(t, recorder.Body.String(), `"name":"Marinara"`)
+		assert.Contains(t, recorder.Body.String(), `"style":"roman-teglia","doughDensity":0.5`)
+		mockRecipeService.AssertExpectations(t)
+	})
+
+	t.Run("HTTP Status 400 without a name", func(t *testing.T) {
+		mockRecipeService := new(MockRecipeService)
+		router := gin.New()
+		NewRecipeHandler(mockRecipeService).RegisterRoutes(router)
+
+		request := httptest.NewRequest(http.MethodPost, "/recipes", bytes.NewBufferString(`{"description":"no name"}`))
+		request.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		mockRecipeService.AssertNotCalled(t, "CreateRecipe", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAdminRoutes(t *testing.T) {
+	recipeUuid := uuid.New()
+	deletedAt := time.Date(2026, time.October, 1, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		setup          func(*MockRecipeService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "lists the deleted recipes",
+			method: http.MethodGet,
+			path:   "/admin/recipes/deleted",
+			setup: func(m *MockRecipeService) {
+				m.On("DeletedRecipes", mock.Anything).Return([]domain.Recipe{{Uuid: recipeUuid, Name: "Pumpkin", DeletedAt: &deletedAt}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"deletedAt":"2026-10-01T09:30:00Z"`,
+		},
+		{
+			name:   "restores a deleted recipe",
+			method: http.MethodPost,
+			path:   "/admin/recipes/" + recipeUuid.String() + "/restore",
+			setup: func(m *MockRecipeService) {
+				m.On("RestoreRecipe", mock.Anything, recipeUuid).Return(&domain.Recipe{Uuid: recipeUuid, Name: "Pumpkin", Version: 5}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"version":5`,
+		},
+		{
+			name:   "restores only deleted recipes",
+			method: http.MethodPost,
+			path:   "/admin/recipes/" + recipeUuid.String() + "/restore",
+			setup: func(m *MockRecipeService) {
+				m.On("RestoreRecipe", mock.Anything, recipeUuid).Return((*domain.Recipe)(nil), domain.ErrRecipeNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"deleted recipe not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRecipeService := new(MockRecipeService)
+			tt.setup(mockRecipeService)
+			router := gin.New()
+			NewRecipeHandler(mockRecipeService).RegisterAdminRoutes(router.Group("/admin"))
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.path, nil))
+
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tt.expectedBody)
+			mockRecipeService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/http/envelope"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/interfaces/api/http/dto"
+)
+
+const (
+	defaultDeliveriesLimit = 100
+	maxDeliveriesLimit     = 500
+)
+
+type WebhookService interface {
+	Subscribe(ctx context.Context, url string, eventTypes []domain.EventType, secret string) (*domain.Subscription, error)
+	Subscriptions(context.Context) ([]domain.Subscription, error)
+	Subscription(context.Context, uuid.UUID) (*domain.Subscription, error)
+	Unsubscribe(context.Context, uuid.UUID) error
+	Deliveries(context.Context, domain.DeliveryFilter) ([]domain.Delivery, error)
+	ReplayDelivery(context.Context, uuid.UUID) (*domain.Delivery, error)
+}
+
+type WebhookHandler struct {
+	webhookService WebhookService
+}
+
+func NewWebhookHandler(webhookService WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+// RegisterAdminRoutes registers the subscription management routes on
+// router, which is expected to require the admin token.
+func (wh *WebhookHandler) RegisterAdminRoutes(router gin.IRouter) {
+	router.POST("/webhooks/subscriptions", wh.Subscribe)
+	router.GET("/webhooks/subscriptions", wh.ListSubscriptions)
+	router.GET("/webhooks/subscriptions/:id", wh.RetrieveSubscription)
+	router.DELETE("/webhooks/subscriptions/:id", wh.Unsubscribe)
+	router.GET("/webhooks/subscriptions/:id/deliveries", wh.ListSubscriptionDeliveries)
+	router.GET("/webhooks/deliveries", wh.ListDeliveries)
+	router.POST("/webhooks/deliveries/:id/replay", wh.ReplayDelivery)
+}
+
+// Subscribe answers with the new subscription, the only response holding
+// its secret.
+func (wh *WebhookHandler) Subscribe(ctx *gin.Context) {
+	var requestBody dto.SubscriptionRequest
+	if err := ctx.ShouldBindJSON(&requestBody); err != nil {
+		errorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	subscription, err := wh.webhookService.Subscribe(ctx.Request.Context(), requestBody.URL, requestBody.EventTypes(), requestBody.Secret)
+	if errors.Is(err, domain.ErrInvalidSubscription) {
+		errorResponse(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		errorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := dto.SubscriptionToDTO(*subscription)
+	response.Secret = subscription.Secret
+	ctx.Header("Location", ctx.Request.URL.Path+"/"+subscription.Id.String())
+	envelope.Respond(ctx, http.StatusCreated, response)
+}
+
+func (wh *WebhookHandler) ListSubscriptions(ctx *gin.Context) {
+	p, ok := parsePage(ctx)
+	if !ok {
+		return
+	}
+
+	subscriptions, err := wh.webhookService.Subscriptions(ctx.Request.Context())
+	if err != nil {
+		errorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondPage(ctx, p, dto.SubscriptionsToDTO(subscriptions))
+}
+
+func (wh *WebhookHandler) RetrieveSubscription(ctx *gin.Context) {
+	id, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	subscription, err := wh.webhookService.Subscription(ctx.Request.Context(), id)
+	if err != nil {
+		writeWebhookError(ctx, err)
+		return
+	}
+
+	envelope.Respond(ctx, http.StatusOK, dto.SubscriptionToDTO(*subscription))
+}
+
+// Unsubscribe removes a subscription and its delivery history.
+func (wh *WebhookHandler) Unsubscribe(ctx *gin.Context) {
+	id, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	if err := wh.webhookService.Unsubscribe(ctx.Request.Context(), id); err != nil {
+		writeWebhookError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListSubscriptionDeliveries returns the delivery history of a
+// subscription, with the same filters as ListDeliveries.
+func (wh *WebhookHandler) ListSubscriptionDeliveries(ctx *gin.Context) {
+	id, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+	if _, err := wh.webhookService.Subscription(ctx.Request.Context(), id); err != nil {
+		writeWebhookError(ctx, err)
+		return
+	}
+	wh.listDeliveries(ctx, id)
+}
+
+// ListDeliveries returns the deliveries of every subscription, newest first.
+// status=dead lists the dead-letter list; limit defaults to 100.
+func (wh *WebhookHandler) ListDeliveries(ctx *gin.Context) {
+	wh.listDeliveries(ctx, uuid.Nil)
+}
+
+func (wh *WebhookHandler) listDeliveries(ctx *gin.Context, subscriptionId uuid.UUID) {
+	filter := domain.DeliveryFilter{SubscriptionId: subscriptionId, Limit: defaultDeliveriesLimit}
+	switch status := domain.DeliveryStatus(ctx.Query("status")); status {
+	case "", domain.DeliveryPending, domain.DeliveryDelivered, domain.DeliveryDead:
+		filter.Status = status
+	default:
+		errorResponse(ctx, http.StatusBadRequest, fmt.Sprintf("status must be pending, delivered or dead, got %q", status))
+		return
+	}
+	if value := ctx.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxDeliveriesLimit {
+			errorResponse(ctx, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d, got %q", maxDeliveriesLimit, value))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	deliveries, err := wh.webhookService.Deliveries(ctx.Request.Context(), filter)
+	if err != nil {
+		errorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	envelope.Respond(ctx, http.StatusOK, dto.DeliveriesToDTO(deliveries))
+}
+
+// ReplayDelivery schedules a delivery again right away, such as one from the
+// dead-letter list once the receiver is fixed.
+func (wh *WebhookHandler) ReplayDelivery(ctx *gin.Context) {
+	id, ok := pathId(ctx)
+	if !ok {
+		return
+	}
+
+	delivery, err := wh.webhookService.ReplayDelivery(ctx.Request.Context(), id)
+	if err != nil {
+		writeWebhookError(ctx, err)
+		return
+	}
+
+	envelope.Respond(ctx, http.StatusAccepted, dto.DeliveryToDTO(*delivery))
+}
+
+func pathId(ctx *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		errorResponse(ctx, http.StatusBadRequest, "invalid id")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func writeWebhookError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrSubscriptionNotFound):
+		errorResponse(ctx, http.StatusNotFound, "subscription not found")
+	case errors.Is(err, domain.ErrDeliveryNotFound):
+		errorResponse(ctx, http.StatusNotFound, "delivery not found")
+	default:
+		errorResponse(ctx, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,202 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+)
+
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) Subscribe(ctx context.Context, url string, eventTypes []domain.EventType, secret string) (*domain.Subscription, error) {
+	args := m.Called(ctx, url, eventTypes, secret)
+	return args.Get(0).(*domain.Subscription), args.Error(1)
+}
+
+func (m *MockWebhookService) Subscriptions(ctx context.Context) ([]domain.Subscription, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.Subscription), args.Error(1)
+}
+
+func (m *MockWebhookService) Subscription(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*domain.Subscription), args.Error(1)
+}
+
+func (m *MockWebhookService) Unsubscribe(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) Deliveries(ctx context.Context, filter domain.DeliveryFilter) ([]domain.Delivery, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]domain.Delivery), args.Error(1)
+}
+
+func (m *MockWebhookService) ReplayDelivery(ctx context.Context, id uuid.UUID) (*domain.Delivery, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*domain.Delivery), args.Error(1)
+}
+
+func TestWebhookRoutes(t *testing.T) {
+	subscriptionId, deliveryId := uuid.New(), uuid.New()
+	createdAt := time.Date(2026, time.October, 19, 7, 0, 0, 0, time.UTC)
+	subscription := domain.Subscription{
+		Id:         subscriptionId,
+		URL:        "https://menu-board.example/hooks",
+		EventTypes: []domain.EventType{domain.EventRecipeCreated},
+		Secret:     "menu-board-secret",
+		CreatedAt:  createdAt,
+	}
+	dead := domain.Delivery{
+		Id:             deliveryId,
+		SubscriptionId: subscriptionId,
+		EventType:      domain.EventRecipeCreated,
+		Payload:        []byte(`{"type":"recipe.created"}`),
+		Status:         domain.DeliveryDead,
+		Attempts:       8,
+		LastError:      "receiver answered 503 Service Unavailable",
+		ResponseStatus: http.StatusServiceUnavailable,
+		NextAttemptAt:  createdAt,
+		CreatedAt:      createdAt,
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		setup          func(*MockWebhookService)
+		expectedStatus int
+		expectedBody   string
+		unexpectedBody string
+	}{
+		{
+			name:   "subscribes with the secret in the response",
+			method: http.MethodPost,
+			path:   "/admin/webhooks/subscriptions",
+			body:   `{"url": "https://menu-board.example/hooks", "events": ["recipe.created"]}`,
+			setup: func(m *MockWebhookService) {
+				m.On("Subscribe", mock.Anything, "https://menu-board.example/hooks", []domain.EventType{domain.EventRecipeCreated}, "").
+					Return(&subscription, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"secret":"menu-board-secret"`,
+		},
+		{
+			name:   "rejects an invalid subscription",
+			method: http.MethodPost,
+			path:   "/admin/webhooks/subscriptions",
+			body:   `{"url": "https://menu-board.example/hooks", "events": ["recipe.eaten"]}`,
+			setup: func(m *MockWebhookService) {
+				m.On("Subscribe", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+					Return((*domain.Subscription)(nil), domain.ErrInvalidSubscription)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "rejects a short secret",
+			method:         http.MethodPost,
+			path:           "/admin/webhooks/subscriptions",
+			body:           `{"url": "https://menu-board.example/hooks", "secret": "short"}`,
+			setup:          func(*MockWebhookService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "lists subscriptions without their secret",
+			method: http.MethodGet,
+			path:   "/admin/webhooks/subscriptions",
+			setup: func(m *MockWebhookService) {
+				m.On("Subscriptions", mock.Anything).Return([]domain.Subscription{subscription}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"events":["recipe.created"]`,
+			unexpectedBody: "menu-board-secret",
+		},
+		{
+			name:   "reports a missing subscription",
+			method: http.MethodDelete,
+			path:   "/admin/webhooks/subscriptions/" + subscriptionId.String(),
+			setup: func(m *MockWebhookService) {
+				m.On("Unsubscribe", mock.Anything, subscriptionId).Return(domain.ErrSubscriptionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"subscription not found"}`,
+		},
+		{
+			name:   "lists the dead-letter list of a subscription",
+			method: http.MethodGet,
+			path:   "/admin/webhooks/subscriptions/" + subscriptionId.String() + "/deliveries?status=dead&limit=10",
+			setup: func(m *MockWebhookService) {
+				m.On("Subscription", mock.Anything, subscriptionId).Return(&subscription, nil)
+				m.On("Deliveries", mock.Anything, domain.DeliveryFilter{SubscriptionId: subscriptionId, Status: domain.DeliveryDead, Limit: 10}).
+					Return([]domain.Delivery{dead}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"payload":{"type":"recipe.created"}`,
+			unexpectedBody: "nextAttemptAt",
+		},
+		{
+			name:           "rejects an unknown status",
+			method:         http.MethodGet,
+			path:           "/admin/webhooks/deliveries?status=lost",
+			setup:          func(*MockWebhookService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "replays a delivery",
+			method: http.MethodPost,
+			path:   "/admin/webhooks/deliveries/" + deliveryId.String() + "/replay",
+			setup: func(m *MockWebhookService) {
+				replayed := dead
+				replayed.Status, replayed.Attempts = domain.DeliveryPending, 0
+				m.On("ReplayDelivery", mock.Anything, deliveryId).Return(&replayed, nil)
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `"status":"pending"`,
+		},
+		{
+			name:   "reports a missing delivery",
+			method: http.MethodPost,
+			path:   "/admin/webhooks/deliveries/" + deliveryId.String() + "/replay",
+			setup: func(m *MockWebhookService) {
+				m.On("ReplayDelivery", mock.Anything, deliveryId).Return((*domain.Delivery)(nil), domain.ErrDeliveryNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"delivery not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockWebhookService := new(MockWebhookService)
+			tt.setup(mockWebhookService)
+			router := gin.New()
+			NewWebhookHandler(mockWebhookService).RegisterAdminRoutes(router.Group("/admin"))
+
+			request := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			request.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tt.expectedBody)
+			if tt.unexpectedBody != "" {
+				assert.NotContains(t, recorder.Body.String(), tt.unexpectedBody)
+			}
+			mockWebhookService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Package openapi serves the OpenAPI 3.1 document of the HTTP API, kept in
+// openapi.yaml next to this file, and a Swagger UI over it.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed openapi.yaml
+var specification []byte
+
+// Document returns the OpenAPI document as JSON.
+func Document() ([]byte, error) {
+	var document map[string]any
+	if err := yaml.Unmarshal(specification, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+	return json.Marshal(document)
+}
+
+// swaggerUI loads Swagger UI from a CDN, so the binary does not ship its
+// assets; the page needs no server-side state.
+const swaggerUI = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Recipe-Manager API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => { window.ui = SwaggerUIBundle({url: "/openapi.json", dom_id: "#swagger-ui"}); };
+  </script>
+</body>
+</html>
+`
+
+type Handler struct {
+	document []byte
+}
+
+func NewHandler() (*Handler, error) {
+	document, err := Document()
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{document: document}, nil
+}
+
+func (h *Handler) RegisterRoutes(router *gin.Engine) {
+	router.GET("/openapi.json", h.handleDocument)
+	router.GET("/docs", h.handleSwaggerUI)
+}
+
+func (h *Handler) handleDocument(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", h.document)
+}
+
+func (h *Handler) handleSwaggerUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUI))
+}
@@ -0,0 +1,178 @@
+// Package card renders the prep sheet of an aggregate, printed for the prep
+// station: the dough and topping quantities of each pan, in grams, and the
+// numbered steps of the recipe. Sheets are rendered as Markdown and HTML from
+// the templates next to this file, or as PDF.
+package card
+
+import (
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+)
+
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatPDF      = "pdf"
+)
+
+// Formats are the formats a sheet is rendered in, HTML first as the default.
+var Formats = []string{FormatHTML, FormatMarkdown, FormatPDF}
+
+//go:embed templates
+var templates embed.FS
+
+var (
+	markdownTemplate = texttemplate.Must(texttemplate.New("card.md.tmpl").
+				Funcs(texttemplate.FuncMap{"cell": markdownCell}).
+				ParseFS(templates, "templates/card.md.tmpl"))
+	htmlTemplate = htmltemplate.Must(htmltemplate.ParseFS(templates, "templates/card.html.tmpl"))
+)
+
+// Sheet is the content of a prep sheet, with the amounts already formatted.
+type Sheet struct {
+	Name        string
+	Description string
+	Author      string
+	// DoughTotal is the weight of the dough of every pan together.
+	DoughTotal string
+	Pans       []Pan
+	Steps      []Step
+}
+
+type Pan struct {
+	Number int
+	// Label names the pan by its shape and measures.
+	Label        string
+	DoughTotal   string
+	Dough        []Quantity
+	ToppingTotal string
+	Topping      []Quantity
+}
+
+type Quantity struct {
+	Ingredient string
+	Notes      string
+	Amount     string
+}
+
+type Step struct {
+	Number      int
+	Description string
+}
+
+// NewSheet lays out the sheet of an aggregate. Amounts are in grams rounded
+// to one decimal; steps keep the order of their numbers.
+func NewSheet(aggregate domain.RecipeAggregate) Sheet {
+	sheet := Sheet{
+		Name:        aggregate.Name,
+		Description: aggregate.Description,
+		Author:      aggregate.Author,
+	}
+
+	doughTotal := 0.0
+	for i, dough := range aggregate.SplitIngredients.SplitDough {
+		pan := Pan{
+			Number:     i + 1,
+			Label:      dough.Name,
+			DoughTotal: formatGrams(dough.Total()),
+			Dough:      quantities(dough.Ingredients),
+		}
+		doughTotal += dough.Total()
+		if i < len(aggregate.Pans.Pans) {
+			pan.Label = panLabel(aggregate.Pans.Pans[i])
+			topping := aggregate.PanTopping(i)
+			pan.Topping = quantities(topping)
+			pan.ToppingTotal = formatGrams(total(topping))
+		}
+		sheet.Pans = append(sheet.Pans, pan)
+	}
+	sheet.DoughTotal = formatGrams(doughTotal)
+
+	for _, step := range aggregate.Steps.Steps {
+		sheet.Steps = append(sheet.Steps, Step{Number: step.StepNumber, Description: step.Description})
+	}
+	return sheet
+}
+
+// Render writes the sheet of aggregate in format, one of Formats.
+func Render(w io.Writer, format string, aggregate domain.RecipeAggregate) error {
+	switch format {
+	case FormatMarkdown:
+		return Markdown(w, aggregate)
+	case FormatHTML:
+		return HTML(w, aggregate)
+	case FormatPDF:
+		return PDF(w, aggregate)
+	}
+	return fmt.Errorf("unknown card format %q, expected one of %s", format, strings.Join(Formats, ", "))
+}
+
+// Markdown writes the sheet of aggregate as a Markdown document, with a
+// table of ingredients per pan.
+func Markdown(w io.Writer, aggregate domain.RecipeAggregate) error {
+	return markdownTemplate.Execute(w, NewSheet(aggregate))
+}
+
+// HTML writes the sheet of aggregate as a standalone HTML page, styled for
+// printing on A4.
+func HTML(w io.Writer, aggregate domain.RecipeAggregate) error {
+	return htmlTemplate.Execute(w, NewSheet(aggregate))
+}
+
+func quantities(ingredients []domain.Ingredient) []Quantity {
+	result := make([]Quantity, 0, len(ingredients))
+	for _, ingredient := range ingredients {
+		result = append(result, Quantity{
+			Ingredient: ingredient.Name,
+			Notes:      ingredient.Notes,
+			Amount:     formatGrams(ingredient.Amount),
+		})
+	}
+	return result
+}
+
+func total(ingredients []domain.Ingredient) float64 {
+	sum := 0.0
+	for _, ingredient := range ingredients {
+		sum += ingredient.Amount
+	}
+	return sum
+}
+
+// panLabel describes a pan by its measures, falling back to its name when
+// they are missing.
+func panLabel(pan domain.Pan) string {
+	measures := pan.Measures
+	var label string
+	switch {
+	case pan.Shape == "round" && measures.Diameter != nil:
+		label = fmt.Sprintf("round, %d cm diameter", *measures.Diameter)
+	case pan.Shape == "square" && measures.Edge != nil:
+		label = fmt.Sprintf("square, %d cm edge", *measures.Edge)
+	case pan.Shape == "rectangular" && measures.Width != nil && measures.Length != nil:
+		label = fmt.Sprintf("rectangular, %d × %d cm", *measures.Width, *measures.Length)
+	default:
+		label = strings.TrimSpace(pan.Name + " " + pan.Shape)
+	}
+	if pan.Slices > 0 {
+		label += fmt.Sprintf(", %d slices", pan.Slices)
+	}
+	return label
+}
+
+func formatGrams(amount float64) string {
+	return strconv.FormatFloat(math.Round(amount*10)/10, 'f', -1, 64) + " g"
+}
+
+// markdownCell escapes the pipes that would end a table cell.
+func markdownCell(value string) string {
+	return strings.ReplaceAll(value, "|", `\|`)
+}
@@ -0,0 +1,129 @@
+package card
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the rendered sheets")
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		format string
+		golden string
+	}{
+		{FormatMarkdown, "margherita.md"},
+		{FormatHTML, "margherita.html"},
+		{FormatPDF, "margherita.pdf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var rendered bytes.Buffer
+			require.NoError(t, Render(&rendered, tt.format, margherita()))
+
+			path := filepath.Join("testdata", tt.golden+".golden")
+			if *update {
+				require.NoError(t, os.WriteFile(path, rendered.Bytes(), 0o644))
+			}
+			expected, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, string(expected), rendered.String())
+		})
+	}
+
+	t.Run("unknown format", func(t *testing.T) {
+		err := Render(&bytes.Buffer{}, "docx", margherita())
+		assert.EqualError(t, err, `unknown card format "docx", expected one of html, markdown, pdf`)
+	})
+}
+
+func TestPDFPagination(t *testing.T) {
+	aggregate := margherita()
+	aggregate.Steps.Steps = nil
+	for i := 1; i <= 60; i++ {
+		aggregate.Steps.Steps = append(aggregate.Steps.Steps, domain.Step{StepNumber: i, Description: fmt.Sprintf("Step number %d", i)})
+	}
+
+	var rendered bytes.Buffer
+	require.NoError(t, PDF(&rendered, aggregate))
+
+	document := rendered.String()
+	assert.Contains(t, document, "/Count 3")
+	assert.Contains(t, document, "(Margherita \xb7 page 3 of 3) Tj")
+	assert.Equal(t, 1, strings.Count(document, "(Steps) Tj"))
+	assert.True(t, strings.HasSuffix(document, "%%EOF\n"))
+}
+
+func TestNewSheet(t *testing.T) {
+	t.Run("labels pans by their name without measures", func(t *testing.T) {
+		aggregate := margherita()
+		aggregate.Pans.Pans[0].Measures = domain.Measures{}
+		aggregate.Pans.Pans[0].Name = "teglia"
+
+		assert.Equal(t, "teglia round", NewSheet(aggregate).Pans[0].Label)
+	})
+
+	t.Run("leaves out the topping of pans it was not split for", func(t *testing.T) {
+		aggregate := margherita()
+		aggregate.Pans = domain.Pans{}
+
+		sheet := NewSheet(aggregate)
+		require.Len(t, sheet.Pans, 2)
+		assert.Equal(t, "round", sheet.Pans[0].Label)
+		assert.Empty(t, sheet.Pans[0].Topping)
+		assert.Equal(t, "397.8 g", sheet.DoughTotal)
+	})
+}
+
+func margherita() domain.RecipeAggregate {
+	diameter, width, length := 30, 30, 40
+	return domain.RecipeAggregate{
+		Recipe: domain.Recipe{
+			Name:        "Margherita",
+			Description: "Tomato, mozzarella & basil",
+			Author:      "PizzaMaker",
+			Topping: domain.Topping{ReferenceArea: 1200, Ingredients: []domain.Ingredient{
+				{Name: "peeledTomatoes", Amount: 300, Unit: "g"},
+				{Name: "mozzarellaCheese", Amount: 250, Unit: "g", Notes: "torn | drained"},
+				{Name: "basil", Amount: 10, Unit: "g"},
+			}},
+			Steps: domain.Steps{Steps: []domain.Step{
+				{StepNumber: 1, Description: "Mix the flour, the water and the yeast until a dough forms."},
+				{StepNumber: 2, Description: "Preheat the oven to 250°C (or the highest temperature possible), then stretch the dough in the pans, spread the tomatoes over it leaving a border for the crust, and bake for 10-15 minutes."},
+				{StepNumber: 3, Description: "Add the <mozzarella> and bake for 5 more minutes."},
+			}},
+		},
+		SplitIngredients: domain.SplitIngredients{SplitDough: []domain.Dough{
+			{Name: "round", Ingredients: []domain.Ingredient{
+				{Name: "flour", Amount: 106.04, Unit: "g"},
+				{Name: "water", Amount: 79.2, Unit: "g"},
+				{Name: "salt", Amount: 2.09, Unit: "g"},
+				{Name: "yeast", Amount: 0.95, Unit: "g"},
+			}},
+			{Name: "rectangular", Ingredients: []domain.Ingredient{
+				{Name: "flour", Amount: 117.98, Unit: "g"},
+				{Name: "water", Amount: 88.11, Unit: "g"},
+				{Name: "salt", Amount: 2.33, Unit: "g"},
+				{Name: "yeast", Amount: 1.06, Unit: "g"},
+			}},
+		}},
+		Pans: domain.Pans{
+			Pans: []domain.Pan{
+				{Shape: "round", Measures: domain.Measures{Diameter: &diameter}, Area: 706.86},
+				{Shape: "rectangular", Measures: domain.Measures{Width: &width, Length: &length}, Area: 1200, Slices: 8},
+			},
+			TotalArea: 1906.86,
+		},
+	}
+}
@@ -0,0 +1,330 @@
+package card
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+)
+
+// A4 in points, the unit of PDF.
+const (
+	pageWidth  = 595
+	pageHeight = 842
+	margin     = 50
+	// footerY is the baseline of the page numbers, below the bottom margin.
+	footerY = 30
+)
+
+// pdfFont is one of the standard Type 1 fonts, which every PDF reader has,
+// so none is embedded. Widths are in thousandths of the font size, for the
+// printable ASCII characters from the space on.
+type pdfFont struct {
+	resource string
+	name     string
+	widths   []int
+}
+
+var (
+	helvetica = pdfFont{resource: "F1", name: "Helvetica", widths: []int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}}
+	helveticaBold = pdfFont{resource: "F2", name: "Helvetica-Bold", widths: []int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}}
+	helveticaOblique = pdfFont{resource: "F3", name: "Helvetica-Oblique", widths: helvetica.widths}
+
+	pdfFonts = []pdfFont{helvetica, helveticaBold, helveticaOblique}
+)
+
+// PDF writes the sheet of aggregate as an A4 PDF document. Pans are kept on
+// one page when they fit on one, and every page is numbered.
+func PDF(w io.Writer, aggregate domain.RecipeAggregate) error {
+	sheet := NewSheet(aggregate)
+	layout := newPDFLayout()
+
+	layout.paragraph(helveticaBold, 20, margin, sheet.Name)
+	layout.advance(4)
+	if sheet.Description != "" {
+		layout.paragraph(helvetica, 11, margin, sheet.Description)
+	}
+	if sheet.Author != "" {
+		layout.paragraph(helveticaOblique, 11, margin, "By "+sheet.Author)
+	}
+	layout.advance(6)
+	pans := "pans"
+	if len(sheet.Pans) == 1 {
+		pans = "pan"
+	}
+	layout.paragraph(helveticaBold, 11, margin, fmt.Sprintf("Dough for %d %s: %s", len(sheet.Pans), pans, sheet.DoughTotal))
+
+	for _, pan := range sheet.Pans {
+		// The heading, the two table headers and their rows.
+		rows := 3 + len(pan.Dough) + len(pan.Topping)
+		layout.keepTogether(26 + float64(rows)*rowHeight)
+
+		heading := fmt.Sprintf("Pan %d", pan.Number)
+		if pan.Label != "" {
+			heading += ": " + pan.Label
+		}
+		layout.heading(heading)
+		layout.row(helveticaBold, "Dough", pan.DoughTotal)
+		for _, quantity := range pan.Dough {
+			layout.row(helvetica, quantity.label(), quantity.Amount)
+		}
+		if len(pan.Topping) > 0 {
+			layout.advance(6)
+			layout.row(helveticaBold, "Topping", pan.ToppingTotal)
+			for _, quantity := range pan.Topping {
+				layout.row(helvetica, quantity.label(), quantity.Amount)
+			}
+		}
+	}
+
+	if len(sheet.Steps) > 0 {
+		layout.keepTogether(26 + 2*rowHeight)
+		layout.heading("Steps")
+		for _, step := range sheet.Steps {
+			layout.step(step)
+		}
+	}
+
+	return writePDF(w, sheet.Name, layout.finish(sheet.Name))
+}
+
+func (q Quantity) label() string {
+	if q.Notes == "" {
+		return q.Ingredient
+	}
+	return q.Ingredient + " (" + q.Notes + ")"
+}
+
+// rowHeight is the line height of the 11 point text.
+const rowHeight = 15
+
+// pdfLayout lays out lines from the top of each page down, starting a new
+// page when the next line would cross the bottom margin.
+type pdfLayout struct {
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+	// y is the top of the next line.
+	y float64
+}
+
+func newPDFLayout() *pdfLayout {
+	layout := &pdfLayout{}
+	layout.newPage()
+	return layout
+}
+
+func (l *pdfLayout) newPage() {
+	l.page = &bytes.Buffer{}
+	l.pages = append(l.pages, l.page)
+	l.y = pageHeight - margin
+}
+
+func (l *pdfLayout) advance(height float64) {
+	l.y -= height
+}
+
+// keepTogether starts a new page unless height fits on this one, or would
+// not fit on a new one either.
+func (l *pdfLayout) keepTogether(height float64) {
+	if l.y-height < margin && height <= pageHeight-2*margin && l.page.Len() > 0 {
+		l.newPage()
+	}
+}
+
+// line makes room for a line of height, on a new page when needed, and
+// returns its baseline.
+func (l *pdfLayout) line(height float64) float64 {
+	if l.y-height < margin {
+		l.newPage()
+	}
+	l.y -= height
+	return l.y + height*0.2
+}
+
+func (l *pdfLayout) text(font pdfFont, size, x, baseline float64, value string) {
+	fmt.Fprintf(l.page, "BT /%s %s Tf %s %s Td (%s) Tj ET\n",
+		font.resource, formatNumber(size), formatNumber(x), formatNumber(baseline), escapePDF(encodePDF(value)))
+}
+
+func (l *pdfLayout) rule(baseline float64) {
+	fmt.Fprintf(l.page, "0.5 w %d %s m %d %s l S\n", margin, formatNumber(baseline), pageWidth-margin, formatNumber(baseline))
+}
+
+// paragraph writes value wrapped to the width of the page from x.
+func (l *pdfLayout) paragraph(font pdfFont, size, x float64, value string) {
+	for _, line := range wrap(font, size, pageWidth-margin-x, value) {
+		l.text(font, size, x, l.line(size*1.35), line)
+	}
+}
+
+func (l *pdfLayout) heading(value string) {
+	l.advance(10)
+	baseline := l.line(20)
+	l.text(helveticaBold, 14, margin, baseline, value)
+	l.rule(baseline - 4)
+	l.advance(6)
+}
+
+// row writes a table row: the label on the left and the amount aligned to
+// the right margin.
+func (l *pdfLayout) row(font pdfFont, label, amount string) {
+	baseline := l.line(rowHeight)
+	l.text(font, 11, margin, baseline, label)
+	l.text(font, 11, pageWidth-margin-font.width(11, amount), baseline, amount)
+}
+
+// step writes a numbered step with its lines indented past the number.
+func (l *pdfLayout) step(step Step) {
+	const indent = 22
+	number := fmt.Sprintf("%d.", step.Number)
+	for i, line := range wrap(helvetica, 11, pageWidth-2*margin-indent, step.Description) {
+		baseline := l.line(rowHeight)
+		if i == 0 {
+			l.text(helvetica, 11, margin, baseline, number)
+		}
+		l.text(helvetica, 11, margin+indent, baseline, line)
+	}
+	l.advance(4)
+}
+
+// finish numbers the pages and returns their content streams.
+func (l *pdfLayout) finish(title string) [][]byte {
+	contents := make([][]byte, len(l.pages))
+	for i, page := range l.pages {
+		l.page = page
+		footer := fmt.Sprintf("%s · page %d of %d", title, i+1, len(l.pages))
+		l.text(helvetica, 8, pageWidth-margin-helvetica.width(8, footer), footerY, footer)
+		contents[i] = page.Bytes()
+	}
+	return contents
+}
+
+// width is the width of value at size, in points.
+func (f pdfFont) width(size float64, value string) float64 {
+	units := 0
+	for _, b := range encodePDF(value) {
+		if int(b) >= 32 && int(b)-32 < len(f.widths) {
+			units += f.widths[b-32]
+		} else {
+			units += 556
+		}
+	}
+	return float64(units) * size / 1000
+}
+
+// wrap breaks value into lines no wider than width, between words.
+func wrap(font pdfFont, size, width float64, value string) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(value) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if line != "" && font.width(size, candidate) > width {
+			lines = append(lines, line)
+			candidate = word
+		}
+		line = candidate
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// encodePDF converts value to WinAnsiEncoding, the encoding of the fonts,
+// replacing the characters it lacks with "?".
+func encodePDF(value string) []byte {
+	encoded := make([]byte, 0, len(value))
+	for _, r := range value {
+		b, ok := charmap.Windows1252.EncodeRune(r)
+		if !ok {
+			b = '?'
+		}
+		encoded = append(encoded, b)
+	}
+	return encoded
+}
+
+func escapePDF(value []byte) []byte {
+	var escaped bytes.Buffer
+	for _, b := range value {
+		if b == '\\' || b == '(' || b == ')' {
+			escaped.WriteByte('\\')
+		}
+		escaped.WriteByte(b)
+	}
+	return escaped.Bytes()
+}
+
+func formatNumber(value float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.2f", value), "0"), ".")
+}
+
+// writePDF writes the document: the catalog, the page tree, the fonts and
+// the document information, then each page with its content stream, and the
+// cross-reference table of their offsets.
+func writePDF(w io.Writer, title string, contents [][]byte) error {
+	var document bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, document.Len())
+		fmt.Fprintf(&document, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// The catalog and the page tree come first, then the fonts and the
+	// document information.
+	info := 3 + len(pdfFonts)
+	firstPage := info + 1
+	kids := make([]string, len(contents))
+	for i := range contents {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	fonts := make([]string, len(pdfFonts))
+	for i, font := range pdfFonts {
+		fonts[i] = fmt.Sprintf("/%s %d 0 R", font.resource, 3+i)
+	}
+
+	document.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(contents)))
+	for _, font := range pdfFonts {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", font.name))
+	}
+	object(fmt.Sprintf("<< /Title (%s) /Producer (recipe-manager) >>", escapePDF(encodePDF(title))))
+	for i, content := range contents {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << %s >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, strings.Join(fonts, " "), firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content))
+	}
+
+	xref := document.Len()
+	fmt.Fprintf(&document, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&document, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&document, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(offsets)+1, info, xref)
+
+	_, err := w.Write(document.Bytes())
+	return err
+}
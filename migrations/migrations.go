@@ -0,0 +1,63 @@
+// Package migrations embeds the SQL migrations so the binary can apply them
+// without shipping the files next to it. Each supported database has its own
+// directory; schema and seed data are versioned separately and tracked in
+// different migrations tables. The ingredient catalogue is bundled here too,
+// as a CSV imported after the schema, along with the recipe fixtures served
+// by the demo mode.
+//
+// PostgreSQL and SQLite start at version 6 with the schema MySQL reached by
+// then, so later migrations share version numbers across databases.
+package migrations
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+)
+
+//go:embed mysql/schema/*.sql mysql/seed/*.sql
+//go:embed postgres/schema/*.sql postgres/seed/*.sql
+//go:embed sqlite/schema/*.sql sqlite/seed/*.sql
+var files embed.FS
+
+//go:embed catalogue/ingredients.csv
+var catalogue []byte
+
+//go:embed fixtures
+var fixtures embed.FS
+
+// Drivers lists the databases migrations are available for.
+var Drivers = []string{"mysql", "postgres", "sqlite"}
+
+// Schema returns the schema migrations of driver.
+func Schema(driver string) (fs.FS, error) {
+	return sub(driver, "schema")
+}
+
+// Seed returns the seed data migrations of driver.
+func Seed(driver string) (fs.FS, error) {
+	return sub(driver, "seed")
+}
+
+// Catalogue returns the bundled ingredient catalogue CSV.
+func Catalogue() io.Reader {
+	return bytes.NewReader(catalogue)
+}
+
+// Fixtures returns the bundled recipe fixtures.
+func Fixtures() fs.FS {
+	sub, err := fs.Sub(fixtures, "fixtures")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+func sub(driver, set string) (fs.FS, error) {
+	if _, err := fs.Stat(files, driver); err != nil {
+		return nil, fmt.Errorf("no migrations for database driver %q", driver)
+	}
+	return fs.Sub(files, driver+"/"+set)
+}
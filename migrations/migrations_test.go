@@ -0,0 +1,62 @@
+package migrations
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	tests := []struct {
+		driver string
+		schema []uint
+		seed   []uint
+	}{
+		{driver: "mysql", schema: []uint{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13}, seed: []uint{100001, 100002}},
+		{driver: "postgres", schema: []uint{6, 7, 8, 9, 10, 11, 12, 13}, seed: []uint{100001, 100002}},
+		{driver: "sqlite", schema: []uint{6, 7, 8, 9, 10, 11, 12, 13}, seed: []uint{100001, 100002}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.driver+" schema", func(t *testing.T) {
+			fsys, err := Schema(tt.driver)
+			require.NoError(t, err)
+			assert.Equal(t, tt.schema, versions(t, fsys))
+		})
+		t.Run(tt.driver+" seed", func(t *testing.T) {
+			fsys, err := Seed(tt.driver)
+			require.NoError(t, err)
+			assert.Equal(t, tt.seed, versions(t, fsys))
+		})
+	}
+
+	t.Run("unknown driver", func(t *testing.T) {
+		_, err := Schema("oracle")
+		assert.EqualError(t, err, `no migrations for database driver "oracle"`)
+	})
+}
+
+func versions(t *testing.T, fsys fs.FS) []uint {
+	t.Helper()
+	driver, err := iofs.New(fsys, ".")
+	require.NoError(t, err)
+
+	var versions []uint
+	version, err := driver.First()
+	for err == nil {
+		versions = append(versions, version)
+
+		_, _, upErr := driver.ReadUp(version)
+		assert.NoError(t, upErr, "missing up migration for %d", version)
+		_, _, downErr := driver.ReadDown(version)
+		assert.NoError(t, downErr, "missing down migration for %d", version)
+
+		version, err = driver.Next(version)
+	}
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+	return versions
+}
@@ -0,0 +1,5 @@
+ALTER TABLE recipes
+    DROP COLUMN dough_percent_variation,
+    DROP COLUMN topping_reference_area;
+DROP TABLE IF EXISTS recipe_ingredients;
+DROP TABLE IF EXISTS ingredients;
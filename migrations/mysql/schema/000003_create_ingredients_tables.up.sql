@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS ingredients
+(
+    id         INT AUTO_INCREMENT PRIMARY KEY,
+    name       VARCHAR(100) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS recipe_ingredients
+(
+    id            INT AUTO_INCREMENT PRIMARY KEY,
+    recipe_id     INT                       NOT NULL,
+    ingredient_id INT                       NOT NULL,
+    section       ENUM ('dough', 'topping') NOT NULL,
+    position      INT                       NOT NULL,
+    amount        DECIMAL(10, 3)            NOT NULL,
+    unit          VARCHAR(16)               NOT NULL,
+    notes         TEXT,
+    UNIQUE KEY uq_recipe_section_position (recipe_id, section, position),
+    FOREIGN KEY (recipe_id) REFERENCES recipes (id) ON DELETE CASCADE,
+    FOREIGN KEY (ingredient_id) REFERENCES ingredients (id)
+);
+
+ALTER TABLE recipes
+    ADD COLUMN dough_percent_variation DECIMAL(6, 2)  NOT NULL DEFAULT 0 AFTER author,
+    ADD COLUMN topping_reference_area  DECIMAL(10, 2) NOT NULL DEFAULT 0 AFTER dough_percent_variation;
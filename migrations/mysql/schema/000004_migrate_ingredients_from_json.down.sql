@@ -0,0 +1,19 @@
+ALTER TABLE recipes
+    ADD COLUMN dough   JSON DEFAULT (JSON_OBJECT()) NOT NULL AFTER author,
+    ADD COLUMN topping JSON DEFAULT (JSON_OBJECT()) NOT NULL AFTER dough;
+
+UPDATE recipes r
+SET r.dough   = JSON_SET(COALESCE((SELECT JSON_OBJECTAGG(i.name, ri.amount)
+                                   FROM recipe_ingredients ri
+                                            JOIN ingredients i ON i.id = ri.ingredient_id
+                                   WHERE ri.recipe_id = r.id
+                                     AND ri.section = 'dough'), JSON_OBJECT()),
+                         '$.percentVariation', r.dough_percent_variation),
+    r.topping = JSON_SET(COALESCE((SELECT JSON_OBJECTAGG(i.name, ri.amount)
+                                   FROM recipe_ingredients ri
+                                            JOIN ingredients i ON i.id = ri.ingredient_id
+                                   WHERE ri.recipe_id = r.id
+                                     AND ri.section = 'topping'), JSON_OBJECT()),
+                         '$.referenceArea', r.topping_reference_area);
+
+DELETE FROM recipe_ingredients;
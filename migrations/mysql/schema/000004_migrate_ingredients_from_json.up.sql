@@ -0,0 +1,47 @@
+-- Moves the dough and topping JSON maps into recipe_ingredients. The magic
+-- percentVariation and referenceArea keys become columns of recipes, and the
+-- position follows the order the API used to return: amount desc, then name.
+UPDATE recipes
+SET dough_percent_variation = COALESCE(CAST(JSON_UNQUOTE(JSON_EXTRACT(dough, '$.percentVariation')) AS DECIMAL(6, 2)), 0),
+    topping_reference_area  = COALESCE(CAST(JSON_UNQUOTE(JSON_EXTRACT(topping, '$.referenceArea')) AS DECIMAL(10, 2)), 0);
+
+INSERT IGNORE INTO ingredients (name)
+SELECT dough_keys.name
+FROM recipes r,
+     JSON_TABLE(JSON_KEYS(r.dough), '$[*]' COLUMNS (name VARCHAR(100) PATH '$')) dough_keys
+WHERE dough_keys.name <> 'percentVariation'
+UNION
+SELECT topping_keys.name
+FROM recipes r,
+     JSON_TABLE(JSON_KEYS(r.topping), '$[*]' COLUMNS (name VARCHAR(100) PATH '$')) topping_keys
+WHERE topping_keys.name <> 'referenceArea';
+
+INSERT INTO recipe_ingredients (recipe_id, ingredient_id, section, position, amount, unit)
+SELECT entries.recipe_id,
+       i.id,
+       entries.section,
+       ROW_NUMBER() OVER (PARTITION BY entries.recipe_id, entries.section ORDER BY entries.amount DESC, entries.name),
+       entries.amount,
+       entries.unit
+FROM (SELECT r.id                                                                                    AS recipe_id,
+             'dough'                                                                                 AS section,
+             dough_keys.name                                                                         AS name,
+             CAST(JSON_UNQUOTE(JSON_EXTRACT(r.dough, CONCAT('$."', dough_keys.name, '"'))) AS DECIMAL(10, 3)) AS amount,
+             '%'                                                                                     AS unit
+      FROM recipes r,
+           JSON_TABLE(JSON_KEYS(r.dough), '$[*]' COLUMNS (name VARCHAR(100) PATH '$')) dough_keys
+      WHERE dough_keys.name <> 'percentVariation'
+      UNION ALL
+      SELECT r.id,
+             'topping',
+             topping_keys.name,
+             CAST(JSON_UNQUOTE(JSON_EXTRACT(r.topping, CONCAT('$."', topping_keys.name, '"'))) AS DECIMAL(10, 3)),
+             'g'
+      FROM recipes r,
+           JSON_TABLE(JSON_KEYS(r.topping), '$[*]' COLUMNS (name VARCHAR(100) PATH '$')) topping_keys
+      WHERE topping_keys.name <> 'referenceArea') entries
+         JOIN ingredients i ON i.name = entries.name;
+
+ALTER TABLE recipes
+    DROP COLUMN dough,
+    DROP COLUMN topping;
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS ingredient_allergens;
+DROP TABLE IF EXISTS ingredient_aliases;
+DROP TABLE IF EXISTS ingredient_display_names;
+
+ALTER TABLE ingredients
+    DROP COLUMN fat,
+    DROP COLUMN carbohydrates,
+    DROP COLUMN protein,
+    DROP COLUMN kcal,
+    DROP COLUMN density,
+    DROP COLUMN category;
@@ -0,0 +1,34 @@
+-- Turns ingredients into a catalogue: nutrition values are per 100 g and
+-- density is in g/ml, all of them NULL when unknown.
+ALTER TABLE ingredients
+    ADD COLUMN category      VARCHAR(32)   NULL AFTER name,
+    ADD COLUMN density       DECIMAL(6, 3) NULL AFTER category,
+    ADD COLUMN kcal          DECIMAL(7, 2) NULL AFTER density,
+    ADD COLUMN protein       DECIMAL(6, 2) NULL AFTER kcal,
+    ADD COLUMN carbohydrates DECIMAL(6, 2) NULL AFTER protein,
+    ADD COLUMN fat           DECIMAL(6, 2) NULL AFTER carbohydrates;
+
+CREATE TABLE IF NOT EXISTS ingredient_display_names
+(
+    ingredient_id INT          NOT NULL,
+    locale        VARCHAR(16)  NOT NULL,
+    display_name  VARCHAR(100) NOT NULL,
+    PRIMARY KEY (ingredient_id, locale),
+    FOREIGN KEY (ingredient_id) REFERENCES ingredients (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS ingredient_aliases
+(
+    alias         VARCHAR(100) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL PRIMARY KEY,
+    ingredient_id INT                                                           NOT NULL,
+    INDEX idx_ingredient_aliases_ingredient (ingredient_id),
+    FOREIGN KEY (ingredient_id) REFERENCES ingredients (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS ingredient_allergens
+(
+    ingredient_id INT         NOT NULL,
+    allergen      VARCHAR(32) NOT NULL,
+    PRIMARY KEY (ingredient_id, allergen),
+    FOREIGN KEY (ingredient_id) REFERENCES ingredients (id) ON DELETE CASCADE
+);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS ingredient_diets
+(
+    ingredient_id INT         NOT NULL,
+    diet          VARCHAR(32) NOT NULL,
+    PRIMARY KEY (ingredient_id, diet),
+    FOREIGN KEY (ingredient_id) REFERENCES ingredients (id) ON DELETE CASCADE
+);
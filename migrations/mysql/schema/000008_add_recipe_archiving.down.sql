@@ -0,0 +1,2 @@
+ALTER TABLE recipes DROP COLUMN deleted_at;
+ALTER TABLE recipes DROP COLUMN archived_at;
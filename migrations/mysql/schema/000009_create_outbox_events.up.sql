@@ -0,0 +1,15 @@
+-- Domain events are written here in the transaction of the change they
+-- describe, and relayed to the configured sink afterwards.
+CREATE TABLE IF NOT EXISTS outbox_events
+(
+    id           BIGINT AUTO_INCREMENT PRIMARY KEY,
+    event_id     CHAR(36)     NOT NULL UNIQUE,
+    event_type   VARCHAR(64)  NOT NULL,
+    recipe_uuid  CHAR(36)     NOT NULL,
+    payload      JSON         NOT NULL,
+    occurred_at  TIMESTAMP(6) NOT NULL,
+    published_at TIMESTAMP    NULL DEFAULT NULL,
+    attempts     INT          NOT NULL DEFAULT 0,
+    last_error   TEXT,
+    INDEX idx_outbox_events_pending (published_at, id)
+);
@@ -0,0 +1,30 @@
+-- Partners subscribe to recipe events, which are posted to them with retries;
+-- the deliveries are kept as history and dead-letter list.
+CREATE TABLE IF NOT EXISTS webhook_subscriptions
+(
+    id          CHAR(36)      NOT NULL PRIMARY KEY,
+    url         VARCHAR(2048) NOT NULL,
+    -- Comma separated, empty for every type.
+    event_types VARCHAR(255)  NOT NULL DEFAULT '',
+    secret      VARCHAR(128)  NOT NULL,
+    created_at  TIMESTAMP(6)  NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries
+(
+    id              CHAR(36)     NOT NULL PRIMARY KEY,
+    subscription_id CHAR(36)     NOT NULL,
+    event_id        CHAR(36)     NOT NULL,
+    event_type      VARCHAR(64)  NOT NULL,
+    payload         JSON         NOT NULL,
+    status          VARCHAR(16)  NOT NULL,
+    attempts        INT          NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    response_status INT,
+    next_attempt_at TIMESTAMP(6) NOT NULL,
+    created_at      TIMESTAMP(6) NOT NULL,
+    delivered_at    TIMESTAMP(6) NULL DEFAULT NULL,
+    UNIQUE KEY uq_webhook_deliveries_event (subscription_id, event_id),
+    INDEX idx_webhook_deliveries_due (status, next_attempt_at),
+    FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions (id) ON DELETE CASCADE
+);
@@ -0,0 +1,3 @@
+-- Events the sink rejected outbox.relay.maxAttempts times are parked: the
+-- relay leaves them alone until they are requeued.
+ALTER TABLE outbox_events ADD COLUMN parked_at TIMESTAMP NULL DEFAULT NULL;
@@ -0,0 +1 @@
+DELETE FROM recipe_steps WHERE recipe_steps.recipe_id = 1;
@@ -0,0 +1,13 @@
+INSERT INTO recipe_steps (recipe_id, step_number, description)
+SELECT 1, steps.step_number, steps.description
+FROM (SELECT 1 AS step_number, 'Dough: In a large mixing bowl, combine the flour and yeast. Gradually add the cold water, the salt, and the olive oil. Mix until a dough forms.' AS description
+      UNION ALL SELECT 2, 'Dough: Knead the dough on a floured surface until smooth and elastic.'
+      UNION ALL SELECT 3, 'Dough: Divide the dough and place them in some lightly oiled bowl, cover with a clean kitchen towel, and let it rise for about 2 hours, or until it doubles in size.'
+      UNION ALL SELECT 4, 'Sauce: Crush tomatoes with a fork for a rustic texture.'
+      UNION ALL SELECT 5, 'Sauce: Add a pinch of salt and 1 tablespoon of olive oil. Mix well.'
+      UNION ALL SELECT 6, 'Assemble: Preheat your oven to 250°C or the highest temperature possible.'
+      UNION ALL SELECT 7, 'Assemble: Place the doughs on a baking sheet. Spread a thin layer of tomato sauce over the surface, leaving a border for the crust.'
+      UNION ALL SELECT 8, 'Bake: Drizzle the pizza with olive oil. Bake in the oven for 10-15 minutes, or until the crust is golden.'
+      UNION ALL SELECT 9, 'Bake: Tear the mozzarella into small pieces, remove pizza from the oven, add a few basil leaves on top and distribute mozzarella evenly over. Bake in the oven for another 5 minutes, or until the cheese is bubbling.'
+      UNION ALL SELECT 10, 'Bake: Remove from the oven, add a few fresh basil leaves, parmesan cheese if desired, and serve immediately.') steps
+WHERE NOT EXISTS (SELECT 1 FROM recipe_steps WHERE recipe_id = 1);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS ingredient_diets;
+DROP TABLE IF EXISTS ingredient_allergens;
+DROP TABLE IF EXISTS ingredient_aliases;
+DROP TABLE IF EXISTS ingredient_display_names;
+DROP TABLE IF EXISTS recipe_ingredients;
+DROP TABLE IF EXISTS ingredients;
+DROP TABLE IF EXISTS recipe_steps;
+DROP TABLE IF EXISTS recipes;
@@ -0,0 +1,78 @@
+-- PostgreSQL starts from the schema reached by MySQL version 6, so the
+-- versions of later migrations match across databases.
+CREATE TABLE IF NOT EXISTS recipes
+(
+    id                      SERIAL PRIMARY KEY,
+    uuid                    UUID           NOT NULL UNIQUE,
+    name                    VARCHAR(255)   NOT NULL,
+    description             TEXT,
+    author                  VARCHAR(100),
+    dough_percent_variation NUMERIC(6, 2)  NOT NULL DEFAULT 0,
+    topping_reference_area  NUMERIC(10, 2) NOT NULL DEFAULT 0,
+    created_at              TIMESTAMPTZ    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at              TIMESTAMPTZ    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS recipe_steps
+(
+    id          SERIAL PRIMARY KEY,
+    recipe_id   INT  NOT NULL REFERENCES recipes (id),
+    step_number INT  NOT NULL,
+    description TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS ingredients
+(
+    id            SERIAL PRIMARY KEY,
+    name          VARCHAR(100) NOT NULL UNIQUE,
+    category      VARCHAR(32),
+    density       NUMERIC(6, 3),
+    kcal          NUMERIC(7, 2),
+    protein       NUMERIC(6, 2),
+    carbohydrates NUMERIC(6, 2),
+    fat           NUMERIC(6, 2),
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS recipe_ingredients
+(
+    id            SERIAL PRIMARY KEY,
+    recipe_id     INT            NOT NULL REFERENCES recipes (id) ON DELETE CASCADE,
+    ingredient_id INT            NOT NULL REFERENCES ingredients (id),
+    section       VARCHAR(16)    NOT NULL CHECK (section IN ('dough', 'topping')),
+    position      INT            NOT NULL,
+    amount        NUMERIC(10, 3) NOT NULL,
+    unit          VARCHAR(16)    NOT NULL,
+    notes         TEXT,
+    UNIQUE (recipe_id, section, position)
+);
+
+CREATE TABLE IF NOT EXISTS ingredient_display_names
+(
+    ingredient_id INT          NOT NULL REFERENCES ingredients (id) ON DELETE CASCADE,
+    locale        VARCHAR(16)  NOT NULL,
+    display_name  VARCHAR(100) NOT NULL,
+    PRIMARY KEY (ingredient_id, locale)
+);
+
+CREATE TABLE IF NOT EXISTS ingredient_aliases
+(
+    alias         VARCHAR(100) NOT NULL,
+    ingredient_id INT          NOT NULL REFERENCES ingredients (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_ingredient_aliases_alias ON ingredient_aliases (LOWER(alias));
+CREATE INDEX IF NOT EXISTS idx_ingredient_aliases_ingredient ON ingredient_aliases (ingredient_id);
+
+CREATE TABLE IF NOT EXISTS ingredient_allergens
+(
+    ingredient_id INT         NOT NULL REFERENCES ingredients (id) ON DELETE CASCADE,
+    allergen      VARCHAR(32) NOT NULL,
+    PRIMARY KEY (ingredient_id, allergen)
+);
+
+CREATE TABLE IF NOT EXISTS ingredient_diets
+(
+    ingredient_id INT         NOT NULL REFERENCES ingredients (id) ON DELETE CASCADE,
+    diet          VARCHAR(32) NOT NULL,
+    PRIMARY KEY (ingredient_id, diet)
+);
@@ -0,0 +1,4 @@
+-- Archived recipes are left out of the listings, deleted ones are hidden
+-- until restored or purged.
+ALTER TABLE recipes ADD COLUMN archived_at TIMESTAMPTZ;
+ALTER TABLE recipes ADD COLUMN deleted_at TIMESTAMPTZ;
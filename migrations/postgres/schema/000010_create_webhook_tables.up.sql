@@ -0,0 +1,30 @@
+-- Partners subscribe to recipe events, which are posted to them with retries;
+-- the deliveries are kept as history and dead-letter list.
+CREATE TABLE IF NOT EXISTS webhook_subscriptions
+(
+    id          UUID          NOT NULL PRIMARY KEY,
+    url         VARCHAR(2048) NOT NULL,
+    -- Comma separated, empty for every type.
+    event_types VARCHAR(255)  NOT NULL DEFAULT '',
+    secret      VARCHAR(128)  NOT NULL,
+    created_at  TIMESTAMPTZ   NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries
+(
+    id              UUID        NOT NULL PRIMARY KEY,
+    subscription_id UUID        NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+    event_id        UUID        NOT NULL,
+    event_type      VARCHAR(64) NOT NULL,
+    payload         TEXT        NOT NULL,
+    status          VARCHAR(16) NOT NULL,
+    attempts        INTEGER     NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    response_status INTEGER,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL,
+    delivered_at    TIMESTAMPTZ,
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
@@ -0,0 +1,2 @@
+ALTER TABLE recipes DROP COLUMN dough_density;
+ALTER TABLE recipes DROP COLUMN style;
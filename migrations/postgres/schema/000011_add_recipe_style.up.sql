@@ -0,0 +1,4 @@
+-- The pizza style of a recipe and the grams of dough per cm² of pan it is
+-- made with, overriding the one of the style.
+ALTER TABLE recipes ADD COLUMN style VARCHAR(32);
+ALTER TABLE recipes ADD COLUMN dough_density DECIMAL(5, 3);
@@ -0,0 +1,2 @@
+-- The grams of dough of a serving, for recipes scaled to servings.
+ALTER TABLE recipes ADD COLUMN serving_weight DECIMAL(8, 2);
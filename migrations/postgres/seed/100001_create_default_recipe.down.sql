@@ -0,0 +1 @@
+DELETE FROM recipes WHERE uuid = '00000000-0000-0000-0000-000000000000';
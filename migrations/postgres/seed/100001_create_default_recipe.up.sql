@@ -0,0 +1,24 @@
+INSERT INTO recipes (uuid, name, description, author, dough_percent_variation, topping_reference_area)
+VALUES ('00000000-0000-0000-0000-000000000000', 'Margherita', 'default pizza', 'PizzaMaker', 8.0, 1200)
+ON CONFLICT (uuid) DO NOTHING;
+
+INSERT INTO ingredients (name)
+VALUES ('flour'), ('water'), ('salt'), ('evoOil'), ('yeast'),
+       ('peeledTomatoes'), ('mozzarellaCheese'), ('basil'), ('parmesanCheese')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO recipe_ingredients (recipe_id, ingredient_id, section, position, amount, unit)
+SELECT r.id, i.id, seed.section, seed.position, seed.amount, seed.unit
+FROM (SELECT 'dough' AS section, 1 AS position, 'flour' AS name, 55.7 AS amount, '%' AS unit
+      UNION ALL SELECT 'dough', 2, 'water', 41.6, '%'
+      UNION ALL SELECT 'dough', 3, 'evoOil', 1.1, '%'
+      UNION ALL SELECT 'dough', 4, 'salt', 1.1, '%'
+      UNION ALL SELECT 'dough', 5, 'yeast', 0.5, '%'
+      UNION ALL SELECT 'topping', 1, 'peeledTomatoes', 300, 'g'
+      UNION ALL SELECT 'topping', 2, 'mozzarellaCheese', 250, 'g'
+      UNION ALL SELECT 'topping', 3, 'parmesanCheese', 20, 'g'
+      UNION ALL SELECT 'topping', 4, 'basil', 10, 'g'
+      UNION ALL SELECT 'topping', 5, 'evoOil', 10, 'g') seed
+         JOIN ingredients i ON i.name = seed.name
+         JOIN recipes r ON r.uuid = '00000000-0000-0000-0000-000000000000'
+ON CONFLICT (recipe_id, section, position) DO NOTHING;
@@ -0,0 +1,2 @@
+DELETE FROM recipe_steps
+WHERE recipe_id IN (SELECT id FROM recipes WHERE uuid = '00000000-0000-0000-0000-000000000000');
@@ -0,0 +1,78 @@
+-- SQLite starts from the schema reached by MySQL version 6, so the versions
+-- of later migrations match across databases. Foreign keys are enforced by
+-- the foreign_keys pragma set in the DSN.
+CREATE TABLE IF NOT EXISTS recipes
+(
+    id                      INTEGER PRIMARY KEY AUTOINCREMENT,
+    uuid                    TEXT    NOT NULL UNIQUE,
+    name                    TEXT    NOT NULL,
+    description             TEXT,
+    author                  TEXT,
+    dough_percent_variation REAL    NOT NULL DEFAULT 0,
+    topping_reference_area  REAL    NOT NULL DEFAULT 0,
+    created_at              TEXT    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at              TEXT    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS recipe_steps
+(
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    recipe_id   INTEGER NOT NULL REFERENCES recipes (id),
+    step_number INTEGER NOT NULL,
+    description TEXT    NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS ingredients
+(
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    name          TEXT NOT NULL UNIQUE,
+    category      TEXT,
+    density       REAL,
+    kcal          REAL,
+    protein       REAL,
+    carbohydrates REAL,
+    fat           REAL,
+    created_at    TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS recipe_ingredients
+(
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    recipe_id     INTEGER NOT NULL REFERENCES recipes (id) ON DELETE CASCADE,
+    ingredient_id INTEGER NOT NULL REFERENCES ingredients (id),
+    section       TEXT    NOT NULL CHECK (section IN ('dough', 'topping')),
+    position      INTEGER NOT NULL,
+    amount        REAL    NOT NULL,
+    unit          TEXT    NOT NULL,
+    notes         TEXT,
+    UNIQUE (recipe_id, section, position)
+);
+
+CREATE TABLE IF NOT EXISTS ingredient_display_names
+(
+    ingredient_id INTEGER NOT NULL REFERENCES ingredients (id) ON DELETE CASCADE,
+    locale        TEXT    NOT NULL,
+    display_name  TEXT    NOT NULL,
+    PRIMARY KEY (ingredient_id, locale)
+);
+
+CREATE TABLE IF NOT EXISTS ingredient_aliases
+(
+    alias         TEXT    NOT NULL PRIMARY KEY COLLATE NOCASE,
+    ingredient_id INTEGER NOT NULL REFERENCES ingredients (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_ingredient_aliases_ingredient ON ingredient_aliases (ingredient_id);
+
+CREATE TABLE IF NOT EXISTS ingredient_allergens
+(
+    ingredient_id INTEGER NOT NULL REFERENCES ingredients (id) ON DELETE CASCADE,
+    allergen      TEXT    NOT NULL,
+    PRIMARY KEY (ingredient_id, allergen)
+);
+
+CREATE TABLE IF NOT EXISTS ingredient_diets
+(
+    ingredient_id INTEGER NOT NULL REFERENCES ingredients (id) ON DELETE CASCADE,
+    diet          TEXT    NOT NULL,
+    PRIMARY KEY (ingredient_id, diet)
+);
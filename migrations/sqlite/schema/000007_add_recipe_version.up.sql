@@ -0,0 +1,2 @@
+-- Bumped by every update, compared by conditional updates and deletes.
+ALTER TABLE recipes ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,16 @@
+-- Domain events are written here in the transaction of the change they
+-- describe, and relayed to the configured sink afterwards.
+CREATE TABLE IF NOT EXISTS outbox_events
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_id     TEXT      NOT NULL UNIQUE,
+    event_type   TEXT      NOT NULL,
+    recipe_uuid  TEXT      NOT NULL,
+    payload      TEXT      NOT NULL,
+    occurred_at  TIMESTAMP NOT NULL,
+    published_at TIMESTAMP,
+    attempts     INTEGER   NOT NULL DEFAULT 0,
+    last_error   TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending ON outbox_events (published_at, id);
@@ -0,0 +1,30 @@
+-- Partners subscribe to recipe events, which are posted to them with retries;
+-- the deliveries are kept as history and dead-letter list.
+CREATE TABLE IF NOT EXISTS webhook_subscriptions
+(
+    id          TEXT      NOT NULL PRIMARY KEY,
+    url         TEXT      NOT NULL,
+    -- Comma separated, empty for every type.
+    event_types TEXT      NOT NULL DEFAULT '',
+    secret      TEXT      NOT NULL,
+    created_at  TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries
+(
+    id              TEXT      NOT NULL PRIMARY KEY,
+    subscription_id TEXT      NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+    event_id        TEXT      NOT NULL,
+    event_type      TEXT      NOT NULL,
+    payload         TEXT      NOT NULL,
+    status          TEXT      NOT NULL,
+    attempts        INTEGER   NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    response_status INTEGER,
+    next_attempt_at TIMESTAMP NOT NULL,
+    created_at      TIMESTAMP NOT NULL,
+    delivered_at    TIMESTAMP,
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
@@ -3,21 +3,24 @@ package integration
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"fmt"
+	"io/fs"
 	"path/filepath"
-	"runtime"
 	"testing"
 	"time"
 
 	"github.com/docker/go-connections/nat"
 	_ "github.com/go-sql-driver/mysql"
-	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/mysql"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/spf13/viper"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/cfioretti/recipe-manager/configs"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/sqlstore"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/sqlstore/migrations"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/sqlstore/sqlstoretest"
+	embedded "github.com/cfioretti/recipe-manager/migrations"
 )
 
 func init() {
@@ -28,14 +31,43 @@ type TestDatabase struct {
 	Container testcontainers.Container
 	DB        *sql.DB
 	Port      string
+	Dialect   sqlstore.Dialect
+	Config    configs.DBConfig
 }
 
+// SetupTestDb starts MySQL with the schema migrations and the ingredient
+// catalogue applied.
 func SetupTestDb(t *testing.T) (*TestDatabase, error) {
-	ctx := context.Background()
+	return SetupMySQLTestDb(t, true)
+}
 
-	ConfigureDockerForTests(t)
+// SetupMySQLTestDb starts MySQL with the schema migrations applied. The
+// catalogue is imported when withCatalogue is set.
+func SetupMySQLTestDb(t *testing.T, withCatalogue bool) (*TestDatabase, error) {
+	return setupDatabase(t, mysqlContainerRequest(), mysqlConfig(), withCatalogue)
+}
+
+// SetupBaselineMySQLTestDb starts MySQL the way the first docker-compose.yml
+// did: the files of testdata/baseline, the migrations directory back then,
+// run from docker-entrypoint-initdb.d and no migrations table is created.
+func SetupBaselineMySQLTestDb(t *testing.T) (*TestDatabase, error) {
+	req := mysqlContainerRequest()
+	files, err := filepath.Glob(filepath.Join("testdata", "baseline", "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		req.Files = append(req.Files, testcontainers.ContainerFile{
+			HostFilePath:      file,
+			ContainerFilePath: "/docker-entrypoint-initdb.d/" + filepath.Base(file),
+			FileMode:          0o644,
+		})
+	}
+	return startDatabase(t, req, mysqlConfig())
+}
 
-	req := testcontainers.ContainerRequest{
+func mysqlContainerRequest() testcontainers.ContainerRequest {
+	return testcontainers.ContainerRequest{
 		Image:        "mysql:8.0",
 		ExposedPorts: []string{"3306/tcp"},
 		Env: map[string]string{
@@ -46,6 +78,55 @@ func SetupTestDb(t *testing.T) (*TestDatabase, error) {
 			return fmt.Sprintf("root:test@tcp(%s:%s)/%s", host, port.Port(), "test_db")
 		}).WithStartupTimeout(2 * time.Minute),
 	}
+}
+
+func mysqlConfig() configs.DBConfig {
+	return configs.DBConfig{Driver: configs.DBDriverMySQL, User: "root", Password: "test", DBName: "test_db"}
+}
+
+// SetupPostgresTestDb starts PostgreSQL with the schema migrations applied.
+// The catalogue is imported when withCatalogue is set.
+func SetupPostgresTestDb(t *testing.T, withCatalogue bool) (*TestDatabase, error) {
+	return setupDatabase(t, testcontainers.ContainerRequest{
+		Image:        "postgres:16-alpine",
+		ExposedPorts: []string{"5432/tcp"},
+		Env: map[string]string{
+			"POSTGRES_USER":     "test",
+			"POSTGRES_PASSWORD": "test",
+			"POSTGRES_DB":       "test_db",
+		},
+		WaitingFor: wait.ForSQL("5432/tcp", "pgx", func(host string, port nat.Port) string {
+			return fmt.Sprintf("postgres://test:test@%s:%s/test_db?sslmode=disable", host, port.Port())
+		}).WithStartupTimeout(2 * time.Minute),
+	}, configs.DBConfig{Driver: configs.DBDriverPostgres, User: "test", Password: "test", DBName: "test_db"}, withCatalogue)
+}
+
+func setupDatabase(t *testing.T, req testcontainers.ContainerRequest, config configs.DBConfig, withCatalogue bool) (*TestDatabase, error) {
+	td, err := startDatabase(t, req, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := td.Migrate(embedded.Schema, migrations.SchemaMigrationsTable); err != nil {
+		return nil, fmt.Errorf("failed to run migrations: %v", err)
+	}
+	if withCatalogue {
+		if err := sqlstoretest.ImportCatalogue(td.DB, td.Dialect); err != nil {
+			return nil, fmt.Errorf("failed to import the catalogue: %v", err)
+		}
+	}
+	return td, nil
+}
+
+func startDatabase(t *testing.T, req testcontainers.ContainerRequest, config configs.DBConfig) (*TestDatabase, error) {
+	ctx := context.Background()
+
+	ConfigureDockerForTests(t)
+
+	dialect, err := sqlstore.DialectFor(config.Driver)
+	if err != nil {
+		return nil, err
+	}
 
 	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
 		ContainerRequest: req,
@@ -55,25 +136,24 @@ func SetupTestDb(t *testing.T) (*TestDatabase, error) {
 		return nil, fmt.Errorf("failed to start container: %v", err)
 	}
 
-	port, err := container.MappedPort(ctx, "3306/tcp")
+	port, err := container.MappedPort(ctx, nat.Port(req.ExposedPorts[0]))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get container port: %v", err)
 	}
 
-	dsn := fmt.Sprintf("root:test@tcp(localhost:%s)/test_db", port.Port())
-	db, err := sql.Open("mysql", dsn)
+	config.Host = "localhost"
+	config.Port = port.Port()
+	db, err := sql.Open(config.DriverName(), config.DSN())
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %v", err)
 	}
 
-	if err := runMigrations(dsn); err != nil {
-		return nil, fmt.Errorf("failed to run migrations: %v", err)
-	}
-
 	return &TestDatabase{
 		Container: container,
 		DB:        db,
 		Port:      port.Port(),
+		Dialect:   dialect,
+		Config:    config,
 	}, nil
 }
 
@@ -86,35 +166,20 @@ func ConfigureDockerForTests(t *testing.T) {
 	t.Setenv("TESTCONTAINERS_RYUK_DISABLED", "true")
 }
 
-func runMigrations(dsn string) error {
-	db, err := sql.Open("mysql", dsn)
+// Migrate applies the migrations files returns for the driver of the
+// database, tracked in table.
+func (td *TestDatabase) Migrate(files func(driver string) (fs.FS, error), table string) error {
+	fsys, err := files(td.Config.Driver)
 	if err != nil {
 		return err
 	}
-	defer db.Close()
-
-	driver, err := mysql.WithInstance(db, &mysql.Config{})
+	migrator, err := migrations.NewMigrator(td.Config.Driver, td.Config.DSN(), fsys, table)
 	if err != nil {
 		return err
 	}
+	defer migrator.Close()
 
-	_, filename, _, _ := runtime.Caller(0)
-	migrationsPath := filepath.Join(filepath.Dir(filename), "../../migrations")
-
-	m, err := migrate.NewWithDatabaseInstance(
-		fmt.Sprintf("file://%s", migrationsPath),
-		"mysql",
-		driver,
-	)
-	if err != nil {
-		return err
-	}
-
-	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		return err
-	}
-
-	return nil
+	return migrator.Up()
 }
 
 func (td *TestDatabase) Cleanup(ctx context.Context) error {
@@ -0,0 +1,126 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/application"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/catalogue"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/memory"
+	apihttp "github.com/cfioretti/recipe-manager/internal/recipe-manager/interfaces/api/http"
+	"github.com/cfioretti/recipe-manager/migrations"
+)
+
+// TestRecipeAPIInMemory runs the HTTP stack on the bundled fixtures, without
+// Docker.
+func TestRecipeAPIInMemory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := newInMemoryRouter(t)
+
+	t.Run("lists the fixtures", func(t *testing.T) {
+		var body struct {
+			Data []struct {
+				Name string `json:"name"`
+			} `json:"data"`
+		}
+		status := serve(t, router, http.MethodGet, "/recipes", "", &body)
+
+		assert.Equal(t, http.StatusOK, status)
+		names := []string{}
+		for _, recipe := range body.Data {
+			names = append(names, recipe.Name)
+		}
+		assert.Equal(t, []string{"Margherita", "Marinara"}, names)
+	})
+
+	t.Run("labels a recipe from the catalogue", func(t *testing.T) {
+		var body struct {
+			Data struct {
+				Labels struct {
+					Allergens []string `json:"allergens"`
+				} `json:"labels"`
+			} `json:"data"`
+		}
+		status := serve(t, router, http.MethodGet, "/recipes/00000000-0000-0000-0000-000000000000", "", &body)
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, []string{"gluten", "milk"}, body.Data.Labels.Allergens)
+	})
+
+	t.Run("balances a recipe", func(t *testing.T) {
+		var body struct {
+			Data struct {
+				Nutrition []struct {
+					Slices int `json:"slices"`
+				} `json:"nutrition"`
+			} `json:"data"`
+		}
+		status := serve(t, router, http.MethodPost, "/recipes/00000000-0000-0000-0000-000000000000/aggregate",
+			`{"pans": [{"shape": "round", "measures": {"diameter": "30"}, "slices": 6}]}`, &body)
+
+		assert.Equal(t, http.StatusOK, status)
+		if assert.Len(t, body.Data.Nutrition, 1) {
+			assert.Equal(t, 6, body.Data.Nutrition[0].Slices)
+		}
+	})
+
+	t.Run("reports a missing recipe", func(t *testing.T) {
+		status := serve(t, router, http.MethodGet, "/recipes/00000000-0000-0000-0000-0000000000ff", "", nil)
+
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+}
+
+func newInMemoryRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	recipes, err := memory.LoadFixtures(migrations.Fixtures())
+	require.NoError(t, err)
+	ingredients, err := catalogue.Parse(migrations.Catalogue())
+	require.NoError(t, err)
+
+	repository := memory.NewRecipeRepository(memory.WithCatalogue(ingredients))
+	for _, recipe := range recipes {
+		repository.Save(recipe)
+	}
+
+	// The balancer stub hands the recipe back for a single pan of its
+	// reference area.
+	balancer := &StubBalancerService{
+		BalanceFunc: func(ctx context.Context, recipe domain.Recipe, pans domain.Pans) (*domain.RecipeAggregate, error) {
+			return &domain.RecipeAggregate{
+				Recipe: recipe,
+				SplitIngredients: domain.SplitIngredients{
+					SplitDough:   []domain.Dough{recipe.Dough},
+					SplitTopping: []domain.Topping{recipe.Topping},
+				},
+			}, nil
+		},
+	}
+
+	router := gin.New()
+	apihttp.NewRecipeHandler(application.NewRecipeService(repository, createStubCalculatorService(), balancer)).
+		RegisterRoutes(router)
+	return router
+}
+
+func serve(t *testing.T, router http.Handler, method, path, body string, response any) int {
+	t.Helper()
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	if response != nil {
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), response), recorder.Body.String())
+	}
+	return recorder.Code
+}
@@ -0,0 +1,29 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/sqlstore/sqlstoretest"
+)
+
+// The SQLite run of the conformance tests lives with the unit tests of sqlstore.
+func TestMySQLRecipeRepositoryConformance(t *testing.T) {
+	db, err := SetupMySQLTestDb(t, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Cleanup(context.Background()) }()
+
+	sqlstoretest.TestRecipeRepository(t, db.DB, db.Dialect)
+}
+
+func TestPostgresRecipeRepositoryConformance(t *testing.T) {
+	db, err := SetupPostgresTestDb(t, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Cleanup(context.Background()) }()
+
+	sqlstoretest.TestRecipeRepository(t, db.DB, db.Dialect)
+}
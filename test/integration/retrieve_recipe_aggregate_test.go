@@ -2,7 +2,6 @@ package integration
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -11,7 +10,8 @@ import (
 
 	"github.com/cfioretti/recipe-manager/internal/recipe-manager/application"
 	"github.com/cfioretti/recipe-manager/internal/recipe-manager/domain"
-	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/mysql"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/sqlstore"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/sqlstore/sqlstoretest"
 )
 
 type StubCalculatorService struct {
@@ -44,7 +44,7 @@ func TestRecipeIntegration(t *testing.T) {
 	stubBalancer := createStubBalancerService()
 
 	service := application.NewRecipeService(
-		mysql.NewMySqlRecipeRepository(db.DB),
+		sqlstore.NewRecipeRepository(db.DB, db.Dialect),
 		stubCalculator,
 		stubBalancer,
 	)
@@ -79,15 +79,18 @@ func TestRecipeIntegration(t *testing.T) {
 			Topping:     topping,
 		}
 
-		stringDoughJSON := `{"salt": 5, "flour": 60, "water": 30, "evoOil": 3, "yeast": 2, "percentVariation": -10}`
-		stringToppingJSON := `{"referenceArea": 1200, "basil": 15, "evoOil": 15, "parmesanCheese": 20, "peeledTomatoes": 300, "mozzarellaCheese": 250}`
 		_, err = db.DB.Exec(`DELETE FROM recipes WHERE true`)
-		query := `INSERT INTO recipes (uuid, name, description, author, dough, topping) VALUES (?, ?, ?, ?, ?, ?)`
-		_, err = db.DB.Exec(query, testRecipe.Uuid, testRecipe.Name, testRecipe.Description, testRecipe.Author, stringDoughJSON, stringToppingJSON)
-		if err != nil {
+		if err := sqlstoretest.InsertRecipe(db.DB, db.Dialect, *testRecipe); err != nil {
 			t.Fatal(err)
 		}
 
+		storedRecipe, err := sqlstore.NewRecipeRepository(db.DB, db.Dialect).GetRecipeByUuid(ctx, testRecipe.Uuid)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"flour", "water", "salt", "evoOil", "yeast"}, ingredientNames(storedRecipe.Dough.Ingredients))
+		assert.Equal(t, "%", storedRecipe.Dough.Ingredients[0].Unit)
+		assert.Equal(t, float64(-10), storedRecipe.Dough.PercentVariation)
+		assert.Equal(t, float64(1200), storedRecipe.Topping.ReferenceArea)
+
 		pans := domain.Pans{
 			Pans: []domain.Pan{
 				{
@@ -172,6 +175,11 @@ func TestRecipeIntegration(t *testing.T) {
 		assert.Equal(t, expectedDough, result.Recipe.Dough)
 		assert.Equal(t, expectedSplitDough, result.SplitIngredients.SplitDough)
 		assert.Equal(t, expectedTopping, result.Recipe.Topping)
+		if assert.Len(t, result.Nutrition, 3) {
+			assert.Equal(t, domain.DefaultSlices, result.Nutrition[0].Slices)
+			assert.Empty(t, result.Nutrition[0].Unknown)
+			assert.Greater(t, result.Nutrition[0].Total.Kcal, result.Nutrition[1].Total.Kcal)
+		}
 	})
 
 	t.Run("Error - Recipe not found in repository", func(t *testing.T) {
@@ -205,7 +213,6 @@ func TestRecipeIntegration(t *testing.T) {
 				{Name: "yeast", Amount: 2},
 			},
 		}
-		doughJSON, _ := json.Marshal(dough)
 		testRecipe := &domain.Recipe{
 			Uuid:        uuid.New(),
 			Name:        "Test Recipe",
@@ -214,9 +221,7 @@ func TestRecipeIntegration(t *testing.T) {
 			Dough:       dough,
 		}
 
-		query := `INSERT INTO recipes (uuid, name, description, author, dough) VALUES (?, ?, ?, ?, ?)`
-		_, err = db.DB.Exec(query, testRecipe.Uuid, testRecipe.Name, testRecipe.Description, testRecipe.Author, string(doughJSON))
-		if err != nil {
+		if err := sqlstoretest.InsertRecipe(db.DB, db.Dialect, *testRecipe); err != nil {
 			t.Fatal(err)
 		}
 
@@ -323,3 +328,11 @@ func createStubBalancerService() *StubBalancerService {
 		},
 	}
 }
+
+func ingredientNames(ingredients []domain.Ingredient) []string {
+	names := make([]string, 0, len(ingredients))
+	for _, ingredient := range ingredients {
+		names = append(names, ingredient.Name)
+	}
+	return names
+}
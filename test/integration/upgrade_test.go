@@ -0,0 +1,42 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/sqlstore"
+	"github.com/cfioretti/recipe-manager/internal/recipe-manager/infrastructure/sqlstore/migrations"
+	embedded "github.com/cfioretti/recipe-manager/migrations"
+)
+
+// Databases created by the first docker-compose.yml already hold the default
+// recipe and its steps but no migrations table, so upgrading them applies
+// every migration again, seed data included.
+func TestUpgradeFromBaseline(t *testing.T) {
+	ctx := context.Background()
+	db, err := SetupBaselineMySQLTestDb(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Cleanup(ctx) }()
+
+	require.NoError(t, db.Migrate(embedded.Schema, migrations.SchemaMigrationsTable))
+	require.NoError(t, db.Migrate(embedded.Seed, migrations.SeedMigrationsTable))
+
+	recipe, err := sqlstore.NewRecipeRepository(db.DB, db.Dialect).GetRecipeByUuid(ctx, uuid.Nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Margherita", recipe.Name)
+	assert.Equal(t, 8.0, recipe.Dough.PercentVariation)
+	assert.Len(t, recipe.Dough.Ingredients, 5)
+	assert.Equal(t, 1200.0, recipe.Topping.ReferenceArea)
+	assert.Len(t, recipe.Topping.Ingredients, 5)
+	assert.Len(t, recipe.Steps.Steps, 10)
+
+	var recipes int
+	require.NoError(t, db.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM recipes").Scan(&recipes))
+	assert.Equal(t, 1, recipes)
+}